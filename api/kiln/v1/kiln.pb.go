@@ -0,0 +1,857 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: kiln/v1/kiln.proto
+
+// The Kiln gRPC API: typed access to the article archive and the scraper for
+// other services. It is served on the HTTP port, over HTTP/2, and takes the
+// same credentials as the JSON API (an API key as a bearer token, or Basic
+// auth).
+
+package kilnv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Article struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// id is the article's UUID, as in the detail page URL
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url         string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Source      string                 `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	Title       string                 `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	Author      string                 `protobuf:"bytes,5,opt,name=author,proto3" json:"author,omitempty"`
+	PublishedAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=published_at,json=publishedAt,proto3" json:"published_at,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	WordCount   int32                  `protobuf:"varint,9,opt,name=word_count,json=wordCount,proto3" json:"word_count,omitempty"`
+	// partial is set for articles saved as teasers
+	Partial    bool     `protobuf:"varint,10,opt,name=partial,proto3" json:"partial,omitempty"`
+	Read       bool     `protobuf:"varint,11,opt,name=read,proto3" json:"read,omitempty"`
+	Categories []string `protobuf:"bytes,12,rep,name=categories,proto3" json:"categories,omitempty"`
+	Tags       []string `protobuf:"bytes,13,rep,name=tags,proto3" json:"tags,omitempty"`
+	ImageUrl   string   `protobuf:"bytes,14,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
+	Summary    string   `protobuf:"bytes,15,opt,name=summary,proto3" json:"summary,omitempty"`
+	// The content is only set by GetArticle
+	ContentHtml   string `protobuf:"bytes,16,opt,name=content_html,json=contentHtml,proto3" json:"content_html,omitempty"`
+	ContentText   string `protobuf:"bytes,17,opt,name=content_text,json=contentText,proto3" json:"content_text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Article) Reset() {
+	*x = Article{}
+	mi := &file_kiln_v1_kiln_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Article) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Article) ProtoMessage() {}
+
+func (x *Article) ProtoReflect() protoreflect.Message {
+	mi := &file_kiln_v1_kiln_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Article.ProtoReflect.Descriptor instead.
+func (*Article) Descriptor() ([]byte, []int) {
+	return file_kiln_v1_kiln_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Article) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Article) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Article) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Article) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Article) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *Article) GetPublishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishedAt
+	}
+	return nil
+}
+
+func (x *Article) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Article) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Article) GetWordCount() int32 {
+	if x != nil {
+		return x.WordCount
+	}
+	return 0
+}
+
+func (x *Article) GetPartial() bool {
+	if x != nil {
+		return x.Partial
+	}
+	return false
+}
+
+func (x *Article) GetRead() bool {
+	if x != nil {
+		return x.Read
+	}
+	return false
+}
+
+func (x *Article) GetCategories() []string {
+	if x != nil {
+		return x.Categories
+	}
+	return nil
+}
+
+func (x *Article) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Article) GetImageUrl() string {
+	if x != nil {
+		return x.ImageUrl
+	}
+	return ""
+}
+
+func (x *Article) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *Article) GetContentHtml() string {
+	if x != nil {
+		return x.ContentHtml
+	}
+	return ""
+}
+
+func (x *Article) GetContentText() string {
+	if x != nil {
+		return x.ContentText
+	}
+	return ""
+}
+
+type ListArticlesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// query is a web-style search: words, "quoted phrases" and -excluded words
+	Query  string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Source string `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Author string `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	Tag    string `protobuf:"bytes,4,opt,name=tag,proto3" json:"tag,omitempty"`
+	// from and to bound the published date (or, without one, the date the
+	// article was saved); from is inclusive, to exclusive
+	From *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=from,proto3" json:"from,omitempty"`
+	To   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=to,proto3" json:"to,omitempty"`
+	// unread keeps the articles not marked read
+	Unread bool `protobuf:"varint,7,opt,name=unread,proto3" json:"unread,omitempty"`
+	// page_size defaults to 50, at most 500
+	PageSize int32 `protobuf:"varint,8,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// page_token is the next_page_token of the previous page
+	PageToken     string `protobuf:"bytes,9,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListArticlesRequest) Reset() {
+	*x = ListArticlesRequest{}
+	mi := &file_kiln_v1_kiln_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListArticlesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListArticlesRequest) ProtoMessage() {}
+
+func (x *ListArticlesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kiln_v1_kiln_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListArticlesRequest.ProtoReflect.Descriptor instead.
+func (*ListArticlesRequest) Descriptor() ([]byte, []int) {
+	return file_kiln_v1_kiln_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListArticlesRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *ListArticlesRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ListArticlesRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *ListArticlesRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *ListArticlesRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ListArticlesRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *ListArticlesRequest) GetUnread() bool {
+	if x != nil {
+		return x.Unread
+	}
+	return false
+}
+
+func (x *ListArticlesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListArticlesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListArticlesResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Articles []*Article             `protobuf:"bytes,1,rep,name=articles,proto3" json:"articles,omitempty"`
+	// next_page_token is empty on the last page
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListArticlesResponse) Reset() {
+	*x = ListArticlesResponse{}
+	mi := &file_kiln_v1_kiln_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListArticlesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListArticlesResponse) ProtoMessage() {}
+
+func (x *ListArticlesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_kiln_v1_kiln_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListArticlesResponse.ProtoReflect.Descriptor instead.
+func (*ListArticlesResponse) Descriptor() ([]byte, []int) {
+	return file_kiln_v1_kiln_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListArticlesResponse) GetArticles() []*Article {
+	if x != nil {
+		return x.Articles
+	}
+	return nil
+}
+
+func (x *ListArticlesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type GetArticleRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// id is the article's UUID
+	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetArticleRequest) Reset() {
+	*x = GetArticleRequest{}
+	mi := &file_kiln_v1_kiln_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetArticleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetArticleRequest) ProtoMessage() {}
+
+func (x *GetArticleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kiln_v1_kiln_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetArticleRequest.ProtoReflect.Descriptor instead.
+func (*GetArticleRequest) Descriptor() ([]byte, []int) {
+	return file_kiln_v1_kiln_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetArticleRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type TriggerScrapeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// retry_failed scrapes only the queued failed articles
+	RetryFailed bool `protobuf:"varint,1,opt,name=retry_failed,json=retryFailed,proto3" json:"retry_failed,omitempty"`
+	// dry_run reports what would be saved, without saving
+	DryRun        bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerScrapeRequest) Reset() {
+	*x = TriggerScrapeRequest{}
+	mi := &file_kiln_v1_kiln_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerScrapeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerScrapeRequest) ProtoMessage() {}
+
+func (x *TriggerScrapeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kiln_v1_kiln_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerScrapeRequest.ProtoReflect.Descriptor instead.
+func (*TriggerScrapeRequest) Descriptor() ([]byte, []int) {
+	return file_kiln_v1_kiln_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TriggerScrapeRequest) GetRetryFailed() bool {
+	if x != nil {
+		return x.RetryFailed
+	}
+	return false
+}
+
+func (x *TriggerScrapeRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type TriggerScrapeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerScrapeResponse) Reset() {
+	*x = TriggerScrapeResponse{}
+	mi := &file_kiln_v1_kiln_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerScrapeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerScrapeResponse) ProtoMessage() {}
+
+func (x *TriggerScrapeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_kiln_v1_kiln_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerScrapeResponse.ProtoReflect.Descriptor instead.
+func (*TriggerScrapeResponse) Descriptor() ([]byte, []int) {
+	return file_kiln_v1_kiln_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TriggerScrapeResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type WatchProgressRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// last_seq resumes a stream after the update with this seq
+	LastSeq       int32 `protobuf:"varint,2,opt,name=last_seq,json=lastSeq,proto3" json:"last_seq,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchProgressRequest) Reset() {
+	*x = WatchProgressRequest{}
+	mi := &file_kiln_v1_kiln_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchProgressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchProgressRequest) ProtoMessage() {}
+
+func (x *WatchProgressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kiln_v1_kiln_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchProgressRequest.ProtoReflect.Descriptor instead.
+func (*WatchProgressRequest) Descriptor() ([]byte, []int) {
+	return file_kiln_v1_kiln_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *WatchProgressRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *WatchProgressRequest) GetLastSeq() int32 {
+	if x != nil {
+		return x.LastSeq
+	}
+	return 0
+}
+
+type ProgressUpdate struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Seq   int32                  `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	// status is queued, starting, logging_in, scraping, paused, completed,
+	// failed or cancelled
+	Status         string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Message        string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	CurrentItem    int32  `protobuf:"varint,4,opt,name=current_item,json=currentItem,proto3" json:"current_item,omitempty"`
+	TotalItems     int32  `protobuf:"varint,5,opt,name=total_items,json=totalItems,proto3" json:"total_items,omitempty"`
+	ArticlesAdded  int32  `protobuf:"varint,6,opt,name=articles_added,json=articlesAdded,proto3" json:"articles_added,omitempty"`
+	ArticlesFailed int32  `protobuf:"varint,7,opt,name=articles_failed,json=articlesFailed,proto3" json:"articles_failed,omitempty"`
+	RunId          int32  `protobuf:"varint,8,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	// new_article_id is the UUID of an article just added
+	NewArticleId  string                 `protobuf:"bytes,9,opt,name=new_article_id,json=newArticleId,proto3" json:"new_article_id,omitempty"`
+	FailedUrl     string                 `protobuf:"bytes,10,opt,name=failed_url,json=failedUrl,proto3" json:"failed_url,omitempty"`
+	FailedStage   string                 `protobuf:"bytes,11,opt,name=failed_stage,json=failedStage,proto3" json:"failed_stage,omitempty"`
+	Error         string                 `protobuf:"bytes,12,opt,name=error,proto3" json:"error,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProgressUpdate) Reset() {
+	*x = ProgressUpdate{}
+	mi := &file_kiln_v1_kiln_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProgressUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProgressUpdate) ProtoMessage() {}
+
+func (x *ProgressUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_kiln_v1_kiln_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProgressUpdate.ProtoReflect.Descriptor instead.
+func (*ProgressUpdate) Descriptor() ([]byte, []int) {
+	return file_kiln_v1_kiln_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ProgressUpdate) GetSeq() int32 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *ProgressUpdate) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ProgressUpdate) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ProgressUpdate) GetCurrentItem() int32 {
+	if x != nil {
+		return x.CurrentItem
+	}
+	return 0
+}
+
+func (x *ProgressUpdate) GetTotalItems() int32 {
+	if x != nil {
+		return x.TotalItems
+	}
+	return 0
+}
+
+func (x *ProgressUpdate) GetArticlesAdded() int32 {
+	if x != nil {
+		return x.ArticlesAdded
+	}
+	return 0
+}
+
+func (x *ProgressUpdate) GetArticlesFailed() int32 {
+	if x != nil {
+		return x.ArticlesFailed
+	}
+	return 0
+}
+
+func (x *ProgressUpdate) GetRunId() int32 {
+	if x != nil {
+		return x.RunId
+	}
+	return 0
+}
+
+func (x *ProgressUpdate) GetNewArticleId() string {
+	if x != nil {
+		return x.NewArticleId
+	}
+	return ""
+}
+
+func (x *ProgressUpdate) GetFailedUrl() string {
+	if x != nil {
+		return x.FailedUrl
+	}
+	return ""
+}
+
+func (x *ProgressUpdate) GetFailedStage() string {
+	if x != nil {
+		return x.FailedStage
+	}
+	return ""
+}
+
+func (x *ProgressUpdate) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ProgressUpdate) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+var File_kiln_v1_kiln_proto protoreflect.FileDescriptor
+
+const file_kiln_v1_kiln_proto_rawDesc = "" +
+	"\n" +
+	"\x12kiln/v1/kiln.proto\x12\akiln.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa4\x04\n" +
+	"\aArticle\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\x12\x14\n" +
+	"\x05title\x18\x04 \x01(\tR\x05title\x12\x16\n" +
+	"\x06author\x18\x05 \x01(\tR\x06author\x12=\n" +
+	"\fpublished_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vpublishedAt\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x1d\n" +
+	"\n" +
+	"word_count\x18\t \x01(\x05R\twordCount\x12\x18\n" +
+	"\apartial\x18\n" +
+	" \x01(\bR\apartial\x12\x12\n" +
+	"\x04read\x18\v \x01(\bR\x04read\x12\x1e\n" +
+	"\n" +
+	"categories\x18\f \x03(\tR\n" +
+	"categories\x12\x12\n" +
+	"\x04tags\x18\r \x03(\tR\x04tags\x12\x1b\n" +
+	"\timage_url\x18\x0e \x01(\tR\bimageUrl\x12\x18\n" +
+	"\asummary\x18\x0f \x01(\tR\asummary\x12!\n" +
+	"\fcontent_html\x18\x10 \x01(\tR\vcontentHtml\x12!\n" +
+	"\fcontent_text\x18\x11 \x01(\tR\vcontentText\"\x9d\x02\n" +
+	"\x13ListArticlesRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x16\n" +
+	"\x06author\x18\x03 \x01(\tR\x06author\x12\x10\n" +
+	"\x03tag\x18\x04 \x01(\tR\x03tag\x12.\n" +
+	"\x04from\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x16\n" +
+	"\x06unread\x18\a \x01(\bR\x06unread\x12\x1b\n" +
+	"\tpage_size\x18\b \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\t \x01(\tR\tpageToken\"l\n" +
+	"\x14ListArticlesResponse\x12,\n" +
+	"\barticles\x18\x01 \x03(\v2\x10.kiln.v1.ArticleR\barticles\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"#\n" +
+	"\x11GetArticleRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"R\n" +
+	"\x14TriggerScrapeRequest\x12!\n" +
+	"\fretry_failed\x18\x01 \x01(\bR\vretryFailed\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\".\n" +
+	"\x15TriggerScrapeResponse\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"H\n" +
+	"\x14WatchProgressRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x19\n" +
+	"\blast_seq\x18\x02 \x01(\x05R\alastSeq\"\xb7\x03\n" +
+	"\x0eProgressUpdate\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x05R\x03seq\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12!\n" +
+	"\fcurrent_item\x18\x04 \x01(\x05R\vcurrentItem\x12\x1f\n" +
+	"\vtotal_items\x18\x05 \x01(\x05R\n" +
+	"totalItems\x12%\n" +
+	"\x0earticles_added\x18\x06 \x01(\x05R\rarticlesAdded\x12'\n" +
+	"\x0farticles_failed\x18\a \x01(\x05R\x0earticlesFailed\x12\x15\n" +
+	"\x06run_id\x18\b \x01(\x05R\x05runId\x12$\n" +
+	"\x0enew_article_id\x18\t \x01(\tR\fnewArticleId\x12\x1d\n" +
+	"\n" +
+	"failed_url\x18\n" +
+	" \x01(\tR\tfailedUrl\x12!\n" +
+	"\ffailed_stage\x18\v \x01(\tR\vfailedStage\x12\x14\n" +
+	"\x05error\x18\f \x01(\tR\x05error\x128\n" +
+	"\ttimestamp\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp2\xb1\x02\n" +
+	"\vKilnService\x12K\n" +
+	"\fListArticles\x12\x1c.kiln.v1.ListArticlesRequest\x1a\x1d.kiln.v1.ListArticlesResponse\x12:\n" +
+	"\n" +
+	"GetArticle\x12\x1a.kiln.v1.GetArticleRequest\x1a\x10.kiln.v1.Article\x12N\n" +
+	"\rTriggerScrape\x12\x1d.kiln.v1.TriggerScrapeRequest\x1a\x1e.kiln.v1.TriggerScrapeResponse\x12I\n" +
+	"\rWatchProgress\x12\x1d.kiln.v1.WatchProgressRequest\x1a\x17.kiln.v1.ProgressUpdate0\x01B-Z+github.com/tkilaker/kiln/api/kiln/v1;kilnv1b\x06proto3"
+
+var (
+	file_kiln_v1_kiln_proto_rawDescOnce sync.Once
+	file_kiln_v1_kiln_proto_rawDescData []byte
+)
+
+func file_kiln_v1_kiln_proto_rawDescGZIP() []byte {
+	file_kiln_v1_kiln_proto_rawDescOnce.Do(func() {
+		file_kiln_v1_kiln_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_kiln_v1_kiln_proto_rawDesc), len(file_kiln_v1_kiln_proto_rawDesc)))
+	})
+	return file_kiln_v1_kiln_proto_rawDescData
+}
+
+var file_kiln_v1_kiln_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_kiln_v1_kiln_proto_goTypes = []any{
+	(*Article)(nil),               // 0: kiln.v1.Article
+	(*ListArticlesRequest)(nil),   // 1: kiln.v1.ListArticlesRequest
+	(*ListArticlesResponse)(nil),  // 2: kiln.v1.ListArticlesResponse
+	(*GetArticleRequest)(nil),     // 3: kiln.v1.GetArticleRequest
+	(*TriggerScrapeRequest)(nil),  // 4: kiln.v1.TriggerScrapeRequest
+	(*TriggerScrapeResponse)(nil), // 5: kiln.v1.TriggerScrapeResponse
+	(*WatchProgressRequest)(nil),  // 6: kiln.v1.WatchProgressRequest
+	(*ProgressUpdate)(nil),        // 7: kiln.v1.ProgressUpdate
+	(*timestamppb.Timestamp)(nil), // 8: google.protobuf.Timestamp
+}
+var file_kiln_v1_kiln_proto_depIdxs = []int32{
+	8,  // 0: kiln.v1.Article.published_at:type_name -> google.protobuf.Timestamp
+	8,  // 1: kiln.v1.Article.created_at:type_name -> google.protobuf.Timestamp
+	8,  // 2: kiln.v1.Article.updated_at:type_name -> google.protobuf.Timestamp
+	8,  // 3: kiln.v1.ListArticlesRequest.from:type_name -> google.protobuf.Timestamp
+	8,  // 4: kiln.v1.ListArticlesRequest.to:type_name -> google.protobuf.Timestamp
+	0,  // 5: kiln.v1.ListArticlesResponse.articles:type_name -> kiln.v1.Article
+	8,  // 6: kiln.v1.ProgressUpdate.timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 7: kiln.v1.KilnService.ListArticles:input_type -> kiln.v1.ListArticlesRequest
+	3,  // 8: kiln.v1.KilnService.GetArticle:input_type -> kiln.v1.GetArticleRequest
+	4,  // 9: kiln.v1.KilnService.TriggerScrape:input_type -> kiln.v1.TriggerScrapeRequest
+	6,  // 10: kiln.v1.KilnService.WatchProgress:input_type -> kiln.v1.WatchProgressRequest
+	2,  // 11: kiln.v1.KilnService.ListArticles:output_type -> kiln.v1.ListArticlesResponse
+	0,  // 12: kiln.v1.KilnService.GetArticle:output_type -> kiln.v1.Article
+	5,  // 13: kiln.v1.KilnService.TriggerScrape:output_type -> kiln.v1.TriggerScrapeResponse
+	7,  // 14: kiln.v1.KilnService.WatchProgress:output_type -> kiln.v1.ProgressUpdate
+	11, // [11:15] is the sub-list for method output_type
+	7,  // [7:11] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_kiln_v1_kiln_proto_init() }
+func file_kiln_v1_kiln_proto_init() {
+	if File_kiln_v1_kiln_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_kiln_v1_kiln_proto_rawDesc), len(file_kiln_v1_kiln_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_kiln_v1_kiln_proto_goTypes,
+		DependencyIndexes: file_kiln_v1_kiln_proto_depIdxs,
+		MessageInfos:      file_kiln_v1_kiln_proto_msgTypes,
+	}.Build()
+	File_kiln_v1_kiln_proto = out.File
+	file_kiln_v1_kiln_proto_goTypes = nil
+	file_kiln_v1_kiln_proto_depIdxs = nil
+}
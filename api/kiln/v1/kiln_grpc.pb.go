@@ -0,0 +1,254 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: kiln/v1/kiln.proto
+
+// The Kiln gRPC API: typed access to the article archive and the scraper for
+// other services. It is served on the HTTP port, over HTTP/2, and takes the
+// same credentials as the JSON API (an API key as a bearer token, or Basic
+// auth).
+
+package kilnv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	KilnService_ListArticles_FullMethodName  = "/kiln.v1.KilnService/ListArticles"
+	KilnService_GetArticle_FullMethodName    = "/kiln.v1.KilnService/GetArticle"
+	KilnService_TriggerScrape_FullMethodName = "/kiln.v1.KilnService/TriggerScrape"
+	KilnService_WatchProgress_FullMethodName = "/kiln.v1.KilnService/WatchProgress"
+)
+
+// KilnServiceClient is the client API for KilnService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type KilnServiceClient interface {
+	// ListArticles lists articles, most recently saved first, one page at a
+	// time. Duplicates of other articles are left out.
+	ListArticles(ctx context.Context, in *ListArticlesRequest, opts ...grpc.CallOption) (*ListArticlesResponse, error)
+	// GetArticle returns an article with its content
+	GetArticle(ctx context.Context, in *GetArticleRequest, opts ...grpc.CallOption) (*Article, error)
+	// TriggerScrape queues a scrape job and returns its ID
+	TriggerScrape(ctx context.Context, in *TriggerScrapeRequest, opts ...grpc.CallOption) (*TriggerScrapeResponse, error)
+	// WatchProgress streams the progress of a scrape job until it finishes
+	WatchProgress(ctx context.Context, in *WatchProgressRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ProgressUpdate], error)
+}
+
+type kilnServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewKilnServiceClient(cc grpc.ClientConnInterface) KilnServiceClient {
+	return &kilnServiceClient{cc}
+}
+
+func (c *kilnServiceClient) ListArticles(ctx context.Context, in *ListArticlesRequest, opts ...grpc.CallOption) (*ListArticlesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListArticlesResponse)
+	err := c.cc.Invoke(ctx, KilnService_ListArticles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kilnServiceClient) GetArticle(ctx context.Context, in *GetArticleRequest, opts ...grpc.CallOption) (*Article, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Article)
+	err := c.cc.Invoke(ctx, KilnService_GetArticle_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kilnServiceClient) TriggerScrape(ctx context.Context, in *TriggerScrapeRequest, opts ...grpc.CallOption) (*TriggerScrapeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TriggerScrapeResponse)
+	err := c.cc.Invoke(ctx, KilnService_TriggerScrape_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kilnServiceClient) WatchProgress(ctx context.Context, in *WatchProgressRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ProgressUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &KilnService_ServiceDesc.Streams[0], KilnService_WatchProgress_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchProgressRequest, ProgressUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type KilnService_WatchProgressClient = grpc.ServerStreamingClient[ProgressUpdate]
+
+// KilnServiceServer is the server API for KilnService service.
+// All implementations must embed UnimplementedKilnServiceServer
+// for forward compatibility.
+type KilnServiceServer interface {
+	// ListArticles lists articles, most recently saved first, one page at a
+	// time. Duplicates of other articles are left out.
+	ListArticles(context.Context, *ListArticlesRequest) (*ListArticlesResponse, error)
+	// GetArticle returns an article with its content
+	GetArticle(context.Context, *GetArticleRequest) (*Article, error)
+	// TriggerScrape queues a scrape job and returns its ID
+	TriggerScrape(context.Context, *TriggerScrapeRequest) (*TriggerScrapeResponse, error)
+	// WatchProgress streams the progress of a scrape job until it finishes
+	WatchProgress(*WatchProgressRequest, grpc.ServerStreamingServer[ProgressUpdate]) error
+	mustEmbedUnimplementedKilnServiceServer()
+}
+
+// UnimplementedKilnServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedKilnServiceServer struct{}
+
+func (UnimplementedKilnServiceServer) ListArticles(context.Context, *ListArticlesRequest) (*ListArticlesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListArticles not implemented")
+}
+func (UnimplementedKilnServiceServer) GetArticle(context.Context, *GetArticleRequest) (*Article, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetArticle not implemented")
+}
+func (UnimplementedKilnServiceServer) TriggerScrape(context.Context, *TriggerScrapeRequest) (*TriggerScrapeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TriggerScrape not implemented")
+}
+func (UnimplementedKilnServiceServer) WatchProgress(*WatchProgressRequest, grpc.ServerStreamingServer[ProgressUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchProgress not implemented")
+}
+func (UnimplementedKilnServiceServer) mustEmbedUnimplementedKilnServiceServer() {}
+func (UnimplementedKilnServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeKilnServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to KilnServiceServer will
+// result in compilation errors.
+type UnsafeKilnServiceServer interface {
+	mustEmbedUnimplementedKilnServiceServer()
+}
+
+func RegisterKilnServiceServer(s grpc.ServiceRegistrar, srv KilnServiceServer) {
+	// If the following call pancis, it indicates UnimplementedKilnServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&KilnService_ServiceDesc, srv)
+}
+
+func _KilnService_ListArticles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListArticlesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KilnServiceServer).ListArticles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KilnService_ListArticles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KilnServiceServer).ListArticles(ctx, req.(*ListArticlesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KilnService_GetArticle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetArticleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KilnServiceServer).GetArticle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KilnService_GetArticle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KilnServiceServer).GetArticle(ctx, req.(*GetArticleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KilnService_TriggerScrape_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TriggerScrapeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KilnServiceServer).TriggerScrape(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KilnService_TriggerScrape_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KilnServiceServer).TriggerScrape(ctx, req.(*TriggerScrapeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KilnService_WatchProgress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchProgressRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KilnServiceServer).WatchProgress(m, &grpc.GenericServerStream[WatchProgressRequest, ProgressUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type KilnService_WatchProgressServer = grpc.ServerStreamingServer[ProgressUpdate]
+
+// KilnService_ServiceDesc is the grpc.ServiceDesc for KilnService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var KilnService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kiln.v1.KilnService",
+	HandlerType: (*KilnServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListArticles",
+			Handler:    _KilnService_ListArticles_Handler,
+		},
+		{
+			MethodName: "GetArticle",
+			Handler:    _KilnService_GetArticle_Handler,
+		},
+		{
+			MethodName: "TriggerScrape",
+			Handler:    _KilnService_TriggerScrape_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchProgress",
+			Handler:       _KilnService_WatchProgress_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "kiln/v1/kiln.proto",
+}
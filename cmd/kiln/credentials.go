@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/joho/godotenv"
+	"github.com/tkilaker/kiln/internal/config"
+	"github.com/tkilaker/kiln/internal/credentials"
+	"github.com/tkilaker/kiln/internal/database"
+	"golang.org/x/term"
+)
+
+// credentialsName matches the names credentials are stored as, like source
+// names
+var credentialsName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+const credentialsUsage = `Usage: kiln credentials COMMAND
+
+Commands:
+  keygen                      print a new credentials key
+  list                        list the stored credentials
+  set NAME [--username USER]  store the username and password for NAME,
+                              reading the password from the terminal or stdin
+  delete NAME                 remove the credentials stored as NAME
+  rotate --new-key-file FILE  seal every credential again under a new key
+
+Sources use stored credentials with the reference store:NAME; Gasetten uses
+those stored as gasetten when GASETTEN_USER is not set.
+`
+
+// runCredentials manages the encrypted credentials store
+func runCredentials(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, credentialsUsage)
+		return fmt.Errorf("a credentials command is required")
+	}
+
+	switch command, args := args[0], args[1:]; command {
+	case "keygen":
+		key, err := credentials.NewKey()
+		if err != nil {
+			return err
+		}
+		fmt.Println(key)
+		return nil
+	case "list":
+		return withCredentials(func(ctx context.Context, store *credentials.Store) error {
+			return listCredentials(ctx, store)
+		})
+	case "set":
+		return setCredentials(args)
+	case "delete":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: kiln credentials delete NAME")
+		}
+		return withCredentials(func(ctx context.Context, store *credentials.Store) error {
+			if err := store.Delete(ctx, args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted the credentials stored as %s\n", args[0])
+			return nil
+		})
+	case "rotate":
+		return rotateCredentials(args)
+	default:
+		fmt.Fprint(os.Stderr, credentialsUsage)
+		return fmt.Errorf("unknown credentials command %q", command)
+	}
+}
+
+// withCredentials opens the credentials store with the configured key and
+// calls fn with it
+func withCredentials(fn func(ctx context.Context, store *credentials.Store) error) error {
+	ctx := context.Background()
+	_ = godotenv.Load()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	db, err := openDatabase(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	store, err := openCredentials(db, cfg)
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return credentials.ErrNoKey
+	}
+	return fn(ctx, store)
+}
+
+// openCredentials opens the credentials store, or returns nil when no key
+// is configured
+func openCredentials(db *database.DB, cfg *config.Config) (*credentials.Store, error) {
+	key, err := credentials.LoadKey(cfg.CredentialsKey, cfg.CredentialsKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, nil
+	}
+	return credentials.New(db, key)
+}
+
+// listCredentials prints the stored credentials, without their secrets
+func listCredentials(ctx context.Context, store *credentials.Store) error {
+	stored, err := store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "NAME\tUPDATED\tKEY\n")
+	for _, c := range stored {
+		key := c.KeyID
+		if key != store.KeyID() {
+			key += " (not the current key)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Name, c.UpdatedAt.Local().Format("2006-01-02 15:04"), key)
+	}
+	return tw.Flush()
+}
+
+// setCredentials stores a username and password. The password is read
+// without echo from a terminal, or as the first line of stdin.
+func setCredentials(args []string) error {
+	fs := flag.NewFlagSet("credentials set", flag.ExitOnError)
+	username := fs.String("username", "", "the username (prompted for when not given)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: kiln credentials set NAME [--username USER]\n")
+		fs.PrintDefaults()
+	}
+	// Allow the name before the flags
+	var name string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if name == "" && fs.NArg() == 1 {
+		name = fs.Arg(0)
+	}
+	if name == "" || !credentialsName.MatchString(name) {
+		fs.Usage()
+		return fmt.Errorf("a name of lowercase letters, digits and dashes is required")
+	}
+
+	stdin := bufio.NewReader(os.Stdin)
+	if *username == "" {
+		fmt.Fprintf(os.Stderr, "Username for %s: ", name)
+		line, err := stdin.ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("failed to read username: %w", err)
+		}
+		*username = strings.TrimSpace(line)
+	}
+	password, err := readPassword(stdin, name)
+	if err != nil {
+		return err
+	}
+	if *username == "" || password == "" {
+		return fmt.Errorf("username and password must not be empty")
+	}
+
+	return withCredentials(func(ctx context.Context, store *credentials.Store) error {
+		if err := store.Set(ctx, name, *username, password); err != nil {
+			return err
+		}
+		fmt.Printf("Stored the credentials for %s; reference them as store:%s\n", name, name)
+		return nil
+	})
+}
+
+// readPassword reads a password without echo from a terminal, else the
+// next line of stdin
+func readPassword(stdin *bufio.Reader, name string) (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "Password for %s: ", name)
+		password, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+		return string(password), nil
+	}
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// rotateCredentials seals the stored credentials again under a new key
+func rotateCredentials(args []string) error {
+	fs := flag.NewFlagSet("credentials rotate", flag.ExitOnError)
+	newKeyFile := fs.String("new-key-file", "", "file holding the new key (from kiln credentials keygen)")
+	fs.Parse(args)
+	if *newKeyFile == "" {
+		fs.Usage()
+		return fmt.Errorf("--new-key-file is required")
+	}
+	newKey, err := credentials.LoadKey("", *newKeyFile)
+	if err != nil {
+		return err
+	}
+	if newKey == nil {
+		return fmt.Errorf("%s is empty", *newKeyFile)
+	}
+
+	return withCredentials(func(ctx context.Context, store *credentials.Store) error {
+		n, err := store.Rotate(ctx, newKey)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Sealed %d credentials with the new key. Set CREDENTIALS_KEY or CREDENTIALS_KEY_FILE to it and restart Kiln.\n", n)
+		return nil
+	})
+}
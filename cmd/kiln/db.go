@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/tkilaker/kiln/internal/config"
+	"github.com/tkilaker/kiln/internal/maintenance"
+	"github.com/tkilaker/kiln/internal/media"
+)
+
+// runDB runs a database subcommand
+func runDB(args []string) error {
+	if len(args) == 0 || args[0] != "maintain" {
+		fmt.Fprintf(os.Stderr, "Usage: kiln db maintain [--skip-vacuum]\n")
+		return fmt.Errorf("unknown db command")
+	}
+	return runMaintain(args[1:])
+}
+
+// runMaintain vacuums and analyzes the database and prints a JSON report of
+// table and index sizes, orphaned mirrored images and duplicate URLs
+func runMaintain(args []string) error {
+	fs := flag.NewFlagSet("db maintain", flag.ExitOnError)
+	skipVacuum := fs.Bool("skip-vacuum", false, "only report, without running VACUUM (ANALYZE)")
+	fs.Parse(args)
+
+	ctx := context.Background()
+	_ = godotenv.Load()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	db, err := openDatabase(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	opts := maintenance.Options{Vacuum: !*skipVacuum}
+	if cfg.MediaStorageURL != "" {
+		store, err := openStore(cfg, cfg.MediaStorageURL)
+		if err != nil {
+			return fmt.Errorf("failed to open media storage: %w", err)
+		}
+		opts.Media = media.NewMirror(store)
+	}
+
+	report, err := maintenance.Run(ctx, db, opts)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
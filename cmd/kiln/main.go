@@ -6,17 +6,55 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
+	_ "time/tzdata" // TIMEZONE works without zoneinfo in the image
 
 	"github.com/joho/godotenv"
+	"github.com/tkilaker/kiln/internal/archive"
 	"github.com/tkilaker/kiln/internal/config"
 	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/export"
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/internal/media"
+	"github.com/tkilaker/kiln/internal/notify"
+	"github.com/tkilaker/kiln/internal/peersync"
+	"github.com/tkilaker/kiln/internal/retention"
 	"github.com/tkilaker/kiln/internal/scraper"
 	"github.com/tkilaker/kiln/internal/server"
+	"github.com/tkilaker/kiln/internal/storage"
+	"github.com/tkilaker/kiln/internal/summary"
+	"github.com/tkilaker/kiln/internal/vault"
+	"github.com/tkilaker/kiln/internal/wayback"
 )
 
 func main() {
-	if err := run(); err != nil {
+	var command string
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	var err error
+	switch command {
+	case "smoke":
+		err = runSmoke(os.Args[2:])
+	case "restore":
+		err = runRestore(os.Args[2:])
+	case "prune":
+		err = runPrune(os.Args[2:])
+	case "db":
+		err = runDB(os.Args[2:])
+	case "scrape":
+		err = runScrapeCommand(os.Args[2:])
+	case "tui":
+		err = runTUI(os.Args[2:])
+	case "credentials":
+		err = runCredentials(os.Args[2:])
+	default:
+		err = run()
+	}
+	if err != nil {
 		log.Fatalf("Application error: %v", err)
 	}
 }
@@ -33,26 +71,172 @@ func run() error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	logFile, err := logging.Setup(logOptions(cfg))
+	if err != nil {
+		return fmt.Errorf("failed to set up logging: %w", err)
+	}
+	defer logFile.Close()
+
+	// Scraped dates without a zone, schedules and every rendered time use
+	// the configured zone
+	time.Local = cfg.Timezone
+
 	log.Println("Starting Kiln...")
 
 	// Connect to database
-	db, err := database.New(ctx, cfg.DatabaseURL)
+	db, err := openDatabase(ctx, cfg)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 	defer db.Close()
 	log.Println("Connected to database")
 
+	// Mirror article images into our own storage
+	var mirror *media.Mirror
+	if cfg.MediaStorageURL != "" {
+		store, err := openStore(cfg, cfg.MediaStorageURL)
+		if err != nil {
+			return fmt.Errorf("failed to open media storage: %w", err)
+		}
+		mirror = media.NewMirror(store)
+		logging.Infof("Mirroring article images")
+	}
+
+	// Where captures of failed pages go
+	diagnostics, err := openDiagnostics(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to open diagnostics storage: %w", err)
+	}
+
+	// Keep the original article pages for extracting them again later
+	var pages *archive.Archive
+	if cfg.ArchiveStorageURL != "" {
+		store, err := openStore(cfg, cfg.ArchiveStorageURL)
+		if err != nil {
+			return fmt.Errorf("failed to open archive storage: %w", err)
+		}
+		pages, err = archive.New(store, cfg.ArchiveFormat)
+		if err != nil {
+			return err
+		}
+		logging.Infof("Archiving article pages as %s", cfg.ArchiveFormat)
+	}
+
+	// Back up scraped articles to the Wayback Machine
+	var submitter *wayback.Submitter
+	if cfg.WaybackSubmit {
+		submitter = wayback.New(db, cfg.WaybackAccessKey, cfg.WaybackSecretKey)
+		go submitter.Run(ctx)
+		logging.Infof("Submitting articles to the Wayback Machine")
+	}
+
+	// Summarize new articles for the feed item descriptions
+	var summarizer *summary.Summarizer
+	if cfg.FeedItemContent == config.FeedItemSummary {
+		summarizer = summary.New(db, cfg.SummaryAPIURL, cfg.SummaryAPIKey, cfg.SummaryModel)
+		go summarizer.Run(ctx)
+		logging.Infof("Summarizing articles with %s", cfg.SummaryModel)
+	}
+
+	// Site URLs and selectors, with the site profiles in SITE_PROFILES_DIR
+	// and any overrides from SOURCES_FILE
+	sources, err := scraper.LoadSources(cfg.ProfilesDir, cfg.SourcesFile)
+	if err != nil {
+		return fmt.Errorf("failed to load source configuration: %w", err)
+	}
+
+	// Source credentials stored encrypted in the database
+	credentialStore, err := openCredentials(db, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to open credentials store: %w", err)
+	}
+	secrets, err := openVault(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
 	// Initialize scraper
-	scraper, err := scraper.New(cfg.GasettenUser, cfg.GasettenPass, db, cfg.ScraperHeadless)
+	scraper, err := scraper.New(db, scraper.Options{
+		Username: cfg.GasettenUser,
+		Password: cfg.GasettenPass,
+		Headless: cfg.ScraperHeadless,
+		Media:    mirror,
+		Archive:  pages,
+		Wayback:  submitter,
+		Comments: cfg.ScrapeComments,
+		Notifier: notify.New(cfg.NotifyWebhookURL),
+
+		ControlURL:  cfg.ChromeURL,
+		Sources:     sources,
+		Credentials: credentialStore,
+		Vault:       secrets,
+		Diagnostics: diagnostics,
+		Summarizer:  summarizer,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to initialize scraper: %w", err)
 	}
 	defer scraper.Close()
 	log.Println("Initialized scraper")
 
+	// Run the scrapes queued from the UI
+	go scraper.RunJobs(ctx)
+
+	// Scrape sources on the schedules set on /sources
+	go scraper.RunSchedules(ctx)
+
+	// Keep the Gasetten session warm between scrapes
+	if cfg.KeepAlive > 0 {
+		go scraper.KeepAlive(ctx, cfg.KeepAlive)
+	}
+
+	// Pull changes from peer instances
+	if len(cfg.SyncPeers) > 0 {
+		peers := make([]peersync.Peer, 0, len(cfg.SyncPeers))
+		for _, peerURL := range cfg.SyncPeers {
+			peers = append(peers, peersync.Peer{URL: peerURL, APIKey: cfg.SyncAPIKey})
+		}
+		go peersync.NewPuller(db, peers, cfg.SyncInterval).Run(ctx)
+		logging.Infof("Syncing with %d peers every %s", len(peers), cfg.SyncInterval)
+	}
+
+	// Nightly export snapshots to remote storage
+	if cfg.ExportSchedule != "" {
+		store, err := openStore(cfg, cfg.StorageURL)
+		if err != nil {
+			return fmt.Errorf("failed to open storage: %w", err)
+		}
+		key, err := export.ParseKey(cfg.ExportEncryptionKey)
+		if err != nil {
+			return fmt.Errorf("invalid EXPORT_ENCRYPTION_KEY: %w", err)
+		}
+		exporter := export.NewExporter(db, store, cfg.ExportFullEvery, cfg.ExportKeepFull, key)
+		scheduler, err := export.NewScheduler(exporter, notify.New(cfg.NotifyWebhookURL), cfg.ExportSchedule)
+		if err != nil {
+			return err
+		}
+		go scheduler.Run(ctx)
+	}
+
+	// Delete articles outside the retention policy every night
+	if policy := retentionPolicy(cfg); policy.Enabled() {
+		pruner, err := retention.NewPruner(db, policy, cfg.RetentionDryRun, notify.New(cfg.NotifyWebhookURL), cfg.RetentionSchedule)
+		if err != nil {
+			return err
+		}
+		go pruner.Run(ctx)
+		if cfg.RetentionDryRun {
+			logging.Infof("Retention policy (dry run): articles %s", policy)
+		} else {
+			logging.Infof("Retention policy: deleting articles %s", policy)
+		}
+	}
+
 	// Create server
-	srv := server.New(db, scraper, cfg)
+	srv, err := server.New(ctx, db, scraper, mirror, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize server: %w", err)
+	}
 	log.Println("Initialized server")
 
 	// Handle graceful shutdown
@@ -66,6 +250,129 @@ func run() error {
 
 	// Start server
 	addr := fmt.Sprintf(":%d", cfg.Port)
-	log.Printf("Server starting on http://localhost%s", addr)
+	if srv.TLSEnabled() {
+		logging.Infof("Server starting on https://localhost:%d", cfg.HTTPSPort)
+	} else {
+		logging.Infof("Server starting on http://localhost%s", addr)
+	}
 	return srv.Start(addr)
 }
+
+// openDatabase connects to the database with the configured pool and
+// content compression settings
+func openDatabase(ctx context.Context, cfg *config.Config) (*database.DB, error) {
+	secrets, err := openVault(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	databaseURL := cfg.DatabaseURL
+	if path, field, ok := vault.ParseRef(databaseURL); ok {
+		if field == "" {
+			field = "url"
+		}
+		databaseURL, err = secrets.Field(ctx, path, field)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var credentials func(ctx context.Context) (string, string, error)
+	if path, _, ok := vault.ParseRef(cfg.DatabaseCredentials); ok {
+		credentials = func(ctx context.Context) (string, string, error) {
+			return secrets.Credentials(ctx, path)
+		}
+	}
+
+	db, err := database.New(ctx, databaseURL, database.PoolOptions{
+		MaxConns:           cfg.DBMaxConns,
+		MinConns:           cfg.DBMinConns,
+		MaxConnLifetime:    cfg.DBMaxConnLifetime,
+		MaxConnIdleTime:    cfg.DBMaxConnIdleTime,
+		HealthCheckPeriod:  cfg.DBHealthCheckPeriod,
+		StatementCache:     cfg.DBStatementCache,
+		StatementCacheSize: cfg.DBStatementCacheSize,
+		Credentials:        credentials,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.SetContentCompression(cfg.ContentCompression); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// vaultClient is the one Vault client of the process, shared by the
+// database and the scraper so a single token is kept renewed
+var vaultClient struct {
+	once   sync.Once
+	client *vault.Client
+	err    error
+}
+
+// openVault returns the Vault client, renewing its leases in the
+// background until ctx ends, or nil when no Vault server is configured. The
+// client is made on the first call, so ctx should last as long as the
+// command.
+func openVault(ctx context.Context, cfg *config.Config) (*vault.Client, error) {
+	vaultClient.once.Do(func() {
+		if !cfg.VaultEnabled() {
+			return
+		}
+		vaultClient.client, vaultClient.err = vault.New(vault.Options{
+			Addr:      cfg.VaultAddr,
+			Token:     cfg.VaultToken,
+			Namespace: cfg.VaultNamespace,
+			RoleID:    cfg.VaultRoleID,
+			SecretID:  cfg.VaultSecretID,
+		})
+		if vaultClient.err == nil {
+			go vaultClient.client.Run(ctx)
+		}
+	})
+	return vaultClient.client, vaultClient.err
+}
+
+// logOptions returns the configured log output
+func logOptions(cfg *config.Config) logging.Options {
+	return logging.Options{
+		Level:      cfg.LogLevel,
+		Format:     cfg.LogFormat,
+		File:       cfg.LogFile,
+		MaxSizeMB:  cfg.LogFileMaxSizeMB,
+		MaxBackups: cfg.LogFileMaxBackups,
+	}
+}
+
+// retentionPolicy returns the configured retention policy
+func retentionPolicy(cfg *config.Config) retention.Policy {
+	return retention.Policy{
+		MaxAge:       time.Duration(cfg.RetentionMaxAgeDays) * 24 * time.Hour,
+		MaxPerSource: cfg.RetentionMaxPerSource,
+	}
+}
+
+// openDiagnostics opens the storage for captures of failed pages:
+// DIAGNOSTICS_STORAGE_URL if set, else DIAGNOSTICS_DIR on local disk. It
+// returns nil when both are empty, which disables the captures.
+func openDiagnostics(cfg *config.Config) (storage.Store, error) {
+	if cfg.DiagnosticsStorageURL != "" {
+		return openStore(cfg, cfg.DiagnosticsStorageURL)
+	}
+	if cfg.DiagnosticsDir == "" {
+		return nil, nil
+	}
+	return storage.NewLocal(cfg.DiagnosticsDir)
+}
+
+// openStore opens the storage at storageURL with the configured S3 settings
+func openStore(cfg *config.Config, storageURL string) (storage.Store, error) {
+	return storage.Open(storage.Config{
+		URL:        storageURL,
+		S3Endpoint: cfg.StorageS3Endpoint,
+		S3Region:   cfg.StorageS3Region,
+		AccessKey:  cfg.StorageAccessKey,
+		SecretKey:  cfg.StorageSecretKey,
+	})
+}
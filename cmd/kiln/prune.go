@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/joho/godotenv"
+	"github.com/tkilaker/kiln/internal/config"
+	"github.com/tkilaker/kiln/internal/retention"
+)
+
+// runPrune applies the configured retention policy once, or with --dry-run
+// prints what it would delete
+func runPrune(args []string) error {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "only report what would be deleted")
+	fs.Parse(args)
+
+	ctx := context.Background()
+	_ = godotenv.Load()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	policy := retentionPolicy(cfg)
+	if !policy.Enabled() {
+		return fmt.Errorf("set RETENTION_MAX_AGE_DAYS and/or RETENTION_MAX_PER_SOURCE")
+	}
+
+	db, err := openDatabase(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	report, err := retention.Prune(ctx, db, policy, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Policy: delete articles %s\n", policy)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "SOURCE\tARTICLES\n")
+	for _, source := range report.Sources {
+		fmt.Fprintf(tw, "%s\t%d\n", source.Name, source.Count)
+	}
+	tw.Flush()
+	fmt.Println(report)
+	return nil
+}
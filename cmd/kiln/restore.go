@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/tkilaker/kiln/internal/config"
+	"github.com/tkilaker/kiln/internal/export"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+// runRestore loads export snapshots into the database: the files given as
+// arguments, in order, or with --latest the newest full snapshot in
+// STORAGE_URL and the incrementals after it
+func runRestore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	latest := fs.Bool("latest", false, "restore the newest snapshots from STORAGE_URL")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: kiln restore [--latest] [snapshot.jsonl.gz[.enc] ...]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *latest == (fs.NArg() > 0) {
+		fs.Usage()
+		return fmt.Errorf("give either snapshot files or --latest")
+	}
+
+	ctx := context.Background()
+	_ = godotenv.Load()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	key, err := export.ParseKey(cfg.ExportEncryptionKey)
+	if err != nil {
+		return fmt.Errorf("invalid EXPORT_ENCRYPTION_KEY: %w", err)
+	}
+
+	db, err := openDatabase(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	started := time.Now()
+	total := 0
+	if *latest {
+		if cfg.StorageURL == "" {
+			return fmt.Errorf("STORAGE_URL is not set")
+		}
+		store, err := openStore(cfg, cfg.StorageURL)
+		if err != nil {
+			return fmt.Errorf("failed to open storage: %w", err)
+		}
+		total, err = export.RestoreLatest(ctx, db, store, key)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, name := range fs.Args() {
+			f, err := os.Open(name)
+			if err != nil {
+				return fmt.Errorf("failed to open snapshot: %w", err)
+			}
+			snapshot, err := export.OpenSnapshot(name, f, key)
+			if err != nil {
+				f.Close()
+				return fmt.Errorf("failed to open %s: %w", name, err)
+			}
+			read, written, err := export.Restore(ctx, db, snapshot)
+			f.Close()
+			total += written
+			if err != nil {
+				return fmt.Errorf("failed to restore %s: %w", name, err)
+			}
+			logging.Infof("Restored %s (%d articles, %d written)", name, read, written)
+		}
+	}
+
+	logging.Infof("Restore finished: %d articles written in %s", total, time.Since(started).Round(time.Millisecond))
+	return nil
+}
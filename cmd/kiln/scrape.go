@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/tkilaker/kiln/internal/config"
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/internal/scraper"
+)
+
+// runScrapeCommand discovers and extracts articles without saving anything
+// and prints what a scrape would add, e.g. when tuning link filters and
+// selectors. Scrapes that save articles run in the server.
+func runScrapeCommand(args []string) error {
+	fs := flag.NewFlagSet("scrape", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "report what would be added without saving anything (required)")
+	source := fs.String("source", "", "only discover articles of this source")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: kiln scrape --dry-run [--source NAME] [ARTICLE_URL...]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if !*dryRun {
+		fs.Usage()
+		return fmt.Errorf("only --dry-run scrapes can be run from the command line")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	_ = godotenv.Load()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	time.Local = cfg.Timezone
+
+	// The log file belongs to the server, which rotates it
+	opts := logOptions(cfg)
+	opts.File = ""
+	if _, err := logging.Setup(opts); err != nil {
+		return fmt.Errorf("failed to set up logging: %w", err)
+	}
+
+	req := scraper.JobRequest{Kind: scraper.JobScrape, Trigger: database.RunTriggerManual, Source: *source}
+	label := "dry run of scrape"
+	if *source != "" {
+		label += " of " + *source
+	}
+	if fs.NArg() > 0 {
+		req = scraper.JobRequest{Kind: scraper.JobURLs, Trigger: database.RunTriggerURL}
+		for _, raw := range fs.Args() {
+			articleURL, err := scraper.NormalizeArticleURL(raw)
+			if err != nil {
+				return fmt.Errorf("%s: %w", raw, err)
+			}
+			req.URLs = append(req.URLs, articleURL)
+		}
+		label = fmt.Sprintf("dry run of %d URLs", len(req.URLs))
+	}
+
+	db, err := openDatabase(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	sources, err := scraper.LoadSources(cfg.ProfilesDir, cfg.SourcesFile)
+	if err != nil {
+		return fmt.Errorf("failed to load source configuration: %w", err)
+	}
+
+	diagnostics, err := openDiagnostics(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to open diagnostics storage: %w", err)
+	}
+
+	credentialStore, err := openCredentials(db, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to open credentials store: %w", err)
+	}
+	secrets, err := openVault(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	// Media, archiving and Wayback submissions are left out; a dry run
+	// would skip them anyway
+	s, err := scraper.New(db, scraper.Options{
+		Username: cfg.GasettenUser,
+		Password: cfg.GasettenPass,
+		Headless: cfg.ScraperHeadless,
+		Comments: cfg.ScrapeComments,
+
+		ControlURL:  cfg.ChromeURL,
+		Sources:     sources,
+		Credentials: credentialStore,
+		Vault:       secrets,
+		Diagnostics: diagnostics,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize scraper: %w", err)
+	}
+	defer s.Close()
+
+	preview, err := s.DryRun(ctx, label, req)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "ACTION\tTITLE\tURL\tREASON\n")
+	counts := map[string]int{}
+	for _, item := range preview {
+		counts[item.Action]++
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", item.Action, item.Title, item.URL, item.Reason)
+	}
+	tw.Flush()
+	fmt.Printf("Would add %d and update %d articles; %d skipped, %d failed. Nothing was saved.\n",
+		counts[scraper.PreviewAdd], counts[scraper.PreviewUpdate], counts[scraper.PreviewSkip], counts[scraper.PreviewFail])
+	return err
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/smoke"
+)
+
+// runSmoke exercises a deployed instance and prints a pass/fail report
+func runSmoke(args []string) error {
+	fs := flag.NewFlagSet("smoke", flag.ExitOnError)
+	baseURL := fs.String("base-url", "", "base URL of the Kiln instance (required)")
+	apiKey := fs.String("api-key", os.Getenv("KILN_API_KEY"), "API key for authenticated checks")
+	user := fs.String("user", "", "username for a browser session login")
+	password := fs.String("password", os.Getenv("KILN_PASSWORD"), "password for a browser session login")
+	timeout := fs.Duration("timeout", 15*time.Second, "timeout per check")
+	scrapeTimeout := fs.Duration("scrape-timeout", 5*time.Minute, "timeout of the dry-run scrape")
+	fs.Parse(args)
+
+	if *baseURL == "" {
+		fs.Usage()
+		return fmt.Errorf("--base-url is required")
+	}
+
+	results := smoke.Run(context.Background(), smoke.Options{
+		BaseURL:  *baseURL,
+		APIKey:   *apiKey,
+		Username: *user,
+		Password: *password,
+		Timeout:  *timeout,
+
+		ScrapeTimeout: *scrapeTimeout,
+	})
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "CHECK\tRESULT\tTIME\tDETAIL\n")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Name, r.Status, r.Duration.Round(time.Millisecond), r.Detail)
+	}
+	tw.Flush()
+
+	if !smoke.Passed(results) {
+		return fmt.Errorf("smoke test failed against %s", *baseURL)
+	}
+	fmt.Println("All checks passed")
+	return nil
+}
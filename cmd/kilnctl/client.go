@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// client calls the HTTP API of a Kiln instance with an API key
+type client struct {
+	base   *url.URL
+	apiKey string
+	http   *http.Client
+}
+
+func newClient(baseURL, apiKey string) (*client, error) {
+	base, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q; set --url or KILN_URL", baseURL)
+	}
+	return &client{
+		base:   base,
+		apiKey: apiKey,
+		http: &http.Client{
+			// Logins redirect; report them instead of following them
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}, nil
+}
+
+// do sends a request and returns the response when it succeeded. Error
+// responses are turned into errors, with the message the API sent.
+func (c *client) do(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Response, error) {
+	target := c.base.String() + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, apiError(method, path, resp)
+	}
+	return resp, nil
+}
+
+// apiError describes a failed request
+func apiError(method, path string, resp *http.Response) error {
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusSeeOther {
+		return fmt.Errorf("%s %s: not authorized; set --api-key or KILN_API_KEY", method, path)
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	message := strings.TrimSpace(string(body))
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+		message = apiErr.Error
+	}
+	return fmt.Errorf("%s %s returned %s: %s", method, path, resp.Status, message)
+}
+
+// article is an article as listed by the articles API
+type article struct {
+	ID          string     `json:"id"`
+	URL         string     `json:"url"`
+	Source      string     `json:"source"`
+	Title       *string    `json:"title"`
+	Author      *string    `json:"author"`
+	PublishedAt *time.Time `json:"published_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	WordCount   int        `json:"word_count"`
+	Partial     bool       `json:"partial"`
+	Read        bool       `json:"read"`
+	Categories  []string   `json:"categories"`
+	Tags        []string   `json:"tags"`
+}
+
+// maxPageSize is the most articles the API returns at a time
+const maxPageSize = 500
+
+// listArticles returns up to limit articles matching the filter query
+// parameters, most recently saved first, fetching as many pages as needed.
+// A limit of 0 fetches them all.
+func (c *client) listArticles(ctx context.Context, filter url.Values, limit int) ([]article, error) {
+	var articles []article
+	cursor := ""
+	for {
+		pageSize := maxPageSize
+		if limit > 0 && limit-len(articles) < pageSize {
+			pageSize = limit - len(articles)
+		}
+
+		query := url.Values{}
+		for key, values := range filter {
+			query[key] = values
+		}
+		query.Set("limit", fmt.Sprint(pageSize))
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+
+		resp, err := c.do(ctx, http.MethodGet, "/api/v1/articles", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page []article
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse articles: %w", err)
+		}
+
+		articles = append(articles, page...)
+		cursor = resp.Header.Get("X-Next-Cursor")
+		if cursor == "" || (limit > 0 && len(articles) >= limit) {
+			return articles, nil
+		}
+	}
+}
+
+// startScrape queues a scrape job (or a retry of the failed articles) and
+// returns its ID
+func (c *client) startScrape(ctx context.Context, retry, dryRun bool) (string, error) {
+	path := "/scrape"
+	if retry {
+		path = "/scrape/retry"
+	}
+	form := url.Values{}
+	if dryRun {
+		form.Set("dry_run", "1")
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, path, nil, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	// Without a job ID the server answers with a notice, e.g. when the same
+	// scrape is already running
+	id := resp.Header.Get("X-Job-ID")
+	if id == "" {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("scrape not started: %s", stripTags(string(body)))
+	}
+	return id, nil
+}
+
+// progress is a progress update of a job, as sent by the progress socket
+type progress struct {
+	Status         string `json:"status"`
+	Message        string `json:"message"`
+	CurrentItem    int    `json:"current_item"`
+	TotalItems     int    `json:"total_items"`
+	ArticlesAdded  int    `json:"articles_added"`
+	ArticlesFailed int    `json:"articles_failed"`
+	FailedURL      string `json:"failed_url"`
+	Error          string `json:"error"`
+}
+
+// finished reports whether the status ends the job
+func (p progress) finished() bool {
+	return p.Status == "completed" || p.Status == "failed" || p.Status == "cancelled"
+}
+
+// socketMessage is a message of the progress socket
+type socketMessage struct {
+	Type     string    `json:"type"`
+	Job      string    `json:"job"`
+	Seq      int       `json:"seq"`
+	Progress *progress `json:"progress"`
+	Error    string    `json:"error"`
+}
+
+// watchProgress follows a job over the progress socket, calling update for
+// every progress update until the job finishes. It returns the last one.
+func (c *client) watchProgress(ctx context.Context, id string, update func(progress)) (progress, error) {
+	socketURL := *c.base
+	socketURL.Scheme = "ws"
+	if c.base.Scheme == "https" {
+		socketURL.Scheme = "wss"
+	}
+	socketURL.Path += "/ws/progress"
+
+	config, err := websocket.NewConfig(socketURL.String(), c.base.String())
+	if err != nil {
+		return progress{}, err
+	}
+	if c.apiKey != "" {
+		config.Header.Set("X-API-Key", c.apiKey)
+	}
+	conn, err := config.DialContext(ctx)
+	if err != nil {
+		return progress{}, fmt.Errorf("failed to connect to the progress socket: %w", err)
+	}
+	defer conn.Close()
+
+	// Close the socket when ctx ends, so the receive below returns
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := websocket.JSON.Send(conn, map[string]any{"type": "subscribe", "job": id}); err != nil {
+		return progress{}, err
+	}
+
+	var last progress
+	for {
+		var msg socketMessage
+		if err := websocket.JSON.Receive(conn, &msg); err != nil {
+			if ctx.Err() != nil {
+				return last, ctx.Err()
+			}
+			return last, fmt.Errorf("progress socket closed: %w", err)
+		}
+
+		switch msg.Type {
+		case "error":
+			return last, fmt.Errorf("job %s: %s", id, msg.Error)
+		case "progress":
+			if msg.Progress == nil {
+				continue
+			}
+			last = *msg.Progress
+			update(last)
+			if last.finished() {
+				return last, nil
+			}
+		}
+	}
+}
+
+// stripTags reduces an HTML fragment to its text
+func stripTags(fragment string) string {
+	var text strings.Builder
+	inTag := false
+	for _, r := range fragment {
+		switch {
+		case r == '<':
+			inTag = true
+		case r == '>':
+			inTag = false
+		case !inTag:
+			text.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(html.UnescapeString(text.String())), " ")
+}
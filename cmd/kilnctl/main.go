@@ -0,0 +1,299 @@
+// Command kilnctl manages a Kiln instance from the terminal over its HTTP
+// API: it starts scrapes, follows their progress, and lists, searches and
+// exports articles. The instance is set with --url or KILN_URL and the API
+// key with --api-key or KILN_API_KEY.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `Usage: kilnctl COMMAND [FLAGS]
+
+Commands:
+  scrape     start a scrape and follow its progress
+  progress   follow the progress of a running job
+  list       list articles, most recently saved first
+  search     search articles
+  export     export the matching articles as CSV or JSON
+
+Every command takes --url (KILN_URL) and --api-key (KILN_API_KEY).
+Run kilnctl COMMAND -h for its flags.
+`
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("kilnctl: ")
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch command, args := os.Args[1], os.Args[2:]; command {
+	case "scrape":
+		err = runScrape(ctx, args)
+	case "progress":
+		err = runProgress(ctx, args)
+	case "list":
+		err = runList(ctx, args, false)
+	case "search":
+		err = runList(ctx, args, true)
+	case "export":
+		err = runExport(ctx, args)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		err = fmt.Errorf("unknown command %q", command)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// connection holds the flags naming the instance
+type connection struct {
+	url    *string
+	apiKey *string
+}
+
+// connectionFlags adds --url and --api-key to a command's flags
+func connectionFlags(fs *flag.FlagSet) connection {
+	return connection{
+		url:    fs.String("url", os.Getenv("KILN_URL"), "base URL of the Kiln instance"),
+		apiKey: fs.String("api-key", os.Getenv("KILN_API_KEY"), "API key of the instance"),
+	}
+}
+
+func (c connection) client() (*client, error) {
+	return newClient(*c.url, *c.apiKey)
+}
+
+// filterFlags holds the article filters, named like the API's query
+// parameters
+type filterFlags struct {
+	source, author, tag, from, to *string
+	unread                        *bool
+}
+
+func addFilterFlags(fs *flag.FlagSet) filterFlags {
+	return filterFlags{
+		source: fs.String("source", "", "only articles of this source"),
+		author: fs.String("author", "", "only articles by this author"),
+		tag:    fs.String("tag", "", "only articles with this tag"),
+		from:   fs.String("from", "", "only articles published on or after this date (YYYY-MM-DD)"),
+		to:     fs.String("to", "", "only articles published on or before this date (YYYY-MM-DD)"),
+		unread: fs.Bool("unread", false, "only articles not marked read"),
+	}
+}
+
+// values returns the filters as query parameters, with the search query q
+func (f filterFlags) values(q string) url.Values {
+	values := url.Values{}
+	for key, value := range map[string]string{"q": q, "source": *f.source, "author": *f.author, "tag": *f.tag, "from": *f.from, "to": *f.to} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	if *f.unread {
+		values.Set("unread", "1")
+	}
+	return values
+}
+
+// runScrape starts a scrape and, unless --detach is given, prints its
+// progress until it finishes
+func runScrape(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("scrape", flag.ExitOnError)
+	conn := connectionFlags(fs)
+	retry := fs.Bool("retry", false, "only retry the failed articles")
+	dryRun := fs.Bool("dry-run", false, "report what would be saved without saving anything")
+	detach := fs.Bool("detach", false, "print the job ID and exit instead of following the progress")
+	fs.Parse(args)
+
+	c, err := conn.client()
+	if err != nil {
+		return err
+	}
+	id, err := c.startScrape(ctx, *retry, *dryRun)
+	if err != nil {
+		return err
+	}
+	if *detach {
+		fmt.Println(id)
+		return nil
+	}
+
+	fmt.Printf("Started job %s\n", id)
+	return followProgress(ctx, c, id)
+}
+
+// runProgress prints the progress of a job until it finishes
+func runProgress(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("progress", flag.ExitOnError)
+	conn := connectionFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: kilnctl progress [FLAGS] JOB_ID\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("a job ID is required")
+	}
+
+	c, err := conn.client()
+	if err != nil {
+		return err
+	}
+	return followProgress(ctx, c, fs.Arg(0))
+}
+
+// followProgress prints a line for every progress update of a job and
+// fails when the job does
+func followProgress(ctx context.Context, c *client, id string) error {
+	last, err := c.watchProgress(ctx, id, func(p progress) {
+		line := fmt.Sprintf("%s  %-10s", time.Now().Format("15:04:05"), p.Status)
+		if p.TotalItems > 0 {
+			line += fmt.Sprintf("  %d/%d", p.CurrentItem, p.TotalItems)
+		}
+		if p.Message != "" {
+			line += "  " + p.Message
+		}
+		if p.FailedURL != "" {
+			line += fmt.Sprintf("  (failed: %s: %s)", p.FailedURL, p.Error)
+		}
+		fmt.Println(line)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Job %s %s: %d articles added, %d failed\n", id, last.Status, last.ArticlesAdded, last.ArticlesFailed)
+	if last.Status != "completed" {
+		return fmt.Errorf("job %s", last.Status)
+	}
+	return nil
+}
+
+// runList prints the matching articles as a table. As search, the words
+// after the flags are the search query.
+func runList(ctx context.Context, args []string, search bool) error {
+	name := "list"
+	if search {
+		name = "search"
+	}
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	conn := connectionFlags(fs)
+	filter := addFilterFlags(fs)
+	limit := fs.Int("limit", 50, "the most articles to list, 0 for all")
+	if search {
+		fs.Usage = func() {
+			fmt.Fprintf(os.Stderr, "Usage: kilnctl search [FLAGS] QUERY\n")
+			fs.PrintDefaults()
+		}
+	}
+	fs.Parse(args)
+
+	query := ""
+	if search {
+		query = strings.Join(fs.Args(), " ")
+		if query == "" {
+			fs.Usage()
+			return fmt.Errorf("a search query is required")
+		}
+	}
+
+	c, err := conn.client()
+	if err != nil {
+		return err
+	}
+	articles, err := c.listArticles(ctx, filter.values(query), *limit)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "ID\tDATE\tSOURCE\tTITLE\n")
+	for _, a := range articles {
+		date := a.CreatedAt
+		if a.PublishedAt != nil {
+			date = *a.PublishedAt
+		}
+		title := "Untitled Article"
+		if a.Title != nil {
+			title = *a.Title
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", a.ID, date.Local().Format("2006-01-02 15:04"), a.Source, title)
+	}
+	return tw.Flush()
+}
+
+// runExport writes the matching articles to stdout or a file: the API's
+// CSV export, or the articles API's JSON as one array
+func runExport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	conn := connectionFlags(fs)
+	filter := addFilterFlags(fs)
+	q := fs.String("q", "", "only articles matching this search")
+	format := fs.String("format", "csv", "csv or json")
+	output := fs.String("o", "", "file to write to instead of stdout")
+	fs.Parse(args)
+
+	if *format != "csv" && *format != "json" {
+		return fmt.Errorf("unknown format %q; use csv or json", *format)
+	}
+	c, err := conn.client()
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if *format == "json" {
+		articles, err := c.listArticles(ctx, filter.values(*q), 0)
+		if err != nil {
+			return err
+		}
+		if articles == nil {
+			articles = []article{}
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(articles)
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, "/api/v1/articles.csv", filter.values(*q), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(out, resp.Body)
+	return err
+}
@@ -0,0 +1,111 @@
+// Package archive keeps the original pages articles were extracted from, so
+// they can be extracted again with improved logic without fetching them from
+// the source.
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/storage"
+)
+
+// Archive formats
+const (
+	// FormatHTML stores each page gzipped as it was fetched
+	FormatHTML = "html"
+
+	// FormatWARC stores each page as a gzipped WARC/1.1 resource record
+	FormatWARC = "warc"
+)
+
+// extensions maps the content types Kiln archives to file extensions
+var extensions = map[string]string{
+	"text/html":        ".html",
+	"application/json": ".json",
+}
+
+// Archive stores fetched pages in a store
+type Archive struct {
+	store  storage.Store
+	format string
+}
+
+// New creates an archive storing pages in store in the given format
+func New(store storage.Store, format string) (*Archive, error) {
+	if format != FormatHTML && format != FormatWARC {
+		return nil, fmt.Errorf("unsupported archive format %q, expected %s or %s", format, FormatHTML, FormatWARC)
+	}
+	return &Archive{store: store, format: format}, nil
+}
+
+// Name returns the name a page is stored under. Each URL has one name per
+// format and content type, so fetching a page again replaces its copy.
+func (a *Archive) Name(pageURL, contentType string) string {
+	sum := sha256.Sum256([]byte(pageURL))
+	name := hex.EncodeToString(sum[:16])
+	if a.format == FormatWARC {
+		return name + ".warc.gz"
+	}
+	return name + extensions[contentType] + ".gz"
+}
+
+// Save stores body, the page at pageURL as fetched at fetchedAt
+func (a *Archive) Save(ctx context.Context, pageURL, contentType string, body []byte, fetchedAt time.Time) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+
+	if a.format == FormatWARC {
+		id, err := recordID()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(gz, "WARC/1.1\r\n"+
+			"WARC-Type: resource\r\n"+
+			"WARC-Record-ID: <urn:uuid:%s>\r\n"+
+			"WARC-Date: %s\r\n"+
+			"WARC-Target-URI: %s\r\n"+
+			"WARC-Block-Digest: sha1:%s\r\n"+
+			"Content-Type: %s\r\n"+
+			"Content-Length: %d\r\n\r\n",
+			id, fetchedAt.UTC().Format(time.RFC3339), pageURL, blockDigest(body), contentType, len(body))
+	}
+	gz.Write(body)
+	if a.format == FormatWARC {
+		gz.Write([]byte("\r\n\r\n"))
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress page: %w", err)
+	}
+
+	name := a.Name(pageURL, contentType)
+	if err := a.store.Put(ctx, name, bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		return fmt.Errorf("failed to store page: %w", err)
+	}
+	return nil
+}
+
+// blockDigest returns the base32 SHA-1 digest WARC tools expect
+func blockDigest(body []byte) string {
+	sum := sha1.Sum(body)
+	return base32.StdEncoding.EncodeToString(sum[:])
+}
+
+// recordID returns a random (version 4) UUID for a WARC record
+func recordID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate record ID: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
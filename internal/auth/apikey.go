@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIKeyAuthenticator accepts static API keys sent as a bearer token or in
+// the X-API-Key header
+type APIKeyAuthenticator struct {
+	keys map[string]string // key -> name
+}
+
+// NewAPIKeyAuthenticator parses keys in "name:key" or bare "key" form
+func NewAPIKeyAuthenticator(keys []string) *APIKeyAuthenticator {
+	a := &APIKeyAuthenticator{keys: make(map[string]string)}
+	for i, entry := range keys {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, key, ok := strings.Cut(entry, ":")
+		if !ok {
+			name, key = fmt.Sprintf("key-%d", i+1), entry
+		}
+		a.keys[key] = name
+	}
+	return a
+}
+
+// Method implements Authenticator
+func (a *APIKeyAuthenticator) Method() Method {
+	return MethodAPIKey
+}
+
+// Authenticate implements Authenticator
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	presented := r.Header.Get("X-API-Key")
+	if presented == "" {
+		presented = bearerToken(r)
+	}
+	if presented == "" {
+		return nil, nil
+	}
+
+	if name, ok := a.Check(presented); ok {
+		return &Principal{Subject: name, Method: MethodAPIKey}, nil
+	}
+
+	// A bearer token may belong to another authenticator (e.g. OIDC)
+	if r.Header.Get("X-API-Key") == "" {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown API key")
+}
+
+// Check returns the name of a configured API key
+func (a *APIKeyAuthenticator) Check(presented string) (string, bool) {
+	for key, name := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(key)) == 1 {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// bearerToken returns the token from an "Authorization: Bearer" header
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
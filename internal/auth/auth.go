@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+// Method identifies how a request was authenticated
+type Method string
+
+const (
+	MethodSession     Method = "session"
+	MethodAPIKey      Method = "api_key"
+	MethodFeedToken   Method = "feed_token"
+	MethodOIDC        Method = "oidc"
+	MethodBasic       Method = "basic"
+	MethodReaderToken Method = "reader_token"
+)
+
+// Principal is an authenticated caller
+type Principal struct {
+	Subject string // user name, API key name or OIDC email
+	Method  Method
+}
+
+// Authenticator extracts a principal from a request. It returns (nil, nil)
+// when the request carries no credentials of its kind, and an error when it
+// carries credentials that are invalid.
+type Authenticator interface {
+	Method() Method
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Policy declares which authentication methods may access a group of routes
+type Policy struct {
+	Name    string
+	Methods []Method
+	// Public routes skip authentication entirely
+	Public bool
+	// LoginRedirect sends unauthenticated browsers to the login page instead
+	// of answering 401
+	LoginRedirect bool
+}
+
+// Route policies. Each route group in the server declares exactly one.
+var (
+	PolicyPublic  = Policy{Name: "public", Public: true}
+	PolicyUI      = Policy{Name: "ui", Methods: []Method{MethodSession, MethodOIDC, MethodBasic}, LoginRedirect: true}
+	PolicyAdmin   = Policy{Name: "admin", Methods: []Method{MethodSession, MethodOIDC, MethodAPIKey, MethodBasic}}
+	PolicyAPI     = Policy{Name: "api", Methods: []Method{MethodAPIKey, MethodSession, MethodOIDC, MethodBasic}}
+	PolicyFeed    = Policy{Name: "feed", Methods: []Method{MethodFeedToken, MethodAPIKey, MethodSession, MethodOIDC, MethodBasic}}
+	PolicyMetrics = Policy{Name: "metrics", Methods: []Method{MethodAPIKey, MethodBasic}}
+
+	// Google Reader API clients send no cookies, so sessions are not
+	// accepted and the API needs no CSRF protection
+	PolicyReader = Policy{Name: "reader", Methods: []Method{MethodReaderToken, MethodAPIKey, MethodBasic}}
+)
+
+// allows reports whether the policy accepts the given method
+func (p Policy) allows(m Method) bool {
+	for _, allowed := range p.Methods {
+		if allowed == m {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the principal
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal of the current request, or nil
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// Chain runs the configured authenticators in order and enforces policies
+type Chain struct {
+	authenticators []Authenticator
+	loginPath      string
+}
+
+// NewChain creates a chain from the given authenticators. With no
+// authenticators the chain is disabled and every request is allowed.
+func NewChain(loginPath string, authenticators ...Authenticator) *Chain {
+	return &Chain{authenticators: authenticators, loginPath: loginPath}
+}
+
+// Enabled reports whether any authenticator is configured
+func (c *Chain) Enabled() bool {
+	return len(c.authenticators) > 0
+}
+
+// Add appends an authenticator to the chain
+func (c *Chain) Add(a Authenticator) {
+	c.authenticators = append(c.authenticators, a)
+}
+
+// Authenticate returns the first principal accepted by both an authenticator
+// and the policy
+func (c *Chain) Authenticate(r *http.Request, policy Policy) (*Principal, error) {
+	var firstErr error
+	for _, a := range c.authenticators {
+		if !policy.allows(a.Method()) {
+			continue
+		}
+		principal, err := a.Authenticate(r)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if principal != nil {
+			return principal, nil
+		}
+	}
+	return nil, firstErr
+}
+
+// Require returns middleware enforcing the policy on every wrapped route
+func (c *Chain) Require(policy Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if policy.Public || !c.Enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, err := c.Authenticate(r, policy)
+			if principal != nil && principal.Method == MethodBasic && crossSiteChange(r) {
+				logging.Warnf("Rejected cross-site %s %s authenticated by Basic auth", r.Method, r.URL.Path)
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			if principal != nil {
+				next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+				return
+			}
+
+			if err != nil {
+				logging.Warnf("Authentication failed for %s %s (policy %s): %v", r.Method, r.URL.Path, policy.Name, err)
+			}
+			c.deny(w, r, policy)
+		})
+	}
+}
+
+// has reports whether an authenticator for the method is configured
+func (c *Chain) has(m Method) bool {
+	for _, a := range c.authenticators {
+		if a.Method() == m {
+			return true
+		}
+	}
+	return false
+}
+
+// deny answers an unauthenticated request according to the policy. Browsers
+// are sent to the login page when there is an interactive login; with only
+// Basic auth they get a Basic challenge so they prompt for credentials.
+func (c *Chain) deny(w http.ResponseWriter, r *http.Request, policy Policy) {
+	interactive := c.has(MethodSession) || c.has(MethodOIDC)
+	if policy.LoginRedirect && c.loginPath != "" && interactive {
+		target := c.loginPath + "?next=" + url.QueryEscape(r.URL.RequestURI())
+		// HTMX requests need a client-side redirect
+		if r.Header.Get("HX-Request") == "true" {
+			w.Header().Set("HX-Redirect", target)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.Redirect(w, r, target, http.StatusSeeOther)
+		return
+	}
+
+	if c.has(MethodBasic) {
+		w.Header().Add("WWW-Authenticate", `Basic realm="kiln", charset="UTF-8"`)
+	}
+	w.Header().Add("WWW-Authenticate", `Bearer realm="kiln"`)
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireBasicCrossSite(t *testing.T) {
+	chain := NewChain("", NewBasicAuthenticator("admin", "secret"))
+	handler := chain.Require(PolicyAdmin)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		want    int
+	}{
+		{
+			name:   "client without browser headers",
+			method: http.MethodPost,
+			want:   http.StatusNoContent,
+		},
+		{
+			name:    "same origin",
+			method:  http.MethodPost,
+			headers: map[string]string{"Sec-Fetch-Site": "same-origin", "Origin": "http://kiln.example"},
+			want:    http.StatusNoContent,
+		},
+		{
+			name:    "typed into the address bar",
+			method:  http.MethodPost,
+			headers: map[string]string{"Sec-Fetch-Site": "none"},
+			want:    http.StatusNoContent,
+		},
+		{
+			name:    "cross-site form",
+			method:  http.MethodPost,
+			headers: map[string]string{"Sec-Fetch-Site": "cross-site", "Origin": "https://evil.example"},
+			want:    http.StatusForbidden,
+		},
+		{
+			name:    "same site but another origin",
+			method:  http.MethodDelete,
+			headers: map[string]string{"Sec-Fetch-Site": "same-site"},
+			want:    http.StatusForbidden,
+		},
+		{
+			name:    "other origin without fetch metadata",
+			method:  http.MethodPost,
+			headers: map[string]string{"Origin": "https://evil.example"},
+			want:    http.StatusForbidden,
+		},
+		{
+			name:    "opaque origin",
+			method:  http.MethodPost,
+			headers: map[string]string{"Origin": "null"},
+			want:    http.StatusForbidden,
+		},
+		{
+			name:    "cross-site reads are allowed",
+			method:  http.MethodGet,
+			headers: map[string]string{"Sec-Fetch-Site": "cross-site"},
+			want:    http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "http://kiln.example/scrape", nil)
+			r.SetBasicAuth("admin", "secret")
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
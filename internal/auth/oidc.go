@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// oidcStateCookie holds the anti-CSRF state during the login round trip
+const oidcStateCookie = "kiln_oidc_state"
+
+// OIDCConfig configures login through an OpenID Connect provider
+type OIDCConfig struct {
+	Issuer        string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string
+	AllowedEmails []string // empty allows any user of the provider
+}
+
+// OIDC implements the authorization code flow for browser logins and
+// validates bearer access tokens against the provider's userinfo endpoint
+type OIDC struct {
+	cfg    OIDCConfig
+	client *http.Client
+
+	authorizationEndpoint string
+	tokenEndpoint         string
+	userinfoEndpoint      string
+
+	mu    sync.Mutex
+	cache map[string]cachedToken
+}
+
+type cachedToken struct {
+	subject string
+	expires time.Time
+}
+
+// bearerCacheTTL bounds how long a validated bearer token is trusted
+const bearerCacheTTL = 5 * time.Minute
+
+// NewOIDC fetches the provider's discovery document
+func NewOIDC(ctx context.Context, cfg OIDCConfig) (*OIDC, error) {
+	o := &OIDC{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+		cache:  make(map[string]cachedToken),
+	}
+
+	discoveryURL := strings.TrimSuffix(cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	var doc struct {
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	}
+	if err := o.getJSON(ctx, discoveryURL, "", &doc); err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC discovery document: %w", err)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.UserinfoEndpoint == "" {
+		return nil, fmt.Errorf("OIDC discovery document is missing endpoints")
+	}
+
+	o.authorizationEndpoint = doc.AuthorizationEndpoint
+	o.tokenEndpoint = doc.TokenEndpoint
+	o.userinfoEndpoint = doc.UserinfoEndpoint
+	return o, nil
+}
+
+// Method implements Authenticator
+func (o *OIDC) Method() Method {
+	return MethodOIDC
+}
+
+// Authenticate implements Authenticator for bearer access tokens
+func (o *OIDC) Authenticate(r *http.Request) (*Principal, error) {
+	token := bearerToken(r)
+	if token == "" {
+		return nil, nil
+	}
+
+	o.mu.Lock()
+	cached, ok := o.cache[token]
+	o.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return &Principal{Subject: cached.subject, Method: MethodOIDC}, nil
+	}
+
+	subject, err := o.userinfo(r.Context(), token)
+	if err != nil {
+		return nil, err
+	}
+
+	o.mu.Lock()
+	now := time.Now()
+	for t, c := range o.cache {
+		if now.After(c.expires) {
+			delete(o.cache, t)
+		}
+	}
+	o.cache[token] = cachedToken{subject: subject, expires: now.Add(bearerCacheTTL)}
+	o.mu.Unlock()
+
+	return &Principal{Subject: subject, Method: MethodOIDC}, nil
+}
+
+// StartLogin redirects the browser to the provider's authorization endpoint
+func (o *OIDC) StartLogin(w http.ResponseWriter, r *http.Request, next string) {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	state := hex.EncodeToString(buf)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state + "|" + next,
+		Path:     "/",
+		MaxAge:   600,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", o.cfg.ClientID)
+	params.Set("redirect_uri", o.cfg.RedirectURL)
+	params.Set("scope", "openid email profile")
+	params.Set("state", state)
+
+	http.Redirect(w, r, o.authorizationEndpoint+"?"+params.Encode(), http.StatusFound)
+}
+
+// FinishLogin handles the provider callback, returning the principal and the
+// page the user originally asked for
+func (o *OIDC) FinishLogin(r *http.Request) (*Principal, string, error) {
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil {
+		return nil, "", fmt.Errorf("missing login state")
+	}
+	state, next, _ := strings.Cut(cookie.Value, "|")
+	if state == "" || r.URL.Query().Get("state") != state {
+		return nil, "", fmt.Errorf("login state mismatch")
+	}
+	if errMsg := r.URL.Query().Get("error"); errMsg != "" {
+		return nil, "", fmt.Errorf("provider returned error: %s", errMsg)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", r.URL.Query().Get("code"))
+	form.Set("redirect_uri", o.cfg.RedirectURL)
+	form.Set("client_id", o.cfg.ClientID)
+	form.Set("client_secret", o.cfg.ClientSecret)
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, o.tokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to exchange code: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return nil, "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	subject, err := o.userinfo(r.Context(), tokens.AccessToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &Principal{Subject: subject, Method: MethodOIDC}, next, nil
+}
+
+// userinfo resolves an access token to the user's email (or subject) and
+// checks it against the allow list
+func (o *OIDC) userinfo(ctx context.Context, accessToken string) (string, error) {
+	var info struct {
+		Subject       string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified *bool  `json:"email_verified"`
+	}
+	if err := o.getJSON(ctx, o.userinfoEndpoint, accessToken, &info); err != nil {
+		return "", fmt.Errorf("failed to fetch userinfo: %w", err)
+	}
+
+	if len(o.cfg.AllowedEmails) == 0 {
+		if info.Email != "" {
+			return info.Email, nil
+		}
+		return info.Subject, nil
+	}
+
+	if info.EmailVerified != nil && !*info.EmailVerified {
+		return "", fmt.Errorf("email %s is not verified", info.Email)
+	}
+	for _, allowed := range o.cfg.AllowedEmails {
+		if strings.EqualFold(allowed, info.Email) {
+			return info.Email, nil
+		}
+	}
+	return "", fmt.Errorf("user %s is not allowed", info.Email)
+}
+
+func (o *OIDC) getJSON(ctx context.Context, endpoint, bearer string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SessionCookieName is the cookie holding the signed session
+const SessionCookieName = "kiln_session"
+
+// Sessions issues and verifies signed session cookies. The cookie value is
+// "<method>|<subject>|<expiry unix>" followed by an HMAC-SHA256 signature.
+type Sessions struct {
+	secret []byte
+	ttl    time.Duration
+	secure bool
+}
+
+// NewSessions creates a session manager signing with secret
+func NewSessions(secret []byte, ttl time.Duration, secure bool) *Sessions {
+	return &Sessions{secret: secret, ttl: ttl, secure: secure}
+}
+
+// Issue sets a session cookie for the principal
+func (s *Sessions) Issue(w http.ResponseWriter, p *Principal) {
+	expires := time.Now().Add(s.ttl)
+	payload := fmt.Sprintf("%s|%s|%d", p.Method, p.Subject, expires.Unix())
+	value := base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + s.sign(payload)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   s.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// Clear removes the session cookie
+func (s *Sessions) Clear(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   s.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// Read verifies the session cookie and returns its principal
+func (s *Sessions) Read(r *http.Request) (*Principal, error) {
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil || cookie.Value == "" {
+		return nil, nil
+	}
+
+	encoded, signature, ok := strings.Cut(cookie.Value, ".")
+	if !ok {
+		return nil, fmt.Errorf("malformed session cookie")
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("malformed session cookie: %w", err)
+	}
+	payload := string(raw)
+	if !hmac.Equal([]byte(signature), []byte(s.sign(payload))) {
+		return nil, fmt.Errorf("invalid session signature")
+	}
+
+	parts := strings.Split(payload, "|")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed session payload")
+	}
+	expiry, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed session expiry: %w", err)
+	}
+	if time.Now().Unix() > expiry {
+		return nil, fmt.Errorf("session expired")
+	}
+
+	return &Principal{Method: Method(parts[0]), Subject: parts[1]}, nil
+}
+
+func (s *Sessions) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SessionAuthenticator accepts session cookies issued for a given method.
+// Password logins and OIDC logins share the cookie but are separate methods
+// so policies can tell them apart.
+type SessionAuthenticator struct {
+	sessions *Sessions
+	method   Method
+}
+
+// NewSessionAuthenticator accepts sessions issued with the given method
+func NewSessionAuthenticator(sessions *Sessions, method Method) *SessionAuthenticator {
+	return &SessionAuthenticator{sessions: sessions, method: method}
+}
+
+// Method implements Authenticator
+func (a *SessionAuthenticator) Method() Method {
+	return a.method
+}
+
+// Authenticate implements Authenticator
+func (a *SessionAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	principal, err := a.sessions.Read(r)
+	if err != nil || principal == nil {
+		return nil, err
+	}
+	if principal.Method != a.method {
+		return nil, nil
+	}
+	return principal, nil
+}
+
+// PasswordLogin verifies the single configured user's credentials
+type PasswordLogin struct {
+	username string
+	password string
+}
+
+// NewPasswordLogin creates a login checker for one username/password pair
+func NewPasswordLogin(username, password string) *PasswordLogin {
+	return &PasswordLogin{username: username, password: password}
+}
+
+// Check reports whether the credentials match, in constant time
+func (l *PasswordLogin) Check(username, password string) bool {
+	userOK := subtle.ConstantTimeCompare([]byte(username), []byte(l.username)) == 1
+	passOK := subtle.ConstantTimeCompare([]byte(password), []byte(l.password)) == 1
+	return userOK && passOK
+}
@@ -2,62 +2,478 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/i18n"
+)
+
+// What feed items carry (FEED_ITEM_CONTENT)
+const (
+	FeedItemText    = "text"    // the start of the text, cut at a word
+	FeedItemSummary = "summary" // a summary written by a language model
+	FeedItemHTML    = "html"    // the whole article
 )
 
 // Config holds all application configuration
 type Config struct {
-	// Database
-	DatabaseURL string
+	// Database. DatabaseURL may be a Vault reference (vault:PATH#FIELD);
+	// DatabaseCredentials (vault:PATH) gives the username and password of
+	// each connection, e.g. from Vault's database secrets engine.
+	DatabaseURL         string
+	DatabaseCredentials string
+
+	// Connection pool tuning (0 or "" keeps the pgx defaults)
+	DBMaxConns           int
+	DBMinConns           int
+	DBMaxConnLifetime    time.Duration
+	DBMaxConnIdleTime    time.Duration
+	DBHealthCheckPeriod  time.Duration
+	DBStatementCache     string // query exec mode, e.g. "exec" behind PgBouncer
+	DBStatementCacheSize int
+	ContentCompression   string // "gzip" or "zstd" to compress stored content
 
-	// Gasetten credentials
+	// Gasetten credentials, which may instead be stored encrypted as
+	// "gasetten" when a credentials key is set
 	GasettenUser string
 	GasettenPass string
 
+	// Credentials key: a base64 AES-256 key sealing the credentials stored
+	// in the database, given directly or in a file
+	CredentialsKey     string
+	CredentialsKeyFile string
+
+	// HashiCorp Vault, for vault: references: its address and a token, or
+	// an AppRole role ID and secret ID to log in with
+	VaultAddr      string
+	VaultToken     string
+	VaultNamespace string
+	VaultRoleID    string
+	VaultSecretID  string
+
 	// Server
 	Port int
 
+	// TLS (plain HTTP when neither certificate files nor ACME domains are set)
+	HTTPSPort    int
+	TLSCertFile  string
+	TLSKeyFile   string
+	ACMEDomains  []string
+	ACMEEmail    string
+	ACMECacheDir string
+
 	// RSS Feed
 	FeedTitle       string
 	FeedDescription string
 	FeedLink        string
 	FeedAuthor      string
 
+	// What feed items carry (one of the FeedItem constants) and how many
+	// characters of text their descriptions keep
+	FeedItemContent string
+	FeedItemLength  int
+
+	// Language model writing the summaries for FeedItemSummary, behind an
+	// OpenAI-compatible chat completions endpoint
+	SummaryAPIURL string
+	SummaryAPIKey string
+	SummaryModel  string
+
 	// Scraper
 	ScraperHeadless bool
+	ChromeURL       string        // remote Chrome to use instead of launching one
+	ProfilesDir     string        // YAML site profiles loaded at startup
+	SourcesFile     string        // JSON overrides of the per-source selectors
+	ScrapeComments  bool          // capture each article's comment thread
+	KeepAlive       time.Duration // how often to refresh the session (0 disables)
+	DiagnosticsDir  string        // captures of failed pages ("" disables them)
+
+	// Zone of dates scraped without one and of times shown in the UI and
+	// feeds (TIMEZONE, e.g. "Europe/Stockholm"; the system zone when unset)
+	Timezone *time.Location
+
+	// Number of rendered pages kept in memory (0 disables the cache)
+	PageCacheSize int
+
+	// Language of the UI, "en" or "sv". When unset, each browser's
+	// Accept-Language picks it.
+	UILanguage string
+
+	// Per-IP rate limit on mutations, scraping and the API (0 disables it)
+	RateLimitPerMinute int
+	RateLimitBurst     int
+
+	// Reverse proxies whose X-Forwarded-For and X-Real-IP headers are
+	// believed (TRUSTED_PROXIES: IPs or CIDR ranges); other clients are
+	// known by their own address
+	TrustedProxies []netip.Prefix
+
+	// Authentication (disabled when no method is configured)
+	AuthSecret        string
+	AuthUser          string
+	AuthPassword      string
+	BasicAuthUser     string
+	BasicAuthPass     string
+	SessionTTL        time.Duration
+	APIKeys           []string
+	OIDCIssuer        string
+	OIDCClientID      string
+	OIDCClientSecret  string
+	OIDCAllowedEmails []string
+
+	// Instance-to-instance sync (disabled when no peers are configured)
+	SyncPeers    []string
+	SyncAPIKey   string
+	SyncInterval time.Duration
+
+	// Remote storage (file://, s3:// or webdav(s):// URL)
+	StorageURL        string
+	StorageS3Endpoint string
+	StorageS3Region   string
+	StorageAccessKey  string
+	StorageSecretKey  string
+
+	// Storage for mirrored article images (images are not mirrored when
+	// empty); uses the S3 settings above for s3:// URLs
+	MediaStorageURL string
+
+	// Storage for captures of failed pages; when empty they are kept in
+	// DiagnosticsDir
+	DiagnosticsStorageURL string
+
+	// Storage for the original article pages (pages are not kept when
+	// empty), as gzipped HTML or WARC records
+	ArchiveStorageURL string
+	ArchiveFormat     string
+
+	// Submit scraped articles to the Wayback Machine; the archive.org keys
+	// are optional and raise its rate limits
+	WaybackSubmit    bool
+	WaybackAccessKey string
+	WaybackSecretKey string
+
+	// Scheduled export snapshots (disabled when ExportSchedule is empty)
+	ExportSchedule      string // daily at HH:MM, local time
+	ExportFullEvery     time.Duration
+	ExportKeepFull      int
+	ExportEncryptionKey string // base64 AES-256 key; snapshots are unencrypted when empty
+
+	// Retention policy (disabled when both limits are 0); on a dry run the
+	// nightly cleanup only reports what it would delete
+	RetentionMaxAgeDays   int
+	RetentionMaxPerSource int
+	RetentionSchedule     string // daily at HH:MM, local time
+	RetentionDryRun       bool
+
+	// Notifications (logged only when no webhook is configured)
+	NotifyWebhookURL string
+
+	// Logging: the lowest level logged (debug, info, warn or error), text or
+	// JSON lines, and a file written besides stderr, rotated at
+	// LogFileMaxSizeMB keeping LogFileMaxBackups old files ("" disables it)
+	LogLevel          string
+	LogFormat         string
+	LogFile           string
+	LogFileMaxSizeMB  int
+	LogFileMaxBackups int
 }
 
 // Load reads configuration from environment variables
 func Load() (*Config, error) {
+	if err := checkSecretFiles(); err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{
 		DatabaseURL:     getEnv("DATABASE_URL", ""),
 		GasettenUser:    getEnv("GASETTEN_USER", ""),
 		GasettenPass:    getEnv("GASETTEN_PASS", ""),
 		Port:            getEnvAsInt("PORT", 8080),
+		HTTPSPort:       getEnvAsInt("HTTPS_PORT", 443),
+		TLSCertFile:     getEnv("TLS_CERT_FILE", ""),
+		TLSKeyFile:      getEnv("TLS_KEY_FILE", ""),
+		ACMEDomains:     getEnvAsList("ACME_DOMAINS"),
+		ACMEEmail:       getEnv("ACME_EMAIL", ""),
+		ACMECacheDir:    getEnv("ACME_CACHE_DIR", "acme-cache"),
 		FeedTitle:       getEnv("FEED_TITLE", "My Personal Kiln Feed"),
 		FeedDescription: getEnv("FEED_DESCRIPTION", "Articles from Gasetten"),
 		FeedLink:        getEnv("FEED_LINK", "http://localhost:8080"),
 		FeedAuthor:      getEnv("FEED_AUTHOR", "Kiln User"),
+		FeedItemContent: strings.ToLower(getEnv("FEED_ITEM_CONTENT", FeedItemText)),
+		FeedItemLength:  getEnvAsInt("FEED_ITEM_LENGTH", 500),
+		SummaryAPIURL:   getEnv("SUMMARY_API_URL", ""),
+		SummaryAPIKey:   getEnv("SUMMARY_API_KEY", ""),
+		SummaryModel:    getEnv("SUMMARY_MODEL", ""),
 		ScraperHeadless: getEnvAsBool("SCRAPER_HEADLESS", true),
+		ChromeURL:       getEnv("CHROME_CONTROL_URL", ""),
+		ProfilesDir:     getEnv("SITE_PROFILES_DIR", "profiles"),
+		SourcesFile:     getEnv("SOURCES_FILE", ""),
+		ScrapeComments:  getEnvAsBool("SCRAPE_COMMENTS", false),
+		KeepAlive:       getEnvAsDuration("SESSION_KEEPALIVE", 3*time.Hour),
+		DiagnosticsDir:  getEnv("DIAGNOSTICS_DIR", "diagnostics"),
+		PageCacheSize:   getEnvAsInt("PAGE_CACHE_SIZE", 256),
+		UILanguage:      strings.ToLower(getEnv("UI_LANGUAGE", "")),
+
+		DBMaxConns:           getEnvAsInt("DB_MAX_CONNS", 0),
+		DBMinConns:           getEnvAsInt("DB_MIN_CONNS", 0),
+		DBMaxConnLifetime:    getEnvAsDuration("DB_MAX_CONN_LIFETIME", 0),
+		DBMaxConnIdleTime:    getEnvAsDuration("DB_MAX_CONN_IDLE_TIME", 0),
+		DBHealthCheckPeriod:  getEnvAsDuration("DB_HEALTH_CHECK_PERIOD", 0),
+		DBStatementCache:     getEnv("DB_STATEMENT_CACHE", ""),
+		DBStatementCacheSize: getEnvAsInt("DB_STATEMENT_CACHE_SIZE", 0),
+		ContentCompression:   getEnv("CONTENT_COMPRESSION", ""),
+
+		RateLimitPerMinute: getEnvAsInt("RATE_LIMIT_PER_MINUTE", 60),
+		RateLimitBurst:     getEnvAsInt("RATE_LIMIT_BURST", 20),
+
+		AuthSecret:        getEnv("AUTH_SECRET", ""),
+		AuthUser:          getEnv("AUTH_USER", "admin"),
+		AuthPassword:      getEnv("AUTH_PASSWORD", ""),
+		BasicAuthUser:     getEnv("BASIC_AUTH_USER", "admin"),
+		BasicAuthPass:     getEnv("BASIC_AUTH_PASS", ""),
+		SessionTTL:        getEnvAsDuration("SESSION_TTL", 30*24*time.Hour),
+		APIKeys:           getEnvAsList("API_KEYS"),
+		OIDCIssuer:        getEnv("OIDC_ISSUER", ""),
+		OIDCClientID:      getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret:  getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCAllowedEmails: getEnvAsList("OIDC_ALLOWED_EMAILS"),
+
+		SyncPeers:    getEnvAsList("SYNC_PEERS"),
+		SyncAPIKey:   getEnv("SYNC_API_KEY", ""),
+		SyncInterval: getEnvAsDuration("SYNC_INTERVAL", 15*time.Minute),
+
+		StorageURL:            getEnv("STORAGE_URL", ""),
+		StorageS3Endpoint:     getEnv("STORAGE_S3_ENDPOINT", ""),
+		StorageS3Region:       getEnv("STORAGE_S3_REGION", "us-east-1"),
+		StorageAccessKey:      getEnv("STORAGE_ACCESS_KEY", ""),
+		StorageSecretKey:      getEnv("STORAGE_SECRET_KEY", ""),
+		MediaStorageURL:       getEnv("MEDIA_STORAGE_URL", ""),
+		DiagnosticsStorageURL: getEnv("DIAGNOSTICS_STORAGE_URL", ""),
+		ArchiveStorageURL:     getEnv("ARCHIVE_STORAGE_URL", ""),
+		ArchiveFormat:         getEnv("ARCHIVE_FORMAT", "html"),
+
+		WaybackSubmit:    getEnvAsBool("WAYBACK_SUBMIT", false),
+		WaybackAccessKey: getEnv("WAYBACK_ACCESS_KEY", ""),
+		WaybackSecretKey: getEnv("WAYBACK_SECRET_KEY", ""),
+
+		CredentialsKey:     getEnv("CREDENTIALS_KEY", ""),
+		CredentialsKeyFile: getEnv("CREDENTIALS_KEY_FILE", ""),
+
+		DatabaseCredentials: getEnv("DATABASE_CREDENTIALS", ""),
+		VaultAddr:           getEnv("VAULT_ADDR", ""),
+		VaultToken:          getEnv("VAULT_TOKEN", ""),
+		VaultNamespace:      getEnv("VAULT_NAMESPACE", ""),
+		VaultRoleID:         getEnv("VAULT_ROLE_ID", ""),
+		VaultSecretID:       getEnv("VAULT_SECRET_ID", ""),
+
+		ExportSchedule:      getEnv("EXPORT_SCHEDULE", ""),
+		ExportFullEvery:     getEnvAsDuration("EXPORT_FULL_EVERY", 7*24*time.Hour),
+		ExportKeepFull:      getEnvAsInt("EXPORT_KEEP_FULL", 4),
+		ExportEncryptionKey: getEnv("EXPORT_ENCRYPTION_KEY", ""),
+
+		RetentionMaxAgeDays:   getEnvAsInt("RETENTION_MAX_AGE_DAYS", 0),
+		RetentionMaxPerSource: getEnvAsInt("RETENTION_MAX_PER_SOURCE", 0),
+		RetentionSchedule:     getEnv("RETENTION_SCHEDULE", "04:00"),
+		RetentionDryRun:       getEnvAsBool("RETENTION_DRY_RUN", false),
+
+		NotifyWebhookURL: getEnv("NOTIFY_WEBHOOK_URL", ""),
+
+		LogLevel:          strings.ToLower(getEnv("LOG_LEVEL", "info")),
+		LogFormat:         strings.ToLower(getEnv("LOG_FORMAT", "text")),
+		LogFile:           getEnv("LOG_FILE", ""),
+		LogFileMaxSizeMB:  getEnvAsInt("LOG_FILE_MAX_SIZE_MB", 10),
+		LogFileMaxBackups: getEnvAsInt("LOG_FILE_MAX_BACKUPS", 5),
+	}
+
+	for _, proxy := range getEnvAsList("TRUSTED_PROXIES") {
+		prefix, err := parsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q: %w", proxy, err)
+		}
+		cfg.TrustedProxies = append(cfg.TrustedProxies, prefix)
+	}
+
+	cfg.Timezone = time.Local
+	if tz := getEnv("TIMEZONE", ""); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TIMEZONE %q: %w", tz, err)
+		}
+		cfg.Timezone = loc
 	}
 
 	// Validate required fields
 	if cfg.DatabaseURL == "" {
 		return nil, fmt.Errorf("DATABASE_URL is required")
 	}
-	if cfg.GasettenUser == "" {
-		return nil, fmt.Errorf("GASETTEN_USER is required")
+	// With a credentials key, Gasetten's credentials may be in the store
+	if !cfg.CredentialsStoreEnabled() {
+		if cfg.GasettenUser == "" {
+			return nil, fmt.Errorf("GASETTEN_USER is required unless CREDENTIALS_KEY or CREDENTIALS_KEY_FILE is set")
+		}
+		if cfg.GasettenPass == "" {
+			return nil, fmt.Errorf("GASETTEN_PASS is required unless CREDENTIALS_KEY or CREDENTIALS_KEY_FILE is set")
+		}
+	}
+	if (cfg.GasettenUser == "") != (cfg.GasettenPass == "") {
+		return nil, fmt.Errorf("GASETTEN_USER and GASETTEN_PASS must be set together")
+	}
+	if cfg.CredentialsKey != "" && cfg.CredentialsKeyFile != "" {
+		return nil, fmt.Errorf("set either CREDENTIALS_KEY or CREDENTIALS_KEY_FILE, not both")
+	}
+
+	if cfg.VaultAddr != "" {
+		if (cfg.VaultRoleID == "") != (cfg.VaultSecretID == "") {
+			return nil, fmt.Errorf("VAULT_ROLE_ID and VAULT_SECRET_ID must be set together")
+		}
+		if cfg.VaultToken == "" && cfg.VaultRoleID == "" {
+			return nil, fmt.Errorf("VAULT_TOKEN or VAULT_ROLE_ID and VAULT_SECRET_ID are required when VAULT_ADDR is set")
+		}
+	}
+	if strings.HasPrefix(cfg.DatabaseURL, "vault:") && cfg.VaultAddr == "" {
+		return nil, fmt.Errorf("VAULT_ADDR is required when DATABASE_URL is a vault: reference")
+	}
+	if cfg.DatabaseCredentials != "" {
+		if !strings.HasPrefix(cfg.DatabaseCredentials, "vault:") {
+			return nil, fmt.Errorf("invalid DATABASE_CREDENTIALS %q: use vault:PATH", cfg.DatabaseCredentials)
+		}
+		if cfg.VaultAddr == "" {
+			return nil, fmt.Errorf("VAULT_ADDR is required when DATABASE_CREDENTIALS is set")
+		}
+	}
+
+	if cfg.OIDCIssuer != "" && cfg.OIDCClientID == "" {
+		return nil, fmt.Errorf("OIDC_CLIENT_ID is required when OIDC_ISSUER is set")
+	}
+
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return nil, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if cfg.TLSCertFile != "" && len(cfg.ACMEDomains) > 0 {
+		return nil, fmt.Errorf("set either TLS_CERT_FILE/TLS_KEY_FILE or ACME_DOMAINS, not both")
+	}
+
+	if (cfg.WaybackAccessKey == "") != (cfg.WaybackSecretKey == "") {
+		return nil, fmt.Errorf("WAYBACK_ACCESS_KEY and WAYBACK_SECRET_KEY must be set together")
+	}
+
+	if cfg.ExportSchedule != "" && cfg.StorageURL == "" {
+		return nil, fmt.Errorf("STORAGE_URL is required when EXPORT_SCHEDULE is set")
+	}
+
+	switch cfg.FeedItemContent {
+	case FeedItemText, FeedItemHTML:
+	case FeedItemSummary:
+		if cfg.SummaryAPIURL == "" || cfg.SummaryModel == "" {
+			return nil, fmt.Errorf("SUMMARY_API_URL and SUMMARY_MODEL are required when FEED_ITEM_CONTENT is summary")
+		}
+	default:
+		return nil, fmt.Errorf("invalid FEED_ITEM_CONTENT %q: use text, summary or html", cfg.FeedItemContent)
+	}
+	if cfg.FeedItemLength <= 0 {
+		return nil, fmt.Errorf("FEED_ITEM_LENGTH must be positive")
 	}
-	if cfg.GasettenPass == "" {
-		return nil, fmt.Errorf("GASETTEN_PASS is required")
+
+	if cfg.UILanguage != "" && !i18n.Supported(cfg.UILanguage) {
+		return nil, fmt.Errorf("invalid UI_LANGUAGE %q: use en or sv", cfg.UILanguage)
+	}
+
+	switch cfg.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		return nil, fmt.Errorf("invalid LOG_LEVEL %q: use debug, info, warn or error", cfg.LogLevel)
+	}
+	if cfg.LogFormat != "text" && cfg.LogFormat != "json" {
+		return nil, fmt.Errorf("invalid LOG_FORMAT %q: use text or json", cfg.LogFormat)
 	}
 
 	return cfg, nil
 }
 
-func getEnv(key, defaultValue string) string {
+// CredentialsStoreEnabled reports whether a credentials key is configured
+func (c *Config) CredentialsStoreEnabled() bool {
+	return c.CredentialsKey != "" || c.CredentialsKeyFile != ""
+}
+
+// VaultEnabled reports whether a Vault server is configured
+func (c *Config) VaultEnabled() bool {
+	return c.VaultAddr != ""
+}
+
+// AuthEnabled reports whether any authentication method is configured
+func (c *Config) AuthEnabled() bool {
+	return c.AuthPassword != "" || c.BasicAuthPass != "" || len(c.APIKeys) > 0 || c.OIDCIssuer != ""
+}
+
+// secretVariables may instead be read from the file named by NAME_FILE, as
+// with Docker or Kubernetes secrets. CREDENTIALS_KEY_FILE has its own field.
+var secretVariables = []string{
+	"DATABASE_URL",
+	"GASETTEN_USER",
+	"GASETTEN_PASS",
+	"SUMMARY_API_KEY",
+	"AUTH_SECRET",
+	"AUTH_PASSWORD",
+	"BASIC_AUTH_PASS",
+	"API_KEYS",
+	"OIDC_CLIENT_SECRET",
+	"SYNC_API_KEY",
+	"STORAGE_ACCESS_KEY",
+	"STORAGE_SECRET_KEY",
+	"WAYBACK_ACCESS_KEY",
+	"WAYBACK_SECRET_KEY",
+	"EXPORT_ENCRYPTION_KEY",
+	"NOTIFY_WEBHOOK_URL",
+	"VAULT_TOKEN",
+	"VAULT_SECRET_ID",
+}
+
+// checkSecretFiles reports secret variables set twice or given as files
+// that can't be read, before the getters below read them
+func checkSecretFiles() error {
+	for _, key := range secretVariables {
+		if os.Getenv(key) != "" && os.Getenv(key+"_FILE") != "" {
+			return fmt.Errorf("set either %s or %s_FILE, not both", key, key)
+		}
+		if _, err := Secret(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupEnv returns the variable key, read from its file for secrets
+func lookupEnv(key string) string {
+	if !slices.Contains(secretVariables, key) {
+		return os.Getenv(key)
+	}
+	value, _ := Secret(key)
+	return value
+}
+
+// Secret returns the variable key or, when that is unset, the contents of
+// the file named by key_FILE without its trailing newline
+func Secret(key string) (string, error) {
 	if value := os.Getenv(key); value != "" {
+		return value, nil
+	}
+	file := os.Getenv(key + "_FILE")
+	if file == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s_FILE: %w", key, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := lookupEnv(key); value != "" {
 		return value
 	}
 	return defaultValue
@@ -86,3 +502,40 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	}
 	return value
 }
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := time.ParseDuration(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// parsePrefix parses a CIDR range, or a single IP as a range of one
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// getEnvAsList splits a comma-separated variable, dropping empty entries
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(lookupEnv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
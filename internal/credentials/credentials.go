@@ -0,0 +1,223 @@
+// Package credentials keeps source usernames and passwords in the database,
+// sealed with AES-256-GCM under a key from the configuration, so they need
+// not be set as plain environment variables. Each credential is bound to
+// its name, so sealed values can't be swapped between rows.
+package credentials
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tkilaker/kiln/internal/database"
+)
+
+// keyIDSize is the number of bytes of the key's hash that identify it
+const keyIDSize = 8
+
+// ErrNoKey is returned when credentials are used without a key configured
+var ErrNoKey = errors.New("no credentials key; set CREDENTIALS_KEY or CREDENTIALS_KEY_FILE")
+
+// ParseKey decodes a key: 32 random bytes, base64 encoded (e.g. from kiln
+// credentials keygen or openssl rand -base64 32)
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("credentials key is not valid base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("credentials key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// LoadKey returns the key given directly or, when that is empty, read from
+// file. It returns nil when both are empty.
+func LoadKey(encoded, file string) ([]byte, error) {
+	if encoded == "" && file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read credentials key file: %w", err)
+		}
+		encoded = string(data)
+	}
+	if strings.TrimSpace(encoded) == "" {
+		return nil, nil
+	}
+	return ParseKey(encoded)
+}
+
+// NewKey returns a new random key, base64 encoded
+func NewKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// Store seals and opens the credentials kept in the database
+type Store struct {
+	db    *database.DB
+	aead  cipher.AEAD
+	keyID string
+}
+
+// New returns a store using key. A nil key gives a store whose every
+// operation fails with ErrNoKey.
+func New(db *database.DB, key []byte) (*Store, error) {
+	s := &Store{db: db}
+	if key == nil {
+		return s, nil
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credentials key: %w", err)
+	}
+	s.aead, err = cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	s.keyID = keyID(key)
+	return s, nil
+}
+
+// keyID identifies a key without revealing it
+func keyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:keyIDSize])
+}
+
+// secret is the sealed plaintext
+type secret struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Get returns the username and password stored as name
+func (s *Store) Get(ctx context.Context, name string) (string, string, error) {
+	if s.aead == nil {
+		return "", "", ErrNoKey
+	}
+	c, err := s.db.GetCredential(ctx, name)
+	if err != nil {
+		return "", "", err
+	}
+	sec, err := s.open(c)
+	if err != nil {
+		return "", "", err
+	}
+	return sec.Username, sec.Password, nil
+}
+
+// Set stores a username and password as name, replacing what was there
+func (s *Store) Set(ctx context.Context, name, username, password string) error {
+	if s.aead == nil {
+		return ErrNoKey
+	}
+	c, err := s.seal(name, secret{Username: username, Password: password})
+	if err != nil {
+		return err
+	}
+	return s.db.PutCredential(ctx, c)
+}
+
+// Delete removes the credentials stored as name
+func (s *Store) Delete(ctx context.Context, name string) error {
+	return s.db.DeleteCredential(ctx, name)
+}
+
+// List returns the stored credentials, still sealed
+func (s *Store) List(ctx context.Context) ([]*database.SealedCredential, error) {
+	return s.db.GetCredentials(ctx)
+}
+
+// Rotate seals every credential again under newKey, all at once, and
+// returns how many there were. The store keeps using its own key; restart
+// with the new one configured.
+func (s *Store) Rotate(ctx context.Context, newKey []byte) (int, error) {
+	if s.aead == nil {
+		return 0, ErrNoKey
+	}
+	next, err := New(s.db, newKey)
+	if err != nil {
+		return 0, err
+	}
+	if next.keyID == s.keyID {
+		return 0, fmt.Errorf("the new key is the current key")
+	}
+
+	stored, err := s.db.GetCredentials(ctx)
+	if err != nil {
+		return 0, err
+	}
+	resealed := make([]*database.SealedCredential, 0, len(stored))
+	for _, c := range stored {
+		sec, err := s.open(c)
+		if err != nil {
+			return 0, err
+		}
+		sealed, err := next.seal(c.Name, sec)
+		if err != nil {
+			return 0, err
+		}
+		resealed = append(resealed, sealed)
+	}
+	if err := s.db.ReplaceCredentialSeals(ctx, s.keyID, resealed); err != nil {
+		return 0, err
+	}
+	return len(resealed), nil
+}
+
+// seal encrypts a secret as a random nonce followed by the ciphertext,
+// authenticated with its name
+func (s *Store) seal(name string, sec secret) (*database.SealedCredential, error) {
+	plain, err := json.Marshal(sec)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return &database.SealedCredential{
+		Name:   name,
+		KeyID:  s.keyID,
+		Sealed: s.aead.Seal(nonce, nonce, plain, []byte(name)),
+	}, nil
+}
+
+// open decrypts a stored credential
+func (s *Store) open(c *database.SealedCredential) (secret, error) {
+	var sec secret
+	if c.KeyID != s.keyID {
+		return sec, fmt.Errorf("credentials %s are sealed with another key (%s)", c.Name, c.KeyID)
+	}
+	size := s.aead.NonceSize()
+	if len(c.Sealed) < size {
+		return sec, fmt.Errorf("credentials %s are damaged", c.Name)
+	}
+	plain, err := s.aead.Open(nil, c.Sealed[:size], c.Sealed[size:], []byte(c.Name))
+	if err != nil {
+		return sec, fmt.Errorf("failed to decrypt credentials %s: %w", c.Name, err)
+	}
+	if err := json.Unmarshal(plain, &sec); err != nil {
+		return sec, fmt.Errorf("credentials %s are damaged: %w", c.Name, err)
+	}
+	return sec, nil
+}
+
+// KeyID returns the ID of the store's key, as recorded with each
+// credential, or "" without a key
+func (s *Store) KeyID() string {
+	return s.keyID
+}
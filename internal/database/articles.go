@@ -8,24 +8,147 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
-// CreateArticle inserts a new article into the database
-func (db *DB) CreateArticle(ctx context.Context, article *Article) error {
-	query := `
-		INSERT INTO articles (source, url, title, author, published_at, content_html, content_text)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, created_at, updated_at
-	`
+// articleColumns is the column list matching scanArticle; it selects from
+// articlesWithContent
+const articleColumns = `id, uuid, source, url, title, author, published_at, content_html, content_text, image_url, created_at, updated_at, duplicate_of, word_count, partial, wayback_url, categories, audio_url, audio_type, summary, content_html_z, content_text_z`
+
+// articlesWithContent joins articles with their content for articleColumns
+const articlesWithContent = `articles LEFT JOIN article_content ON article_content.article_id = articles.id`
+
+// articleSummaryColumns selects the same columns for lists of articles from
+// the articles table alone: content_html is NULL and content_text is the
+// excerpt, long enough for the article cards and the feed descriptions
+const articleSummaryColumns = `id, uuid, source, url, title, author, published_at, NULL::text, excerpt, image_url, created_at, updated_at, duplicate_of, word_count, partial, wayback_url, categories, audio_url, audio_type, summary, NULL::bytea, NULL::bytea`
+
+// articleSearch matches articles whose title or text matches the search
+// query in param, for ArticleFilter.Query. Titles and texts have a search
+// index each; only the excerpt of compressed articles is searched.
+func articleSearch(param string) string {
+	query := `websearch_to_tsquery('simple', ` + param + `)`
+	return `(to_tsvector('simple', COALESCE(title, '')) @@ ` + query + `
+		OR id IN (SELECT article_id FROM article_content WHERE to_tsvector('simple', COALESCE(content_text, '')) @@ ` + query + `))`
+}
+
+// articleUnread matches the articles not marked read, for
+// ArticleFilter.Unread
+const articleUnread = `NOT EXISTS (SELECT 1 FROM article_reads r WHERE r.article_id = articles.id)`
+
+// scanArticle scans a single article row selected with articleColumns or
+// articleSummaryColumns
+func scanArticle(row pgx.Row) (*Article, error) {
+	var article Article
+	var htmlZ, textZ []byte
+	err := row.Scan(
+		&article.ID,
+		&article.UUID,
+		&article.Source,
+		&article.URL,
+		&article.Title,
+		&article.Author,
+		&article.PublishedAt,
+		&article.ContentHTML,
+		&article.ContentText,
+		&article.ImageURL,
+		&article.CreatedAt,
+		&article.UpdatedAt,
+		&article.DuplicateOf,
+		&article.WordCount,
+		&article.Partial,
+		&article.WaybackURL,
+		&article.Categories,
+		&article.AudioURL,
+		&article.AudioType,
+		&article.Summary,
+		&htmlZ,
+		&textZ,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadContent(&article, htmlZ, textZ); err != nil {
+		return nil, err
+	}
+	return &article, nil
+}
+
+// queryArticles runs a query selecting articleColumns or
+// articleSummaryColumns and collects the rows
+func (db *DB) queryArticles(ctx context.Context, query string, args ...any) ([]*Article, error) {
+	rows, err := db.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query articles: %w", err)
+	}
+	defer rows.Close()
+
+	var articles []*Article
+	for rows.Next() {
+		article, err := scanArticle(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan article: %w", err)
+		}
+		articles = append(articles, article)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating articles: %w", err)
+	}
+
+	return articles, nil
+}
 
-	err := db.pool.QueryRow(ctx, query,
+// createArticleQuery inserts an article and its content and returns its
+// generated columns
+const createArticleQuery = `
+	WITH a AS (
+		INSERT INTO articles (source, url, title, author, published_at, excerpt, image_url, word_count, partial, categories, audio_url, audio_type)
+		VALUES ($1, $2, $3, $4, $5, $14, $8, $9, $10, COALESCE($11, '{}'::text[]), $15, $16)
+		RETURNING id, uuid, created_at, updated_at
+	), c AS (
+		INSERT INTO article_content (article_id, content_html, content_text, content_html_z, content_text_z)
+		SELECT id, $6, $7, $12, $13 FROM a
+	)
+	SELECT id, uuid, created_at, updated_at FROM a
+`
+
+// createArticleArgs counts an article's words and returns the arguments of
+// createArticleQuery for it
+func (db *DB) createArticleArgs(article *Article) ([]any, error) {
+	article.WordCount = CountWords(article.ContentText)
+	content, err := db.storeContent(article)
+	if err != nil {
+		return nil, err
+	}
+	return []any{
 		article.Source,
 		article.URL,
 		article.Title,
 		article.Author,
 		article.PublishedAt,
-		article.ContentHTML,
-		article.ContentText,
-	).Scan(&article.ID, &article.CreatedAt, &article.UpdatedAt)
+		content.html,
+		content.text,
+		article.ImageURL,
+		article.WordCount,
+		article.Partial,
+		article.Categories,
+		content.htmlZ,
+		content.textZ,
+		content.excerpt,
+		article.AudioURL,
+		article.AudioType,
+	}, nil
+}
+
+// CreateArticle inserts a new article into the database
+func (db *DB) CreateArticle(ctx context.Context, article *Article) error {
+	defer db.articlesChanged()
+
+	args, err := db.createArticleArgs(article)
+	if err != nil {
+		return err
+	}
 
+	err = db.pool.QueryRow(ctx, createArticleQuery, args...).
+		Scan(&article.ID, &article.UUID, &article.CreatedAt, &article.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create article: %w", err)
 	}
@@ -33,28 +156,205 @@ func (db *DB) CreateArticle(ctx context.Context, article *Article) error {
 	return nil
 }
 
-// GetArticleByID retrieves an article by its ID
-func (db *DB) GetArticleByID(ctx context.Context, id int) (*Article, error) {
+// CreateArticles inserts new articles in one round-trip and transaction.
+// Either all of them are saved or, on error, none are.
+func (db *DB) CreateArticles(ctx context.Context, articles []*Article) error {
+	if len(articles) == 0 {
+		return nil
+	}
+	defer db.articlesChanged()
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	for _, article := range articles {
+		args, err := db.createArticleArgs(article)
+		if err != nil {
+			return err
+		}
+		batch.Queue(createArticleQuery, args...)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	for _, article := range articles {
+		err := results.QueryRow().Scan(&article.ID, &article.UUID, &article.CreatedAt, &article.UpdatedAt)
+		if err != nil {
+			results.Close()
+			return fmt.Errorf("failed to create article %s: %w", article.URL, err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		return fmt.Errorf("failed to create articles: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to save articles: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateArticleMetadata saves an article's title, author and published date
+func (db *DB) UpdateArticleMetadata(ctx context.Context, article *Article) error {
+	defer db.articlesChanged()
+
 	query := `
-		SELECT id, source, url, title, author, published_at, content_html, content_text, created_at, updated_at
-		FROM articles
+		UPDATE articles
+		SET title = $2, author = $3, published_at = $4
 		WHERE id = $1
+		RETURNING updated_at
 	`
 
-	var article Article
-	err := db.pool.QueryRow(ctx, query, id).Scan(
-		&article.ID,
-		&article.Source,
-		&article.URL,
-		&article.Title,
-		&article.Author,
-		&article.PublishedAt,
-		&article.ContentHTML,
-		&article.ContentText,
-		&article.CreatedAt,
-		&article.UpdatedAt,
-	)
+	err := db.pool.QueryRow(ctx, query, article.ID, article.Title, article.Author, article.PublishedAt).
+		Scan(&article.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("article not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update article: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateArticleContent replaces the scraped fields of an existing article,
+// e.g. when a partial article is scraped again
+func (db *DB) UpdateArticleContent(ctx context.Context, article *Article) error {
+	defer db.articlesChanged()
+
+	article.WordCount = CountWords(article.ContentText)
+	content, err := db.storeContent(article)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		WITH a AS (
+			UPDATE articles
+			SET title = $2, author = $3, published_at = $4, excerpt = $13,
+			    image_url = $7, word_count = $8, partial = $9,
+			    categories = COALESCE($10, '{}'::text[]), audio_url = $14, audio_type = $15
+			WHERE id = $1
+			RETURNING id, uuid, created_at, updated_at
+		), c AS (
+			INSERT INTO article_content (article_id, content_html, content_text, content_html_z, content_text_z)
+			SELECT id, $5, $6, $11, $12 FROM a
+			ON CONFLICT (article_id) DO UPDATE SET
+				content_html = EXCLUDED.content_html,
+				content_text = EXCLUDED.content_text,
+				content_html_z = EXCLUDED.content_html_z,
+				content_text_z = EXCLUDED.content_text_z
+		)
+		SELECT uuid, created_at, updated_at FROM a
+	`
+
+	err = db.pool.QueryRow(ctx, query,
+		article.ID,
+		article.Title,
+		article.Author,
+		article.PublishedAt,
+		content.html,
+		content.text,
+		article.ImageURL,
+		article.WordCount,
+		article.Partial,
+		article.Categories,
+		content.htmlZ,
+		content.textZ,
+		content.excerpt,
+		article.AudioURL,
+		article.AudioType,
+	).Scan(&article.UUID, &article.CreatedAt, &article.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("article not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update article content: %w", err)
+	}
+
+	return nil
+}
+
+// SetArticleWaybackURL records the Wayback Machine snapshot of an article
+func (db *DB) SetArticleWaybackURL(ctx context.Context, id int, snapshotURL string) error {
+	defer db.articlesChanged()
+
+	tag, err := db.pool.Exec(ctx, `UPDATE articles SET wayback_url = $2, updated_at = NOW() WHERE id = $1`, id, snapshotURL)
+	if err != nil {
+		return fmt.Errorf("failed to set wayback URL: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("article not found")
+	}
+	return nil
+}
+
+// SetArticleSummary records the generated summary of an article
+func (db *DB) SetArticleSummary(ctx context.Context, id int, summary string) error {
+	defer db.articlesChanged()
+
+	tag, err := db.pool.Exec(ctx, `UPDATE articles SET summary = $2, updated_at = NOW() WHERE id = $1`, id, summary)
+	if err != nil {
+		return fmt.Errorf("failed to set summary: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("article not found")
+	}
+	return nil
+}
+
+// LoadArticleContent fills in the full content of articles retrieved as
+// summaries, e.g. for feeds carrying the whole article
+func (db *DB) LoadArticleContent(ctx context.Context, articles []*Article) error {
+	byID := make(map[int]*Article, len(articles))
+	ids := make([]int, 0, len(articles))
+	for _, article := range articles {
+		byID[article.ID] = article
+		ids = append(ids, article.ID)
+	}
+
+	query := `
+		SELECT article_id, content_html, content_text, content_html_z, content_text_z
+		FROM article_content
+		WHERE article_id = ANY($1)
+	`
+
+	rows, err := db.pool.Query(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("failed to query article content: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var html, text *string
+		var htmlZ, textZ []byte
+		if err := rows.Scan(&id, &html, &text, &htmlZ, &textZ); err != nil {
+			return fmt.Errorf("failed to scan article content: %w", err)
+		}
+		article := byID[id]
+		article.ContentHTML, article.ContentText = html, text
+		if err := loadContent(article, htmlZ, textZ); err != nil {
+			return fmt.Errorf("failed to load article content: %w", err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating article content: %w", err)
+	}
+
+	return nil
+}
+
+// GetArticleByID retrieves an article by its ID
+func (db *DB) GetArticleByID(ctx context.Context, id int) (*Article, error) {
+	query := `SELECT ` + articleColumns + ` FROM ` + articlesWithContent + ` WHERE id = $1`
 
+	article, err := scanArticle(db.pool.QueryRow(ctx, query, id))
 	if err == pgx.ErrNoRows {
 		return nil, fmt.Errorf("article not found")
 	}
@@ -62,31 +362,29 @@ func (db *DB) GetArticleByID(ctx context.Context, id int) (*Article, error) {
 		return nil, fmt.Errorf("failed to get article: %w", err)
 	}
 
-	return &article, nil
+	return article, nil
+}
+
+// GetArticleByUUID retrieves an article by its public UUID
+func (db *DB) GetArticleByUUID(ctx context.Context, uuid string) (*Article, error) {
+	query := `SELECT ` + articleColumns + ` FROM ` + articlesWithContent + ` WHERE uuid = $1`
+
+	article, err := scanArticle(db.pool.QueryRow(ctx, query, uuid))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("article not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get article: %w", err)
+	}
+
+	return article, nil
 }
 
 // GetArticleByURL retrieves an article by its URL (for deduplication)
 func (db *DB) GetArticleByURL(ctx context.Context, url string) (*Article, error) {
-	query := `
-		SELECT id, source, url, title, author, published_at, content_html, content_text, created_at, updated_at
-		FROM articles
-		WHERE url = $1
-	`
-
-	var article Article
-	err := db.pool.QueryRow(ctx, query, url).Scan(
-		&article.ID,
-		&article.Source,
-		&article.URL,
-		&article.Title,
-		&article.Author,
-		&article.PublishedAt,
-		&article.ContentHTML,
-		&article.ContentText,
-		&article.CreatedAt,
-		&article.UpdatedAt,
-	)
+	query := `SELECT ` + articleColumns + ` FROM ` + articlesWithContent + ` WHERE url = $1`
 
+	article, err := scanArticle(db.pool.QueryRow(ctx, query, url))
 	if err == pgx.ErrNoRows {
 		return nil, nil // Return nil if not found (not an error for deduplication check)
 	}
@@ -94,94 +392,251 @@ func (db *DB) GetArticleByURL(ctx context.Context, url string) (*Article, error)
 		return nil, fmt.Errorf("failed to get article by URL: %w", err)
 	}
 
-	return &article, nil
+	return article, nil
 }
 
-// GetAllArticles retrieves all articles, ordered by most recent first
+// GetAllArticles retrieves summaries of all articles except duplicates,
+// ordered by most recent first
 func (db *DB) GetAllArticles(ctx context.Context, limit int) ([]*Article, error) {
 	query := `
-		SELECT id, source, url, title, author, published_at, content_html, content_text, created_at, updated_at
+		SELECT ` + articleSummaryColumns + `
+		FROM articles
+		WHERE duplicate_of IS NULL
+		ORDER BY COALESCE(published_at, created_at) DESC
+		LIMIT $1
+	`
+
+	return db.queryArticles(ctx, query, limit)
+}
+
+// GetFilteredArticles retrieves summaries of the articles matching the
+// filter, ordered by most recent first. Duplicates of other articles are left
+// out. Date bounds apply to the published date, falling back to the time the
+// article was scraped.
+func (db *DB) GetFilteredArticles(ctx context.Context, filter ArticleFilter, limit int) ([]*Article, error) {
+	var q articleQuery
+	q.where(`duplicate_of IS NULL`)
+	q.filter(filter)
+
+	query := `
+		SELECT ` + articleSummaryColumns + `
+		FROM articles
+		WHERE ` + q.clause() + `
+		ORDER BY COALESCE(published_at, created_at) DESC
+		LIMIT ` + q.param(limit)
+
+	return db.queryArticles(ctx, query, q.args...)
+}
+
+// GetArticlesBefore retrieves summaries of the articles matching the filter
+// that were created before the cursor, newest first by creation time. Paging
+// by (created_at, id) keeps deep pages as cheap as the first. Duplicates of
+// other articles are left out.
+func (db *DB) GetArticlesBefore(ctx context.Context, filter ArticleFilter, cursor ArticleCursor, limit int) ([]*Article, error) {
+	var q articleQuery
+	q.where(`duplicate_of IS NULL`)
+	q.filter(filter)
+	if !cursor.IsZero() {
+		q.where(`(created_at, id) < (` + q.param(cursor.CreatedAt) + `, ` + q.param(cursor.ID) + `)`)
+	}
+
+	query := `
+		SELECT ` + articleSummaryColumns + `
+		FROM articles
+		WHERE ` + q.clause() + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ` + q.param(limit)
+
+	return db.queryArticles(ctx, query, q.args...)
+}
+
+// GetRecentArticles retrieves summaries of the articles published within a
+// time range
+func (db *DB) GetRecentArticles(ctx context.Context, since time.Time, limit int) ([]*Article, error) {
+	return db.GetRecentArticlesFiltered(ctx, since, ArticleFilter{}, limit)
+}
+
+// GetRecentArticlesFiltered retrieves summaries of the articles published
+// within a time range and matching the filter. Duplicates of other articles
+// and partial (teaser) articles are left out.
+func (db *DB) GetRecentArticlesFiltered(ctx context.Context, since time.Time, filter ArticleFilter, limit int) ([]*Article, error) {
+	var q articleQuery
+	q.where(`published_at >= ` + q.param(since))
+	q.where(`duplicate_of IS NULL`)
+	q.where(`NOT partial`)
+	q.filter(filter)
+
+	query := `
+		SELECT ` + articleSummaryColumns + `
+		FROM articles
+		WHERE ` + q.clause() + `
+		ORDER BY published_at DESC
+		LIMIT ` + q.param(limit)
+
+	return db.queryArticles(ctx, query, q.args...)
+}
+
+// GetRecentArticleContent retrieves the full articles scraped since the
+// given time, newest first, for reading offline. Duplicates of other
+// articles are left out.
+func (db *DB) GetRecentArticleContent(ctx context.Context, since time.Time, limit int) ([]*Article, error) {
+	query := `
+		SELECT ` + articleColumns + `
+		FROM ` + articlesWithContent + `
+		WHERE created_at >= $1
+		  AND duplicate_of IS NULL
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`
+
+	return db.queryArticles(ctx, query, since, limit)
+}
+
+// GetSimilarTitles retrieves summaries of the articles whose title contains
+// something close to text, best match first. It suggests articles when a
+// search finds nothing, e.g. because a name was misspelled.
+func (db *DB) GetSimilarTitles(ctx context.Context, text string, limit int) ([]*Article, error) {
+	query := `
+		SELECT ` + articleSummaryColumns + `
+		FROM articles
+		WHERE duplicate_of IS NULL
+		  AND $1 <% title
+		ORDER BY word_similarity($1, title) DESC, COALESCE(published_at, created_at) DESC
+		LIMIT $2
+	`
+
+	return db.queryArticles(ctx, query, text, limit)
+}
+
+// GetDuplicateCandidates returns original articles with the same normalized
+// title as article, published within window of it, oldest first
+func (db *DB) GetDuplicateCandidates(ctx context.Context, article *Article, window time.Duration) ([]*Article, error) {
+	query := `
+		SELECT ` + articleColumns + `
+		FROM ` + articlesWithContent + `
+		WHERE id <> $1
+		  AND url <> $2
+		  AND duplicate_of IS NULL
+		  AND title_key <> ''
+		  AND title_key = (SELECT title_key FROM articles WHERE id = $1)
+		  AND COALESCE(published_at, created_at) BETWEEN $3 AND $4
+		ORDER BY created_at, id
+		LIMIT 10
+	`
+
+	published := article.CreatedAt
+	if article.PublishedAt != nil {
+		published = *article.PublishedAt
+	}
+
+	return db.queryArticles(ctx, query, article.ID, article.URL, published.Add(-window), published.Add(window))
+}
+
+// SetDuplicateOf marks an article as a duplicate of original
+func (db *DB) SetDuplicateOf(ctx context.Context, id, originalID int) error {
+	defer db.articlesChanged()
+
+	query := `UPDATE articles SET duplicate_of = $2 WHERE id = $1`
+
+	if _, err := db.pool.Exec(ctx, query, id, originalID); err != nil {
+		return fmt.Errorf("failed to mark duplicate: %w", err)
+	}
+
+	return nil
+}
+
+// GetDuplicates returns the articles marked as duplicates of id
+func (db *DB) GetDuplicates(ctx context.Context, id int) ([]*Article, error) {
+	query := `
+		SELECT ` + articleColumns + `
+		FROM ` + articlesWithContent + `
+		WHERE duplicate_of = $1
+		ORDER BY created_at
+	`
+
+	return db.queryArticles(ctx, query, id)
+}
+
+// GetSitemapEntries returns the articles to list in the sitemap, all but
+// duplicates, most recent first
+func (db *DB) GetSitemapEntries(ctx context.Context, limit int) ([]SitemapEntry, error) {
+	query := `
+		SELECT uuid, updated_at
 		FROM articles
+		WHERE duplicate_of IS NULL
 		ORDER BY COALESCE(published_at, created_at) DESC
 		LIMIT $1
 	`
 
 	rows, err := db.pool.Query(ctx, query, limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query articles: %w", err)
+		return nil, fmt.Errorf("failed to query sitemap entries: %w", err)
 	}
 	defer rows.Close()
 
-	var articles []*Article
+	var entries []SitemapEntry
 	for rows.Next() {
-		var article Article
-		err := rows.Scan(
-			&article.ID,
-			&article.Source,
-			&article.URL,
-			&article.Title,
-			&article.Author,
-			&article.PublishedAt,
-			&article.ContentHTML,
-			&article.ContentText,
-			&article.CreatedAt,
-			&article.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan article: %w", err)
+		var entry SitemapEntry
+		if err := rows.Scan(&entry.UUID, &entry.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan sitemap entry: %w", err)
 		}
-		articles = append(articles, &article)
+		entries = append(entries, entry)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating articles: %w", err)
+		return nil, fmt.Errorf("error iterating sitemap entries: %w", err)
 	}
 
-	return articles, nil
+	return entries, nil
 }
 
-// GetRecentArticles retrieves articles published within a time range
-func (db *DB) GetRecentArticles(ctx context.Context, since time.Time, limit int) ([]*Article, error) {
+// GetSourceCounts returns every article source with its number of articles
+func (db *DB) GetSourceCounts(ctx context.Context) ([]FacetCount, error) {
 	query := `
-		SELECT id, source, url, title, author, published_at, content_html, content_text, created_at, updated_at
+		SELECT source, COUNT(*)
 		FROM articles
-		WHERE published_at >= $1
-		ORDER BY published_at DESC
-		LIMIT $2
+		GROUP BY source
+		ORDER BY COUNT(*) DESC, source
 	`
 
-	rows, err := db.pool.Query(ctx, query, since, limit)
+	return db.queryFacets(ctx, query)
+}
+
+// GetAuthorCounts returns every known author with their number of articles
+func (db *DB) GetAuthorCounts(ctx context.Context) ([]FacetCount, error) {
+	query := `
+		SELECT author, COUNT(*)
+		FROM articles
+		WHERE author IS NOT NULL AND author <> ''
+		GROUP BY author
+		ORDER BY COUNT(*) DESC, author
+	`
+
+	return db.queryFacets(ctx, query)
+}
+
+// queryFacets runs a (name, count) aggregation query
+func (db *DB) queryFacets(ctx context.Context, query string, args ...any) ([]FacetCount, error) {
+	rows, err := db.pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query recent articles: %w", err)
+		return nil, fmt.Errorf("failed to query counts: %w", err)
 	}
 	defer rows.Close()
 
-	var articles []*Article
+	var facets []FacetCount
 	for rows.Next() {
-		var article Article
-		err := rows.Scan(
-			&article.ID,
-			&article.Source,
-			&article.URL,
-			&article.Title,
-			&article.Author,
-			&article.PublishedAt,
-			&article.ContentHTML,
-			&article.ContentText,
-			&article.CreatedAt,
-			&article.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan article: %w", err)
+		var facet FacetCount
+		if err := rows.Scan(&facet.Name, &facet.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan count: %w", err)
 		}
-		articles = append(articles, &article)
+		facets = append(facets, facet)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating recent articles: %w", err)
+		return nil, fmt.Errorf("error iterating counts: %w", err)
 	}
 
-	return articles, nil
+	return facets, nil
 }
 
 // ArticleExists checks if an article with the given URL already exists
@@ -199,6 +654,8 @@ func (db *DB) ArticleExists(ctx context.Context, url string) (bool, error) {
 
 // DeleteArticle deletes a specific article by ID
 func (db *DB) DeleteArticle(ctx context.Context, id int) error {
+	defer db.articlesChanged()
+
 	query := `DELETE FROM articles WHERE id = $1`
 
 	result, err := db.pool.Exec(ctx, query, id)
@@ -215,6 +672,8 @@ func (db *DB) DeleteArticle(ctx context.Context, id int) error {
 
 // DeleteAllArticles deletes all articles from the database
 func (db *DB) DeleteAllArticles(ctx context.Context) (int64, error) {
+	defer db.articlesChanged()
+
 	query := `DELETE FROM articles`
 
 	result, err := db.pool.Exec(ctx, query)
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Audited actions
+const (
+	AuditArticleDelete   = "article.delete"
+	AuditArticleEdit     = "article.edit"
+	AuditArticleRescrape = "article.rescrape"
+	AuditArticlesClear   = "articles.clear"
+	AuditArticlesPrune   = "articles.prune"
+	AuditSourceCreate    = "source.create"
+	AuditSourceUpdate    = "source.update"
+	AuditSourceDelete    = "source.delete"
+	AuditSearchCreate    = "search.create"
+	AuditSearchDelete    = "search.delete"
+	AuditWatchCreate     = "watch.create"
+	AuditWatchDelete     = "watch.delete"
+)
+
+// AuditActorSystem is the actor of operations Kiln does by itself, such as
+// the nightly retention cleanup
+const AuditActorSystem = "system"
+
+// auditColumns is the column list matching scanAuditEntry
+const auditColumns = `id, actor, auth_method, remote_addr, action, target, details, created_at`
+
+func scanAuditEntry(row pgx.Row) (*AuditEntry, error) {
+	var entry AuditEntry
+	var details []byte
+	err := row.Scan(
+		&entry.ID,
+		&entry.Actor,
+		&entry.AuthMethod,
+		&entry.RemoteAddr,
+		&entry.Action,
+		&entry.Target,
+		&details,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	entry.Details = details
+	return &entry, nil
+}
+
+// NewAuditEntry prepares an entry for action by actor, with details encoded
+// as JSON (nil for none)
+func NewAuditEntry(actor, action, target string, details any) (*AuditEntry, error) {
+	entry := &AuditEntry{Actor: actor, Action: action}
+	if target != "" {
+		entry.Target = &target
+	}
+	if details != nil {
+		encoded, err := json.Marshal(details)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode audit details: %w", err)
+		}
+		entry.Details = encoded
+	}
+	return entry, nil
+}
+
+// CreateAuditEntry stores an audit log entry
+func (db *DB) CreateAuditEntry(ctx context.Context, entry *AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (actor, auth_method, remote_addr, action, target, details)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	// A nil RawMessage would be stored as JSON null rather than NULL
+	var details any
+	if len(entry.Details) > 0 {
+		details = entry.Details
+	}
+
+	err := db.pool.QueryRow(ctx, query, entry.Actor, entry.AuthMethod, entry.RemoteAddr, entry.Action, entry.Target, details).
+		Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// GetAuditEntries returns the newest audit log entries, only those of
+// action unless it is empty
+func (db *DB) GetAuditEntries(ctx context.Context, action string, limit int) ([]*AuditEntry, error) {
+	query := `
+		SELECT ` + auditColumns + `
+		FROM audit_log
+		WHERE $1 = '' OR action = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`
+
+	rows, err := db.pool.Query(ctx, query, action, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit log: %w", err)
+	}
+
+	return entries, nil
+}
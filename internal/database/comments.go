@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetComments returns an article's comment thread in order
+func (db *DB) GetComments(ctx context.Context, articleID int) ([]*Comment, error) {
+	query := `
+		SELECT id, article_id, position, author, posted_at, body, fetched_at
+		FROM comments
+		WHERE article_id = $1
+		ORDER BY position
+	`
+
+	rows, err := db.pool.Query(ctx, query, articleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comments: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []*Comment
+	for rows.Next() {
+		var c Comment
+		if err := rows.Scan(&c.ID, &c.ArticleID, &c.Position, &c.Author, &c.PostedAt, &c.Body, &c.FetchedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, &c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating comments: %w", err)
+	}
+
+	return comments, nil
+}
+
+// ReplaceComments replaces an article's stored comment thread with comments,
+// numbering them in the given order. The article counts as changed, so
+// instance sync and incremental exports carry the new thread.
+func (db *DB) ReplaceComments(ctx context.Context, articleID int, comments []*Comment) error {
+	defer db.articlesChanged()
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM comments WHERE article_id = $1`, articleID); err != nil {
+		return fmt.Errorf("failed to delete comments: %w", err)
+	}
+
+	query := `
+		INSERT INTO comments (article_id, position, author, posted_at, body)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	for i, c := range comments {
+		c.ArticleID = articleID
+		c.Position = i
+		if _, err := tx.Exec(ctx, query, articleID, i, c.Author, c.PostedAt, c.Body); err != nil {
+			return fmt.Errorf("failed to insert comment: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, touchArticle, articleID); err != nil {
+		return fmt.Errorf("failed to touch article: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to save comments: %w", err)
+	}
+
+	return nil
+}
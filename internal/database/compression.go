@@ -0,0 +1,155 @@
+package database
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Content compression codecs; CompressionNone stores content as plain text
+const (
+	CompressionNone = ""
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// ExcerptLength is how much of an article's text is kept as its excerpt,
+// enough for the article cards and the feed descriptions. Compressed
+// articles keep the excerpt uncompressed in content_text too, for search.
+const ExcerptLength = 1000
+
+// Frame headers, which tell the codec of stored content whatever the
+// current setting
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// zstd encoders and decoders are safe for concurrent use with EncodeAll
+// and DecodeAll
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// SetContentCompression selects the codec new article content is
+// compressed with. Content stored with another codec, or uncompressed, is
+// still read.
+func (db *DB) SetContentCompression(codec string) error {
+	switch codec {
+	case CompressionNone, CompressionGzip, CompressionZstd:
+		db.compression = codec
+		return nil
+	default:
+		return fmt.Errorf("unknown content compression %q", codec)
+	}
+}
+
+// storedContent is an article's content in the form it is written in
+type storedContent struct {
+	html, text   *string
+	htmlZ, textZ []byte
+	excerpt      *string
+}
+
+// storeContent prepares an article's content for writing. With compression
+// on, the content goes into the compressed columns and content_text keeps
+// the excerpt.
+func (db *DB) storeContent(article *Article) (storedContent, error) {
+	var excerpt *string
+	if article.ContentText != nil {
+		e := truncateUTF8(*article.ContentText, ExcerptLength)
+		excerpt = &e
+	}
+	if db.compression == CompressionNone {
+		return storedContent{html: article.ContentHTML, text: article.ContentText, excerpt: excerpt}, nil
+	}
+
+	stored := storedContent{text: excerpt, excerpt: excerpt}
+	var err error
+	if article.ContentHTML != nil {
+		if stored.htmlZ, err = compress(db.compression, *article.ContentHTML); err != nil {
+			return stored, err
+		}
+	}
+	if article.ContentText != nil {
+		if stored.textZ, err = compress(db.compression, *article.ContentText); err != nil {
+			return stored, err
+		}
+	}
+	return stored, nil
+}
+
+// loadContent replaces the content read into an article with its
+// decompressed form, when it was stored compressed
+func loadContent(article *Article, htmlZ, textZ []byte) error {
+	if htmlZ != nil {
+		html, err := decompress(htmlZ)
+		if err != nil {
+			return err
+		}
+		article.ContentHTML = &html
+	}
+	if textZ != nil {
+		text, err := decompress(textZ)
+		if err != nil {
+			return err
+		}
+		article.ContentText = &text
+	}
+	return nil
+}
+
+func compress(codec, s string) ([]byte, error) {
+	switch codec {
+	case CompressionZstd:
+		return zstdEncoder.EncodeAll([]byte(s), nil), nil
+	case CompressionGzip:
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write([]byte(s)); err != nil {
+			return nil, fmt.Errorf("failed to compress content: %w", err)
+		}
+		if err := gz.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress content: %w", err)
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unknown content compression %q", codec)
+	}
+}
+
+func decompress(b []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(b, zstdMagic):
+		out, err := zstdDecoder.DecodeAll(b, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to decompress content: %w", err)
+		}
+		return string(out), nil
+	case bytes.HasPrefix(b, gzipMagic):
+		gz, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return "", fmt.Errorf("failed to decompress content: %w", err)
+		}
+		out, err := io.ReadAll(gz)
+		if err != nil {
+			return "", fmt.Errorf("failed to decompress content: %w", err)
+		}
+		return string(out), nil
+	default:
+		return "", fmt.Errorf("failed to decompress content: unknown format")
+	}
+}
+
+// truncateUTF8 cuts s to at most n characters, like Postgres' left()
+func truncateUTF8(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
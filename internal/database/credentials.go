@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrCredentialNotFound is returned for a credential name that is not stored
+var ErrCredentialNotFound = errors.New("credentials not found")
+
+const credentialColumns = `name, key_id, sealed, created_at, updated_at`
+
+func scanCredential(row pgx.Row) (*SealedCredential, error) {
+	var c SealedCredential
+	if err := row.Scan(&c.Name, &c.KeyID, &c.Sealed, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetCredential returns the stored credential named name, or
+// ErrCredentialNotFound
+func (db *DB) GetCredential(ctx context.Context, name string) (*SealedCredential, error) {
+	query := `SELECT ` + credentialColumns + ` FROM credentials WHERE name = $1`
+
+	c, err := scanCredential(db.pool.QueryRow(ctx, query, name))
+	if err == pgx.ErrNoRows {
+		return nil, ErrCredentialNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
+	}
+	return c, nil
+}
+
+// GetCredentials returns every stored credential by name
+func (db *DB) GetCredentials(ctx context.Context) ([]*SealedCredential, error) {
+	query := `SELECT ` + credentialColumns + ` FROM credentials ORDER BY name`
+
+	rows, err := db.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credentials: %w", err)
+	}
+	defer rows.Close()
+
+	var credentials []*SealedCredential
+	for rows.Next() {
+		c, err := scanCredential(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan credentials: %w", err)
+		}
+		credentials = append(credentials, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating credentials: %w", err)
+	}
+
+	return credentials, nil
+}
+
+// PutCredential stores a credential, replacing any with the same name
+func (db *DB) PutCredential(ctx context.Context, c *SealedCredential) error {
+	query := `
+		INSERT INTO credentials (name, key_id, sealed)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET
+			key_id = EXCLUDED.key_id,
+			sealed = EXCLUDED.sealed,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+
+	if err := db.pool.QueryRow(ctx, query, c.Name, c.KeyID, c.Sealed).Scan(&c.CreatedAt, &c.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+	return nil
+}
+
+// DeleteCredential removes the credential named name
+func (db *DB) DeleteCredential(ctx context.Context, name string) error {
+	tag, err := db.pool.Exec(ctx, `DELETE FROM credentials WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete credentials: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCredentialNotFound
+	}
+	return nil
+}
+
+// ReplaceCredentialSeals saves credentials sealed again, e.g. under a new
+// key, all at once: each must still be sealed with oldKeyID, so a second
+// rotation running at the same time fails instead of mixing keys.
+func (db *DB) ReplaceCredentialSeals(ctx context.Context, oldKeyID string, credentials []*SealedCredential) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE credentials SET key_id = $2, sealed = $3, updated_at = NOW()
+		WHERE name = $1 AND key_id = $4
+	`
+	for _, c := range credentials {
+		tag, err := tx.Exec(ctx, query, c.Name, c.KeyID, c.Sealed, oldKeyID)
+		if err != nil {
+			return fmt.Errorf("failed to save credentials %s: %w", c.Name, err)
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("credentials %s changed while being sealed again", c.Name)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+	return nil
+}
@@ -3,18 +3,41 @@ package database
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // DB wraps the database connection pool
 type DB struct {
 	pool *pgxpool.Pool
+
+	// articlesVersion is bumped on every article write made through this
+	// DB, so callers can tell when cached article data went stale
+	articlesVersion atomic.Uint64
+
+	// compression is the codec new article content is stored with
+	compression string
 }
 
 // New creates a new database connection pool
-func New(ctx context.Context, databaseURL string) (*DB, error) {
-	pool, err := pgxpool.New(ctx, databaseURL)
+func New(ctx context.Context, databaseURL string, opts PoolOptions) (*DB, error) {
+	config, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database URL: %w", err)
+	}
+	if err := opts.apply(config); err != nil {
+		return nil, err
+	}
+
+	// Group dates (e.g. the monthly stats) in the configured time zone
+	if zone := time.Local.String(); zone != "Local" {
+		config.ConnConfig.RuntimeParams["timezone"] = zone
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
 	}
@@ -27,6 +50,82 @@ func New(ctx context.Context, databaseURL string) (*DB, error) {
 	return &DB{pool: pool}, nil
 }
 
+// PoolOptions tunes the connection pool. Zero values keep the pgx defaults,
+// or the pool_* and statement cache parameters of the database URL.
+type PoolOptions struct {
+	MaxConns          int
+	MinConns          int
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+
+	// StatementCache is how queries are prepared: "cache_statement",
+	// "cache_describe", "describe_exec", "exec" or "simple_protocol". The
+	// last two don't rely on prepared statements surviving between
+	// transactions, as PgBouncer's transaction pooling requires.
+	StatementCache     string
+	StatementCacheSize int
+
+	// Credentials, when set, returns the username and password each new
+	// connection logs in with, e.g. short-lived ones from Vault. Keep
+	// MaxConnLifetime below their lifetime so old connections are replaced.
+	Credentials func(ctx context.Context) (username, password string, err error)
+}
+
+// queryExecModes maps the StatementCache names to pgx modes
+var queryExecModes = map[string]pgx.QueryExecMode{
+	"cache_statement": pgx.QueryExecModeCacheStatement,
+	"cache_describe":  pgx.QueryExecModeCacheDescribe,
+	"describe_exec":   pgx.QueryExecModeDescribeExec,
+	"exec":            pgx.QueryExecModeExec,
+	"simple_protocol": pgx.QueryExecModeSimpleProtocol,
+}
+
+func (o PoolOptions) apply(config *pgxpool.Config) error {
+	if o.MaxConns > 0 {
+		config.MaxConns = int32(o.MaxConns)
+	}
+	if o.MinConns > 0 {
+		config.MinConns = int32(o.MinConns)
+	}
+	if config.MinConns > config.MaxConns {
+		return fmt.Errorf("minimum connections (%d) exceed the maximum (%d)", config.MinConns, config.MaxConns)
+	}
+	if o.MaxConnLifetime > 0 {
+		config.MaxConnLifetime = o.MaxConnLifetime
+	}
+	if o.MaxConnIdleTime > 0 {
+		config.MaxConnIdleTime = o.MaxConnIdleTime
+	}
+	if o.HealthCheckPeriod > 0 {
+		config.HealthCheckPeriod = o.HealthCheckPeriod
+	}
+
+	if o.StatementCache != "" {
+		mode, ok := queryExecModes[o.StatementCache]
+		if !ok {
+			return fmt.Errorf("unknown statement cache mode %q", o.StatementCache)
+		}
+		config.ConnConfig.DefaultQueryExecMode = mode
+	}
+	if o.StatementCacheSize > 0 {
+		config.ConnConfig.StatementCacheCapacity = o.StatementCacheSize
+		config.ConnConfig.DescriptionCacheCapacity = o.StatementCacheSize
+	}
+
+	if o.Credentials != nil {
+		config.BeforeConnect = func(ctx context.Context, conn *pgx.ConnConfig) error {
+			username, password, err := o.Credentials(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get database credentials: %w", err)
+			}
+			conn.User, conn.Password = username, password
+			return nil
+		}
+	}
+	return nil
+}
+
 // Close closes the database connection pool
 func (db *DB) Close() {
 	db.pool.Close()
@@ -36,3 +135,14 @@ func (db *DB) Close() {
 func (db *DB) Pool() *pgxpool.Pool {
 	return db.pool
 }
+
+// ArticlesVersion returns a number that changes whenever articles are
+// written through this DB
+func (db *DB) ArticlesVersion() uint64 {
+	return db.articlesVersion.Load()
+}
+
+// articlesChanged marks cached article data as stale
+func (db *DB) articlesChanged() {
+	db.articlesVersion.Add(1)
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// AddFeedAccess adds request counts per feed to the counters of day
+func (db *DB) AddFeedAccess(ctx context.Context, day time.Time, counts map[string]int) error {
+	query := `
+		INSERT INTO feed_access (feed, day, requests)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (feed, day) DO UPDATE SET requests = feed_access.requests + EXCLUDED.requests
+	`
+
+	batch := &pgx.Batch{}
+	for feed, count := range counts {
+		batch.Queue(query, feed, day, count)
+	}
+	if err := db.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to record feed access: %w", err)
+	}
+	return nil
+}
+
+// GetFeedAccessCounts returns the requests per feed since the given day,
+// busiest first
+func (db *DB) GetFeedAccessCounts(ctx context.Context, since time.Time) ([]FacetCount, error) {
+	query := `
+		SELECT feed, SUM(requests)::int
+		FROM feed_access
+		WHERE day >= $1
+		GROUP BY feed
+		ORDER BY SUM(requests) DESC, feed
+	`
+
+	return db.queryFacets(ctx, query, since)
+}
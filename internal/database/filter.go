@@ -0,0 +1,59 @@
+package database
+
+import (
+	"strconv"
+	"strings"
+)
+
+// articleQuery builds the WHERE clause of a query on the articles table,
+// numbering the parameters as they are added. Only the filters that are set
+// become conditions, so each query has just the predicates it needs.
+type articleQuery struct {
+	conditions []string
+	args       []any
+}
+
+// param adds an argument and returns its placeholder
+func (q *articleQuery) param(value any) string {
+	q.args = append(q.args, value)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// where adds a condition
+func (q *articleQuery) where(condition string) {
+	q.conditions = append(q.conditions, condition)
+}
+
+// filter adds the conditions of the set fields of filter. Date bounds apply
+// to the published date, falling back to the time the article was scraped.
+func (q *articleQuery) filter(filter ArticleFilter) {
+	if filter.Source != "" {
+		q.where(`source = ` + q.param(filter.Source))
+	}
+	if filter.Author != "" {
+		q.where(`author = ` + q.param(filter.Author))
+	}
+	if filter.From != nil {
+		q.where(`COALESCE(published_at, created_at) >= ` + q.param(*filter.From))
+	}
+	if filter.To != nil {
+		q.where(`COALESCE(published_at, created_at) < ` + q.param(*filter.To))
+	}
+	if filter.Tag != "" {
+		q.where(`id IN (SELECT article_id FROM article_tags WHERE tag = ` + q.param(filter.Tag) + `)`)
+	}
+	if filter.Query != "" {
+		q.where(articleSearch(q.param(filter.Query)))
+	}
+	if filter.Unread {
+		q.where(articleUnread)
+	}
+}
+
+// clause returns the conditions for a WHERE clause
+func (q *articleQuery) clause() string {
+	if len(q.conditions) == 0 {
+		return "TRUE"
+	}
+	return strings.Join(q.conditions, "\n\t\t  AND ")
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetSourceIcon retrieves the cached icon of a source. Returns nil without
+// error if it has not been fetched.
+func (db *DB) GetSourceIcon(ctx context.Context, source string) (*SourceIcon, error) {
+	query := `SELECT source, icon_url, content_type, data, fetched_at FROM source_icons WHERE source = $1`
+
+	var icon SourceIcon
+	err := db.pool.QueryRow(ctx, query, source).Scan(&icon.Source, &icon.IconURL, &icon.ContentType, &icon.Data, &icon.FetchedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source icon: %w", err)
+	}
+
+	return &icon, nil
+}
+
+// SaveSourceIcon caches the icon of a source, replacing the previous one
+func (db *DB) SaveSourceIcon(ctx context.Context, icon *SourceIcon) error {
+	query := `
+		INSERT INTO source_icons (source, icon_url, content_type, data, fetched_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (source) DO UPDATE SET
+			icon_url = EXCLUDED.icon_url,
+			content_type = EXCLUDED.content_type,
+			data = EXCLUDED.data,
+			fetched_at = EXCLUDED.fetched_at
+		RETURNING fetched_at
+	`
+
+	if err := db.pool.QueryRow(ctx, query, icon.Source, icon.IconURL, icon.ContentType, icon.Data).Scan(&icon.FetchedAt); err != nil {
+		return fmt.Errorf("failed to save source icon: %w", err)
+	}
+
+	return nil
+}
+
+// GetSourceSiteURL returns a URL on the site a source is scraped from: its
+// first start URL, else the URL of its newest article. Returns "" without
+// error for unknown sources.
+func (db *DB) GetSourceSiteURL(ctx context.Context, source string) (string, error) {
+	query := `
+		SELECT COALESCE(
+			(SELECT start_urls[1] FROM sources WHERE name = $1),
+			(SELECT url FROM articles WHERE source = $1 ORDER BY created_at DESC LIMIT 1),
+			''
+		)
+	`
+
+	var siteURL string
+	if err := db.pool.QueryRow(ctx, query, source).Scan(&siteURL); err != nil {
+		return "", fmt.Errorf("failed to get source site: %w", err)
+	}
+
+	return siteURL, nil
+}
@@ -0,0 +1,206 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Job statuses
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusDone      = "done"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+// ErrJobActive is returned when the same work is already queued or running
+var ErrJobActive = errors.New("already queued or running")
+
+// jobColumns is the column list matching scanJob
+const jobColumns = `id, kind, label, payload, status, attempts, error, paused, created_at, started_at, finished_at`
+
+func scanJob(row pgx.Row) (*Job, error) {
+	var job Job
+	err := row.Scan(
+		&job.ID,
+		&job.Kind,
+		&job.Label,
+		&job.Payload,
+		&job.Status,
+		&job.Attempts,
+		&job.Error,
+		&job.Paused,
+		&job.CreatedAt,
+		&job.StartedAt,
+		&job.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// EnqueueJob adds a pending job. A job with the same label that is pending
+// or running already makes it fail with ErrJobActive.
+func (db *DB) EnqueueJob(ctx context.Context, job *Job) error {
+	query := `
+		INSERT INTO jobs (id, kind, label, payload, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`
+
+	err := db.pool.QueryRow(ctx, query, job.ID, job.Kind, job.Label, job.Payload, JobStatusPending).
+		Scan(&job.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrJobActive
+	}
+	if err != nil {
+		return fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	job.Status = JobStatusPending
+	return nil
+}
+
+// ClaimJob marks the oldest pending job as running and returns it, or nil
+// when none is pending. Concurrent workers never claim the same job.
+func (db *DB) ClaimJob(ctx context.Context) (*Job, error) {
+	query := `
+		UPDATE jobs
+		SET status = $1, attempts = attempts + 1, started_at = NOW()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = $2
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + jobColumns
+
+	job, err := scanJob(db.pool.QueryRow(ctx, query, JobStatusRunning, JobStatusPending))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	return job, nil
+}
+
+// FinishJob records the outcome of a running job: done, cancelled, or
+// failed with the error that ended it
+func (db *DB) FinishJob(ctx context.Context, id string, jobErr error) error {
+	status := JobStatusDone
+	var message *string
+	switch {
+	case errors.Is(jobErr, context.Canceled):
+		status = JobStatusCancelled
+	case jobErr != nil:
+		status = JobStatusFailed
+		msg := jobErr.Error()
+		message = &msg
+	}
+
+	query := `UPDATE jobs SET status = $2, error = $3, finished_at = NOW() WHERE id = $1`
+
+	if _, err := db.pool.Exec(ctx, query, id, status, message); err != nil {
+		return fmt.Errorf("failed to finish job: %w", err)
+	}
+
+	return nil
+}
+
+// CancelPendingJob takes a job that has not started off the queue. It
+// reports false when the job is not pending.
+func (db *DB) CancelPendingJob(ctx context.Context, id string) (bool, error) {
+	query := `UPDATE jobs SET status = $3, finished_at = NOW() WHERE id = $1 AND status = $2`
+
+	tag, err := db.pool.Exec(ctx, query, id, JobStatusPending, JobStatusCancelled)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel job: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// SetJobPaused records that a job was paused or resumed
+func (db *DB) SetJobPaused(ctx context.Context, id string, paused bool) error {
+	query := `UPDATE jobs SET paused = $2 WHERE id = $1`
+
+	if _, err := db.pool.Exec(ctx, query, id, paused); err != nil {
+		return fmt.Errorf("failed to pause job: %w", err)
+	}
+
+	return nil
+}
+
+// RequeueJob returns a running job to the queue, e.g. when it was
+// interrupted by a shutdown
+func (db *DB) RequeueJob(ctx context.Context, id string) error {
+	query := `UPDATE jobs SET status = $2, started_at = NULL WHERE id = $1`
+
+	if _, err := db.pool.Exec(ctx, query, id, JobStatusPending); err != nil {
+		return fmt.Errorf("failed to requeue job: %w", err)
+	}
+
+	return nil
+}
+
+// RecoverJobs requeues the jobs left running by a previous process that
+// ended without finishing them. Jobs that already had maxAttempts attempts
+// are failed instead, so a job that crashes Kiln is not run forever.
+func (db *DB) RecoverJobs(ctx context.Context, maxAttempts int) (requeued, failed int, err error) {
+	query := `
+		UPDATE jobs
+		SET status = CASE WHEN attempts >= $3 THEN $4 ELSE $2 END,
+		    error = CASE WHEN attempts >= $3 THEN 'interrupted too many times' ELSE error END,
+		    finished_at = CASE WHEN attempts >= $3 THEN NOW() ELSE NULL END,
+		    started_at = CASE WHEN attempts >= $3 THEN started_at ELSE NULL END
+		WHERE status = $1
+		RETURNING status
+	`
+
+	rows, err := db.pool.Query(ctx, query, JobStatusRunning, JobStatusPending, maxAttempts, JobStatusFailed)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to recover jobs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		if err := rows.Scan(&status); err != nil {
+			return 0, 0, fmt.Errorf("failed to scan job: %w", err)
+		}
+		if status == JobStatusFailed {
+			failed++
+		} else {
+			requeued++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("error iterating jobs: %w", err)
+	}
+
+	return requeued, failed, nil
+}
+
+// DeleteFinishedJobs removes done, failed and cancelled jobs that finished
+// before cutoff
+func (db *DB) DeleteFinishedJobs(ctx context.Context, cutoff time.Time) (int64, error) {
+	query := `DELETE FROM jobs WHERE status IN ($1, $2, $3) AND finished_at < $4`
+
+	tag, err := db.pool.Exec(ctx, query, JobStatusDone, JobStatusFailed, JobStatusCancelled, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished jobs: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
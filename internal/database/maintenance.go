@@ -0,0 +1,195 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// TableSize is the disk usage of a table
+type TableSize struct {
+	Name       string `json:"name"`
+	Rows       int64  `json:"rows"`        // estimated live rows
+	DeadRows   int64  `json:"dead_rows"`   // estimated dead rows left for vacuum
+	TableBytes int64  `json:"table_bytes"` // the table with its TOAST data
+	IndexBytes int64  `json:"index_bytes"`
+	TotalBytes int64  `json:"total_bytes"`
+}
+
+// IndexSize is the disk usage of an index and how often it is used
+type IndexSize struct {
+	Name  string `json:"name"`
+	Table string `json:"table"`
+	Bytes int64  `json:"bytes"`
+	Scans int64  `json:"scans"`
+}
+
+// DuplicateURL is a group of articles whose URLs differ only in scheme,
+// "www.", case, fragment or a trailing slash
+type DuplicateURL struct {
+	Key  string   `json:"key"`
+	IDs  []int    `json:"ids"`
+	URLs []string `json:"urls"`
+}
+
+// Vacuum reclaims the space of deleted and updated rows and refreshes the
+// planner statistics of every table
+func (db *DB) Vacuum(ctx context.Context) error {
+	if _, err := db.pool.Exec(ctx, `VACUUM (ANALYZE)`); err != nil {
+		return fmt.Errorf("failed to vacuum: %w", err)
+	}
+	return nil
+}
+
+// GetTableSizes returns the disk usage of every table, largest first
+func (db *DB) GetTableSizes(ctx context.Context) ([]TableSize, error) {
+	query := `
+		SELECT relname, n_live_tup, n_dead_tup,
+		       pg_table_size(relid), pg_indexes_size(relid), pg_total_relation_size(relid)
+		FROM pg_stat_user_tables
+		ORDER BY pg_total_relation_size(relid) DESC, relname
+	`
+
+	rows, err := db.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table sizes: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []TableSize
+	for rows.Next() {
+		var t TableSize
+		if err := rows.Scan(&t.Name, &t.Rows, &t.DeadRows, &t.TableBytes, &t.IndexBytes, &t.TotalBytes); err != nil {
+			return nil, fmt.Errorf("failed to scan table size: %w", err)
+		}
+		tables = append(tables, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating table sizes: %w", err)
+	}
+
+	return tables, nil
+}
+
+// GetIndexSizes returns the disk usage of every index, largest first
+func (db *DB) GetIndexSizes(ctx context.Context) ([]IndexSize, error) {
+	query := `
+		SELECT indexrelname, relname, pg_relation_size(indexrelid), idx_scan
+		FROM pg_stat_user_indexes
+		ORDER BY pg_relation_size(indexrelid) DESC, indexrelname
+	`
+
+	rows, err := db.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query index sizes: %w", err)
+	}
+	defer rows.Close()
+
+	var indexes []IndexSize
+	for rows.Next() {
+		var i IndexSize
+		if err := rows.Scan(&i.Name, &i.Table, &i.Bytes, &i.Scans); err != nil {
+			return nil, fmt.Errorf("failed to scan index size: %w", err)
+		}
+		indexes = append(indexes, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating index sizes: %w", err)
+	}
+
+	return indexes, nil
+}
+
+// GetDuplicateURLs returns the groups of articles stored under URLs that
+// most likely point at the same page
+func (db *DB) GetDuplicateURLs(ctx context.Context) ([]DuplicateURL, error) {
+	query := `
+		SELECT key, array_agg(id ORDER BY id), array_agg(url ORDER BY id)
+		FROM (
+			SELECT id, url,
+			       rtrim(lower(regexp_replace(regexp_replace(url, '^https?://(www\.)?', ''), '#.*$', '')), '/') AS key
+			FROM articles
+		) normalized
+		GROUP BY key
+		HAVING COUNT(*) > 1
+		ORDER BY key
+	`
+
+	rows, err := db.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query duplicate URLs: %w", err)
+	}
+	defer rows.Close()
+
+	var duplicates []DuplicateURL
+	for rows.Next() {
+		var d DuplicateURL
+		if err := rows.Scan(&d.Key, &d.IDs, &d.URLs); err != nil {
+			return nil, fmt.Errorf("failed to scan duplicate URLs: %w", err)
+		}
+		duplicates = append(duplicates, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating duplicate URLs: %w", err)
+	}
+
+	return duplicates, nil
+}
+
+// GetMediaReferences returns the names of the mirrored images that articles
+// refer to, as lead images or in their content, through paths starting
+// with prefix
+func (db *DB) GetMediaReferences(ctx context.Context, prefix string) (map[string]bool, error) {
+	pattern := regexp.MustCompile(regexp.QuoteMeta(prefix) + `([0-9a-f]{32}(?:\.[a-z0-9]+)?)`)
+
+	query := `
+		SELECT image_url, content_html, content_html_z
+		FROM ` + articlesWithContent + `
+		WHERE image_url LIKE '%' || $1 || '%'
+		   OR content_html LIKE '%' || $1 || '%'
+		   OR content_html_z IS NOT NULL
+	`
+
+	rows, err := db.pool.Query(ctx, query, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query media references: %w", err)
+	}
+	defer rows.Close()
+
+	names := make(map[string]bool)
+	for rows.Next() {
+		var imageURL, html *string
+		var htmlZ []byte
+		if err := rows.Scan(&imageURL, &html, &htmlZ); err != nil {
+			return nil, fmt.Errorf("failed to scan media references: %w", err)
+		}
+
+		var text string
+		if imageURL != nil {
+			text += *imageURL + "\n"
+		}
+		if html != nil {
+			text += *html
+		}
+		if htmlZ != nil {
+			content, err := decompress(htmlZ)
+			if err != nil {
+				return nil, err
+			}
+			text += content
+		}
+
+		for _, m := range pattern.FindAllStringSubmatch(text, -1) {
+			names[m[1]] = true
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating media references: %w", err)
+	}
+
+	return names, nil
+}
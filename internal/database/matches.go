@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// GetMatches returns the matches kicking off after since in kickoff order,
+// of every source or only the one named
+func (db *DB) GetMatches(ctx context.Context, source string, since time.Time) ([]*Match, error) {
+	query := `
+		SELECT id, source, kickoff, home_team, away_team, competition, venue, result, url, updated_at
+		FROM matches
+		WHERE kickoff >= $1 AND ($2 = '' OR source = $2)
+		ORDER BY kickoff, id
+	`
+
+	rows, err := db.pool.Query(ctx, query, since, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query matches: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []*Match
+	for rows.Next() {
+		var m Match
+		if err := rows.Scan(&m.ID, &m.Source, &m.Kickoff, &m.HomeTeam, &m.AwayTeam, &m.Competition, &m.Venue, &m.Result, &m.URL, &m.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan match: %w", err)
+		}
+		matches = append(matches, &m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating matches: %w", err)
+	}
+
+	return matches, nil
+}
+
+// ReplaceMatches saves the matches scraped from a source's fixtures page.
+// The source's upcoming matches are replaced, so rescheduled and cancelled
+// ones drop out; played matches are updated, e.g. with their result.
+func (db *DB) ReplaceMatches(ctx context.Context, source string, matches []*Match) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM matches WHERE source = $1 AND kickoff > NOW()`, source); err != nil {
+		return fmt.Errorf("failed to delete upcoming matches: %w", err)
+	}
+
+	query := `
+		INSERT INTO matches (source, kickoff, home_team, away_team, competition, venue, result, url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (source, kickoff, home_team, away_team) DO UPDATE SET
+			competition = EXCLUDED.competition,
+			venue = EXCLUDED.venue,
+			result = COALESCE(EXCLUDED.result, matches.result),
+			url = COALESCE(EXCLUDED.url, matches.url),
+			updated_at = NOW()
+		RETURNING id, updated_at
+	`
+	for _, m := range matches {
+		m.Source = source
+		if err := tx.QueryRow(ctx, query, source, m.Kickoff, m.HomeTeam, m.AwayTeam, m.Competition, m.Venue, m.Result, m.URL).Scan(&m.ID, &m.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to save match: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to save matches: %w", err)
+	}
+
+	return nil
+}
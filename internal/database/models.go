@@ -1,10 +1,18 @@
 package database
 
-import "time"
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// WordsPerMinute is the reading speed used for reading time estimates
+const WordsPerMinute = 200
 
 // Article represents a scraped article from Gasetten or other sources
 type Article struct {
 	ID          int        `db:"id"`
+	UUID        string     `db:"uuid"`
 	Source      string     `db:"source"`
 	URL         string     `db:"url"`
 	Title       *string    `db:"title"`
@@ -12,6 +20,252 @@ type Article struct {
 	PublishedAt *time.Time `db:"published_at"`
 	ContentHTML *string    `db:"content_html"`
 	ContentText *string    `db:"content_text"`
+	ImageURL    *string    `db:"image_url"`
 	CreatedAt   time.Time  `db:"created_at"`
 	UpdatedAt   time.Time  `db:"updated_at"`
+	DuplicateOf *int       `db:"duplicate_of"`
+	WordCount   int        `db:"word_count"`
+	Partial     bool       `db:"partial"`     // content looks like a paywall teaser
+	WaybackURL  *string    `db:"wayback_url"` // Wayback Machine snapshot, once captured
+	Categories  []string   `db:"categories"`  // categories on the source site, as named there
+	AudioURL    *string    `db:"audio_url"`   // podcast episode the article embeds
+	AudioType   *string    `db:"audio_type"`  // MIME type of AudioURL, e.g. audio/mpeg
+	Summary     *string    `db:"summary"`     // written by a language model, once generated
+
+	// Tags, Read and Comments are only loaded for instance sync and export
+	// (see GetArticlesChangedSince); nil when not loaded
+	Tags     []ArticleTag
+	Read     *bool
+	Comments []*Comment
+}
+
+// ArticleTag is a tag of an article, extracted from its content or added by
+// hand
+type ArticleTag struct {
+	Tag    string
+	Manual bool
+}
+
+// ReadingTime estimates how long the article takes to read
+func (a *Article) ReadingTime() time.Duration {
+	return time.Duration(a.WordCount) * time.Minute / WordsPerMinute
+}
+
+// ReadingMinutes is the reading time rounded up to whole minutes, at least one
+func (a *Article) ReadingMinutes() int {
+	return max(1, (a.WordCount+WordsPerMinute-1)/WordsPerMinute)
+}
+
+// CountWords counts the whitespace-separated words in text
+func CountWords(text *string) int {
+	if text == nil {
+		return 0
+	}
+	return len(strings.Fields(*text))
+}
+
+// Comment is one comment from an article's comment thread
+type Comment struct {
+	ID        int        `db:"id"`
+	ArticleID int        `db:"article_id"`
+	Position  int        `db:"position"` // order within the thread
+	Author    *string    `db:"author"`
+	PostedAt  *time.Time `db:"posted_at"`
+	Body      string     `db:"body"`
+	FetchedAt time.Time  `db:"fetched_at"`
+}
+
+// ArticleFilter narrows article queries; empty fields match everything
+type ArticleFilter struct {
+	Source string
+	Author string
+	Tag    string
+	Query  string     // full-text search of the title and text
+	From   *time.Time // inclusive lower bound on the article date
+	To     *time.Time // exclusive upper bound on the article date
+	Unread bool       // only articles not marked read
+}
+
+// ArticleCursor is a position in the articles ordered newest first by
+// creation time; the zero cursor is the start of the list
+type ArticleCursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+// IsZero reports whether the cursor is the start of the list
+func (c ArticleCursor) IsZero() bool {
+	return c.CreatedAt.IsZero()
+}
+
+// SitemapEntry is an article as listed in the sitemap
+type SitemapEntry struct {
+	UUID      string
+	UpdatedAt time.Time
+}
+
+// FacetCount is a value (source, author, ...) with its number of articles
+type FacetCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// ScrapeRun records a single scrape run and its outcome
+type ScrapeRun struct {
+	ID              int        `db:"id" json:"id"`
+	Trigger         string     `db:"trigger" json:"trigger"`
+	Source          *string    `db:"source" json:"source,omitempty"` // nil when every source was scraped
+	Status          string     `db:"status" json:"status"`
+	StartedAt       time.Time  `db:"started_at" json:"started_at"`
+	FinishedAt      *time.Time `db:"finished_at" json:"finished_at,omitempty"`
+	ArticlesFound   int        `db:"articles_found" json:"articles_found"`
+	ArticlesAdded   int        `db:"articles_added" json:"articles_added"`
+	ArticlesSkipped int        `db:"articles_skipped" json:"articles_skipped"`
+	ArticlesFailed  int        `db:"articles_failed" json:"articles_failed"`
+	Error           *string    `db:"error" json:"error,omitempty"`
+}
+
+// Duration returns how long the run took, or has been running so far
+func (r *ScrapeRun) Duration() time.Duration {
+	if r.FinishedAt != nil {
+		return r.FinishedAt.Sub(r.StartedAt)
+	}
+	return time.Since(r.StartedAt)
+}
+
+// ScrapeFailure records a single article URL that failed during a run
+type ScrapeFailure struct {
+	ID        int       `db:"id" json:"id"`
+	RunID     int       `db:"run_id" json:"run_id"`
+	URL       string    `db:"url" json:"url"`
+	Stage     string    `db:"stage" json:"stage"`
+	Error     string    `db:"error" json:"error"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+
+	// Diagnostics names the screenshot and HTML captured of the failed page
+	Diagnostics *string `db:"diagnostics" json:"diagnostics,omitempty"`
+}
+
+// Retry is an article URL queued to be scraped again after a failure
+type Retry struct {
+	ID            int       `db:"id" json:"id"`
+	URL           string    `db:"url" json:"url"`
+	Status        string    `db:"status" json:"status"`
+	Attempts      int       `db:"attempts" json:"attempts"`
+	LastError     *string   `db:"last_error" json:"last_error,omitempty"`
+	LastAttemptAt time.Time `db:"last_attempt_at" json:"last_attempt_at"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
+
+// Job is a queued unit of background work, such as a scrape
+type Job struct {
+	ID         string     `db:"id" json:"id"`
+	Kind       string     `db:"kind" json:"kind"`
+	Label      string     `db:"label" json:"label"`
+	Payload    []byte     `db:"payload" json:"-"` // JSON describing the work
+	Status     string     `db:"status" json:"status"`
+	Attempts   int        `db:"attempts" json:"attempts"`
+	Error      *string    `db:"error" json:"error,omitempty"`
+	Paused     bool       `db:"paused" json:"paused"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	StartedAt  *time.Time `db:"started_at" json:"started_at,omitempty"`
+	FinishedAt *time.Time `db:"finished_at" json:"finished_at,omitempty"`
+}
+
+// Source is a site Kiln scrapes articles from
+type Source struct {
+	ID             int       `db:"id" json:"id"`
+	Name           string    `db:"name" json:"name"`
+	StartURLs      []string  `db:"start_urls" json:"start_urls"`
+	CredentialsRef *string   `db:"credentials_ref" json:"credentials_ref,omitempty"` // e.g. "env:GASETTEN"
+	FeedURL        *string   `db:"feed_url" json:"feed_url,omitempty"`               // RSS or Atom feed replacing the start pages
+	Schedule       *string   `db:"schedule" json:"schedule,omitempty"`               // daily "HH:MM" times
+	ScrapeInterval *string   `db:"scrape_interval" json:"scrape_interval,omitempty"` // e.g. "6h"
+	QuietHours     *string   `db:"quiet_hours" json:"quiet_hours,omitempty"`         // e.g. "23:00-06:00"
+	MaxRunsPerDay  *int      `db:"max_runs_per_day" json:"max_runs_per_day,omitempty"`
+	Enabled        bool      `db:"enabled" json:"enabled"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// SourceIcon is the cached favicon of a source. Data is nil when the site
+// had no usable icon when it was fetched.
+type SourceIcon struct {
+	Source      string    `db:"source" json:"source"`
+	IconURL     *string   `db:"icon_url" json:"icon_url,omitempty"`
+	ContentType *string   `db:"content_type" json:"content_type,omitempty"`
+	Data        []byte    `db:"data" json:"-"`
+	FetchedAt   time.Time `db:"fetched_at" json:"fetched_at"`
+}
+
+// Match is a fixture from a source's match calendar. Result is set once
+// the site reports one.
+type Match struct {
+	ID          int       `db:"id" json:"id"`
+	Source      string    `db:"source" json:"source"`
+	Kickoff     time.Time `db:"kickoff" json:"kickoff"`
+	HomeTeam    string    `db:"home_team" json:"home_team"`
+	AwayTeam    string    `db:"away_team" json:"away_team"`
+	Competition *string   `db:"competition" json:"competition,omitempty"`
+	Venue       *string   `db:"venue" json:"venue,omitempty"`
+	Result      *string   `db:"result" json:"result,omitempty"`
+	URL         *string   `db:"url" json:"url,omitempty"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// WatchTerm is a word or name that triggers an alert when a newly scraped
+// article mentions it
+type WatchTerm struct {
+	ID        int       `db:"id" json:"id"`
+	Term      string    `db:"term" json:"term"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// SealedCredential is a stored username and password, encrypted by the
+// credentials package
+type SealedCredential struct {
+	Name      string    `db:"name" json:"name"`
+	KeyID     string    `db:"key_id" json:"key_id"`
+	Sealed    []byte    `db:"sealed" json:"-"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// SavedSearch is a named article search, served as a feed at its slug
+type SavedSearch struct {
+	ID        int       `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	Slug      string    `db:"slug" json:"slug"`
+	Query     string    `db:"query" json:"query"`
+	Source    *string   `db:"source" json:"source,omitempty"`
+	Author    *string   `db:"author" json:"author,omitempty"`
+	Tag       *string   `db:"tag" json:"tag,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Filter returns the article filter the search applies
+func (s *SavedSearch) Filter() ArticleFilter {
+	filter := ArticleFilter{Query: s.Query}
+	if s.Source != nil {
+		filter.Source = *s.Source
+	}
+	if s.Author != nil {
+		filter.Author = *s.Author
+	}
+	if s.Tag != nil {
+		filter.Tag = *s.Tag
+	}
+	return filter
+}
+
+// AuditEntry records a destructive operation or a configuration change
+type AuditEntry struct {
+	ID         int64           `db:"id" json:"id"`
+	Actor      string          `db:"actor" json:"actor"`                       // user, API key name or AuditActorSystem
+	AuthMethod *string         `db:"auth_method" json:"auth_method,omitempty"` // how the actor signed in
+	RemoteAddr *string         `db:"remote_addr" json:"remote_addr,omitempty"`
+	Action     string          `db:"action" json:"action"`           // e.g. AuditArticleDelete
+	Target     *string         `db:"target" json:"target,omitempty"` // what was acted on, e.g. an article URL
+	Details    json.RawMessage `db:"details" json:"details,omitempty"`
+	CreatedAt  time.Time       `db:"created_at" json:"created_at"`
 }
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetQueuedArticles retrieves summaries of the articles in the reading
+// queue, most recently added first
+func (db *DB) GetQueuedArticles(ctx context.Context, limit int) ([]*Article, error) {
+	query := `
+		SELECT ` + articleSummaryColumns + `
+		FROM articles
+		JOIN article_queue q ON q.article_id = articles.id
+		ORDER BY q.added_at DESC, articles.id DESC
+		LIMIT $1
+	`
+
+	return db.queryArticles(ctx, query, limit)
+}
+
+// GetQueuedArticleIDs returns which of the given articles are in the
+// reading queue
+func (db *DB) GetQueuedArticleIDs(ctx context.Context, ids []int) (map[int]bool, error) {
+	query := `SELECT article_id FROM article_queue WHERE article_id = ANY($1)`
+
+	rows, err := db.pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reading queue: %w", err)
+	}
+	defer rows.Close()
+
+	queued := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan reading queue: %w", err)
+		}
+		queued[id] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reading queue: %w", err)
+	}
+
+	return queued, nil
+}
+
+// SetArticleQueued adds an article to the reading queue or removes it.
+// Adding a queued article again keeps its place.
+func (db *DB) SetArticleQueued(ctx context.Context, id int, queued bool) error {
+	defer db.articlesChanged()
+
+	query := `DELETE FROM article_queue WHERE article_id = $1`
+	if queued {
+		query = `INSERT INTO article_queue (article_id) VALUES ($1) ON CONFLICT (article_id) DO NOTHING`
+	}
+
+	if _, err := db.pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to update reading queue: %w", err)
+	}
+
+	return nil
+}
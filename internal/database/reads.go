@@ -0,0 +1,236 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ReaderQuery selects the articles of a Google Reader stream. Duplicates and
+// partial (teaser) articles are left out, as in feeds.
+type ReaderQuery struct {
+	Source      string     // "" for every source
+	Read        *bool      // only read (true) or unread (false) articles
+	Since       *time.Time // scraped at or after
+	Until       *time.Time // scraped before
+	OldestFirst bool
+}
+
+// UnreadCount is the number of unread articles of a source
+type UnreadCount struct {
+	Source string
+	Count  int
+	Newest time.Time // when the newest unread article was scraped
+}
+
+// readerWhere is the condition matching a ReaderQuery given as $1 to $4
+const readerWhere = `
+	duplicate_of IS NULL
+	AND NOT partial
+	AND ($1 = '' OR source = $1)
+	AND ($2::boolean IS NULL OR EXISTS (SELECT 1 FROM article_reads r WHERE r.article_id = articles.id) = $2)
+	AND ($3::timestamptz IS NULL OR created_at >= $3)
+	AND ($4::timestamptz IS NULL OR created_at < $4)
+`
+
+func (q ReaderQuery) order() string {
+	if q.OldestFirst {
+		return `ORDER BY created_at, id`
+	}
+	return `ORDER BY created_at DESC, id DESC`
+}
+
+// GetReaderArticles retrieves a page of the articles matching q
+func (db *DB) GetReaderArticles(ctx context.Context, q ReaderQuery, limit, offset int) ([]*Article, error) {
+	query := `
+		SELECT ` + articleColumns + `
+		FROM ` + articlesWithContent + `
+		WHERE ` + readerWhere + `
+		` + q.order() + `
+		LIMIT $5 OFFSET $6
+	`
+
+	return db.queryArticles(ctx, query, q.Source, q.Read, q.Since, q.Until, limit, offset)
+}
+
+// GetReaderArticleIDs retrieves the IDs and scrape times of a page of the
+// articles matching q
+func (db *DB) GetReaderArticleIDs(ctx context.Context, q ReaderQuery, limit, offset int) ([]int, []time.Time, error) {
+	query := `
+		SELECT id, created_at
+		FROM articles
+		WHERE ` + readerWhere + `
+		` + q.order() + `
+		LIMIT $5 OFFSET $6
+	`
+
+	rows, err := db.pool.Query(ctx, query, q.Source, q.Read, q.Since, q.Until, limit, offset)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query article IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	var times []time.Time
+	for rows.Next() {
+		var id int
+		var created time.Time
+		if err := rows.Scan(&id, &created); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan article ID: %w", err)
+		}
+		ids = append(ids, id)
+		times = append(times, created)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating article IDs: %w", err)
+	}
+
+	return ids, times, nil
+}
+
+// GetArticlesByIDs retrieves the articles with the given IDs, newest first.
+// Unknown IDs are skipped.
+func (db *DB) GetArticlesByIDs(ctx context.Context, ids []int) ([]*Article, error) {
+	query := `
+		SELECT ` + articleColumns + `
+		FROM ` + articlesWithContent + `
+		WHERE id = ANY($1)
+		ORDER BY created_at DESC, id DESC
+	`
+
+	return db.queryArticles(ctx, query, ids)
+}
+
+// GetReadArticleIDs returns which of the given articles are read
+func (db *DB) GetReadArticleIDs(ctx context.Context, ids []int) (map[int]bool, error) {
+	query := `SELECT article_id FROM article_reads WHERE article_id = ANY($1)`
+
+	rows, err := db.pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query read state: %w", err)
+	}
+	defer rows.Close()
+
+	read := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan read state: %w", err)
+		}
+		read[id] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating read state: %w", err)
+	}
+
+	return read, nil
+}
+
+// touchReadArticles marks the articles whose read state a statement named r
+// changed as changed, so instance sync and incremental exports carry it
+const touchReadArticles = `UPDATE articles SET updated_at = NOW() WHERE id IN (SELECT article_id FROM r)`
+
+// SetArticlesRead marks the given articles read or unread. Cached pages only
+// go stale when the read state of an article changes, so viewing a read
+// article again keeps them.
+func (db *DB) SetArticlesRead(ctx context.Context, ids []int, read bool) error {
+	query := `
+		WITH r AS (
+			DELETE FROM article_reads WHERE article_id = ANY($1)
+			RETURNING article_id
+		)
+	` + touchReadArticles
+	if read {
+		query = `
+			WITH r AS (
+				INSERT INTO article_reads (article_id)
+				SELECT id FROM articles WHERE id = ANY($1)
+				ON CONFLICT (article_id) DO NOTHING
+				RETURNING article_id
+			)
+		` + touchReadArticles
+	}
+
+	result, err := db.pool.Exec(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("failed to update read state: %w", err)
+	}
+	if result.RowsAffected() > 0 {
+		db.articlesChanged()
+	}
+
+	return nil
+}
+
+// MarkAllRead marks the articles of a source ("" for every source) scraped
+// before the given time read
+func (db *DB) MarkAllRead(ctx context.Context, source string, before time.Time) (int64, error) {
+	query := `
+		WITH r AS (
+			INSERT INTO article_reads (article_id)
+			SELECT id FROM articles
+			WHERE ($1 = '' OR source = $1) AND created_at < $2
+			ON CONFLICT (article_id) DO NOTHING
+			RETURNING article_id
+		)
+	` + touchReadArticles
+
+	result, err := db.pool.Exec(ctx, query, source, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark articles read: %w", err)
+	}
+	if result.RowsAffected() > 0 {
+		db.articlesChanged()
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// CountUnreadArticles returns the number of articles not marked read,
+// counted like the article list: duplicates are left out
+func (db *DB) CountUnreadArticles(ctx context.Context) (int, error) {
+	query := `SELECT COUNT(*) FROM articles WHERE duplicate_of IS NULL AND ` + articleUnread
+
+	var count int
+	if err := db.pool.QueryRow(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unread articles: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetUnreadCounts returns the number of unread articles per source
+func (db *DB) GetUnreadCounts(ctx context.Context) ([]UnreadCount, error) {
+	query := `
+		SELECT source, COUNT(*), MAX(created_at)
+		FROM articles
+		WHERE duplicate_of IS NULL
+		  AND NOT partial
+		  AND ` + articleUnread + `
+		GROUP BY source
+		ORDER BY source
+	`
+
+	rows, err := db.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unread counts: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []UnreadCount
+	for rows.Next() {
+		var c UnreadCount
+		if err := rows.Scan(&c.Source, &c.Count, &c.Newest); err != nil {
+			return nil, fmt.Errorf("failed to scan unread count: %w", err)
+		}
+		counts = append(counts, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating unread counts: %w", err)
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// expiredArticles selects the articles a retention policy removes: those
+// published before $1 (unless it is NULL) and those beyond the $2 newest of
+// their source (unless it is 0), with the duplicates of either, which would
+// otherwise show up once their original was gone
+const expiredArticles = `
+	WITH ranked AS (
+		SELECT id,
+		       COALESCE(published_at, created_at) AS at,
+		       row_number() OVER (PARTITION BY source ORDER BY COALESCE(published_at, created_at) DESC, id DESC) AS rank
+		FROM articles
+	), expired AS (
+		SELECT id FROM ranked
+		WHERE ($1::timestamptz IS NOT NULL AND at < $1)
+		   OR ($2 > 0 AND rank > $2)
+	), doomed AS (
+		SELECT id, source FROM articles
+		WHERE id IN (SELECT id FROM expired)
+		   OR duplicate_of IN (SELECT id FROM expired)
+	)
+`
+
+// CountExpiredArticles returns, per source, how many articles
+// DeleteExpiredArticles would delete
+func (db *DB) CountExpiredArticles(ctx context.Context, before *time.Time, maxPerSource int) ([]FacetCount, error) {
+	query := expiredArticles + `
+		SELECT source, COUNT(*)
+		FROM doomed
+		GROUP BY source
+		ORDER BY COUNT(*) DESC, source
+	`
+
+	return db.queryFacets(ctx, query, before, maxPerSource)
+}
+
+// DeleteExpiredArticles deletes the articles published before before (when
+// set) and those beyond the maxPerSource newest of their source (when
+// above 0), and returns how many were deleted per source
+func (db *DB) DeleteExpiredArticles(ctx context.Context, before *time.Time, maxPerSource int) ([]FacetCount, error) {
+	defer db.articlesChanged()
+
+	query := expiredArticles + `
+		, deleted AS (
+			DELETE FROM articles
+			WHERE id IN (SELECT id FROM doomed)
+			RETURNING source
+		)
+		SELECT source, COUNT(*)
+		FROM deleted
+		GROUP BY source
+		ORDER BY COUNT(*) DESC, source
+	`
+
+	return db.queryFacets(ctx, query, before, maxPerSource)
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// Retry statuses
+const (
+	RetryStatusPending   = "pending"
+	RetryStatusAbandoned = "abandoned"
+)
+
+// EnqueueRetry queues a failed URL for another attempt. Repeated failures
+// count attempts; once maxAttempts is reached the URL is abandoned.
+func (db *DB) EnqueueRetry(ctx context.Context, url, lastError string, maxAttempts int) error {
+	query := `
+		INSERT INTO retry_queue (url, last_error)
+		VALUES ($1, $2)
+		ON CONFLICT (url) DO UPDATE
+		SET attempts = retry_queue.attempts + 1,
+		    last_error = EXCLUDED.last_error,
+		    last_attempt_at = NOW(),
+		    status = CASE WHEN retry_queue.attempts + 1 >= $3 THEN $4 ELSE $5 END
+	`
+
+	_, err := db.pool.Exec(ctx, query, url, lastError, maxAttempts, RetryStatusAbandoned, RetryStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue retry: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRetry removes a URL from the retry queue (e.g. after it succeeded)
+func (db *DB) DeleteRetry(ctx context.Context, url string) error {
+	query := `DELETE FROM retry_queue WHERE url = $1`
+
+	if _, err := db.pool.Exec(ctx, query, url); err != nil {
+		return fmt.Errorf("failed to delete retry: %w", err)
+	}
+
+	return nil
+}
+
+// GetPendingRetries retrieves queued URLs, least recently attempted first
+func (db *DB) GetPendingRetries(ctx context.Context, limit int) ([]*Retry, error) {
+	query := `
+		SELECT id, url, status, attempts, last_error, last_attempt_at, created_at
+		FROM retry_queue
+		WHERE status = $1
+		ORDER BY last_attempt_at
+		LIMIT $2
+	`
+
+	rows, err := db.pool.Query(ctx, query, RetryStatusPending, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query retries: %w", err)
+	}
+	defer rows.Close()
+
+	var retries []*Retry
+	for rows.Next() {
+		var r Retry
+		if err := rows.Scan(&r.ID, &r.URL, &r.Status, &r.Attempts, &r.LastError, &r.LastAttemptAt, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan retry: %w", err)
+		}
+		retries = append(retries, &r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating retries: %w", err)
+	}
+
+	return retries, nil
+}
+
+// CountRetries returns the number of pending and abandoned retries
+func (db *DB) CountRetries(ctx context.Context) (pending, abandoned int, err error) {
+	query := `
+		SELECT COUNT(*) FILTER (WHERE status = $1),
+		       COUNT(*) FILTER (WHERE status = $2)
+		FROM retry_queue
+	`
+
+	err = db.pool.QueryRow(ctx, query, RetryStatusPending, RetryStatusAbandoned).Scan(&pending, &abandoned)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count retries: %w", err)
+	}
+
+	return pending, abandoned, nil
+}
@@ -0,0 +1,263 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Scrape run statuses
+const (
+	RunStatusRunning   = "running"
+	RunStatusCompleted = "completed"
+	RunStatusFailed    = "failed"
+	RunStatusCancelled = "cancelled"
+)
+
+// Scrape run triggers
+const (
+	RunTriggerManual    = "manual"
+	RunTriggerScheduled = "scheduled"
+	RunTriggerRetry     = "retry"
+	RunTriggerURL       = "url"
+	RunTriggerBatch     = "batch"
+	RunTriggerBackfill  = "backfill"
+)
+
+// Scrape failure stages
+const (
+	FailureStageLogin  = "login"
+	FailureStageCheck  = "check"
+	FailureStageScrape = "scrape"
+	FailureStageSave   = "save"
+)
+
+// RunStats summarizes the recorded scrape runs
+type RunStats struct {
+	TotalRuns      int        `json:"total_runs"`
+	SuccessfulRuns int        `json:"successful_runs"`
+	FailedRuns     int        `json:"failed_runs"`
+	LastRun        *ScrapeRun `json:"last_run,omitempty"`
+}
+
+// SuccessRate returns the fraction of finished runs that completed
+func (rs RunStats) SuccessRate() float64 {
+	finished := rs.SuccessfulRuns + rs.FailedRuns
+	if finished == 0 {
+		return 0
+	}
+	return float64(rs.SuccessfulRuns) / float64(finished)
+}
+
+const scrapeRunColumns = `id, trigger, source, status, started_at, finished_at, articles_found, articles_added, articles_skipped, articles_failed, error`
+
+func scanScrapeRun(row pgx.Row) (*ScrapeRun, error) {
+	var run ScrapeRun
+	err := row.Scan(
+		&run.ID,
+		&run.Trigger,
+		&run.Source,
+		&run.Status,
+		&run.StartedAt,
+		&run.FinishedAt,
+		&run.ArticlesFound,
+		&run.ArticlesAdded,
+		&run.ArticlesSkipped,
+		&run.ArticlesFailed,
+		&run.Error,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// CreateScrapeRun records the start of a scrape run. source names the
+// source the run is limited to, nil for runs over every source.
+func (db *DB) CreateScrapeRun(ctx context.Context, trigger string, source *string) (*ScrapeRun, error) {
+	query := `
+		INSERT INTO scrape_runs (trigger, source, status)
+		VALUES ($1, $2, $3)
+		RETURNING ` + scrapeRunColumns
+
+	run, err := scanScrapeRun(db.pool.QueryRow(ctx, query, trigger, source, RunStatusRunning))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scrape run: %w", err)
+	}
+
+	return run, nil
+}
+
+// FinishScrapeRun stores the final status, counts and error of a run
+func (db *DB) FinishScrapeRun(ctx context.Context, run *ScrapeRun) error {
+	query := `
+		UPDATE scrape_runs
+		SET status = $2, finished_at = NOW(), articles_found = $3, articles_added = $4,
+		    articles_skipped = $5, articles_failed = $6, error = $7
+		WHERE id = $1
+		RETURNING finished_at
+	`
+
+	err := db.pool.QueryRow(ctx, query,
+		run.ID,
+		run.Status,
+		run.ArticlesFound,
+		run.ArticlesAdded,
+		run.ArticlesSkipped,
+		run.ArticlesFailed,
+		run.Error,
+	).Scan(&run.FinishedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to finish scrape run: %w", err)
+	}
+
+	return nil
+}
+
+// GetScrapeRuns retrieves the most recent scrape runs
+func (db *DB) GetScrapeRuns(ctx context.Context, limit int) ([]*ScrapeRun, error) {
+	query := `
+		SELECT ` + scrapeRunColumns + `
+		FROM scrape_runs
+		ORDER BY started_at DESC
+		LIMIT $1
+	`
+
+	rows, err := db.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scrape runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*ScrapeRun
+	for rows.Next() {
+		run, err := scanScrapeRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scrape run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scrape runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// GetScrapeRun retrieves a single scrape run by ID
+func (db *DB) GetScrapeRun(ctx context.Context, id int) (*ScrapeRun, error) {
+	query := `SELECT ` + scrapeRunColumns + ` FROM scrape_runs WHERE id = $1`
+
+	run, err := scanScrapeRun(db.pool.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("scrape run not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scrape run: %w", err)
+	}
+
+	return run, nil
+}
+
+// SourceRunHistory is what the scheduler needs to know about the earlier
+// scheduled runs of a source
+type SourceRunHistory struct {
+	RunsSince int        // scheduled runs started since the given time
+	LastRun   *time.Time // start of the latest scheduled run
+}
+
+// GetSourceRunHistory summarizes the scheduled runs of a source
+func (db *DB) GetSourceRunHistory(ctx context.Context, source string, since time.Time) (*SourceRunHistory, error) {
+	query := `
+		SELECT COUNT(*) FILTER (WHERE started_at >= $3), MAX(started_at)
+		FROM scrape_runs
+		WHERE source = $1 AND trigger = $2
+	`
+
+	var history SourceRunHistory
+	err := db.pool.QueryRow(ctx, query, source, RunTriggerScheduled, since).Scan(&history.RunsSince, &history.LastRun)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get run history of source %s: %w", source, err)
+	}
+
+	return &history, nil
+}
+
+// CreateScrapeFailure records a URL that failed during a run
+func (db *DB) CreateScrapeFailure(ctx context.Context, failure *ScrapeFailure) error {
+	query := `
+		INSERT INTO scrape_failures (run_id, url, stage, error, diagnostics)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := db.pool.QueryRow(ctx, query, failure.RunID, failure.URL, failure.Stage, failure.Error, failure.Diagnostics).
+		Scan(&failure.ID, &failure.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create scrape failure: %w", err)
+	}
+
+	return nil
+}
+
+// GetScrapeFailures retrieves the failures recorded for a run
+func (db *DB) GetScrapeFailures(ctx context.Context, runID int) ([]*ScrapeFailure, error) {
+	query := `
+		SELECT id, run_id, url, stage, error, diagnostics, created_at
+		FROM scrape_failures
+		WHERE run_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := db.pool.Query(ctx, query, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scrape failures: %w", err)
+	}
+	defer rows.Close()
+
+	var failures []*ScrapeFailure
+	for rows.Next() {
+		var f ScrapeFailure
+		if err := rows.Scan(&f.ID, &f.RunID, &f.URL, &f.Stage, &f.Error, &f.Diagnostics, &f.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan scrape failure: %w", err)
+		}
+		failures = append(failures, &f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scrape failures: %w", err)
+	}
+
+	return failures, nil
+}
+
+// GetRunStats summarizes all recorded scrape runs
+func (db *DB) GetRunStats(ctx context.Context) (*RunStats, error) {
+	query := `
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE status = $1),
+		       COUNT(*) FILTER (WHERE status IN ($2, $3))
+		FROM scrape_runs
+	`
+
+	stats := &RunStats{}
+	err := db.pool.QueryRow(ctx, query, RunStatusCompleted, RunStatusFailed, RunStatusCancelled).
+		Scan(&stats.TotalRuns, &stats.SuccessfulRuns, &stats.FailedRuns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize scrape runs: %w", err)
+	}
+
+	runs, err := db.GetScrapeRuns(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) > 0 {
+		stats.LastRun = runs[0]
+	}
+
+	return stats, nil
+}
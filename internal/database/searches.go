@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrSavedSearchExists is returned when a saved search's slug is taken
+var ErrSavedSearchExists = errors.New("a saved search with this name exists")
+
+// savedSearchColumns is the column list matching scanSavedSearch
+const savedSearchColumns = `id, name, slug, query, source, author, tag, created_at`
+
+func scanSavedSearch(row pgx.Row) (*SavedSearch, error) {
+	var s SavedSearch
+	if err := row.Scan(&s.ID, &s.Name, &s.Slug, &s.Query, &s.Source, &s.Author, &s.Tag, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetSavedSearches returns the saved searches in alphabetical order
+func (db *DB) GetSavedSearches(ctx context.Context) ([]*SavedSearch, error) {
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_searches ORDER BY LOWER(name)`
+
+	rows, err := db.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	var searches []*SavedSearch
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan saved search: %w", err)
+		}
+		searches = append(searches, search)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating saved searches: %w", err)
+	}
+
+	return searches, nil
+}
+
+// GetSavedSearch retrieves a saved search by its slug. Returns nil without
+// error if there is none.
+func (db *DB) GetSavedSearch(ctx context.Context, slug string) (*SavedSearch, error) {
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_searches WHERE slug = $1`
+
+	search, err := scanSavedSearch(db.pool.QueryRow(ctx, query, slug))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved search: %w", err)
+	}
+
+	return search, nil
+}
+
+// CreateSavedSearch saves a search. A taken slug makes it fail with
+// ErrSavedSearchExists.
+func (db *DB) CreateSavedSearch(ctx context.Context, search *SavedSearch) error {
+	query := `
+		INSERT INTO saved_searches (name, slug, query, source, author, tag)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	err := db.pool.QueryRow(ctx, query, search.Name, search.Slug, search.Query, search.Source, search.Author, search.Tag).
+		Scan(&search.ID, &search.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrSavedSearchExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create saved search: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSavedSearch removes a saved search and returns it
+func (db *DB) DeleteSavedSearch(ctx context.Context, id int) (*SavedSearch, error) {
+	query := `DELETE FROM saved_searches WHERE id = $1 RETURNING ` + savedSearchColumns
+
+	search, err := scanSavedSearch(db.pool.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("saved search not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete saved search: %w", err)
+	}
+
+	return search, nil
+}
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const sourceColumns = `id, name, start_urls, credentials_ref, feed_url, schedule, scrape_interval, quiet_hours, max_runs_per_day, enabled, created_at, updated_at`
+
+func scanSource(row pgx.Row) (*Source, error) {
+	var source Source
+	err := row.Scan(
+		&source.ID,
+		&source.Name,
+		&source.StartURLs,
+		&source.CredentialsRef,
+		&source.FeedURL,
+		&source.Schedule,
+		&source.ScrapeInterval,
+		&source.QuietHours,
+		&source.MaxRunsPerDay,
+		&source.Enabled,
+		&source.CreatedAt,
+		&source.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &source, nil
+}
+
+// GetSources retrieves all sources ordered by name
+func (db *DB) GetSources(ctx context.Context) ([]*Source, error) {
+	query := `SELECT ` + sourceColumns + ` FROM sources ORDER BY name`
+
+	rows, err := db.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sources: %w", err)
+	}
+	defer rows.Close()
+
+	var sources []*Source
+	for rows.Next() {
+		source, err := scanSource(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan source: %w", err)
+		}
+		sources = append(sources, source)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sources: %w", err)
+	}
+
+	return sources, nil
+}
+
+// GetSource retrieves a source by ID
+func (db *DB) GetSource(ctx context.Context, id int) (*Source, error) {
+	query := `SELECT ` + sourceColumns + ` FROM sources WHERE id = $1`
+
+	source, err := scanSource(db.pool.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("source not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source: %w", err)
+	}
+
+	return source, nil
+}
+
+// GetSourceByName retrieves a source by name. Returns nil without error if
+// there is no such source.
+func (db *DB) GetSourceByName(ctx context.Context, name string) (*Source, error) {
+	query := `SELECT ` + sourceColumns + ` FROM sources WHERE name = $1`
+
+	source, err := scanSource(db.pool.QueryRow(ctx, query, name))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source: %w", err)
+	}
+
+	return source, nil
+}
+
+// CreateSource inserts a new source
+func (db *DB) CreateSource(ctx context.Context, source *Source) error {
+	query := `
+		INSERT INTO sources (name, start_urls, credentials_ref, feed_url, schedule, scrape_interval, quiet_hours, max_runs_per_day, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := db.pool.QueryRow(ctx, query,
+		source.Name,
+		source.StartURLs,
+		source.CredentialsRef,
+		source.FeedURL,
+		source.Schedule,
+		source.ScrapeInterval,
+		source.QuietHours,
+		source.MaxRunsPerDay,
+		source.Enabled,
+	).Scan(&source.ID, &source.CreatedAt, &source.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create source: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateSource stores the editable fields of a source
+func (db *DB) UpdateSource(ctx context.Context, source *Source) error {
+	query := `
+		UPDATE sources
+		SET name = $2, start_urls = $3, credentials_ref = $4, feed_url = $5, schedule = $6, scrape_interval = $7,
+		    quiet_hours = $8, max_runs_per_day = $9, enabled = $10, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := db.pool.QueryRow(ctx, query,
+		source.ID,
+		source.Name,
+		source.StartURLs,
+		source.CredentialsRef,
+		source.FeedURL,
+		source.Schedule,
+		source.ScrapeInterval,
+		source.QuietHours,
+		source.MaxRunsPerDay,
+		source.Enabled,
+	).Scan(&source.UpdatedAt)
+
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("source not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update source: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSource removes a source. Its articles are kept.
+func (db *DB) DeleteSource(ctx context.Context, id int) error {
+	query := `DELETE FROM sources WHERE id = $1`
+
+	result, err := db.pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete source: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("source not found")
+	}
+
+	return nil
+}
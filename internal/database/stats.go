@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// Stats holds aggregate counts over the article archive
+type Stats struct {
+	TotalArticles int          `json:"total_articles"`
+	Sources       []FacetCount `json:"sources"`
+	Authors       []FacetCount `json:"authors"`
+	Months        []FacetCount `json:"months"`
+	DatabaseSize  int64        `json:"database_size_bytes"`
+}
+
+// GetStats aggregates article counts per source, author and month along
+// with the total database size
+func (db *DB) GetStats(ctx context.Context) (*Stats, error) {
+	stats := &Stats{}
+
+	if err := db.pool.QueryRow(ctx, `SELECT COUNT(*) FROM articles`).Scan(&stats.TotalArticles); err != nil {
+		return nil, fmt.Errorf("failed to count articles: %w", err)
+	}
+
+	var err error
+	if stats.Sources, err = db.GetSourceCounts(ctx); err != nil {
+		return nil, err
+	}
+	if stats.Authors, err = db.GetAuthorCounts(ctx); err != nil {
+		return nil, err
+	}
+	if stats.Months, err = db.GetMonthCounts(ctx); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT pg_database_size(current_database())`
+	if err := db.pool.QueryRow(ctx, query).Scan(&stats.DatabaseSize); err != nil {
+		return nil, fmt.Errorf("failed to get database size: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetMonthCounts returns the number of articles per month (YYYY-MM), newest first
+func (db *DB) GetMonthCounts(ctx context.Context) ([]FacetCount, error) {
+	query := `
+		SELECT to_char(date_trunc('month', COALESCE(published_at, created_at)), 'YYYY-MM') AS month, COUNT(*)
+		FROM articles
+		GROUP BY month
+		ORDER BY month DESC
+	`
+
+	return db.queryFacets(ctx, query)
+}
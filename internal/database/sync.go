@@ -0,0 +1,436 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SyncPeer is the pull position of this instance against a peer
+type SyncPeer struct {
+	Peer         string     `db:"peer"`
+	Cursor       string     `db:"cursor"`
+	LastSyncedAt *time.Time `db:"last_synced_at"`
+	LastError    *string    `db:"last_error"`
+}
+
+// GetArticlesChangedSince returns articles changed after the (since,
+// afterUUID) position, ordered by updated_at then uuid, with their tags,
+// read state and comments.
+// Use the nil UUID to start at since itself.
+func (db *DB) GetArticlesChangedSince(ctx context.Context, since time.Time, afterUUID string, limit int) ([]*Article, error) {
+	query := `
+		SELECT ` + articleColumns + `
+		FROM ` + articlesWithContent + `
+		WHERE (updated_at, uuid) > ($1, $2::uuid)
+		ORDER BY updated_at, uuid
+		LIMIT $3
+	`
+
+	articles, err := db.queryArticles(ctx, query, since, afterUUID, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.loadSyncedState(ctx, articles); err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+// loadSyncedState fills in what instance sync carries besides the article
+// row: its tags, read state and comments
+func (db *DB) loadSyncedState(ctx context.Context, articles []*Article) error {
+	byID := make(map[int]*Article, len(articles))
+	ids := make([]int, 0, len(articles))
+	for _, article := range articles {
+		article.Tags = []ArticleTag{}
+		byID[article.ID] = article
+		ids = append(ids, article.ID)
+	}
+
+	rows, err := db.pool.Query(ctx, `SELECT article_id, tag, manual FROM article_tags WHERE article_id = ANY($1) ORDER BY article_id, tag`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var tag ArticleTag
+		if err := rows.Scan(&id, &tag.Tag, &tag.Manual); err != nil {
+			return fmt.Errorf("failed to scan tag: %w", err)
+		}
+		byID[id].Tags = append(byID[id].Tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating tags: %w", err)
+	}
+
+	read, err := db.GetReadArticleIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, article := range articles {
+		r := read[article.ID]
+		article.Read = &r
+		article.Comments = []*Comment{}
+	}
+
+	rows, err = db.pool.Query(ctx, `SELECT id, article_id, position, author, posted_at, body, fetched_at FROM comments WHERE article_id = ANY($1) ORDER BY article_id, position`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to query comments: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c Comment
+		if err := rows.Scan(&c.ID, &c.ArticleID, &c.Position, &c.Author, &c.PostedAt, &c.Body, &c.FetchedAt); err != nil {
+			return fmt.Errorf("failed to scan comment: %w", err)
+		}
+		byID[c.ArticleID].Comments = append(byID[c.ArticleID].Comments, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating comments: %w", err)
+	}
+	return nil
+}
+
+// queueSyncedState queues the statements storing the tags, read state and
+// comments a synced or imported article carries, once its copy has been written as
+// article id. Whatever is nil was not in the record and is left alone.
+func queueSyncedState(batch *pgx.Batch, id int, article *Article) {
+	if article.Tags != nil {
+		tags := make([]string, 0, len(article.Tags))
+		manual := make([]bool, 0, len(article.Tags))
+		for _, t := range article.Tags {
+			tags = append(tags, t.Tag)
+			manual = append(manual, t.Manual)
+		}
+		batch.Queue(`DELETE FROM article_tags WHERE article_id = $1 AND tag <> ALL($2::text[])`, id, tags)
+		batch.Queue(`
+			INSERT INTO article_tags (article_id, tag, manual)
+			SELECT $1, t.tag, t.manual FROM unnest($2::text[], $3::boolean[]) AS t(tag, manual)
+			ON CONFLICT (article_id, tag) DO UPDATE SET manual = EXCLUDED.manual
+		`, id, tags, manual)
+	}
+	if article.Read != nil {
+		if *article.Read {
+			batch.Queue(`INSERT INTO article_reads (article_id) VALUES ($1) ON CONFLICT (article_id) DO NOTHING`, id)
+		} else {
+			batch.Queue(`DELETE FROM article_reads WHERE article_id = $1`, id)
+		}
+	}
+	if article.Comments != nil {
+		authors := make([]*string, 0, len(article.Comments))
+		posted := make([]*time.Time, 0, len(article.Comments))
+		bodies := make([]string, 0, len(article.Comments))
+		for _, c := range article.Comments {
+			authors = append(authors, c.Author)
+			posted = append(posted, c.PostedAt)
+			bodies = append(bodies, c.Body)
+		}
+		batch.Queue(`DELETE FROM comments WHERE article_id = $1`, id)
+		batch.Queue(`
+			INSERT INTO comments (article_id, position, author, posted_at, body)
+			SELECT $1, c.position - 1, c.author, c.posted_at, c.body
+			FROM unnest($2::text[], $3::timestamptz[], $4::text[]) WITH ORDINALITY AS c(author, posted_at, body, position)
+		`, id, authors, posted, bodies)
+	}
+}
+
+// UpsertSyncedArticle stores an article received from a peer, with its
+// tags, read state and comments. Articles are matched by URL; an existing article is only
+// overwritten when the incoming copy was changed more recently. Reports
+// whether anything was written.
+func (db *DB) UpsertSyncedArticle(ctx context.Context, article *Article) (bool, error) {
+	defer db.articlesChanged()
+
+	article.WordCount = CountWords(article.ContentText)
+	content, err := db.storeContent(article)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		WITH a AS (
+			INSERT INTO articles (uuid, source, url, title, author, published_at, excerpt, image_url, word_count, partial, created_at, updated_at,
+				categories, audio_url, audio_type, wayback_url, summary)
+			VALUES ($1, $2, $3, $4, $5, $6, $16, $9, $10, $11, $12, $13, COALESCE($17::text[], '{}'), $18, $19, $20, $21)
+			ON CONFLICT (url) DO UPDATE SET
+				source = EXCLUDED.source,
+				title = EXCLUDED.title,
+				author = EXCLUDED.author,
+				published_at = EXCLUDED.published_at,
+				excerpt = EXCLUDED.excerpt,
+				image_url = EXCLUDED.image_url,
+				word_count = EXCLUDED.word_count,
+				partial = EXCLUDED.partial,
+				updated_at = EXCLUDED.updated_at,
+				categories = EXCLUDED.categories,
+				audio_url = EXCLUDED.audio_url,
+				audio_type = EXCLUDED.audio_type,
+				wayback_url = COALESCE(EXCLUDED.wayback_url, articles.wayback_url),
+				summary = COALESCE(EXCLUDED.summary, articles.summary)
+			WHERE articles.updated_at < EXCLUDED.updated_at
+			RETURNING id
+		), c AS (
+			INSERT INTO article_content (article_id, content_html, content_text, content_html_z, content_text_z)
+			SELECT id, $7, $8, $14, $15 FROM a
+			ON CONFLICT (article_id) DO UPDATE SET
+				content_html = EXCLUDED.content_html,
+				content_text = EXCLUDED.content_text,
+				content_html_z = EXCLUDED.content_html_z,
+				content_text_z = EXCLUDED.content_text_z
+		)
+		SELECT id FROM a
+	`
+
+	var id int
+	err = tx.QueryRow(ctx, query,
+		article.UUID,
+		article.Source,
+		article.URL,
+		article.Title,
+		article.Author,
+		article.PublishedAt,
+		content.html,
+		content.text,
+		article.ImageURL,
+		article.WordCount,
+		article.Partial,
+		article.CreatedAt,
+		article.UpdatedAt,
+		content.htmlZ,
+		content.textZ,
+		content.excerpt,
+		article.Categories,
+		article.AudioURL,
+		article.AudioType,
+		article.WaybackURL,
+		article.Summary,
+	).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to upsert synced article: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	queueSyncedState(batch, id, article)
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return false, fmt.Errorf("failed to store synced article state: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to store synced article: %w", err)
+	}
+	return true, nil
+}
+
+// importColumns are the article columns copied in by ImportArticles
+var importColumns = []string{
+	"uuid", "source", "url", "title", "author", "published_at", "content_html",
+	"content_text", "image_url", "word_count", "partial", "created_at", "updated_at",
+	"content_html_z", "content_text_z", "excerpt", "categories", "audio_url",
+	"audio_type", "wayback_url", "summary",
+}
+
+// ImportArticles stores articles read from an export snapshot, with their
+// tags, read state and comments, by the same rules as UpsertSyncedArticle. The articles are copied
+// into a temporary table with COPY and merged with one statement, which is
+// much faster than an upsert per article for large archives. When an article appears more
+// than once, its most recently changed copy is used. Returns how many
+// articles were written.
+func (db *DB) ImportArticles(ctx context.Context, articles []*Article) (int, error) {
+	if len(articles) == 0 {
+		return 0, nil
+	}
+	defer db.articlesChanged()
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		CREATE TEMP TABLE article_import (
+			uuid TEXT,
+			source TEXT,
+			url TEXT,
+			title TEXT,
+			author TEXT,
+			published_at TIMESTAMPTZ,
+			content_html TEXT,
+			content_text TEXT,
+			image_url TEXT,
+			word_count INTEGER,
+			partial BOOLEAN,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			content_html_z BYTEA,
+			content_text_z BYTEA,
+			excerpt TEXT,
+			categories TEXT[],
+			audio_url TEXT,
+			audio_type TEXT,
+			wayback_url TEXT,
+			summary TEXT
+		) ON COMMIT DROP
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create import table: %w", err)
+	}
+
+	source := pgx.CopyFromSlice(len(articles), func(i int) ([]any, error) {
+		a := articles[i]
+		a.WordCount = CountWords(a.ContentText)
+		content, err := db.storeContent(a)
+		if err != nil {
+			return nil, err
+		}
+		return []any{
+			a.UUID, a.Source, a.URL, a.Title, a.Author, a.PublishedAt, content.html,
+			content.text, a.ImageURL, a.WordCount, a.Partial, a.CreatedAt, a.UpdatedAt,
+			content.htmlZ, content.textZ, content.excerpt, a.Categories, a.AudioURL,
+			a.AudioType, a.WaybackURL, a.Summary,
+		}, nil
+	})
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"article_import"}, importColumns, source); err != nil {
+		return 0, fmt.Errorf("failed to copy articles: %w", err)
+	}
+
+	query := `
+		WITH i AS (
+			SELECT DISTINCT ON (url) *
+			FROM article_import
+			ORDER BY url, updated_at DESC
+		), a AS (
+			INSERT INTO articles (uuid, source, url, title, author, published_at, excerpt, image_url, word_count, partial, created_at, updated_at,
+				categories, audio_url, audio_type, wayback_url, summary)
+			SELECT uuid::uuid, source, url, title, author, published_at, excerpt, image_url, word_count, partial, created_at, updated_at,
+				COALESCE(categories, '{}'), audio_url, audio_type, wayback_url, summary
+			FROM i
+			ON CONFLICT (url) DO UPDATE SET
+				source = EXCLUDED.source,
+				title = EXCLUDED.title,
+				author = EXCLUDED.author,
+				published_at = EXCLUDED.published_at,
+				excerpt = EXCLUDED.excerpt,
+				image_url = EXCLUDED.image_url,
+				word_count = EXCLUDED.word_count,
+				partial = EXCLUDED.partial,
+				updated_at = EXCLUDED.updated_at,
+				categories = EXCLUDED.categories,
+				audio_url = EXCLUDED.audio_url,
+				audio_type = EXCLUDED.audio_type,
+				wayback_url = COALESCE(EXCLUDED.wayback_url, articles.wayback_url),
+				summary = COALESCE(EXCLUDED.summary, articles.summary)
+			WHERE articles.updated_at < EXCLUDED.updated_at
+			RETURNING id, url
+		), c AS (
+			INSERT INTO article_content (article_id, content_html, content_text, content_html_z, content_text_z)
+			SELECT a.id, i.content_html, i.content_text, i.content_html_z, i.content_text_z
+			FROM a JOIN i ON i.url = a.url
+			ON CONFLICT (article_id) DO UPDATE SET
+				content_html = EXCLUDED.content_html,
+				content_text = EXCLUDED.content_text,
+				content_html_z = EXCLUDED.content_html_z,
+				content_text_z = EXCLUDED.content_text_z
+		)
+		SELECT id, url FROM a
+	`
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to merge imported articles: %w", err)
+	}
+	ids := make(map[string]int)
+	for rows.Next() {
+		var id int
+		var url string
+		if err := rows.Scan(&id, &url); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan imported article: %w", err)
+		}
+		ids[url] = id
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to merge imported articles: %w", err)
+	}
+
+	// The merge took the most recently changed copy of each URL; store the
+	// state that copy carries
+	latest := make(map[string]*Article, len(ids))
+	for _, a := range articles {
+		if _, ok := ids[a.URL]; !ok {
+			continue
+		}
+		if prev := latest[a.URL]; prev == nil || a.UpdatedAt.After(prev.UpdatedAt) {
+			latest[a.URL] = a
+		}
+	}
+	batch := &pgx.Batch{}
+	for url, a := range latest {
+		queueSyncedState(batch, ids[url], a)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return 0, fmt.Errorf("failed to store imported article state: %w", err)
+	}
+	written := len(ids)
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to import articles: %w", err)
+	}
+
+	return written, nil
+}
+
+// GetSyncPeer returns the stored pull position for a peer, or an empty
+// position when the peer has never been synced
+func (db *DB) GetSyncPeer(ctx context.Context, peer string) (*SyncPeer, error) {
+	query := `SELECT peer, cursor, last_synced_at, last_error FROM sync_peers WHERE peer = $1`
+
+	var p SyncPeer
+	err := db.pool.QueryRow(ctx, query, peer).Scan(&p.Peer, &p.Cursor, &p.LastSyncedAt, &p.LastError)
+	if err == pgx.ErrNoRows {
+		return &SyncPeer{Peer: peer}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync peer: %w", err)
+	}
+
+	return &p, nil
+}
+
+// SaveSyncPeer records the position reached in a pull from a peer and the
+// error that stopped it, if any
+func (db *DB) SaveSyncPeer(ctx context.Context, peer, cursor string, syncErr error) error {
+	var lastError *string
+	if syncErr != nil {
+		msg := syncErr.Error()
+		lastError = &msg
+	}
+
+	query := `
+		INSERT INTO sync_peers (peer, cursor, last_synced_at, last_error)
+		VALUES ($1, $2, NOW(), $3)
+		ON CONFLICT (peer) DO UPDATE SET
+			cursor = EXCLUDED.cursor,
+			last_synced_at = EXCLUDED.last_synced_at,
+			last_error = EXCLUDED.last_error
+	`
+
+	if _, err := db.pool.Exec(ctx, query, peer, cursor, lastError); err != nil {
+		return fmt.Errorf("failed to save sync peer: %w", err)
+	}
+
+	return nil
+}
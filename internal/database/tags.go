@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// touchArticle marks an article changed, so instance sync and incremental
+// exports carry the change
+const touchArticle = `UPDATE articles SET updated_at = NOW() WHERE id = $1`
+
+// SetArticleTags replaces the extracted tags of an article. Tags added by
+// hand are kept.
+func (db *DB) SetArticleTags(ctx context.Context, articleID int, tags []string) error {
+	defer db.articlesChanged()
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM article_tags WHERE article_id = $1 AND NOT manual`, articleID); err != nil {
+		return fmt.Errorf("failed to clear tags: %w", err)
+	}
+
+	query := `INSERT INTO article_tags (article_id, tag) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	for _, tag := range tags {
+		if _, err := tx.Exec(ctx, query, articleID, tag); err != nil {
+			return fmt.Errorf("failed to insert tag: %w", err)
+		}
+	}
+	if _, err := tx.Exec(ctx, touchArticle, articleID); err != nil {
+		return fmt.Errorf("failed to touch article: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to save tags: %w", err)
+	}
+
+	return nil
+}
+
+// AddArticleTag tags an article by hand. An extracted tag that is added
+// again becomes a manual one.
+func (db *DB) AddArticleTag(ctx context.Context, articleID int, tag string) error {
+	defer db.articlesChanged()
+
+	query := `
+		WITH t AS (
+			INSERT INTO article_tags (article_id, tag, manual)
+			VALUES ($1, $2, TRUE)
+			ON CONFLICT (article_id, tag) DO UPDATE SET manual = TRUE
+			WHERE NOT article_tags.manual
+			RETURNING article_id
+		)
+		UPDATE articles SET updated_at = NOW() WHERE id IN (SELECT article_id FROM t)
+	`
+	if _, err := db.pool.Exec(ctx, query, articleID, tag); err != nil {
+		return fmt.Errorf("failed to add tag: %w", err)
+	}
+	return nil
+}
+
+// RemoveArticleTag removes a tag from an article
+func (db *DB) RemoveArticleTag(ctx context.Context, articleID int, tag string) error {
+	defer db.articlesChanged()
+
+	query := `
+		WITH t AS (
+			DELETE FROM article_tags WHERE article_id = $1 AND tag = $2
+			RETURNING article_id
+		)
+		UPDATE articles SET updated_at = NOW() WHERE id IN (SELECT article_id FROM t)
+	`
+	if _, err := db.pool.Exec(ctx, query, articleID, tag); err != nil {
+		return fmt.Errorf("failed to remove tag: %w", err)
+	}
+	return nil
+}
+
+// GetArticleTags returns the tags of an article in alphabetical order
+func (db *DB) GetArticleTags(ctx context.Context, articleID int) ([]string, error) {
+	rows, err := db.pool.Query(ctx, `SELECT tag FROM article_tags WHERE article_id = $1 ORDER BY tag`, articleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+	return tags, nil
+}
+
+// GetTagCounts returns the tags of original articles with their number of
+// articles, most used first, up to limit tags
+func (db *DB) GetTagCounts(ctx context.Context, limit int) ([]FacetCount, error) {
+	query := `
+		SELECT t.tag, COUNT(*)
+		FROM article_tags t
+		JOIN articles a ON a.id = t.article_id
+		WHERE a.duplicate_of IS NULL
+		GROUP BY t.tag
+		ORDER BY COUNT(*) DESC, t.tag
+		LIMIT $1
+	`
+
+	return db.queryFacets(ctx, query, limit)
+}
+
+// GetTagsForArticles returns the tags of each of the articles, by article ID
+func (db *DB) GetTagsForArticles(ctx context.Context, articleIDs []int) (map[int][]string, error) {
+	query := `
+		SELECT article_id, tag
+		FROM article_tags
+		WHERE article_id = ANY($1)
+		ORDER BY article_id, tag
+	`
+
+	rows, err := db.pool.Query(ctx, query, articleIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make(map[int][]string)
+	for rows.Next() {
+		var id int
+		var tag string
+		if err := rows.Scan(&id, &tag); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags[id] = append(tags[id], tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+	return tags, nil
+}
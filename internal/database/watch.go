@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrWatchTermExists is returned when a watch term is added twice, in any
+// letter case
+var ErrWatchTermExists = errors.New("already watched")
+
+// GetWatchTerms returns the watch terms in alphabetical order
+func (db *DB) GetWatchTerms(ctx context.Context) ([]*WatchTerm, error) {
+	query := `SELECT id, term, created_at FROM watch_terms ORDER BY LOWER(term)`
+
+	rows, err := db.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watch terms: %w", err)
+	}
+	defer rows.Close()
+
+	var terms []*WatchTerm
+	for rows.Next() {
+		var t WatchTerm
+		if err := rows.Scan(&t.ID, &t.Term, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan watch term: %w", err)
+		}
+		terms = append(terms, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating watch terms: %w", err)
+	}
+
+	return terms, nil
+}
+
+// CreateWatchTerm adds a watch term. A term that is watched already makes
+// it fail with ErrWatchTermExists.
+func (db *DB) CreateWatchTerm(ctx context.Context, term *WatchTerm) error {
+	query := `INSERT INTO watch_terms (term) VALUES ($1) RETURNING id, created_at`
+
+	err := db.pool.QueryRow(ctx, query, term.Term).Scan(&term.ID, &term.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrWatchTermExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create watch term: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteWatchTerm removes a watch term and returns it
+func (db *DB) DeleteWatchTerm(ctx context.Context, id int) (*WatchTerm, error) {
+	query := `DELETE FROM watch_terms WHERE id = $1 RETURNING id, term, created_at`
+
+	var t WatchTerm
+	err := db.pool.QueryRow(ctx, query, id).Scan(&t.ID, &t.Term, &t.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("watch term not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete watch term: %w", err)
+	}
+
+	return &t, nil
+}
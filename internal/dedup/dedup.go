@@ -0,0 +1,102 @@
+// Package dedup detects near-duplicate articles, such as a post syndicated
+// by several sources. Candidates share a normalized title and were published
+// within a few days of each other; a candidate is a duplicate when the
+// content is similar enough.
+package dedup
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+const (
+	// Window is how far apart the publish dates of duplicates may be
+	Window = 3 * 24 * time.Hour
+	// Threshold is the minimum content similarity of duplicates
+	Threshold = 0.6
+	// shingleSize is the number of words per shingle
+	shingleSize = 3
+)
+
+// Detector marks newly saved articles that duplicate an existing one
+type Detector struct {
+	db *database.DB
+}
+
+// New creates a detector
+func New(db *database.DB) *Detector {
+	return &Detector{db: db}
+}
+
+// Check compares article with earlier articles sharing its title and marks
+// it as a duplicate of the first sufficiently similar one. It returns the
+// original, or nil when the article is not a duplicate.
+func (d *Detector) Check(ctx context.Context, article *database.Article) (*database.Article, error) {
+	candidates, err := d.db.GetDuplicateCandidates(ctx, article, Window)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range candidates {
+		score := Similarity(text(article), text(candidate))
+		if score < Threshold {
+			continue
+		}
+		if err := d.db.SetDuplicateOf(ctx, article.ID, candidate.ID); err != nil {
+			return nil, err
+		}
+		article.DuplicateOf = &candidate.ID
+		logging.Infof("Article %s duplicates %s (similarity %.2f)", article.URL, candidate.URL, score)
+		return candidate, nil
+	}
+	return nil, nil
+}
+
+func text(article *database.Article) string {
+	if article.ContentText != nil {
+		return *article.ContentText
+	}
+	return ""
+}
+
+// Similarity returns the Jaccard similarity of the word shingles of a and
+// b, between 0 (nothing in common) and 1 (same words in the same order)
+func Similarity(a, b string) float64 {
+	sa, sb := shingles(a), shingles(b)
+	if len(sa) == 0 || len(sb) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for s := range sa {
+		if sb[s] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(sa)+len(sb)-shared)
+}
+
+// shingles returns the set of consecutive word sequences of s, ignoring
+// case and punctuation
+func shingles(s string) map[string]bool {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	set := make(map[string]bool)
+	if len(words) < shingleSize {
+		if len(words) > 0 {
+			set[strings.Join(words, " ")] = true
+		}
+		return set
+	}
+	for i := 0; i+shingleSize <= len(words); i++ {
+		set[strings.Join(words[i:i+shingleSize], " ")] = true
+	}
+	return set
+}
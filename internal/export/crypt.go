@@ -0,0 +1,204 @@
+package export
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Encrypted snapshots are a header followed by chunks sealed with
+// AES-256-GCM. Each chunk's nonce is the header's random prefix and the
+// chunk's number, and its flag byte (authenticated with the chunk) marks
+// the last chunk, so chunks can't be reordered, dropped or cut off.
+const (
+	encryptedSuffix = ".enc"
+	encryptMagic    = "KILNENC1"
+	encryptChunk    = 64 << 10
+	noncePrefixSize = 8
+
+	chunkMore  byte = 0
+	chunkFinal byte = 1
+)
+
+// ErrSnapshotKey is returned when an encrypted snapshot is restored without
+// the key it was encrypted with
+var ErrSnapshotKey = errors.New("snapshot is encrypted; set EXPORT_ENCRYPTION_KEY to the key it was written with")
+
+// ParseKey decodes an encryption key: 32 random bytes, base64 encoded (e.g.
+// from openssl rand -base64 32). An empty key disables encryption.
+func ParseKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key is not valid base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce is the nonce of chunk n
+func chunkNonce(prefix []byte, n uint32) []byte {
+	nonce := make([]byte, noncePrefixSize+4)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], n)
+	return nonce
+}
+
+// encryptWriter encrypts what is written to it into w; Close seals the last
+// chunk
+type encryptWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	prefix []byte
+	n      uint32
+	buf    []byte
+}
+
+func newEncryptWriter(w io.Writer, key []byte) (*encryptWriter, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	if _, err := w.Write(append([]byte(encryptMagic), prefix...)); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{w: w, aead: aead, prefix: prefix, buf: make([]byte, 0, encryptChunk)}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// A full chunk is only sealed once more follows, so the last one
+		// can be flagged on Close
+		if len(e.buf) == encryptChunk {
+			if err := e.seal(chunkMore); err != nil {
+				return written, err
+			}
+		}
+		n := copy(e.buf[len(e.buf):encryptChunk], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (e *encryptWriter) Close() error {
+	return e.seal(chunkFinal)
+}
+
+// seal writes the buffered chunk as flag, length and ciphertext
+func (e *encryptWriter) seal(flag byte) error {
+	sealed := e.aead.Seal(nil, chunkNonce(e.prefix, e.n), e.buf, []byte{flag})
+	header := make([]byte, 5)
+	header[0] = flag
+	binary.BigEndian.PutUint32(header[1:], uint32(len(sealed)))
+	if _, err := e.w.Write(header); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(sealed); err != nil {
+		return err
+	}
+	e.n++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// decryptReader reads the plaintext of an encrypted snapshot
+type decryptReader struct {
+	r      io.Reader
+	aead   cipher.AEAD
+	prefix []byte
+	n      uint32
+	buf    bytes.Reader
+	done   bool
+}
+
+func newDecryptReader(r io.Reader, key []byte) (*decryptReader, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, len(encryptMagic)+noncePrefixSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot header: %w", err)
+	}
+	if string(header[:len(encryptMagic)]) != encryptMagic {
+		return nil, fmt.Errorf("not an encrypted snapshot")
+	}
+	return &decryptReader{r: r, aead: aead, prefix: header[len(encryptMagic):]}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for d.buf.Len() == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+	return d.buf.Read(p)
+}
+
+// open reads and decrypts the next chunk
+func (d *decryptReader) open() error {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(d.r, header); err != nil {
+		return fmt.Errorf("snapshot is truncated: %w", err)
+	}
+	flag := header[0]
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > encryptChunk+uint32(d.aead.Overhead()) {
+		return fmt.Errorf("snapshot chunk %d is too large", d.n)
+	}
+
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(d.r, sealed); err != nil {
+		return fmt.Errorf("snapshot is truncated: %w", err)
+	}
+	plain, err := d.aead.Open(nil, chunkNonce(d.prefix, d.n), sealed, []byte{flag})
+	if err != nil {
+		return fmt.Errorf("failed to decrypt snapshot (wrong key or damaged file): %w", err)
+	}
+	d.n++
+	d.done = flag == chunkFinal
+	d.buf.Reset(plain)
+	return nil
+}
+
+// OpenSnapshot returns the gzipped JSON Lines of the snapshot named name
+// read from r, decrypting it with key when it is encrypted
+func OpenSnapshot(name string, r io.Reader, key []byte) (io.Reader, error) {
+	if !strings.HasSuffix(name, encryptedSuffix) {
+		return r, nil
+	}
+	if key == nil {
+		return nil, ErrSnapshotKey
+	}
+	return newDecryptReader(r, key)
+}
@@ -0,0 +1,233 @@
+// Package export writes the article archive as gzipped JSON Lines snapshots
+// to a storage backend. A full snapshot holds every article; incremental
+// snapshots hold the articles changed since the previous snapshot. Records
+// use the peersync wire format, so an article comes with its categories,
+// audio, Wayback snapshot, summary, comments, tags and read state, and
+// Restore loads a snapshot back with the same matching rules as instance
+// sync. Snapshots are optionally encrypted with a key of the administrator's.
+package export
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/internal/peersync"
+	"github.com/tkilaker/kiln/internal/storage"
+)
+
+// Snapshot kinds
+const (
+	KindFull        = "full"
+	KindIncremental = "incr"
+)
+
+// snapshotTimeLayout is the timestamp format used in snapshot names
+const snapshotTimeLayout = "20060102T150405Z"
+
+// snapshotPattern matches names produced by Snapshot.name
+var snapshotPattern = regexp.MustCompile(`^kiln-(\d{8}T\d{6}Z)-(full|incr)\.jsonl\.gz(\.enc)?$`)
+
+// exportPageSize is how many articles are read from the database at a time
+const exportPageSize = 500
+
+// Snapshot describes one export file
+type Snapshot struct {
+	Name      string
+	Kind      string
+	Time      time.Time
+	Articles  int
+	Size      int64
+	Encrypted bool
+}
+
+func snapshotName(kind string, t time.Time, encrypted bool) string {
+	name := fmt.Sprintf("kiln-%s-%s.jsonl.gz", t.UTC().Format(snapshotTimeLayout), kind)
+	if encrypted {
+		name += encryptedSuffix
+	}
+	return name
+}
+
+// parseSnapshot recognises a snapshot among stored objects
+func parseSnapshot(obj storage.Object) (Snapshot, bool) {
+	m := snapshotPattern.FindStringSubmatch(obj.Name)
+	if m == nil {
+		return Snapshot{}, false
+	}
+	t, err := time.Parse(snapshotTimeLayout, m[1])
+	if err != nil {
+		return Snapshot{}, false
+	}
+	return Snapshot{Name: obj.Name, Kind: m[2], Time: t, Size: obj.Size, Encrypted: m[3] != ""}, true
+}
+
+// Exporter writes snapshots to a store and applies the retention rules
+type Exporter struct {
+	db        *database.DB
+	store     storage.Store
+	fullEvery time.Duration
+	keepFull  int
+	key       []byte // nil writes unencrypted snapshots
+}
+
+// NewExporter creates an exporter. A full snapshot is written when the last
+// one is older than fullEvery; the keepFull newest full snapshots and the
+// incrementals that build on them are kept, older snapshots are deleted.
+// Snapshots are encrypted with key unless it is nil (see ParseKey).
+func NewExporter(db *database.DB, store storage.Store, fullEvery time.Duration, keepFull int, key []byte) *Exporter {
+	return &Exporter{db: db, store: store, fullEvery: fullEvery, keepFull: max(keepFull, 1), key: key}
+}
+
+// Run writes the next snapshot and prunes old ones
+func (e *Exporter) Run(ctx context.Context) (*Snapshot, error) {
+	existing, err := e.snapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	kind, since := e.plan(existing, now)
+	encrypted := e.key != nil
+	snap := &Snapshot{Name: snapshotName(kind, now, encrypted), Kind: kind, Time: now, Encrypted: encrypted}
+
+	tmp, err := os.CreateTemp("", "kiln-export-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	var out io.WriteCloser = nopWriteCloser{tmp}
+	if encrypted {
+		if out, err = newEncryptWriter(tmp, e.key); err != nil {
+			return nil, fmt.Errorf("failed to encrypt export file: %w", err)
+		}
+	}
+	snap.Articles, err = e.write(ctx, out, since)
+	if err != nil {
+		return nil, err
+	}
+	if err := out.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish export file: %w", err)
+	}
+	snap.Size, err = tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to size export file: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind export file: %w", err)
+	}
+
+	if err := e.store.Put(ctx, snap.Name, tmp, snap.Size); err != nil {
+		return nil, err
+	}
+	logging.Infof("Export snapshot %s uploaded (%d articles, %d bytes)", snap.Name, snap.Articles, snap.Size)
+
+	if err := e.prune(ctx, append(existing, *snap)); err != nil {
+		return snap, fmt.Errorf("snapshot %s uploaded but pruning failed: %w", snap.Name, err)
+	}
+	return snap, nil
+}
+
+// snapshots lists the stored snapshots, oldest first
+func (e *Exporter) snapshots(ctx context.Context) ([]Snapshot, error) {
+	objects, err := e.store.List(ctx, "kiln-")
+	if err != nil {
+		return nil, err
+	}
+
+	var snaps []Snapshot
+	for _, obj := range objects {
+		if snap, ok := parseSnapshot(obj); ok {
+			snaps = append(snaps, snap)
+		}
+	}
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].Time.Before(snaps[j].Time) })
+	return snaps, nil
+}
+
+// plan decides the kind of the next snapshot and the change time it starts
+// from (zero for a full snapshot)
+func (e *Exporter) plan(existing []Snapshot, now time.Time) (string, time.Time) {
+	var lastFull *Snapshot
+	for i := range existing {
+		if existing[i].Kind == KindFull {
+			lastFull = &existing[i]
+		}
+	}
+	if lastFull == nil || now.Sub(lastFull.Time) >= e.fullEvery {
+		return KindFull, time.Time{}
+	}
+	return KindIncremental, existing[len(existing)-1].Time
+}
+
+// write streams the articles changed after since into w as gzipped JSON Lines
+func (e *Exporter) write(ctx context.Context, w io.Writer, since time.Time) (int, error) {
+	gz := gzip.NewWriter(w)
+	enc := json.NewEncoder(gz)
+
+	count := 0
+	cursor := peersync.CursorAt(since)
+	for {
+		articles, err := e.db.GetArticlesChangedSince(ctx, cursor.UpdatedAt, cursor.UUID, exportPageSize)
+		if err != nil {
+			return count, err
+		}
+		for _, article := range articles {
+			if err := enc.Encode(peersync.FromArticle(article)); err != nil {
+				return count, fmt.Errorf("failed to write export record: %w", err)
+			}
+			count++
+		}
+		if len(articles) < exportPageSize {
+			break
+		}
+		last := articles[len(articles)-1]
+		cursor = peersync.Cursor{UpdatedAt: last.UpdatedAt, UUID: last.UUID}
+	}
+
+	if err := gz.Close(); err != nil {
+		return count, fmt.Errorf("failed to finish export file: %w", err)
+	}
+	return count, nil
+}
+
+// prune deletes snapshots older than the oldest full snapshot to keep
+func (e *Exporter) prune(ctx context.Context, snaps []Snapshot) error {
+	var fulls []Snapshot
+	for _, snap := range snaps {
+		if snap.Kind == KindFull {
+			fulls = append(fulls, snap)
+		}
+	}
+	if len(fulls) <= e.keepFull {
+		return nil
+	}
+	cutoff := fulls[len(fulls)-e.keepFull].Time
+
+	for _, snap := range snaps {
+		if !snap.Time.Before(cutoff) {
+			continue
+		}
+		if err := e.store.Delete(ctx, snap.Name); err != nil {
+			return err
+		}
+		logging.Infof("Deleted expired export snapshot %s", snap.Name)
+	}
+	return nil
+}
+
+// nopWriteCloser is the output of an unencrypted snapshot, which needs no
+// finishing
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
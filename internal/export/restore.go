@@ -0,0 +1,111 @@
+package export
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/internal/peersync"
+	"github.com/tkilaker/kiln/internal/storage"
+)
+
+// restoreChunkSize is how many articles are copied into the database at a
+// time
+const restoreChunkSize = 5000
+
+// maxRecordSize bounds a single JSON Lines record in a snapshot
+const maxRecordSize = 64 << 20
+
+// Restore reads a snapshot written by an exporter and stores its articles.
+// Articles are matched by URL and the most recently changed copy wins, as
+// with instance sync. Returns how many articles were read and written.
+func Restore(ctx context.Context, db *database.DB, r io.Reader) (read, written int, err error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer gz.Close()
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 0, 1<<20), maxRecordSize)
+
+	chunk := make([]*database.Article, 0, restoreChunkSize)
+	flush := func() error {
+		n, err := db.ImportArticles(ctx, chunk)
+		if err != nil {
+			return err
+		}
+		written += n
+		chunk = chunk[:0]
+		return nil
+	}
+
+	for scanner.Scan() {
+		var record peersync.Article
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return read, written, fmt.Errorf("failed to read snapshot record %d: %w", read+1, err)
+		}
+		chunk = append(chunk, record.ToArticle())
+		read++
+
+		if len(chunk) == restoreChunkSize {
+			if err := flush(); err != nil {
+				return read, written, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return read, written, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if err := flush(); err != nil {
+		return read, written, err
+	}
+	return read, written, nil
+}
+
+// RestoreLatest restores the newest full snapshot in a store and the
+// incrementals written after it, oldest first. Encrypted snapshots are
+// decrypted with key.
+func RestoreLatest(ctx context.Context, db *database.DB, store storage.Store, key []byte) (int, error) {
+	e := &Exporter{db: db, store: store}
+	snaps, err := e.snapshots(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	start := -1
+	for i, snap := range snaps {
+		if snap.Kind == KindFull {
+			start = i
+		}
+	}
+	if start < 0 {
+		return 0, fmt.Errorf("no full snapshot found")
+	}
+
+	total := 0
+	for _, snap := range snaps[start:] {
+		body, err := store.Get(ctx, snap.Name)
+		if err != nil {
+			return total, err
+		}
+		snapshot, err := OpenSnapshot(snap.Name, body, key)
+		if err != nil {
+			body.Close()
+			return total, fmt.Errorf("failed to open %s: %w", snap.Name, err)
+		}
+		read, written, err := Restore(ctx, db, snapshot)
+		body.Close()
+		total += written
+		if err != nil {
+			return total, fmt.Errorf("failed to restore %s: %w", snap.Name, err)
+		}
+		logging.Infof("Restored %s (%d articles, %d written)", snap.Name, read, written)
+	}
+	return total, nil
+}
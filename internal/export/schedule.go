@@ -0,0 +1,72 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/internal/notify"
+)
+
+// Scheduler runs an exporter once a day at a fixed local time and reports
+// the outcome through a notifier
+type Scheduler struct {
+	exporter *Exporter
+	notifier notify.Notifier
+	hour     int
+	minute   int
+}
+
+// NewScheduler creates a scheduler running at timeOfDay ("HH:MM", local time)
+func NewScheduler(exporter *Exporter, notifier notify.Notifier, timeOfDay string) (*Scheduler, error) {
+	t, err := time.Parse("15:04", timeOfDay)
+	if err != nil {
+		return nil, fmt.Errorf("invalid export time %q, expected HH:MM", timeOfDay)
+	}
+	return &Scheduler{exporter: exporter, notifier: notifier, hour: t.Hour(), minute: t.Minute()}, nil
+}
+
+// Run waits for each scheduled time and exports until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		next := s.next(time.Now())
+		logging.Infof("Next export snapshot at %s", next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.runOnce(ctx)
+	}
+}
+
+// next returns the first scheduled time after now
+func (s *Scheduler) next(now time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), s.hour, s.minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+func (s *Scheduler) runOnce(ctx context.Context) {
+	snap, err := s.exporter.Run(ctx)
+
+	msg := notify.Message{Level: notify.LevelInfo, Title: "Kiln export succeeded"}
+	if err != nil {
+		msg.Level = notify.LevelError
+		msg.Title = "Kiln export failed"
+		msg.Body = err.Error()
+	} else {
+		msg.Body = fmt.Sprintf("%s snapshot %s: %d articles, %d bytes", snap.Kind, snap.Name, snap.Articles, snap.Size)
+	}
+
+	if err := s.notifier.Notify(ctx, msg); err != nil {
+		logging.Warnf("Failed to send export notification: %v", err)
+	}
+}
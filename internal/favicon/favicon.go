@@ -0,0 +1,172 @@
+// Package favicon finds and downloads the icon of a web site, for telling
+// sources apart in the UI and in feeds.
+package favicon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	// maxIconSize bounds a downloaded icon
+	maxIconSize = 256 << 10
+
+	// maxPageSize bounds how much of the home page is read looking for
+	// <link rel="icon">
+	maxPageSize = 1 << 20
+
+	// fetchTimeout bounds finding and downloading an icon
+	fetchTimeout = 30 * time.Second
+)
+
+// ErrNotFound is returned when a site has no usable icon
+var ErrNotFound = errors.New("no icon found")
+
+// Icon is a downloaded site icon
+type Icon struct {
+	URL         string
+	ContentType string
+	Data        []byte
+}
+
+// Fetch downloads the icon of the site siteURL is on: the first icon its
+// home page links to, else /favicon.ico
+func Fetch(ctx context.Context, siteURL string) (*Icon, error) {
+	site, err := url.Parse(siteURL)
+	if err != nil || (site.Scheme != "http" && site.Scheme != "https") || site.Host == "" {
+		return nil, fmt.Errorf("invalid site URL %q", siteURL)
+	}
+	home := &url.URL{Scheme: site.Scheme, Host: site.Host, Path: "/"}
+
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+	client := &http.Client{}
+
+	// When the home page fails the conventional location may still work
+	candidates, _ := linkedIcons(ctx, client, home)
+	candidates = append(candidates, home.ResolveReference(&url.URL{Path: "/favicon.ico"}).String())
+
+	for _, iconURL := range candidates {
+		icon, err := download(ctx, client, iconURL)
+		if err == nil {
+			return icon, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// linkedIcons returns the URLs of the icons the page at pageURL links to,
+// in document order
+func linkedIcons(ctx context.Context, client *http.Client, pageURL *url.URL) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	// Redirects may have moved the page; links are relative to where it is
+	base := resp.Request.URL
+	var icons []string
+	tokens := html.NewTokenizer(io.LimitReader(resp.Body, maxPageSize))
+	for {
+		switch tokens.Next() {
+		case html.ErrorToken:
+			return icons, nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokens.Token()
+			if token.DataAtom == atom.Body {
+				return icons, nil
+			}
+			if token.DataAtom != atom.Link {
+				continue
+			}
+			var rel, href string
+			for _, attr := range token.Attr {
+				switch attr.Key {
+				case "rel":
+					rel = strings.ToLower(attr.Val)
+				case "href":
+					href = strings.TrimSpace(attr.Val)
+				}
+			}
+			if href == "" || !isIconRel(rel) {
+				continue
+			}
+			if ref, err := url.Parse(href); err == nil {
+				icons = append(icons, base.ResolveReference(ref).String())
+			}
+		}
+	}
+}
+
+// isIconRel reports whether a link's rel names an icon ("icon",
+// "shortcut icon" or "apple-touch-icon")
+func isIconRel(rel string) bool {
+	for _, value := range strings.Fields(rel) {
+		if value == "icon" || value == "apple-touch-icon" {
+			return true
+		}
+	}
+	return false
+}
+
+// download fetches a single icon, which must be an image
+func download(ctx context.Context, client *http.Client, iconURL string) (*Icon, error) {
+	if strings.HasPrefix(iconURL, "data:") {
+		return nil, fmt.Errorf("data URLs are not supported")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, iconURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxIconSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty icon")
+	}
+	if len(data) > maxIconSize {
+		return nil, fmt.Errorf("icon is larger than %d bytes", maxIconSize)
+	}
+
+	// Servers often send icons as text/plain or octet-stream; SVG icons
+	// can't be sniffed
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if contentType != "image/svg+xml" {
+		contentType = http.DetectContentType(data)
+	}
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("not an image: %s", contentType)
+	}
+	return &Icon{URL: iconURL, ContentType: contentType, Data: data}, nil
+}
@@ -0,0 +1,118 @@
+// Package i18n translates the UI. Messages are looked up by their English
+// text, so English needs no catalog and a message missing from a catalog
+// shows in English.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported UI languages
+const (
+	English = "en"
+	Swedish = "sv"
+)
+
+// catalogs maps each language but English to its translations
+var catalogs = map[string]map[string]string{
+	Swedish: swedish,
+}
+
+type contextKey struct{}
+
+// Supported reports whether lang is a UI language
+func Supported(lang string) bool {
+	return lang == English || catalogs[lang] != nil
+}
+
+// WithLanguage returns a context whose messages are shown in lang
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// Language returns the UI language of ctx, English unless one was set
+func Language(ctx context.Context) string {
+	if lang, ok := ctx.Value(contextKey{}).(string); ok && lang != "" {
+		return lang
+	}
+	return English
+}
+
+// T translates msg into the language of ctx
+func T(ctx context.Context, msg string) string {
+	if translated, ok := catalogs[Language(ctx)][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// Tf translates format into the language of ctx and formats it like
+// fmt.Sprintf
+func Tf(ctx context.Context, format string, args ...any) string {
+	return fmt.Sprintf(T(ctx, format), args...)
+}
+
+// Negotiate picks the supported language an Accept-Language header prefers,
+// e.g. "sv" for "sv-SE,sv;q=0.9,en;q=0.8". It returns "" when the header
+// names none of them.
+func Negotiate(acceptLanguage string) string {
+	type choice struct {
+		lang string
+		q    float64
+	}
+	var choices []choice
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if !Supported(lang) {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			choices = append(choices, choice{lang, q})
+		}
+	}
+	if len(choices) == 0 {
+		return ""
+	}
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].q > choices[j].q })
+	return choices[0].lang
+}
+
+// Date formats a day in the language of ctx, e.g. "January 2, 2006" or
+// "2 januari 2006"
+func Date(ctx context.Context, t time.Time) string {
+	if Language(ctx) == Swedish {
+		return fmt.Sprintf("%d %s %d", t.Day(), swedishMonths[t.Month()-1], t.Year())
+	}
+	return t.Format("January 2, 2006")
+}
+
+// DateTime formats a day and time of day in the language of ctx
+func DateTime(ctx context.Context, t time.Time) string {
+	return Date(ctx, t) + " " + t.Format("15:04")
+}
+
+// ShortDateTime formats a day of the year and time of day briefly, e.g.
+// "Jan 2 15:04" or "2 jan 15:04"
+func ShortDateTime(ctx context.Context, t time.Time) string {
+	if Language(ctx) == Swedish {
+		return fmt.Sprintf("%d %s %s", t.Day(), swedishMonths[t.Month()-1][:3], t.Format("15:04"))
+	}
+	return t.Format("Jan 2 15:04")
+}
+
+var swedishMonths = [12]string{
+	"januari", "februari", "mars", "april", "maj", "juni",
+	"juli", "augusti", "september", "oktober", "november", "december",
+}
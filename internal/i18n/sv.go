@@ -0,0 +1,260 @@
+package i18n
+
+// swedish translates the UI into Swedish
+var swedish = map[string]string{
+	// Navigation and layout
+	"Articles":             "Artiklar",
+	"Tags":                 "Taggar",
+	"Queue":                "Kö",
+	"Feeds":                "Flöden",
+	"Alerts":               "Bevakningar",
+	"Stats":                "Statistik",
+	"Runs":                 "Körningar",
+	"Sources":              "Källor",
+	"Audit":                "Logg",
+	"Sign out":             "Logga ut",
+	"Gasetten: unchecked":  "Gasetten: ej kontrollerad",
+	"Gasetten: signed in":  "Gasetten: inloggad",
+	"Gasetten: signed out": "Gasetten: utloggad",
+	"The Gasetten login has not been checked yet": "Inloggningen på Gasetten har inte kontrollerats än",
+	"Verified %s":    "Kontrollerad %s",
+	"Checked %s: %s": "Kontrollerad %s: %s",
+
+	// Article list
+	"Scrape New Articles": "Hämta nya artiklar",
+	"Discover and extract articles without saving anything": "Hitta och läs ut artiklar utan att spara något",
+	"Dry Run":   "Provkörning",
+	"Clear All": "Rensa allt",
+	"Are you sure you want to delete all articles? This cannot be undone.": "Vill du verkligen ta bort alla artiklar? Det går inte att ångra.",
+	"Paste an article URL to add it":                                       "Klistra in en artikeladress för att lägga till den",
+	"Add":                                                                  "Lägg till",
+	"Import a list of URLs":                                                "Importera en lista med adresser",
+	"One URL per line":                                                     "En adress per rad",
+	"Import":                                                               "Importera",
+	"No articles match these filters.":                                     "Inga artiklar matchar filtren.",
+	"No articles yet. Click \"Scrape New Articles\" to get started!": "Inga artiklar än. Klicka på \"Hämta nya artiklar\" för att komma igång!",
+	"No articles match this search. Did you mean:":                   "Inga artiklar matchar sökningen. Menade du:",
+	"Deleted %d articles. Database is now empty.":                    "Tog bort %d artiklar. Databasen är nu tom.",
+	"This article is already saved:":                                 "Artikeln är redan sparad:",
+
+	// Filter bar
+	"Search":                "Sök",
+	"Source":                "Källa",
+	"Author":                "Skribent",
+	"Tag":                   "Tagg",
+	"From":                  "Från",
+	"To":                    "Till",
+	"Words or \"a phrase\"": "Ord eller \"en fras\"",
+	"All sources":           "Alla källor",
+	"All authors":           "Alla skribenter",
+	"All tags":              "Alla taggar",
+	"Unread only":           "Endast olästa",
+	"Filter":                "Filtrera",
+	"Reset":                 "Återställ",
+	"Name this search":      "Namnge sökningen",
+	"Save as feed":          "Spara som flöde",
+	"The feed has the latest articles; the dates are not saved.": "Flödet har de senaste artiklarna; datumen sparas inte.",
+
+	// Article cards and details
+	"Are you sure you want to delete this article?": "Vill du verkligen ta bort artikeln?",
+	"Delete article":   "Ta bort artikeln",
+	"Untitled Article": "Artikel utan rubrik",
+	"Only a teaser was captured; it will be scraped again": "Bara ingressen hämtades; artikeln hämtas igen",
+	"Partial":                       "Ofullständig",
+	"Unread":                        "Oläst",
+	"By %s":                         "Av %s",
+	"%d min read":                   "%d min läsning",
+	"%d words":                      "%d ord",
+	"Remove from the reading queue": "Ta bort från läskön",
+	"In queue":                      "I kön",
+	"Add to the reading queue":      "Lägg till i läskön",
+	"Read later":                    "Läs senare",
+	"Back to articles":              "Tillbaka till artiklarna",
+	"View original":                 "Visa originalet",
+	"Podcast episode":               "Poddavsnitt",
+	"Only a teaser of this article was captured, so it is left out of feeds. It will be scraped again on the next run.": "Bara ingressen av artikeln hämtades, så den är utelämnad ur flödena. Den hämtas igen vid nästa körning.",
+	"Duplicate of":       "Dubblett av",
+	"Also published by:": "Även publicerad av:",
+	"Share":              "Dela",
+	"Copy":               "Kopiera",
+	"Anyone with this link can read this article.": "Alla med länken kan läsa artikeln.",
+	"Edit metadata":         "Redigera metadata",
+	"Title":                 "Rubrik",
+	"Published":             "Publicerad",
+	"Save":                  "Spara",
+	"No content available":  "Inget innehåll finns",
+	"Remove tag":            "Ta bort taggen",
+	"Add tag":               "Lägg till tagg",
+	"Comments (%d)":         "Kommentarer (%d)",
+	"Refresh":               "Uppdatera",
+	"No comments captured.": "Inga kommentarer hämtade.",
+	"Anonymous":             "Anonym",
+	"Captured %s":           "Hämtade %s",
+
+	// Reading queue
+	"Reading queue": "Läskö",
+	"Articles marked \"Read later\", newest first. Subscribe to": "Artiklar markerade \"Läs senare\", nyaste först. Prenumerera på",
+	"the queue's feed":        "köns flöde",
+	"to read them elsewhere.": "för att läsa dem någon annanstans.",
+	"The queue is empty. Click \"Read later\" on an article to add it.": "Kön är tom. Klicka på \"Läs senare\" på en artikel för att lägga till den.",
+
+	// Feeds
+	"Copy a feed URL into your reader to subscribe, or": "Kopiera en flödesadress till din läsare för att prenumerera, eller",
+	"download them all as OPML":                         "ladda ner alla som OPML",
+	"to import into a reader in one step.":              "för att importera dem i en läsare på en gång.",
+	"Match calendar":                                    "Matchkalender",
+	"Subscribe in your calendar app to get the fixtures of the sources with a match schedule.": "Prenumerera i din kalenderapp för att få matcherna för källorna med ett matchschema.",
+	"All articles":    "Alla artiklar",
+	"Unread articles": "Olästa artiklar",
+	"Saved searches":  "Sparade sökningar",
+	"By source":       "Per källa",
+	"By author":       "Per skribent",
+	"By tag":          "Per tagg",
+	"Also as":         "Även som",
+	"%d articles":     "%d artiklar",
+	"Delete the saved search %s? Readers subscribed to it will stop getting articles.": "Ta bort den sparade sökningen %s? Läsare som prenumererar på den slutar få artiklar.",
+	"Delete":                                 "Ta bort",
+	"Name the search, e.g. academy players.": "Namnge sökningen, t.ex. akademispelare.",
+	"Search for something or pick a filter before saving.": "Sök efter något eller välj ett filter innan du sparar.",
+	"Cannot save %q: %v.": "Kan inte spara %q: %v.",
+	"Saved search %q. Its feed is listed on the Feeds page.": "Sparade sökningen %q. Dess flöde finns på sidan Flöden.",
+	"Invalid form.": "Ogiltigt formulär.",
+	"Invalid form":  "Ogiltigt formulär",
+
+	// Tags
+	"Players, teams and competitions mentioned in the articles.": "Spelare, lag och tävlingar som nämns i artiklarna.",
+	"No tags yet. Articles are tagged when they are scraped.":    "Inga taggar än. Artiklar taggas när de hämtas.",
+
+	// Alerts
+	"A notification is sent as soon as a newly scraped article mentions one of these words or names in its title or text.": "En avisering skickas så snart en nyhämtad artikel nämner något av de här orden eller namnen i rubriken eller texten.",
+	"End a term with * to also match longer words, e.g. transfer* for \"transfern\".":                                      "Avsluta ett ord med * för att även matcha längre ord, t.ex. transfer* för \"transfern\".",
+	"Set NOTIFY_WEBHOOK_URL to receive them; until then they are only logged.":                                             "Sätt NOTIFY_WEBHOOK_URL för att ta emot dem; tills dess loggas de bara.",
+	"Watch":                                 "Bevaka",
+	"No watch terms yet.":                   "Inga bevakningar än.",
+	"Enter a word or name to watch for":     "Skriv ett ord eller namn att bevaka",
+	"Watch terms are at most %d characters": "Bevakningar är högst %d tecken",
+
+	// Statistics
+	"Statistics":              "Statistik",
+	"Storage":                 "Lagring",
+	"Scrape success":          "Lyckade hämtningar",
+	"%.0f%% of %d runs":       "%.0f%% av %d körningar",
+	"Last run":                "Senaste körning",
+	"Never":                   "Aldrig",
+	"Per source":              "Per källa",
+	"Per month":               "Per månad",
+	"Per author":              "Per skribent",
+	"Feed requests (30 days)": "Flödesanrop (30 dagar)",
+	"No data yet.":            "Ingen data än.",
+
+	// Scrape jobs
+	"Initializing...":         "Startar...",
+	"View run details":        "Visa körningen",
+	"(%d failed)":             "(%d misslyckades)",
+	"Failed (%s):":            "Misslyckades (%s):",
+	"Would add:":              "Skulle lägga till:",
+	"Would update:":           "Skulle uppdatera:",
+	"Would skip:":             "Skulle hoppa över:",
+	"Scraping paused":         "Hämtningen är pausad",
+	"Resume":                  "Fortsätt",
+	"Scraping in progress...": "Hämtning pågår...",
+	"Pause":                   "Pausa",
+	"Cancel":                  "Avbryt",
+	"Articles added: %d":      "Tillagda artiklar: %d",
+	", failed: %d":            ", misslyckade: %d",
+	"Not started: %v. Please wait for it to complete.": "Inte startad: %v. Vänta tills den är klar.",
+	"Cannot add article: %v":                           "Kan inte lägga till artikeln: %v",
+	"Cannot read URL list: %v":                         "Kan inte läsa adresslistan: %v",
+	"Cannot read uploaded file: %v":                    "Kan inte läsa den uppladdade filen: %v",
+	"No valid article URLs found (%d lines rejected).": "Inga giltiga artikeladresser hittades (%d rader avvisade).",
+	"Too many URLs (%d); import at most %d at a time.": "För många adresser (%d); importera högst %d åt gången.",
+	"Ignored %d invalid lines: %s":                     "Hoppade över %d ogiltiga rader: %s",
+
+	// Audit log
+	"Audit Log":             "Logg",
+	"All actions":           "Alla åtgärder",
+	"Nothing recorded yet.": "Inget loggat än.",
+	"When":                  "När",
+	"Who":                   "Vem",
+	"Action":                "Åtgärd",
+	"Target":                "Mål",
+	"Details":               "Detaljer",
+	"via %s":                "via %s",
+	"from %s":               "från %s",
+
+	// Runs
+	"Scrape Runs":                            "Körningar",
+	"Retry Failed":                           "Försök igen med misslyckade",
+	"%d articles waiting for retry":          "%d artiklar väntar på nytt försök",
+	", %d abandoned after repeated failures": ", %d övergivna efter upprepade misslyckanden",
+	"No scrape runs recorded yet.":           "Inga körningar än.",
+	"Started":                                "Startad",
+	"Trigger":                                "Orsak",
+	"Status":                                 "Status",
+	"Duration":                               "Tid",
+	"Found":                                  "Hittade",
+	"Added":                                  "Tillagda",
+	"Skipped":                                "Överhoppade",
+	"Failed":                                 "Misslyckade",
+	"Run #%d":                                "Körning #%d",
+	"Back to runs":                           "Tillbaka till körningarna",
+	"Found / added":                          "Hittade / tillagda",
+	"Failures":                               "Fel",
+	"No failures in this run.":               "Inga fel i den här körningen.",
+	"Screenshot":                             "Skärmbild",
+	"Page HTML":                              "Sidans HTML",
+
+	// Sources
+	"Add Source":             "Lägg till källa",
+	"Edit %s":                "Redigera %s",
+	"Import feeds from OPML": "Importera flöden från OPML",
+	"Every feed becomes a source read every %s, e.g. from a FreshRSS export. Feeds already added are skipped.": "Varje flöde blir en källa som läses var %s, t.ex. från en export från FreshRSS. Flöden som redan finns hoppas över.",
+	"No sources configured.": "Inga källor inlagda.",
+	"Name":                   "Namn",
+	"Start URLs / feed":      "Startadresser / flöde",
+	"Credentials":            "Inloggning",
+	"Schedule":               "Schema",
+	"Feed: %s":               "Flöde: %s",
+	"enabled":                "aktiv",
+	"disabled":               "avstängd",
+	"Scrape every article in the archive of %s? This can take a long time.": "Hämta varje artikel i arkivet för %s? Det kan ta lång tid.",
+	"Backfill": "Hämta arkivet",
+	"Delete the source %s? Its articles are kept.": "Ta bort källan %s? Dess artiklar behålls.",
+	"Back to sources":            "Tillbaka till källorna",
+	"Start URLs":                 "Startadresser",
+	"Feed URL":                   "Flödesadress",
+	"Credentials reference":      "Inloggningsreferens",
+	"Interval":                   "Intervall",
+	"Quiet hours":                "Tysta timmar",
+	"Max scheduled runs per day": "Högst antal schemalagda körningar per dag",
+	"Enabled":                    "Aktiv",
+	"Lowercase letters, digits and dashes. Stored on every article from this source.":                                                                                                               "Gemener, siffror och bindestreck. Sparas på varje artikel från källan.",
+	"Pages article links are collected from, one per line. For feed sources, the site the articles are on.":                                                                                         "Sidor som artikellänkar samlas från, en per rad. För flödeskällor, webbplatsen artiklarna finns på.",
+	"RSS or Atom feed to find new articles in instead of the start pages. Articles are extracted in full from their pages; no site profile is needed.":                                              "RSS- eller Atom-flöde att hitta nya artiklar i i stället för startsidorna. Artiklarna läses ut i sin helhet från sina sidor; ingen webbplatsprofil behövs.",
+	"Where the login comes from: env:NAME for NAME_USER and NAME_PASS, store:NAME for credentials saved by kiln credentials set, or vault:PATH for a Vault secret. Empty if the site has no login.": "Var inloggningen kommer ifrån: env:NAMN för NAMN_USER och NAMN_PASS, store:NAMN för inloggningar sparade med kiln credentials set eller vault:SÖKVÄG för en hemlighet i Vault. Tomt om webbplatsen saknar inloggning.",
+	"Daily scrape times (HH:MM), comma separated. Leave empty to scrape manually or on an interval.":                                                                                                "Dagliga hämtningstider (HH:MM), kommaseparerade. Lämna tomt för att hämta manuellt eller med ett intervall.",
+	"Time between scrapes, e.g. 6h or 90m (at least 15m). Combines with the daily times.":                                                                                                           "Tid mellan hämtningar, t.ex. 6h eller 90m (minst 15m). Kombineras med de dagliga tiderna.",
+	"No scheduled scrapes start in this range (HH:MM-HH:MM); missed daily times run afterwards.":                                                                                                    "Inga schemalagda hämtningar startar i det här spannet (HH:MM-HH:MM); missade dagliga tider körs efteråt.",
+	"Leave empty for no limit. Manual scrapes don't count.":                                                                                                                                         "Lämna tomt för ingen gräns. Manuella hämtningar räknas inte.",
+	"at %s":      "kl. %s",
+	"every %s":   "var %s",
+	"manual":     "manuellt",
+	"quiet %s":   "tyst %s",
+	"max %d/day": "högst %d/dag",
+	"Source %s has no sitemaps or WordPress API to backfill from.":      "Källan %s har inga webbplatskartor eller WordPress-API att hämta arkivet från.",
+	"Cannot read OPML file: %v":                                         "Kan inte läsa OPML-filen: %v",
+	"Choose an OPML file to import.":                                    "Välj en OPML-fil att importera.",
+	"Cannot import: %v":                                                 "Kan inte importera: %v",
+	"Added %d feed sources":                                             "Lade till %d flödeskällor",
+	". %d already registered, %d invalid. Reload the page to see them.": ". %d fanns redan, %d ogiltiga. Ladda om sidan för att se dem.",
+
+	// Sign in
+	"Sign in":                     "Logga in",
+	"Username":                    "Användarnamn",
+	"Password":                    "Lösenord",
+	"Sign in with single sign-on": "Logga in med single sign-on",
+	"Interactive sign-in is not configured. Use an API key or feed token.": "Inloggning är inte konfigurerad. Använd en API-nyckel eller flödestoken.",
+
+	// Shared articles
+	"Shared from %s": "Delad från %s",
+}
@@ -0,0 +1,94 @@
+// Package logging configures Kiln's log output. Infof, and the standard log
+// package, log at the info level; Debugf and Warnf log below and above it.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Options configures the log output
+type Options struct {
+	Level  string // debug, info, warn or error
+	Format string // text or json
+
+	// File is written besides stderr ("" writes stderr only). It is rotated
+	// when it reaches MaxSizeMB, keeping MaxBackups rotated files.
+	File       string
+	MaxSizeMB  int
+	MaxBackups int
+}
+
+// Setup installs the configured output as the default logger. The returned
+// closer closes the log file, if any.
+func Setup(opts Options) (io.Closer, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(opts.Level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", opts.Level, err)
+	}
+
+	var out io.Writer = os.Stderr
+	var closer io.Closer = nopCloser{}
+	if opts.File != "" {
+		file, err := openRotating(opts.File, int64(opts.MaxSizeMB)<<20, opts.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		out = io.MultiWriter(os.Stderr, file)
+		closer = file
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case "json":
+		handler = slog.NewJSONHandler(out, handlerOpts)
+	case "", "text":
+		handler = slog.NewTextHandler(out, handlerOpts)
+	default:
+		closer.Close()
+		return nil, fmt.Errorf("invalid log format %q", opts.Format)
+	}
+
+	// Also routes the standard log package, at the info level
+	slog.SetDefault(slog.New(handler))
+	return closer, nil
+}
+
+// Debugf logs a message only shown with LOG_LEVEL=debug, e.g. the details
+// of how an article was extracted
+func Debugf(format string, args ...any) {
+	logf(slog.LevelDebug, format, args...)
+}
+
+// Infof logs the progress of Kiln's work, e.g. a job starting or an article
+// being saved
+func Infof(format string, args ...any) {
+	logf(slog.LevelInfo, format, args...)
+}
+
+// Warnf logs a failure that needs attention but doesn't stop the work at
+// hand
+func Warnf(format string, args ...any) {
+	logf(slog.LevelWarn, format, args...)
+}
+
+// logf formats and logs a message at level, skipping the formatting when
+// the level is not logged
+func logf(level slog.Level, format string, args ...any) {
+	ctx := context.Background()
+	logger := slog.Default()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	logger.Log(ctx, level, fmt.Sprintf(format, args...))
+}
+
+// nopCloser is the closer of output without a log file
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
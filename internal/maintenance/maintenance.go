@@ -0,0 +1,101 @@
+// Package maintenance runs database upkeep (VACUUM and ANALYZE) and reports
+// on the health of the archive: table and index sizes, mirrored images no
+// article refers to any more, and articles stored twice under variants of
+// the same URL.
+package maintenance
+
+import (
+	"context"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/media"
+)
+
+// Report is the outcome of a maintenance run
+type Report struct {
+	StartedAt time.Time `json:"started_at"`
+
+	// Vacuumed is false when vacuuming was skipped
+	Vacuumed       bool   `json:"vacuumed"`
+	VacuumDuration string `json:"vacuum_duration,omitempty"`
+
+	Tables  []database.TableSize `json:"tables"`
+	Indexes []database.IndexSize `json:"indexes"`
+
+	// OrphanedMedia is nil when images are not mirrored
+	OrphanedMedia *OrphanedMedia `json:"orphaned_media,omitempty"`
+
+	DuplicateURLs []database.DuplicateURL `json:"duplicate_urls"`
+}
+
+// OrphanedMedia lists the mirrored images no article refers to
+type OrphanedMedia struct {
+	Stored int      `json:"stored"` // mirrored images in the store
+	Count  int      `json:"count"`
+	Bytes  int64    `json:"bytes"`
+	Names  []string `json:"names"`
+}
+
+// Options selects the steps of a run
+type Options struct {
+	Vacuum bool
+	Media  *media.Mirror // nil when images are not mirrored
+}
+
+// Run vacuums and analyzes the database and builds the report
+func Run(ctx context.Context, db *database.DB, opts Options) (*Report, error) {
+	report := &Report{StartedAt: time.Now()}
+
+	if opts.Vacuum {
+		started := time.Now()
+		if err := db.Vacuum(ctx); err != nil {
+			return nil, err
+		}
+		report.Vacuumed = true
+		report.VacuumDuration = time.Since(started).Round(time.Millisecond).String()
+	}
+
+	var err error
+	if report.Tables, err = db.GetTableSizes(ctx); err != nil {
+		return nil, err
+	}
+	if report.Indexes, err = db.GetIndexSizes(ctx); err != nil {
+		return nil, err
+	}
+
+	if opts.Media != nil {
+		if report.OrphanedMedia, err = orphanedMedia(ctx, db, opts.Media); err != nil {
+			return nil, err
+		}
+	}
+
+	if report.DuplicateURLs, err = db.GetDuplicateURLs(ctx); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// orphanedMedia finds the mirrored images that no article refers to
+func orphanedMedia(ctx context.Context, db *database.DB, mirror *media.Mirror) (*OrphanedMedia, error) {
+	images, err := mirror.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	referenced, err := db.GetMediaReferences(ctx, media.URLPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := &OrphanedMedia{Stored: len(images), Names: []string{}}
+	for _, image := range images {
+		if referenced[image.Name] {
+			continue
+		}
+		orphans.Count++
+		orphans.Bytes += image.Size
+		orphans.Names = append(orphans.Names, image.Name)
+	}
+	return orphans, nil
+}
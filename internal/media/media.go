@@ -0,0 +1,226 @@
+// Package media mirrors images referenced by articles into Kiln's own
+// storage, so they keep working after the source removes them or puts them
+// behind a login.
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/internal/storage"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// URLPrefix is the path under which mirrored images are served
+const URLPrefix = "/media/"
+
+const (
+	// maxImageSize bounds a single downloaded image
+	maxImageSize = 10 << 20
+
+	// fetchTimeout bounds a single image download
+	fetchTimeout = 30 * time.Second
+)
+
+// namePattern matches the names Mirror stores images under
+var namePattern = regexp.MustCompile(`^[0-9a-f]{32}(\.[a-z0-9]+)?$`)
+
+// imageExtensions maps the image types Kiln mirrors to file extensions
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"image/avif": ".avif",
+}
+
+// Mirror downloads images into a store
+type Mirror struct {
+	store storage.Store
+}
+
+// NewMirror creates a mirror storing images in store
+func NewMirror(store storage.Store) *Mirror {
+	return &Mirror{store: store}
+}
+
+// Localize downloads the images referenced by <img> tags in contentHTML and
+// rewrites them to point at their mirrored copies. Relative URLs are resolved
+// against base, and jar supplies cookies for images behind the source's
+// login. Images that cannot be downloaded keep their original URL. Returns
+// the rewritten HTML and the local path of every mirrored image by its
+// original (absolute) URL.
+func (m *Mirror) Localize(ctx context.Context, contentHTML string, base *url.URL, jar http.CookieJar) (string, map[string]string, error) {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(contentHTML), body)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse content: %w", err)
+	}
+
+	client := &http.Client{Timeout: fetchTimeout, Jar: jar}
+	mirrored := make(map[string]string)
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Img {
+			m.localizeImage(ctx, client, n, base, mirrored)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			visit(child)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, n := range nodes {
+		visit(n)
+		if err := html.Render(&buf, n); err != nil {
+			return "", nil, fmt.Errorf("failed to render content: %w", err)
+		}
+	}
+	return buf.String(), mirrored, nil
+}
+
+// localizeImage mirrors the src of a single <img> and rewrites it in place
+func (m *Mirror) localizeImage(ctx context.Context, client *http.Client, img *html.Node, base *url.URL, mirrored map[string]string) {
+	for i, attr := range img.Attr {
+		if attr.Key != "src" {
+			continue
+		}
+		ref, err := url.Parse(strings.TrimSpace(attr.Val))
+		if err != nil {
+			return
+		}
+		src := base.ResolveReference(ref)
+		if src.Scheme != "http" && src.Scheme != "https" {
+			return
+		}
+
+		local, ok := mirrored[src.String()]
+		if !ok {
+			local, err = m.fetch(ctx, client, src.String())
+			if err != nil {
+				logging.Warnf("Failed to mirror image %s: %v", src, err)
+				return
+			}
+			mirrored[src.String()] = local
+		}
+		img.Attr[i].Val = local
+
+		// Responsive variants still point at the source
+		img.Attr = removeAttrs(img.Attr, "srcset", "sizes")
+		if picture := img.Parent; picture != nil && picture.DataAtom == atom.Picture {
+			for child := picture.FirstChild; child != nil; child = child.NextSibling {
+				if child.DataAtom == atom.Source {
+					child.Attr = removeAttrs(child.Attr, "srcset")
+				}
+			}
+		}
+		return
+	}
+}
+
+// Fetch mirrors a single image, returning its local path
+func (m *Mirror) Fetch(ctx context.Context, imageURL string, jar http.CookieJar) (string, error) {
+	return m.fetch(ctx, &http.Client{Timeout: fetchTimeout, Jar: jar}, imageURL)
+}
+
+func (m *Mirror) fetch(ctx context.Context, client *http.Client, imageURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download returned %s", resp.Status)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	ext, ok := imageExtensions[mediaType]
+	if !ok {
+		return "", fmt.Errorf("unsupported content type %q", mediaType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) > maxImageSize {
+		return "", fmt.Errorf("image is larger than %d bytes", maxImageSize)
+	}
+
+	// Names are derived from the source URL, so re-scraping overwrites
+	sum := sha256.Sum256([]byte(imageURL))
+	name := hex.EncodeToString(sum[:16]) + ext
+	if err := m.store.Put(ctx, name, bytes.NewReader(data), int64(len(data))); err != nil {
+		return "", err
+	}
+	return URLPrefix + name, nil
+}
+
+// Open returns a mirrored image and its content type. Unknown names return
+// storage.ErrNotFound.
+func (m *Mirror) Open(ctx context.Context, name string) (io.ReadCloser, string, error) {
+	if !namePattern.MatchString(name) {
+		return nil, "", storage.ErrNotFound
+	}
+	body, err := m.store.Get(ctx, name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	contentType := "application/octet-stream"
+	for t, ext := range imageExtensions {
+		if ext == path.Ext(name) {
+			contentType = t
+		}
+	}
+	return body, contentType, nil
+}
+
+// List returns the mirrored images in the store
+func (m *Mirror) List(ctx context.Context) ([]storage.Object, error) {
+	objects, err := m.store.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	images := objects[:0]
+	for _, obj := range objects {
+		if namePattern.MatchString(obj.Name) {
+			images = append(images, obj)
+		}
+	}
+	return images, nil
+}
+
+func removeAttrs(attrs []html.Attribute, keys ...string) []html.Attribute {
+	kept := attrs[:0]
+	for _, attr := range attrs {
+		remove := false
+		for _, key := range keys {
+			if attr.Key == key {
+				remove = true
+			}
+		}
+		if !remove {
+			kept = append(kept, attr)
+		}
+	}
+	return kept
+}
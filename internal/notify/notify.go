@@ -0,0 +1,96 @@
+// Package notify delivers operational notifications (e.g. a failed backup)
+// to the administrator.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+// Levels of a notification
+const (
+	LevelInfo  = "info"
+	LevelError = "error"
+)
+
+// Message is a single notification
+type Message struct {
+	Title string
+	Body  string
+	Level string
+}
+
+// Notifier delivers notifications
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// New returns a webhook notifier for webhookURL, or a notifier that only
+// logs when no URL is configured
+func New(webhookURL string) Notifier {
+	if webhookURL == "" {
+		return logNotifier{}
+	}
+	return NewWebhook(webhookURL)
+}
+
+// logNotifier writes notifications to the log
+type logNotifier struct{}
+
+func (logNotifier) Notify(ctx context.Context, msg Message) error {
+	logging.Infof("[%s] %s: %s", msg.Level, msg.Title, msg.Body)
+	return nil
+}
+
+// Webhook posts notifications as JSON. The "text" field makes the payload
+// work with Slack-compatible incoming webhooks (Slack, Mattermost, Discord's
+// /slack endpoint); the other fields suit custom receivers.
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook creates a notifier posting to url
+func NewWebhook(url string) *Webhook {
+	return &Webhook{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Notify implements Notifier. The notification is also logged so it is not
+// lost when the webhook is unreachable.
+func (w *Webhook) Notify(ctx context.Context, msg Message) error {
+	logNotifier{}.Notify(ctx, msg)
+
+	payload, err := json.Marshal(map[string]string{
+		"text":    fmt.Sprintf("*%s*\n%s", msg.Title, msg.Body),
+		"title":   msg.Title,
+		"message": msg.Body,
+		"level":   msg.Level,
+		"source":  "kiln",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("notification webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+// Package peersync keeps the article sets of several kiln instances in step.
+//
+// Each instance exposes its changes through GET /api/v1/sync/articles, paged
+// in (updated_at, uuid) order with an opaque cursor. A Puller periodically
+// fetches the changes of its peers and applies them locally: articles are
+// matched by URL and the most recently updated copy wins. Two instances that
+// pull from each other converge on the same set of articles.
+package peersync
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/database"
+)
+
+// Page sizes for the sync endpoint
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 500
+)
+
+// nilUUID sorts before every other UUID and marks the start of a timestamp
+const nilUUID = "00000000-0000-0000-0000-000000000000"
+
+// Article is the wire form of an article exchanged between instances
+type Article struct {
+	UUID        string     `json:"uuid"`
+	Source      string     `json:"source"`
+	URL         string     `json:"url"`
+	Title       *string    `json:"title,omitempty"`
+	Author      *string    `json:"author,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	ContentHTML *string    `json:"content_html,omitempty"`
+	ContentText *string    `json:"content_text,omitempty"`
+	ImageURL    *string    `json:"image_url,omitempty"`
+	Partial     bool       `json:"partial,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	Categories []string `json:"categories,omitempty"`
+	AudioURL   *string  `json:"audio_url,omitempty"`
+	AudioType  *string  `json:"audio_type,omitempty"`
+	WaybackURL *string  `json:"wayback_url,omitempty"`
+	Summary    *string  `json:"summary,omitempty"`
+
+	// Tags, Read and Comments are always sent, the lists empty when the
+	// article has none; records from versions without them leave the
+	// receiver's state alone
+	Tags     []Tag     `json:"tags"`
+	Read     *bool     `json:"read,omitempty"`
+	Comments []Comment `json:"comments"`
+}
+
+// Tag is the wire form of an article tag
+type Tag struct {
+	Tag    string `json:"tag"`
+	Manual bool   `json:"manual,omitempty"`
+}
+
+// Comment is the wire form of a comment of an article's thread
+type Comment struct {
+	Author   *string    `json:"author,omitempty"`
+	PostedAt *time.Time `json:"posted_at,omitempty"`
+	Body     string     `json:"body"`
+}
+
+// FromArticle converts a stored article to its wire form
+func FromArticle(a *database.Article) Article {
+	article := Article{
+		UUID:        a.UUID,
+		Source:      a.Source,
+		URL:         a.URL,
+		Title:       a.Title,
+		Author:      a.Author,
+		PublishedAt: a.PublishedAt,
+		ContentHTML: a.ContentHTML,
+		ContentText: a.ContentText,
+		ImageURL:    a.ImageURL,
+		Partial:     a.Partial,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+		Categories:  a.Categories,
+		AudioURL:    a.AudioURL,
+		AudioType:   a.AudioType,
+		WaybackURL:  a.WaybackURL,
+		Summary:     a.Summary,
+		Tags:        make([]Tag, 0, len(a.Tags)),
+		Read:        a.Read,
+		Comments:    make([]Comment, 0, len(a.Comments)),
+	}
+	for _, t := range a.Tags {
+		article.Tags = append(article.Tags, Tag{Tag: t.Tag, Manual: t.Manual})
+	}
+	for _, c := range a.Comments {
+		article.Comments = append(article.Comments, Comment{Author: c.Author, PostedAt: c.PostedAt, Body: c.Body})
+	}
+	return article
+}
+
+// ToArticle converts the wire form back to a database article
+func (a Article) ToArticle() *database.Article {
+	article := &database.Article{
+		UUID:        a.UUID,
+		Source:      a.Source,
+		URL:         a.URL,
+		Title:       a.Title,
+		Author:      a.Author,
+		PublishedAt: a.PublishedAt,
+		ContentHTML: a.ContentHTML,
+		ContentText: a.ContentText,
+		ImageURL:    a.ImageURL,
+		Partial:     a.Partial,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+		Categories:  a.Categories,
+		AudioURL:    a.AudioURL,
+		AudioType:   a.AudioType,
+		WaybackURL:  a.WaybackURL,
+		Summary:     a.Summary,
+		Read:        a.Read,
+	}
+	if a.Tags != nil {
+		article.Tags = make([]database.ArticleTag, 0, len(a.Tags))
+		for _, t := range a.Tags {
+			article.Tags = append(article.Tags, database.ArticleTag{Tag: t.Tag, Manual: t.Manual})
+		}
+	}
+	if a.Comments != nil {
+		article.Comments = make([]*database.Comment, 0, len(a.Comments))
+		for _, c := range a.Comments {
+			article.Comments = append(article.Comments, &database.Comment{Author: c.Author, PostedAt: c.PostedAt, Body: c.Body})
+		}
+	}
+	return article
+}
+
+// Page is one page of changes returned by the sync endpoint
+type Page struct {
+	Articles   []Article `json:"articles"`
+	NextCursor string    `json:"next_cursor"`
+	HasMore    bool      `json:"has_more"`
+}
+
+// NewPage builds a page from up to limit+1 articles fetched after from; the
+// extra article only signals that more changes follow
+func NewPage(articles []*database.Article, limit int, from Cursor) Page {
+	page := Page{Articles: make([]Article, 0, len(articles)), NextCursor: from.String()}
+	if len(articles) > limit {
+		articles = articles[:limit]
+		page.HasMore = true
+	}
+	for _, a := range articles {
+		page.Articles = append(page.Articles, FromArticle(a))
+	}
+	if n := len(articles); n > 0 {
+		last := articles[n-1]
+		page.NextCursor = Cursor{UpdatedAt: last.UpdatedAt, UUID: last.UUID}.String()
+	}
+	return page
+}
+
+// Cursor is a position in the change stream of an instance
+type Cursor struct {
+	UpdatedAt time.Time
+	UUID      string
+}
+
+// CursorAt returns the cursor positioned before every change at or after t
+func CursorAt(t time.Time) Cursor {
+	return Cursor{UpdatedAt: t, UUID: nilUUID}
+}
+
+// String encodes the cursor; the zero cursor encodes as ""
+func (c Cursor) String() string {
+	if c.UpdatedAt.IsZero() {
+		return ""
+	}
+	raw := c.UpdatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.UUID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseCursor decodes a cursor; "" is the start of the change stream
+func ParseCursor(s string) (Cursor, error) {
+	if s == "" {
+		return CursorAt(time.Time{}), nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("malformed cursor")
+	}
+	ts, uuid, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return Cursor{}, fmt.Errorf("malformed cursor")
+	}
+	updatedAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("malformed cursor: %w", err)
+	}
+	if uuid == "" {
+		uuid = nilUUID
+	}
+	return Cursor{UpdatedAt: updatedAt, UUID: uuid}, nil
+}
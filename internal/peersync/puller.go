@@ -0,0 +1,151 @@
+package peersync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+// SyncPath is the API path serving an instance's changes
+const SyncPath = "/api/v1/sync/articles"
+
+// Peer is another kiln instance to pull changes from
+type Peer struct {
+	URL    string
+	APIKey string
+}
+
+// Puller periodically pulls article changes from peers
+type Puller struct {
+	db       *database.DB
+	peers    []Peer
+	interval time.Duration
+	client   *http.Client
+}
+
+// NewPuller creates a puller for the given peers
+func NewPuller(db *database.DB, peers []Peer, interval time.Duration) *Puller {
+	return &Puller{
+		db:       db,
+		peers:    peers,
+		interval: interval,
+		client:   &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Run pulls from every peer immediately and then once per interval until
+// ctx is cancelled
+func (p *Puller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.SyncAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncAll pulls from each peer in turn, logging failures
+func (p *Puller) SyncAll(ctx context.Context) {
+	for _, peer := range p.peers {
+		applied, err := p.Pull(ctx, peer)
+		if err != nil {
+			logging.Warnf("Sync from %s failed after %d changes: %v", peer.URL, applied, err)
+			continue
+		}
+		if applied > 0 {
+			logging.Infof("Sync from %s applied %d changes", peer.URL, applied)
+		}
+	}
+}
+
+// Pull fetches all changes since the stored cursor of peer and applies them,
+// returning the number of articles written locally
+func (p *Puller) Pull(ctx context.Context, peer Peer) (int, error) {
+	state, err := p.db.GetSyncPeer(ctx, peer.URL)
+	if err != nil {
+		return 0, err
+	}
+
+	cursor := state.Cursor
+	applied := 0
+	for {
+		var page *Page
+		page, err = p.fetch(ctx, peer, cursor)
+		if err != nil {
+			break
+		}
+
+		for _, article := range page.Articles {
+			var written bool
+			written, err = p.db.UpsertSyncedArticle(ctx, article.ToArticle())
+			if err != nil {
+				break
+			}
+			if written {
+				applied++
+			}
+		}
+		if err != nil {
+			break
+		}
+
+		cursor = page.NextCursor
+		if !page.HasMore {
+			break
+		}
+	}
+
+	// Keep the position reached so the next pull resumes from there
+	if saveErr := p.db.SaveSyncPeer(context.WithoutCancel(ctx), peer.URL, cursor, err); saveErr != nil {
+		logging.Warnf("Failed to save sync position for %s: %v", peer.URL, saveErr)
+	}
+	return applied, err
+}
+
+// fetch requests one page of changes after cursor
+func (p *Puller) fetch(ctx context.Context, peer Peer, cursor string) (*Page, error) {
+	params := url.Values{}
+	params.Set("limit", strconv.Itoa(DefaultPageSize))
+	if cursor != "" {
+		params.Set("cursor", cursor)
+	}
+	endpoint := strings.TrimSuffix(peer.URL, "/") + SyncPath + "?" + params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sync request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if peer.APIKey != "" {
+		req.Header.Set("X-API-Key", peer.APIKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch changes: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("peer returned %s", resp.Status)
+	}
+
+	var page Page
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode changes: %w", err)
+	}
+	return &page, nil
+}
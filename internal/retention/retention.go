@@ -0,0 +1,173 @@
+// Package retention keeps the article archive within a retention policy: a
+// maximum age and/or a maximum number of articles per source. A Pruner
+// enforces the policy once a day, or only reports what it would delete.
+package retention
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/internal/notify"
+)
+
+// Policy decides which articles are kept. Zero fields don't limit anything.
+type Policy struct {
+	MaxAge       time.Duration // by publication date, or scrape date without one
+	MaxPerSource int
+}
+
+// Enabled reports whether the policy removes anything
+func (p Policy) Enabled() bool {
+	return p.MaxAge > 0 || p.MaxPerSource > 0
+}
+
+// String describes the policy, e.g. "older than 730 days, beyond 5000 per source"
+func (p Policy) String() string {
+	var rules []string
+	if p.MaxAge > 0 {
+		rules = append(rules, fmt.Sprintf("older than %d days", int(p.MaxAge.Hours()/24)))
+	}
+	if p.MaxPerSource > 0 {
+		rules = append(rules, fmt.Sprintf("beyond %d per source", p.MaxPerSource))
+	}
+	return strings.Join(rules, ", ")
+}
+
+// Report is the outcome of a prune: the articles deleted, or that would be
+// deleted on a dry run, per source
+type Report struct {
+	DryRun  bool
+	Sources []database.FacetCount
+	Total   int
+}
+
+// String summarizes the report, e.g. "Deleted 120 articles (gasetten: 100, nt: 20)"
+func (r *Report) String() string {
+	verb := "Deleted"
+	if r.DryRun {
+		verb = "Would delete"
+	}
+	if r.Total == 0 {
+		return verb + " no articles"
+	}
+
+	counts := make([]string, 0, len(r.Sources))
+	for _, source := range r.Sources {
+		counts = append(counts, fmt.Sprintf("%s: %d", source.Name, source.Count))
+	}
+	return fmt.Sprintf("%s %d articles (%s)", verb, r.Total, strings.Join(counts, ", "))
+}
+
+// Pruner enforces a policy every day at a fixed local time and reports the
+// outcome through a notifier
+type Pruner struct {
+	db       *database.DB
+	policy   Policy
+	dryRun   bool
+	notifier notify.Notifier
+	hour     int
+	minute   int
+}
+
+// NewPruner creates a pruner running at timeOfDay ("HH:MM", local time). On
+// a dry run it only reports what the policy would delete.
+func NewPruner(db *database.DB, policy Policy, dryRun bool, notifier notify.Notifier, timeOfDay string) (*Pruner, error) {
+	t, err := time.Parse("15:04", timeOfDay)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retention time %q, expected HH:MM", timeOfDay)
+	}
+	return &Pruner{db: db, policy: policy, dryRun: dryRun, notifier: notifier, hour: t.Hour(), minute: t.Minute()}, nil
+}
+
+// Run waits for each scheduled time and prunes until ctx is cancelled
+func (p *Pruner) Run(ctx context.Context) {
+	for {
+		next := p.next(time.Now())
+		logging.Infof("Next retention cleanup at %s", next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		p.runOnce(ctx)
+	}
+}
+
+// next returns the first scheduled time after now
+func (p *Pruner) next(now time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), p.hour, p.minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+func (p *Pruner) runOnce(ctx context.Context) {
+	report, err := Prune(ctx, p.db, p.policy, p.dryRun)
+
+	msg := notify.Message{Level: notify.LevelInfo, Title: "Kiln retention cleanup"}
+	if err != nil {
+		msg.Level = notify.LevelError
+		msg.Title = "Kiln retention cleanup failed"
+		msg.Body = err.Error()
+	} else {
+		logging.Infof("Retention cleanup (%s): %s", p.policy, report)
+		if report.Total == 0 {
+			return
+		}
+		msg.Body = report.String()
+	}
+
+	if err := p.notifier.Notify(ctx, msg); err != nil {
+		logging.Warnf("Failed to send retention notification: %v", err)
+	}
+}
+
+// Prune deletes the articles outside policy, or on a dry run counts them
+func Prune(ctx context.Context, db *database.DB, policy Policy, dryRun bool) (*Report, error) {
+	if !policy.Enabled() {
+		return nil, fmt.Errorf("no retention policy configured")
+	}
+
+	var before *time.Time
+	if policy.MaxAge > 0 {
+		t := time.Now().Add(-policy.MaxAge)
+		before = &t
+	}
+
+	var sources []database.FacetCount
+	var err error
+	if dryRun {
+		sources, err = db.CountExpiredArticles(ctx, before, policy.MaxPerSource)
+	} else {
+		sources, err = db.DeleteExpiredArticles(ctx, before, policy.MaxPerSource)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{DryRun: dryRun, Sources: sources}
+	for _, source := range sources {
+		report.Total += source.Count
+	}
+
+	if !dryRun && report.Total > 0 {
+		details := map[string]any{"policy": policy.String(), "articles": report.Total, "sources": sources}
+		entry, err := database.NewAuditEntry(database.AuditActorSystem, database.AuditArticlesPrune, "", details)
+		if err == nil {
+			err = db.CreateAuditEntry(ctx, entry)
+		}
+		if err != nil {
+			logging.Warnf("Failed to record the pruning in the audit log: %v", err)
+		}
+	}
+	return report, nil
+}
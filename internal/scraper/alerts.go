@@ -0,0 +1,103 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/internal/notify"
+)
+
+// sendAlerts notifies about the newly saved articles that mention a watch
+// term, one notification per article
+func (s *Scraper) sendAlerts(ctx context.Context, articles []*database.Article) {
+	if s.notifier == nil || len(articles) == 0 {
+		return
+	}
+	terms, err := s.db.GetWatchTerms(ctx)
+	if err != nil {
+		logging.Warnf("Failed to load watch terms: %v", err)
+		return
+	}
+	if len(terms) == 0 {
+		return
+	}
+
+	for _, article := range articles {
+		matched := matchingTerms(terms, article)
+		if len(matched) == 0 {
+			continue
+		}
+
+		title := article.URL
+		if article.Title != nil {
+			title = *article.Title
+		}
+		msg := notify.Message{
+			Level: notify.LevelInfo,
+			Title: "Kiln alert: " + strings.Join(matched, ", "),
+			Body:  fmt.Sprintf("%s (%s)\n%s", title, article.Source, article.URL),
+		}
+		if err := s.notifier.Notify(ctx, msg); err != nil {
+			logging.Warnf("Failed to send alert for %s: %v", article.URL, err)
+		}
+	}
+}
+
+// matchingTerms returns the watch terms an article's title or text mentions.
+// A term ending in "*" also matches words it begins, so "transfer*" matches
+// "transfern".
+func matchingTerms(terms []*database.WatchTerm, article *database.Article) []string {
+	var text strings.Builder
+	if article.Title != nil {
+		text.WriteString(*article.Title)
+		text.WriteString("\n")
+	}
+	if article.ContentText != nil {
+		text.WriteString(*article.ContentText)
+	}
+	haystack := strings.ToLower(text.String())
+
+	var matched []string
+	for _, term := range terms {
+		word, prefix := strings.CutSuffix(strings.ToLower(term.Term), "*")
+		if containsWord(haystack, word, prefix) {
+			matched = append(matched, term.Term)
+		}
+	}
+	return matched
+}
+
+// containsWord reports whether text contains word as a whole word or
+// phrase, so "isaj" doesn't match "isajev", or as the start of a word when
+// prefix is set. Both are lower case. Unlike \b in regular expressions,
+// letters such as å, ä and ö count as part of a word.
+func containsWord(text, word string, prefix bool) bool {
+	if word == "" {
+		return false
+	}
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], word)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(word)
+
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !isWordRune(before)) && (prefix || end == len(text) || !isWordRune(after)) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		offset = start + size
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
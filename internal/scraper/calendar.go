@@ -0,0 +1,196 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/pkg/extract"
+)
+
+// kickoffFormats are the layouts tried on kickoff times, after the source's
+// own date formats. Fixtures pages often leave out the year.
+var kickoffFormats = []string{
+	"2 January 2006 15:04",
+	"2 January 2006",
+	"2 January 15:04",
+	"2 January",
+	"2 Jan 15:04",
+	"2 Jan",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2/1 15:04",
+	"2/1",
+}
+
+// teamSeparator splits "Malmö FF - AIK" or "Malmö FF vs. AIK" into the
+// home and away team
+var teamSeparator = regexp.MustCompile(`\s+(?:[-–—]|vs\.?|v\.?)\s+`)
+
+// check validates the calendar selectors, which are all optional until a
+// fixtures page is configured
+func (c *CalendarSelectors) check() error {
+	if c.URL == "" {
+		return nil
+	}
+	if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("invalid calendar url %q", c.URL)
+	}
+	if c.Match == "" || c.Kickoff == "" {
+		return fmt.Errorf("calendar needs match and kickoff selectors")
+	}
+	if (c.Home == "" || c.Away == "") && c.Teams == "" {
+		return fmt.Errorf("calendar needs home and away selectors, or a teams selector")
+	}
+	return nil
+}
+
+// scrapeCalendar reads the matches on a source's fixtures page into the
+// match calendar. A page without any matches is left unsaved, as that is
+// more likely a changed layout than an empty schedule.
+func (s *Scraper) scrapeCalendar(ctx context.Context, name string, source *SourceConfig) error {
+	progressOf(ctx).UpdateStatus(StatusScraping, "Loading match calendar...")
+
+	page, err := s.createPageWithRetry(source.Calendar.URL)
+	if err != nil {
+		return fmt.Errorf("failed to create calendar page: %w", err)
+	}
+	defer page.Close()
+
+	page = page.Timeout(PageTimeout)
+	if err := page.WaitLoad(); err != nil {
+		return fmt.Errorf("timeout waiting for calendar page to load: %w", err)
+	}
+
+	elements, err := page.Elements(source.Calendar.Match)
+	if err != nil {
+		return fmt.Errorf("failed to find matches: %w", err)
+	}
+
+	base, _ := url.Parse(source.Calendar.URL)
+	now := time.Now()
+	var matches []*database.Match
+	for _, el := range elements {
+		if match, ok := parseMatch(el, source, base, now); ok {
+			matches = append(matches, match)
+		}
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no matches found on %s (%d elements matched)", source.Calendar.URL, len(elements))
+	}
+
+	if isDryRun(ctx) {
+		logging.Infof("Dry run: found %d matches on %s, not saving them", len(matches), source.Calendar.URL)
+		return nil
+	}
+	if err := s.db.ReplaceMatches(context.WithoutCancel(ctx), name, matches); err != nil {
+		return err
+	}
+	logging.Infof("Saved %d matches from %s", len(matches), source.Calendar.URL)
+	return nil
+}
+
+// parseMatch reads a match from its element on a fixtures page. Relative
+// links are resolved against base, and kickoffs without a year are placed
+// in the season around now.
+func parseMatch(el *rod.Element, source *SourceConfig, base *url.URL, now time.Time) (*database.Match, bool) {
+	selectors := &source.Calendar
+
+	home, away := firstText(el, selectors.Home), firstText(el, selectors.Away)
+	if home == "" || away == "" {
+		home, away = splitTeams(firstText(el, selectors.Teams))
+	}
+	if home == "" || away == "" {
+		logging.Debugf("Skipping match without both teams")
+		return nil, false
+	}
+
+	kickoff, ok := parseKickoff(el, source, now)
+	if !ok {
+		logging.Debugf("Skipping match %s - %s: no kickoff time found", home, away)
+		return nil, false
+	}
+
+	match := &database.Match{Kickoff: kickoff, HomeTeam: home, AwayTeam: away}
+	if competition := firstText(el, selectors.Competition); competition != "" {
+		match.Competition = &competition
+	}
+	if venue := firstText(el, selectors.Venue); venue != "" {
+		match.Venue = &venue
+	}
+	if result := firstText(el, selectors.Result); result != "" {
+		match.Result = &result
+	}
+	if links, err := el.Elements(`a[href]`); err == nil && len(links) > 0 {
+		if href, err := links.First().Attribute("href"); err == nil && href != nil {
+			if ref, err := url.Parse(*href); err == nil {
+				link := base.ResolveReference(ref).String()
+				match.URL = &link
+			}
+		}
+	}
+	return match, true
+}
+
+// parseKickoff reads a match's kickoff from the datetime attribute or the
+// text of its kickoff element
+func parseKickoff(el *rod.Element, source *SourceConfig, now time.Time) (time.Time, bool) {
+	found, err := el.Elements(source.Calendar.Kickoff)
+	if err != nil || len(found) == 0 {
+		return time.Time{}, false
+	}
+	kickoff := found.First()
+
+	if datetime, err := kickoff.Attribute("datetime"); err == nil && datetime != nil {
+		for _, format := range slices.Concat(source.DateFormats, []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"}) {
+			if t, err := time.ParseInLocation(format, *datetime, time.Local); err == nil {
+				return t, true
+			}
+		}
+	}
+
+	text, err := kickoff.Text()
+	if err != nil {
+		return time.Time{}, false
+	}
+	t, ok := extract.ParseDate(strings.TrimSpace(text), source.DateLocale, source.MonthNames, slices.Concat(source.DateFormats, kickoffFormats))
+	if !ok {
+		return time.Time{}, false
+	}
+	if t.Year() == 0 {
+		t = withSeasonYear(t, now)
+	}
+	return t, true
+}
+
+// withSeasonYear gives a date parsed without a year the year that puts it
+// closest to now: fixtures pages list the rest of the season and the
+// recent results, so a date far in the past is in the next year
+func withSeasonYear(t, now time.Time) time.Time {
+	t = time.Date(now.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location())
+	switch {
+	case t.Before(now.AddDate(0, -6, 0)):
+		return t.AddDate(1, 0, 0)
+	case t.After(now.AddDate(0, 6, 0)):
+		return t.AddDate(-1, 0, 0)
+	}
+	return t
+}
+
+// splitTeams splits the text of a teams element into the home and away
+// team. Returns "" when it doesn't name two teams.
+func splitTeams(text string) (string, string) {
+	parts := teamSeparator.Split(strings.TrimSpace(text), 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+}
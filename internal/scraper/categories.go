@@ -0,0 +1,104 @@
+package scraper
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/go-rod/rod"
+)
+
+// defaultCategorySelectors find the categories of an article on pages whose
+// source has no category selector: the Open Graph section and WordPress
+// category links
+var defaultCategorySelectors = []string{
+	`meta[property="article:section"]`,
+	`a[rel~="category"]`,
+}
+
+// slugLetters folds the accented letters common in Swedish and other
+// European category names, so "Malmö FF" matches the slug "malmo-ff"
+var slugLetters = strings.NewReplacer(
+	"å", "a", "ä", "a", "á", "a", "à", "a", "â", "a",
+	"ö", "o", "ø", "o", "ó", "o", "ò", "o", "ô", "o",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"ü", "u", "ú", "u", "í", "i", "ñ", "n", "ç", "c", "æ", "ae", "ß", "ss",
+)
+
+// extractCategories returns the categories an article page is filed under,
+// from the source's category selector or else the default selectors
+func (s *Scraper) extractCategories(page *rod.Page, source *SourceConfig) []string {
+	selectors := defaultCategorySelectors
+	if source.Content.Category != "" {
+		selectors = strings.Split(source.Content.Category, ",")
+	}
+
+	for _, selector := range selectors {
+		elements, err := page.Elements(strings.TrimSpace(selector))
+		if err != nil || len(elements) == 0 {
+			continue
+		}
+		var categories []string
+		for _, el := range elements {
+			// Meta tags carry the category in their content attribute
+			if content, err := el.Attribute("content"); err == nil && content != nil {
+				categories = appendCategory(categories, *content)
+			} else if text, err := el.Text(); err == nil {
+				categories = appendCategory(categories, text)
+			}
+		}
+		if len(categories) > 0 {
+			return categories
+		}
+	}
+	return nil
+}
+
+// appendCategory adds a category unless it is empty or already listed
+func appendCategory(categories []string, category string) []string {
+	category = strings.Join(strings.Fields(category), " ")
+	if category == "" {
+		return categories
+	}
+	for _, c := range categories {
+		if categorySlug(c) == categorySlug(category) {
+			return categories
+		}
+	}
+	return append(categories, category)
+}
+
+// categoryTags maps an article's categories to tags with the source's
+// category_tags rules
+func (c *SourceConfig) categoryTags(categories []string) []string {
+	var tags []string
+	for _, category := range categories {
+		tag, ok := c.CategoryTags[categorySlug(category)]
+		if !ok {
+			tag = category
+		}
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// categorySlug returns the WordPress-style slug of a category name, e.g.
+// "malmo-ff" for "Malmö FF". Slugs are unchanged.
+func categorySlug(name string) string {
+	name = slugLetters.Replace(strings.ToLower(strings.TrimSpace(name)))
+	var b strings.Builder
+	dash := false
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
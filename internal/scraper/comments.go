@@ -0,0 +1,111 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+// commentSelectors find the individual comments of a thread, tried in order.
+// Gasetten runs WordPress, whose themes mark comments up like this.
+var commentSelectors = []string{
+	`.comment-list .comment-body`,
+	`#comments .comment-body`,
+	`#comments li.comment`,
+}
+
+// RefreshComments scrapes the current comment thread of an article and
+// replaces the stored one
+func (s *Scraper) RefreshComments(ctx context.Context, article *database.Article) ([]*database.Comment, error) {
+	if err := s.initBrowser(); err != nil {
+		return nil, err
+	}
+	if err := s.Login(ctx); err != nil {
+		return nil, fmt.Errorf("failed to login: %w", err)
+	}
+
+	var comments []*database.Comment
+	if _, source := s.sourceFor(article.URL); source.Mode == ModeWordPress {
+		var err error
+		comments, err = s.wordPressComments(ctx, &source, article.URL)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		page, err := s.createPageWithRetry(article.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create article page: %w", err)
+		}
+		defer page.Close()
+
+		page = page.Timeout(PageTimeout)
+		if err := page.WaitLoad(); err != nil {
+			return nil, fmt.Errorf("timeout waiting for article page to load: %w", err)
+		}
+		comments = s.extractComments(page)
+	}
+
+	if err := s.db.ReplaceComments(ctx, article.ID, comments); err != nil {
+		return nil, err
+	}
+	logging.Infof("Refreshed %d comments for %s", len(comments), article.URL)
+	return comments, nil
+}
+
+// extractComments reads the comment thread of a loaded article page. Replies
+// are flattened into page order.
+func (s *Scraper) extractComments(page *rod.Page) []*database.Comment {
+	var elements rod.Elements
+	for _, selector := range commentSelectors {
+		found, err := page.Elements(selector)
+		if err == nil && len(found) > 0 {
+			elements = found
+			break
+		}
+	}
+
+	comments := make([]*database.Comment, 0, len(elements))
+	for _, el := range elements {
+		body := firstText(el, `.comment-content`, `.comment-text`)
+		if body == "" {
+			continue
+		}
+
+		comment := &database.Comment{Body: body}
+		if author := firstText(el, `.comment-author .fn`, `.comment-author`); author != "" {
+			comment.Author = &author
+		}
+		if times, err := el.Elements(`time[datetime]`); err == nil && len(times) > 0 {
+			if datetime, err := times.First().Attribute("datetime"); err == nil && datetime != nil {
+				if t, err := time.Parse(time.RFC3339, *datetime); err == nil {
+					comment.PostedAt = &t
+				}
+			}
+		}
+		comments = append(comments, comment)
+	}
+	return comments
+}
+
+// firstText returns the text of the first element below el matching one of
+// the selectors. Unlike Element, Elements does not wait for a match.
+func firstText(el *rod.Element, selectors ...string) string {
+	for _, selector := range selectors {
+		if selector == "" {
+			continue
+		}
+		found, err := el.Elements(selector)
+		if err != nil || len(found) == 0 {
+			continue
+		}
+		if text, err := found.First().Text(); err == nil && strings.TrimSpace(text) != "" {
+			return strings.TrimSpace(text)
+		}
+	}
+	return ""
+}
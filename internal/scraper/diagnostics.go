@@ -0,0 +1,149 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/internal/storage"
+)
+
+const (
+	// maxDiagnostics is how many captures are kept; older ones are pruned
+	maxDiagnostics = 100
+
+	// diagnosticsTimeout bounds taking a single capture
+	diagnosticsTimeout = 15 * time.Second
+)
+
+// Every capture is stored as <name>.png (full-page screenshot) and
+// <name>.html (the page's HTML)
+var diagnosticsFile = regexp.MustCompile(`^([0-9]{8}-[0-9]{6}-[a-z]+-[0-9a-f]{8})\.(png|html)$`)
+
+// diagnosedError is a failure with a capture of the page it happened on
+type diagnosedError struct {
+	err     error
+	capture string
+}
+
+func (e *diagnosedError) Error() string { return e.err.Error() }
+func (e *diagnosedError) Unwrap() error { return e.err }
+
+// diagnosticsCapture returns the name of the capture attached to err, if any
+func diagnosticsCapture(err error) *string {
+	var diagnosed *diagnosedError
+	if errors.As(err, &diagnosed) {
+		return &diagnosed.capture
+	}
+	return nil
+}
+
+// withDiagnostics saves a screenshot and the HTML of page and attaches the
+// capture to err. Capturing is best effort: err is returned unchanged when
+// diagnostics are disabled or the page cannot be captured. Dry runs capture
+// nothing, and so prune nothing either.
+func (s *Scraper) withDiagnostics(ctx context.Context, page *rod.Page, kind string, err error) error {
+	if s.diagnostics == nil || isDryRun(ctx) {
+		return err
+	}
+
+	name, captureErr := s.captureDiagnostics(ctx, page, kind)
+	if captureErr != nil {
+		logging.Warnf("Failed to capture %s diagnostics: %v", kind, captureErr)
+		return err
+	}
+	logging.Infof("Saved %s diagnostics as %s", kind, name)
+	return &diagnosedError{err: err, capture: name}
+}
+
+func (s *Scraper) captureDiagnostics(ctx context.Context, page *rod.Page, kind string) (string, error) {
+	// The page's own timeout has often expired by the time it failed, and
+	// the run may have been cancelled
+	ctx = context.WithoutCancel(ctx)
+	page = page.Context(ctx).Timeout(diagnosticsTimeout)
+	defer page.CancelTimeout()
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	name := time.Now().UTC().Format("20060102-150405") + "-" + kind + "-" + hex.EncodeToString(suffix)
+
+	ctx, cancel := context.WithTimeout(ctx, diagnosticsTimeout)
+	defer cancel()
+
+	html, err := page.HTML()
+	if err != nil {
+		return "", fmt.Errorf("failed to get page HTML: %w", err)
+	}
+	if err := s.saveDiagnostics(ctx, name+".html", []byte(html)); err != nil {
+		return "", fmt.Errorf("failed to save page HTML: %w", err)
+	}
+
+	screenshot, err := page.Screenshot(true, &proto.PageCaptureScreenshot{Format: proto.PageCaptureScreenshotFormatPng})
+	if err != nil {
+		// The HTML alone is still useful
+		logging.Warnf("Failed to take %s screenshot: %v", kind, err)
+	} else if err := s.saveDiagnostics(ctx, name+".png", screenshot); err != nil {
+		return "", fmt.Errorf("failed to save screenshot: %w", err)
+	}
+
+	s.pruneDiagnostics(ctx)
+	return name, nil
+}
+
+func (s *Scraper) saveDiagnostics(ctx context.Context, file string, data []byte) error {
+	return s.diagnostics.Put(ctx, file, bytes.NewReader(data), int64(len(data)))
+}
+
+// pruneDiagnostics removes all but the newest maxDiagnostics captures
+func (s *Scraper) pruneDiagnostics(ctx context.Context) {
+	objects, err := s.diagnostics.List(ctx, "")
+	if err != nil {
+		logging.Warnf("Failed to list diagnostics: %v", err)
+		return
+	}
+
+	// Names start with the capture time, so they sort chronologically
+	var captures []string
+	seen := make(map[string]bool)
+	for _, obj := range objects {
+		m := diagnosticsFile.FindStringSubmatch(obj.Name)
+		if m != nil && !seen[m[1]] {
+			seen[m[1]] = true
+			captures = append(captures, m[1])
+		}
+	}
+	if len(captures) <= maxDiagnostics {
+		return
+	}
+	sort.Strings(captures)
+
+	for _, name := range captures[:len(captures)-maxDiagnostics] {
+		for _, ext := range []string{".png", ".html"} {
+			if err := s.diagnostics.Delete(ctx, name+ext); err != nil {
+				logging.Warnf("Failed to remove diagnostics %s: %v", name+ext, err)
+			}
+		}
+	}
+}
+
+// OpenDiagnostics opens a file of a diagnostics capture, e.g. "<name>.png".
+// Names that are not captures return storage.ErrNotFound.
+func (s *Scraper) OpenDiagnostics(ctx context.Context, file string) (io.ReadCloser, error) {
+	if s.diagnostics == nil || !diagnosticsFile.MatchString(file) {
+		return nil, storage.ErrNotFound
+	}
+	return s.diagnostics.Get(ctx, file)
+}
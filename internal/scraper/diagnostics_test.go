@@ -0,0 +1,51 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tkilaker/kiln/internal/storage"
+)
+
+func TestWithDiagnosticsDryRun(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+
+	// More captures than are kept, so a prune would remove some
+	for i := 0; i <= maxDiagnostics; i++ {
+		name := fmt.Sprintf("20250101-%06d-article-0000000%d.html", i, i%10)
+		if err := store.Put(ctx, name, strings.NewReader("<html></html>"), 13); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+	before, err := store.List(ctx, "")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	s := &Scraper{diagnostics: store}
+	extractErr := errors.New("no readable content")
+
+	// The page is never touched: a dry run captures nothing
+	got := s.withDiagnostics(withDryRun(ctx), nil, "article", extractErr)
+	if got != extractErr {
+		t.Errorf("withDiagnostics() = %v, want the original error", got)
+	}
+	if capture := diagnosticsCapture(got); capture != nil {
+		t.Errorf("withDiagnostics() attached capture %q", *capture)
+	}
+
+	after, err := store.List(ctx, "")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(after) != len(before) {
+		t.Errorf("dry run left %d diagnostics files, want the %d there before", len(after), len(before))
+	}
+}
@@ -0,0 +1,92 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+)
+
+// What a dry run would do with an article
+const (
+	PreviewAdd    = "add"    // a new article would be saved
+	PreviewUpdate = "update" // a saved partial article would be replaced
+	PreviewSkip   = "skip"   // the article would be left alone
+	PreviewFail   = "fail"   // the article could not be scraped
+)
+
+// PreviewItem is the outcome of one article in a dry run
+type PreviewItem struct {
+	URL    string `json:"url"`
+	Title  string `json:"title,omitempty"`
+	Action string `json:"action"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type dryRunKey struct{}
+
+// withDryRun returns a context for a run that discovers and scrapes
+// articles but writes nothing: no articles, runs, retries, media or
+// archived pages are stored
+func withDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// isDryRun reports whether ctx belongs to a dry run
+func isDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// Preview returns what a dry run job found so far, in the order the
+// articles were processed
+func (j *Job) Preview() []PreviewItem {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]PreviewItem{}, j.preview...)
+}
+
+// DryRun performs the work req describes as a dry run and returns what it
+// would have done, e.g. to tune link filters and selectors
+func (s *Scraper) DryRun(ctx context.Context, label string, req JobRequest) ([]PreviewItem, error) {
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+	job := s.registerJob(id, label)
+	req.DryRun = true
+	_, err = s.runJob(ctx, job, func(ctx context.Context) (int, error) {
+		return s.runRequest(ctx, req)
+	})
+	return job.Preview(), err
+}
+
+// previewArticle records what a dry run would do with an article and
+// reports it to progress listeners
+func previewArticle(ctx context.Context, item PreviewItem) {
+	job := jobOf(ctx)
+	if job == nil {
+		return
+	}
+	job.mu.Lock()
+	job.preview = append(job.preview, item)
+	job.mu.Unlock()
+
+	update := job.progress.GetCurrent()
+	update.Preview = &item
+	job.progress.Update(update)
+}
+
+// previewScraped records a scraped article a dry run would save
+func previewScraped(ctx context.Context, p *scrapedArticle) {
+	item := PreviewItem{URL: p.link, Action: PreviewAdd}
+	if p.article.Title != nil {
+		item.Title = *p.article.Title
+	}
+	if p.existing != nil {
+		item.Action = PreviewUpdate
+		item.Reason = "replaces a partial copy"
+	}
+	if p.reason != "" {
+		item.Reason = fmt.Sprintf("partial: %s", p.reason)
+	}
+	previewArticle(ctx, item)
+}
@@ -0,0 +1,114 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/pkg/extract"
+)
+
+// maxFeedSize bounds a downloaded RSS or Atom feed
+const maxFeedSize = 10 << 20
+
+// feedSourceConfig is the configuration of a feed source without a site
+// profile: its articles are on the site of its first start URL and are
+// extracted with readability
+func feedSourceConfig(row *database.Source) (SourceConfig, bool) {
+	if len(row.StartURLs) == 0 {
+		return SourceConfig{}, false
+	}
+	site, err := url.Parse(row.StartURLs[0])
+	if err != nil || site.Host == "" {
+		return SourceConfig{}, false
+	}
+	return SourceConfig{Mode: ModeBrowser, BaseURL: site.Scheme + "://" + site.Host}, true
+}
+
+// refreshFeedSources reloads the configuration of the feed sources without
+// a site profile, which sourceFor falls back to
+func (s *Scraper) refreshFeedSources(ctx context.Context) {
+	rows, err := s.db.GetSources(ctx)
+	if err != nil {
+		logging.Warnf("Failed to load feed sources: %v", err)
+		return
+	}
+
+	feeds := make(map[string]SourceConfig)
+	for _, row := range rows {
+		if _, profiled := s.sources[row.Name]; profiled || row.FeedURL == nil {
+			continue
+		}
+		if source, ok := feedSourceConfig(row); ok {
+			feeds[row.Name] = source
+		}
+	}
+
+	s.feedMu.Lock()
+	s.feedSources = feeds
+	s.feedMu.Unlock()
+}
+
+// sourceConfig returns the configuration of the source named name: its
+// site profile, else its feed source configuration
+func (s *Scraper) sourceConfig(name string) (SourceConfig, bool) {
+	if source, ok := s.sources[name]; ok {
+		return source, true
+	}
+	s.feedMu.RLock()
+	defer s.feedMu.RUnlock()
+	source, ok := s.feedSources[name]
+	return source, ok
+}
+
+// collectFeedLinks returns the links of a feed's items that are on the
+// source's site
+func (s *Scraper) collectFeedLinks(ctx context.Context, feedURL string, source *SourceConfig) ([]string, error) {
+	progressOf(ctx).UpdateStatus(StatusScraping, "Reading feed...")
+
+	base, err := url.Parse(source.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base_url: %w", err)
+	}
+	client := &http.Client{Timeout: PageTimeout, Jar: s.browserCookies(base)}
+
+	items, err := fetchFeed(ctx, client, feedURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []string
+	for _, raw := range items {
+		link := source.articleLink(raw)
+		if link == "" {
+			logging.Debugf("Skipping feed item %s: not an article on %s", raw, source.BaseURL)
+			continue
+		}
+		links = appendNew(links, []string{link})
+	}
+
+	logging.Infof("Found %d article links in feed %s", len(links), feedURL)
+	return links, nil
+}
+
+// fetchFeed downloads an RSS or Atom feed and returns its item links
+func fetchFeed(ctx context.Context, client *http.Client, feedURL string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return extract.FeedLinks(io.LimitReader(resp.Body, maxFeedSize))
+}
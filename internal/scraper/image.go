@@ -0,0 +1,72 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+// mirrorImages copies the article's images into Kiln's media store and
+// points the content and lead image at the copies. Failures are logged and
+// leave the original URLs in place. Dry runs leave them in place too.
+func (s *Scraper) mirrorImages(ctx context.Context, article *database.Article, base *url.URL) {
+	if isDryRun(ctx) {
+		return
+	}
+	jar := s.browserCookies(base)
+
+	mirrored := map[string]string{}
+	if article.ContentHTML != nil {
+		content, localized, err := s.media.Localize(ctx, *article.ContentHTML, base, jar)
+		if err != nil {
+			logging.Warnf("Failed to mirror images for %s: %v", article.URL, err)
+		} else {
+			article.ContentHTML = &content
+			mirrored = localized
+		}
+	}
+
+	if article.ImageURL != nil {
+		local, ok := mirrored[*article.ImageURL]
+		if !ok {
+			var err error
+			local, err = s.media.Fetch(ctx, *article.ImageURL, jar)
+			if err != nil {
+				logging.Warnf("Failed to mirror lead image %s: %v", *article.ImageURL, err)
+				return
+			}
+		}
+		article.ImageURL = &local
+	}
+}
+
+// browserCookies returns a cookie jar holding the browser's cookies for
+// base, so images behind the login can be downloaded
+func (s *Scraper) browserCookies(base *url.URL) http.CookieJar {
+	jar, _ := cookiejar.New(nil)
+	if s.browser == nil {
+		return jar
+	}
+
+	cookies, err := s.browser.GetCookies()
+	if err != nil {
+		logging.Warnf("Failed to read browser cookies: %v", err)
+		return jar
+	}
+	httpCookies := make([]*http.Cookie, 0, len(cookies))
+	for _, c := range cookies {
+		httpCookies = append(httpCookies, &http.Cookie{
+			Name:   c.Name,
+			Value:  c.Value,
+			Domain: c.Domain,
+			Path:   c.Path,
+			Secure: c.Secure,
+		})
+	}
+	jar.SetCookies(base, httpCookies)
+	return jar
+}
@@ -0,0 +1,504 @@
+package scraper
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+const (
+	// jobRetention is how long a finished job stays listed, so a progress
+	// stream opened late still gets its outcome
+	jobRetention = 15 * time.Minute
+
+	// jobWorkers is how many queued jobs run at once
+	jobWorkers = 2
+
+	// jobPollInterval is how often idle workers look for queued jobs
+	// enqueued elsewhere (e.g. before a restart)
+	jobPollInterval = 10 * time.Second
+
+	// maxJobAttempts bounds how often a job interrupted by a crash or
+	// restart is started again
+	maxJobAttempts = 3
+
+	// jobHistory is how long finished jobs are kept in the database
+	jobHistory = 30 * 24 * time.Hour
+)
+
+// Kinds of queued jobs
+const (
+	JobScrape   = "scrape"   // discover and scrape every source
+	JobBackfill = "backfill" // scrape a source's whole archive
+	JobRetry    = "retry"    // attempt the retry queue
+	JobURLs     = "urls"     // scrape the given article URLs
+)
+
+// JobRequest describes the work of a queued job. It is stored as the job's
+// payload.
+type JobRequest struct {
+	Kind    string   `json:"kind"`
+	Trigger string   `json:"trigger"` // run trigger, e.g. database.RunTriggerManual
+	Source  string   `json:"source,omitempty"`
+	URLs    []string `json:"urls,omitempty"`
+	DryRun  bool     `json:"dry_run,omitempty"` // report what would be saved, without saving
+}
+
+// Job is a scrape running in the background with its own progress. Several
+// jobs can run at once (e.g. a backfill and a scheduled run); an article
+// being scraped by one job is skipped by the others. Jobs requested from the
+// UI are queued in the database first (see Enqueue).
+type Job struct {
+	ID        string
+	Label     string
+	StartedAt time.Time
+
+	progress *ProgressTracker
+
+	mu         sync.Mutex
+	cancel     context.CancelFunc // nil until the job starts
+	finishedAt time.Time
+	paused     bool
+	resumed    chan struct{} // closed when a paused job is resumed
+	preview    []PreviewItem // what a dry run found
+}
+
+// Errors returned by Cancel, Pause and Resume
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobFinished = errors.New("job has already finished")
+)
+
+// Progress returns the job's progress tracker
+func (j *Job) Progress() *ProgressTracker {
+	return j.progress
+}
+
+// Running reports whether the job has not finished yet
+func (j *Job) Running() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.finishedAt.IsZero()
+}
+
+// Paused reports whether the job is paused
+func (j *Job) Paused() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.paused
+}
+
+// setPaused pauses or resumes the job, reporting false when it already was
+func (j *Job) setPaused(paused bool) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.paused == paused {
+		return false
+	}
+	j.paused = paused
+	if paused {
+		j.resumed = make(chan struct{})
+	} else {
+		close(j.resumed)
+	}
+	return true
+}
+
+// waitWhilePaused blocks while the job is paused, returning early when ctx
+// is cancelled
+func (j *Job) waitWhilePaused(ctx context.Context) error {
+	j.mu.Lock()
+	paused, resumed := j.paused, j.resumed
+	j.mu.Unlock()
+	if !paused {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-resumed:
+		return nil
+	}
+}
+
+type jobKey struct{}
+
+// withJob returns a context carrying job, whose tracker receives the
+// progress of the work done with it
+func withJob(ctx context.Context, job *Job) context.Context {
+	return context.WithValue(ctx, jobKey{}, job)
+}
+
+// jobOf returns the job ctx belongs to, or nil outside of jobs
+func jobOf(ctx context.Context) *Job {
+	job, _ := ctx.Value(jobKey{}).(*Job)
+	return job
+}
+
+// progressOf returns the progress tracker of the job ctx belongs to, or a
+// tracker nobody listens to outside of jobs
+func progressOf(ctx context.Context) *ProgressTracker {
+	if job := jobOf(ctx); job != nil {
+		return job.progress
+	}
+	return NewProgressTracker()
+}
+
+// Enqueue queues a job and returns it at once; a worker started by RunJobs
+// picks it up. The same label is only queued or running once.
+func (s *Scraper) Enqueue(ctx context.Context, label string, req JobRequest) (*Job, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job: %w", err)
+	}
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	queued := &database.Job{ID: id, Kind: req.Kind, Label: label, Payload: payload}
+	if err := s.db.EnqueueJob(ctx, queued); err != nil {
+		if errors.Is(err, database.ErrJobActive) {
+			return nil, fmt.Errorf("a %s is %w", label, err)
+		}
+		return nil, err
+	}
+
+	// Register the job now, so its progress can be followed while it waits
+	job := s.registerJob(id, label)
+	job.progress.UpdateStatus(StatusQueued, "Waiting for a worker...")
+
+	select {
+	case s.jobWake <- struct{}{}:
+	default:
+	}
+	logging.Infof("Queued job %s: %s", id, label)
+	return job, nil
+}
+
+// RunJobs processes queued jobs until ctx is cancelled. Jobs left running
+// by a previous process are queued again first.
+func (s *Scraper) RunJobs(ctx context.Context) {
+	requeued, failed, err := s.db.RecoverJobs(ctx, maxJobAttempts)
+	if err != nil {
+		logging.Warnf("Failed to recover interrupted jobs: %v", err)
+	} else if requeued > 0 || failed > 0 {
+		logging.Infof("Recovered interrupted jobs: %d queued again, %d failed", requeued, failed)
+	}
+	if _, err := s.db.DeleteFinishedJobs(ctx, time.Now().Add(-jobHistory)); err != nil {
+		logging.Warnf("Failed to delete old jobs: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for range jobWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.jobWorker(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// jobWorker claims and runs queued jobs one at a time
+func (s *Scraper) jobWorker(ctx context.Context) {
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		queued, err := s.db.ClaimJob(ctx)
+		if err != nil {
+			logging.Warnf("Failed to claim job: %v", err)
+		}
+		if queued != nil {
+			s.runQueued(ctx, queued)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.jobWake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// runQueued runs a claimed job and records its outcome. A job interrupted
+// by a shutdown goes back to the queue.
+func (s *Scraper) runQueued(ctx context.Context, queued *database.Job) {
+	job := s.registerJob(queued.ID, queued.Label)
+	logging.Infof("Starting job %s: %s (attempt %d)", job.ID, job.Label, queued.Attempts)
+
+	// A job paused before a restart stays paused; it starts over when
+	// resumed, skipping the articles it saved already
+	if queued.Paused {
+		job.setPaused(true)
+	}
+
+	count, err := s.runJob(ctx, job, func(ctx context.Context) (int, error) {
+		var req JobRequest
+		if err := json.Unmarshal(queued.Payload, &req); err != nil {
+			return 0, fmt.Errorf("invalid job payload: %w", err)
+		}
+		return s.runRequest(ctx, req)
+	})
+
+	// The job's context may be cancelled; still record the outcome
+	recordCtx := context.WithoutCancel(ctx)
+	if ctx.Err() != nil {
+		logging.Infof("Job %s (%s) interrupted, queuing it again", job.ID, job.Label)
+		if err := s.db.RequeueJob(recordCtx, job.ID); err != nil {
+			logging.Warnf("Failed to requeue job %s: %v", job.ID, err)
+		}
+		return
+	}
+	if err != nil {
+		logging.Warnf("Job %s (%s) failed: %v", job.ID, job.Label, err)
+	} else {
+		logging.Infof("Job %s (%s) completed: %d new articles", job.ID, job.Label, count)
+	}
+	if err := s.db.FinishJob(recordCtx, job.ID, err); err != nil {
+		logging.Warnf("Failed to record job %s: %v", job.ID, err)
+	}
+}
+
+// runRequest does the work a job request describes
+func (s *Scraper) runRequest(ctx context.Context, req JobRequest) (int, error) {
+	if req.DryRun {
+		ctx = withDryRun(ctx)
+	}
+	switch req.Kind {
+	case JobScrape:
+		if req.Source != "" {
+			return s.ScrapeSource(ctx, req.Trigger, req.Source)
+		}
+		return s.ScrapeArticles(ctx, req.Trigger)
+	case JobBackfill:
+		return s.Backfill(ctx, req.Trigger, req.Source)
+	case JobRetry:
+		return s.RetryFailed(ctx, req.Trigger)
+	case JobURLs:
+		return s.ScrapeURLs(ctx, req.Trigger, req.URLs)
+	default:
+		return 0, fmt.Errorf("unknown job kind %q", req.Kind)
+	}
+}
+
+// RunJob runs scrape as a new job, without queuing it, and waits for it to
+// finish
+func (s *Scraper) RunJob(ctx context.Context, label string, scrape func(ctx context.Context) (int, error)) (int, error) {
+	id, err := newJobID()
+	if err != nil {
+		return 0, err
+	}
+	return s.runJob(ctx, s.registerJob(id, label), scrape)
+}
+
+func (s *Scraper) runJob(ctx context.Context, job *Job, scrape func(ctx context.Context) (int, error)) (int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	job.mu.Lock()
+	job.cancel = cancel
+	job.mu.Unlock()
+
+	defer s.endJob(job)
+	count, err := scrape(withJob(ctx, job))
+
+	// Tell listeners when the job ended without saying so itself, e.g.
+	// when it was cancelled while discovering articles
+	switch job.progress.GetCurrent().Status {
+	case StatusCompleted, StatusFailed, StatusCancelled:
+	default:
+		if errors.Is(err, context.Canceled) {
+			job.progress.UpdateStatus(StatusCancelled, "Operation cancelled.")
+		} else if err != nil {
+			job.progress.UpdateStatus(StatusFailed, fmt.Sprintf("Failed: %v", err))
+		}
+	}
+	return count, err
+}
+
+// endJob marks a job as finished
+func (s *Scraper) endJob(job *Job) {
+	job.mu.Lock()
+	job.finishedAt = time.Now()
+	job.mu.Unlock()
+	job.progress.SetActive(false)
+}
+
+// Cancel stops a running job, or takes a queued one off the queue. A
+// running job stops after the article it is scraping.
+func (s *Scraper) Cancel(ctx context.Context, id string) error {
+	job := s.Job(id)
+	if job != nil && job.cancelRunning() {
+		logging.Infof("Cancelling job %s (%s)", id, job.Label)
+		return nil
+	}
+
+	cancelled, err := s.db.CancelPendingJob(ctx, id)
+	if err != nil {
+		return err
+	}
+	switch {
+	case cancelled && job != nil:
+		job.progress.UpdateStatus(StatusCancelled, "Cancelled before it started.")
+		s.endJob(job)
+	case cancelled:
+	case job == nil:
+		return ErrJobNotFound
+	case job.cancelRunning():
+		// A worker started the job in the meantime
+	default:
+		return ErrJobFinished
+	}
+	logging.Infof("Cancelled job %s", id)
+	return nil
+}
+
+// Pause makes a running job idle once it has finished the article it is
+// scraping, until it is resumed. The pause is recorded with the job, so a
+// job paused before a restart stays paused.
+func (s *Scraper) Pause(ctx context.Context, id string) error {
+	return s.setPaused(ctx, id, true)
+}
+
+// Resume continues a paused job
+func (s *Scraper) Resume(ctx context.Context, id string) error {
+	return s.setPaused(ctx, id, false)
+}
+
+func (s *Scraper) setPaused(ctx context.Context, id string, paused bool) error {
+	job := s.Job(id)
+	if job == nil {
+		return ErrJobNotFound
+	}
+	if !job.Running() {
+		return ErrJobFinished
+	}
+	if !job.setPaused(paused) {
+		return nil
+	}
+
+	if err := s.db.SetJobPaused(ctx, id, paused); err != nil {
+		logging.Warnf("Failed to record pause of job %s: %v", id, err)
+	}
+	if paused {
+		logging.Infof("Pausing job %s (%s)", id, job.Label)
+	} else {
+		logging.Infof("Resuming job %s (%s)", id, job.Label)
+	}
+	return nil
+}
+
+// cancelRunning cancels the job's context, reporting false when the job
+// has not started or has finished
+func (j *Job) cancelRunning() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.cancel == nil || !j.finishedAt.IsZero() {
+		return false
+	}
+	j.cancel()
+	return true
+}
+
+// registerJob returns the job with the given ID, adding it when it is not
+// known yet, and drops jobs that finished long enough ago
+func (s *Scraper) registerJob(id, label string) *Job {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+
+	if job, ok := s.jobs[id]; ok {
+		return job
+	}
+	for jobID, job := range s.jobs {
+		job.mu.Lock()
+		finishedAt := job.finishedAt
+		job.mu.Unlock()
+
+		if !finishedAt.IsZero() && time.Since(finishedAt) > jobRetention {
+			delete(s.jobs, jobID)
+		}
+	}
+
+	job := &Job{ID: id, Label: label, StartedAt: time.Now(), progress: NewProgressTracker()}
+	job.progress.SetActive(true)
+	s.jobs[id] = job
+	return job
+}
+
+// Job returns the job with the given ID, or nil when there is none
+func (s *Scraper) Job(id string) *Job {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	return s.jobs[id]
+}
+
+// Jobs returns the running and recently finished jobs, oldest first
+func (s *Scraper) Jobs() []*Job {
+	s.jobsMu.Lock()
+	jobs := make([]*Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	s.jobsMu.Unlock()
+
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].StartedAt.Before(jobs[j].StartedAt) })
+	return jobs
+}
+
+// Busy reports whether any job is running and not paused
+func (s *Scraper) Busy() bool {
+	for _, job := range s.Jobs() {
+		if job.Running() && !job.Paused() {
+			return true
+		}
+	}
+	return false
+}
+
+// claimURL marks an article URL as being scraped, reporting false when
+// another job is scraping it already. Dry runs don't claim articles, so
+// they never hold up a job that saves them.
+func (s *Scraper) claimURL(ctx context.Context, link string) bool {
+	if isDryRun(ctx) {
+		return true
+	}
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	if s.claimed[link] {
+		return false
+	}
+	s.claimed[link] = true
+	return true
+}
+
+// releaseURL ends a claim on an article URL
+func (s *Scraper) releaseURL(ctx context.Context, link string) {
+	if isDryRun(ctx) {
+		return
+	}
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	delete(s.claimed, link)
+}
+
+func newJobID() (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,37 @@
+package scraper
+
+import (
+	"context"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+// KeepAlive loads an authenticated Gasetten page every interval so the
+// WordPress session stays warm, logging in again when it has expired. This
+// keeps the next scrape from spending its first minute on a full login. It
+// runs until ctx is cancelled.
+func (s *Scraper) KeepAlive(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// A running scrape keeps the session warm by itself
+		if s.Busy() {
+			continue
+		}
+
+		started := time.Now()
+		if err := s.Login(ctx); err != nil {
+			logging.Warnf("Session keepalive failed: %v", err)
+			continue
+		}
+		logging.Infof("Session keepalive completed in %s", time.Since(started).Round(time.Millisecond))
+	}
+}
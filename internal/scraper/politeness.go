@@ -0,0 +1,101 @@
+package scraper
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+const (
+	// MinRequestDelay is the delay between article requests when the site is healthy
+	MinRequestDelay = 1 * time.Second
+	// MaxRequestDelay caps the delay when the site is slow or erroring
+	MaxRequestDelay = 30 * time.Second
+
+	// slowFactor marks a response as slow when it takes this many times the baseline
+	slowFactor = 2.0
+	// latencySmoothing is the EWMA weight given to each new response time
+	latencySmoothing = 0.2
+)
+
+// politeness adapts the delay between requests to how the source site is
+// coping: errors and slow responses back off, healthy responses recover
+// gradually towards MinRequestDelay.
+type politeness struct {
+	mu       sync.Mutex
+	delay    time.Duration
+	baseline time.Duration // smoothed response time while the site is healthy
+	latency  time.Duration // smoothed response time over all requests
+	requests int
+	errors   int
+}
+
+// newPoliteness creates a politeness tracker starting at MinRequestDelay
+func newPoliteness() *politeness {
+	return &politeness{delay: MinRequestDelay}
+}
+
+// Observe records the outcome of a request and adjusts the delay
+func (p *politeness) Observe(elapsed time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.requests++
+	p.latency = smooth(p.latency, elapsed)
+
+	previous := p.delay
+	switch {
+	case err != nil:
+		p.errors++
+		p.delay *= 2
+	case p.baseline > 0 && float64(elapsed) > slowFactor*float64(p.baseline):
+		p.delay = p.delay * 3 / 2
+	default:
+		p.baseline = smooth(p.baseline, elapsed)
+		// Recover by 10% per healthy response
+		p.delay = p.delay * 9 / 10
+	}
+
+	if p.delay < MinRequestDelay {
+		p.delay = MinRequestDelay
+	}
+	if p.delay > MaxRequestDelay {
+		p.delay = MaxRequestDelay
+	}
+
+	if p.delay != previous && (p.delay > previous || p.delay == MinRequestDelay) {
+		logging.Infof("Adjusted request delay from %v to %v (response %v, baseline %v, errors %d/%d)",
+			previous.Round(time.Millisecond), p.delay.Round(time.Millisecond),
+			elapsed.Round(time.Millisecond), p.baseline.Round(time.Millisecond), p.errors, p.requests)
+	}
+}
+
+// Delay returns the current delay between requests
+func (p *politeness) Delay() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.delay
+}
+
+// Wait sleeps for the current delay, returning early if ctx is cancelled
+func (p *politeness) Wait(ctx context.Context) error {
+	timer := time.NewTimer(p.Delay())
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// smooth folds a new sample into an exponentially weighted moving average
+func smooth(avg, sample time.Duration) time.Duration {
+	if avg == 0 {
+		return sample
+	}
+	return time.Duration((1-latencySmoothing)*float64(avg) + latencySmoothing*float64(sample))
+}
@@ -1,6 +1,7 @@
 package scraper
 
 import (
+	"fmt"
 	"sync"
 	"time"
 )
@@ -9,29 +10,48 @@ import (
 type ProgressStatus string
 
 const (
+	StatusQueued    ProgressStatus = "queued"
 	StatusStarting  ProgressStatus = "starting"
 	StatusLoggingIn ProgressStatus = "logging_in"
 	StatusScraping  ProgressStatus = "scraping"
+	StatusPaused    ProgressStatus = "paused"
 	StatusCompleted ProgressStatus = "completed"
 	StatusFailed    ProgressStatus = "failed"
 	StatusCancelled ProgressStatus = "cancelled"
 )
 
+// Finished reports whether the status ends the operation
+func (s ProgressStatus) Finished() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCancelled
+}
+
+// progressHistory is how many updates a tracker keeps for subscribers that
+// reconnect after missing some
+const progressHistory = 200
+
 // ProgressUpdate represents a single progress update
 type ProgressUpdate struct {
-	Status        ProgressStatus
-	Message       string
-	CurrentItem   int
-	TotalItems    int
-	ArticlesAdded int
-	NewArticleID  int // ID of newly added article (0 if none)
-	Timestamp     time.Time
+	Status         ProgressStatus
+	Message        string
+	CurrentItem    int
+	TotalItems     int
+	ArticlesAdded  int
+	ArticlesFailed int
+	NewArticleID   int          // ID of newly added article (0 if none)
+	FailedURL      string       // URL of an article that just failed ("" if none)
+	FailedStage    string       // stage FailedURL failed at
+	Error          string       // why FailedURL failed
+	RunID          int          // ID of the scrape run being tracked (0 if not recorded)
+	Preview        *PreviewItem // what a dry run would do with an article (nil if none)
+	Seq            int          // position of the update in the tracker's sequence
+	Timestamp      time.Time
 }
 
 // ProgressTracker tracks the progress of a scraping operation
 type ProgressTracker struct {
 	mu        sync.RWMutex
 	current   ProgressUpdate
+	history   []ProgressUpdate // the latest updates, as sent
 	listeners []chan ProgressUpdate
 	active    bool
 }
@@ -52,7 +72,18 @@ func NewProgressTracker() *ProgressTracker {
 func (pt *ProgressTracker) Update(update ProgressUpdate) {
 	pt.mu.Lock()
 	update.Timestamp = time.Now()
+	update.Seq = pt.current.Seq + 1
+
+	pt.history = append(pt.history, update)
+	if len(pt.history) > progressHistory {
+		pt.history = pt.history[len(pt.history)-progressHistory:]
+	}
+
+	// A new or failed article is reported once, not with every later update
 	pt.current = update
+	pt.current.NewArticleID = 0
+	pt.current.FailedURL, pt.current.FailedStage, pt.current.Error = "", "", ""
+	pt.current.Preview = nil
 
 	// Send to all listeners
 	for _, listener := range pt.listeners {
@@ -98,6 +129,20 @@ func (pt *ProgressTracker) IncrementArticlesAdded() {
 	pt.Update(update)
 }
 
+// ArticleFailed counts an article that failed and reports which one and why
+func (pt *ProgressTracker) ArticleFailed(url, stage, reason string) {
+	pt.mu.Lock()
+	pt.current.ArticlesFailed++
+	update := pt.current
+	pt.mu.Unlock()
+
+	update.FailedURL = url
+	update.FailedStage = stage
+	update.Error = reason
+	update.Message = fmt.Sprintf("Article %d/%d failed", update.CurrentItem, update.TotalItems)
+	pt.Update(update)
+}
+
 // GetCurrent returns the current progress
 func (pt *ProgressTracker) GetCurrent() ProgressUpdate {
 	pt.mu.RLock()
@@ -107,14 +152,39 @@ func (pt *ProgressTracker) GetCurrent() ProgressUpdate {
 
 // Subscribe creates a new listener channel for progress updates
 func (pt *ProgressTracker) Subscribe() chan ProgressUpdate {
+	return pt.SubscribeFrom(0)
+}
+
+// SubscribeFrom creates a listener channel for a subscriber that has seen
+// the updates up to lastSeq, replaying the ones it missed. When they are no
+// longer kept, or lastSeq is 0, it gets the current state instead.
+func (pt *ProgressTracker) SubscribeFrom(lastSeq int) chan ProgressUpdate {
 	pt.mu.Lock()
 	defer pt.mu.Unlock()
 
-	ch := make(chan ProgressUpdate, 10)
-	pt.listeners = append(pt.listeners, ch)
+	var missed []ProgressUpdate
+	switch {
+	case lastSeq <= 0 || lastSeq > pt.current.Seq:
+		missed = []ProgressUpdate{pt.current}
+	case len(pt.history) > 0 && pt.history[0].Seq > lastSeq+1:
+		missed = []ProgressUpdate{pt.current}
+	default:
+		for _, update := range pt.history {
+			if update.Seq > lastSeq {
+				missed = append(missed, update)
+			}
+		}
+		// Nothing missed, but a finished operation sends nothing more
+		if len(missed) == 0 && pt.current.Status.Finished() {
+			missed = []ProgressUpdate{pt.current}
+		}
+	}
 
-	// Send current state immediately
-	ch <- pt.current
+	ch := make(chan ProgressUpdate, len(missed)+10)
+	pt.listeners = append(pt.listeners, ch)
+	for _, update := range missed {
+		ch <- update
+	}
 
 	return ch
 }
@@ -156,6 +226,7 @@ func (pt *ProgressTracker) Reset() {
 		Status:    StatusStarting,
 		Timestamp: time.Now(),
 	}
+	pt.history = nil
 	pt.active = false
 
 	// Close all listener channels
@@ -0,0 +1,137 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+const (
+	// MaxRetryAttempts is how many times a failing article is tried before
+	// it is abandoned
+	MaxRetryAttempts = 5
+	// MaxRetriesPerRun caps how many queued retries a single run attempts
+	MaxRetriesPerRun = 50
+)
+
+// startRun records the start of a scrape run, limited to source unless it
+// is empty. If the run cannot be stored, or is a dry run, an unsaved run is
+// returned so counting still works.
+func (s *Scraper) startRun(ctx context.Context, trigger, source string) *database.ScrapeRun {
+	var sourcePtr *string
+	if source != "" {
+		sourcePtr = &source
+	}
+	unsaved := &database.ScrapeRun{
+		Trigger:   trigger,
+		Source:    sourcePtr,
+		Status:    database.RunStatusRunning,
+		StartedAt: time.Now(),
+	}
+	if isDryRun(ctx) {
+		return unsaved
+	}
+
+	run, err := s.db.CreateScrapeRun(ctx, trigger, sourcePtr)
+	if err != nil {
+		logging.Warnf("Failed to record scrape run start: %v", err)
+		return unsaved
+	}
+	return run
+}
+
+// finishRun stores the outcome of a scrape run
+func (s *Scraper) finishRun(ctx context.Context, run *database.ScrapeRun, added int, err error) {
+	run.ArticlesAdded = added
+
+	switch {
+	case err == nil:
+		run.Status = database.RunStatusCompleted
+	case errors.Is(err, context.Canceled):
+		run.Status = database.RunStatusCancelled
+	default:
+		run.Status = database.RunStatusFailed
+	}
+	if err != nil {
+		msg := err.Error()
+		run.Error = &msg
+	}
+
+	if run.ID == 0 {
+		return
+	}
+
+	// The run context may already be cancelled; still record the outcome
+	if err := s.db.FinishScrapeRun(context.WithoutCancel(ctx), run); err != nil {
+		logging.Warnf("Failed to record scrape run %d: %v", run.ID, err)
+	}
+}
+
+// recordFailure logs and stores a URL that failed during a run and reports
+// it to progress listeners
+func (s *Scraper) recordFailure(ctx context.Context, run *database.ScrapeRun, url, stage string, err error) {
+	logging.Warnf("Article %s failed at %s stage: %v", url, stage, err)
+	run.ArticlesFailed++
+	progressOf(ctx).ArticleFailed(url, stage, err.Error())
+
+	if isDryRun(ctx) {
+		previewArticle(ctx, PreviewItem{URL: url, Action: PreviewFail, Reason: stage + ": " + err.Error()})
+		return
+	}
+
+	// Transient failures are queued for another attempt
+	if stage == database.FailureStageScrape || stage == database.FailureStageSave {
+		if err := s.db.EnqueueRetry(context.WithoutCancel(ctx), url, err.Error(), MaxRetryAttempts); err != nil {
+			logging.Warnf("Failed to queue retry for %s: %v", url, err)
+		}
+	}
+
+	if run.ID == 0 {
+		return
+	}
+
+	failure := &database.ScrapeFailure{
+		RunID: run.ID,
+		URL:   url,
+		Stage: stage,
+		Error: err.Error(),
+
+		Diagnostics: diagnosticsCapture(err),
+	}
+	if err := s.db.CreateScrapeFailure(context.WithoutCancel(ctx), failure); err != nil {
+		logging.Warnf("Failed to record scrape failure for %s: %v", url, err)
+	}
+}
+
+// recordLoginFailure stores a failed login on the run's failure list, so its
+// diagnostics are linked from the run. It does not count as a failed article.
+func (s *Scraper) recordLoginFailure(ctx context.Context, run *database.ScrapeRun, err error) {
+	if run.ID == 0 {
+		return
+	}
+
+	failure := &database.ScrapeFailure{
+		RunID: run.ID,
+		URL:   s.sources[SourceGasetten].LoginURL,
+		Stage: database.FailureStageLogin,
+		Error: err.Error(),
+
+		Diagnostics: diagnosticsCapture(err),
+	}
+	if err := s.db.CreateScrapeFailure(context.WithoutCancel(ctx), failure); err != nil {
+		logging.Warnf("Failed to record login failure: %v", err)
+	}
+}
+
+// dequeueRetry removes a URL from the retry queue once it no longer needs one
+func (s *Scraper) dequeueRetry(ctx context.Context, url string) {
+	if isDryRun(ctx) {
+		return
+	}
+	if err := s.db.DeleteRetry(ctx, url); err != nil {
+		logging.Warnf("Failed to remove %s from retry queue: %v", url, err)
+	}
+}
@@ -0,0 +1,121 @@
+package scraper
+
+import (
+	"context"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+// saveBatchSize is how many scraped articles a run saves at once
+const saveBatchSize = 25
+
+// scrapedArticle is an article scraped in a run, waiting to be saved with
+// the rest of its chunk
+type scrapedArticle struct {
+	link     string
+	name     string
+	source   SourceConfig
+	article  *database.Article
+	comments []*database.Comment
+	existing *database.Article // the partial copy being replaced, if any
+	reason   string            // why the article looks partial, if it does
+}
+
+// savePending saves a chunk of scraped articles and returns the ones that
+// were saved. New articles are inserted in one batch; when that fails they
+// are saved one at a time, so only the articles at fault are recorded as
+// failures.
+func (s *Scraper) savePending(ctx context.Context, run *database.ScrapeRun, pending []*scrapedArticle) []*database.Article {
+	var fresh []*database.Article
+	for _, p := range pending {
+		if p.existing == nil {
+			fresh = append(fresh, p.article)
+		}
+	}
+
+	created := true
+	if err := s.db.CreateArticles(ctx, fresh); err != nil {
+		logging.Warnf("Failed to save %d articles in one batch, saving them one at a time: %v", len(fresh), err)
+		created = false
+	}
+
+	saved := make([]*database.Article, 0, len(pending))
+	var alerts []*database.Article
+	for _, p := range pending {
+		if err := s.saveScraped(ctx, p, created); err != nil {
+			s.recordFailure(ctx, run, p.link, database.FailureStageSave, err)
+			continue
+		}
+		saved = append(saved, p.article)
+
+		// Re-scraped partial articles and duplicates were alerted about
+		// the first time
+		if p.existing == nil && p.article.DuplicateOf == nil {
+			alerts = append(alerts, p.article)
+		}
+	}
+	s.sendAlerts(ctx, alerts)
+	return saved
+}
+
+// saveScraped stores a scraped article, unless its batch created it
+// already, along with its tags and comments
+func (s *Scraper) saveScraped(ctx context.Context, p *scrapedArticle, created bool) error {
+	article := p.article
+	if p.existing != nil {
+		article.ID = p.existing.ID
+		if err := s.db.UpdateArticleContent(ctx, article); err != nil {
+			return err
+		}
+		s.auditReplaced(ctx, p)
+	} else {
+		if !created {
+			if err := s.db.CreateArticle(ctx, article); err != nil {
+				return err
+			}
+		}
+
+		// Hide syndicated copies of articles we already have
+		if _, err := s.dedup.Check(ctx, article); err != nil {
+			logging.Warnf("Duplicate check failed for %s: %v", p.link, err)
+		}
+	}
+
+	s.tagArticle(ctx, &p.source, article)
+
+	if p.comments != nil {
+		if err := s.db.ReplaceComments(ctx, article.ID, p.comments); err != nil {
+			logging.Warnf("Failed to save comments for %s: %v", p.link, err)
+		}
+	}
+
+	if article.Partial {
+		s.queuePartial(ctx, p.name, p.link, p.reason)
+	} else {
+		s.dequeueRetry(ctx, p.link)
+		if s.wayback != nil && (p.existing == nil || p.existing.WaybackURL == nil) {
+			s.wayback.Submit(article)
+		}
+		if s.summarizer != nil {
+			s.summarizer.Summarize(article)
+		}
+	}
+	return nil
+}
+
+// auditReplaced records in the audit log that a partial article was
+// scraped again and its content replaced
+func (s *Scraper) auditReplaced(ctx context.Context, p *scrapedArticle) {
+	details := map[string]any{"part": "content", "replaced": "partial copy"}
+	if job := jobOf(ctx); job != nil {
+		details["job"] = job.Label
+	}
+	entry, err := database.NewAuditEntry(database.AuditActorSystem, database.AuditArticleRescrape, p.link, details)
+	if err == nil {
+		err = s.db.CreateAuditEntry(ctx, entry)
+	}
+	if err != nil {
+		logging.Warnf("Failed to record the re-scrape of %s in the audit log: %v", p.link, err)
+	}
+}
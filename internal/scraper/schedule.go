@@ -0,0 +1,266 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+const (
+	// scheduleTick is how often the scheduler looks for due sources
+	scheduleTick = time.Minute
+
+	// scheduleSpacing separates consecutive scheduled runs, so sources due
+	// at the same time don't keep the browser busy back to back
+	scheduleSpacing = 5 * time.Minute
+
+	// MinScrapeInterval is the shortest interval a source can run at
+	MinScrapeInterval = 15 * time.Minute
+)
+
+// SourceSchedule is when a source is scraped automatically
+type SourceSchedule struct {
+	Times    []time.Duration // daily run times, as offsets from midnight
+	Interval time.Duration   // time between runs (0 for none)
+
+	// Quiet hours, as offsets from midnight; no runs start from QuietStart
+	// until QuietEnd, which may be on the next day
+	QuietStart, QuietEnd time.Duration
+	HasQuietHours        bool
+
+	MaxRunsPerDay int // 0 for no limit
+}
+
+// ParseSourceSchedule reads the schedule settings of a source. A source
+// without daily times or an interval has an empty schedule.
+func ParseSourceSchedule(source *database.Source) (*SourceSchedule, error) {
+	var schedule SourceSchedule
+
+	if source.Schedule != nil {
+		for _, t := range strings.Split(*source.Schedule, ",") {
+			offset, err := parseClock(strings.TrimSpace(t))
+			if err != nil {
+				return nil, fmt.Errorf("invalid schedule time %q, expected HH:MM", strings.TrimSpace(t))
+			}
+			schedule.Times = append(schedule.Times, offset)
+		}
+	}
+
+	if source.ScrapeInterval != nil {
+		interval, err := time.ParseDuration(*source.ScrapeInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval %q, expected e.g. 6h or 90m", *source.ScrapeInterval)
+		}
+		if interval < MinScrapeInterval {
+			return nil, fmt.Errorf("interval must be at least %s", MinScrapeInterval)
+		}
+		schedule.Interval = interval
+	}
+
+	if source.QuietHours != nil {
+		start, end, ok := strings.Cut(*source.QuietHours, "-")
+		quietStart, err1 := parseClock(strings.TrimSpace(start))
+		quietEnd, err2 := parseClock(strings.TrimSpace(end))
+		if !ok || err1 != nil || err2 != nil || quietStart == quietEnd {
+			return nil, fmt.Errorf("invalid quiet hours %q, expected HH:MM-HH:MM", *source.QuietHours)
+		}
+		schedule.QuietStart, schedule.QuietEnd, schedule.HasQuietHours = quietStart, quietEnd, true
+	}
+
+	if source.MaxRunsPerDay != nil {
+		if *source.MaxRunsPerDay < 1 {
+			return nil, fmt.Errorf("max runs per day must be at least 1")
+		}
+		schedule.MaxRunsPerDay = *source.MaxRunsPerDay
+	}
+
+	return &schedule, nil
+}
+
+// parseClock returns an "HH:MM" time as an offset from midnight
+func parseClock(clock string) (time.Duration, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Empty reports whether the schedule never runs the source
+func (sc *SourceSchedule) Empty() bool {
+	return len(sc.Times) == 0 && sc.Interval == 0
+}
+
+// DueAt returns when the source was last due to run, given the start of its
+// previous scheduled run (nil if it never ran), or false when it is not due
+// at now. A missed daily time is caught up once.
+func (sc *SourceSchedule) DueAt(lastRun *time.Time, runsToday int, now time.Time) (time.Time, bool) {
+	if sc.Empty() || sc.quiet(now) {
+		return time.Time{}, false
+	}
+	if sc.MaxRunsPerDay > 0 && runsToday >= sc.MaxRunsPerDay {
+		return time.Time{}, false
+	}
+
+	var due time.Time
+	midnight := startOfDay(now)
+	for _, offset := range sc.Times {
+		// The latest occurrence of this time, today or yesterday
+		slot := midnight.Add(offset)
+		if slot.After(now) {
+			slot = midnight.AddDate(0, 0, -1).Add(offset)
+		}
+		if (lastRun == nil || lastRun.Before(slot)) && (due.IsZero() || slot.Before(due)) {
+			due = slot
+		}
+	}
+
+	if sc.Interval > 0 {
+		slot := now
+		if lastRun != nil {
+			slot = lastRun.Add(sc.Interval)
+		}
+		if !slot.After(now) && (due.IsZero() || slot.Before(due)) {
+			due = slot
+		}
+	}
+
+	return due, !due.IsZero()
+}
+
+// quiet reports whether now is within the quiet hours
+func (sc *SourceSchedule) quiet(now time.Time) bool {
+	if !sc.HasQuietHours {
+		return false
+	}
+	offset := now.Sub(startOfDay(now))
+	if sc.QuietStart < sc.QuietEnd {
+		return offset >= sc.QuietStart && offset < sc.QuietEnd
+	}
+	// Quiet hours span midnight
+	return offset >= sc.QuietStart || offset < sc.QuietEnd
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// RunSchedules scrapes sources on their schedules until ctx is cancelled.
+// Due sources run one at a time, the most overdue first and spaced apart.
+// Scheduled runs are jobs of their own, so they run alongside manual scrapes
+// and backfills.
+func (s *Scraper) RunSchedules(ctx context.Context) {
+	ticker := time.NewTicker(scheduleTick)
+	defer ticker.Stop()
+
+	var lastFinished time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if time.Since(lastFinished) < scheduleSpacing {
+			continue
+		}
+
+		name := s.nextScheduled(ctx, time.Now())
+		if name == "" {
+			continue
+		}
+
+		logging.Infof("Starting scheduled scrape of %s", name)
+		count, err := s.ScrapeSource(ctx, database.RunTriggerScheduled, name)
+		if err != nil {
+			logging.Warnf("Scheduled scrape of %s failed: %v", name, err)
+		} else {
+			logging.Infof("Scheduled scrape of %s completed: %d new articles", name, count)
+		}
+		lastFinished = time.Now()
+	}
+}
+
+// nextScheduled returns the name of the source that has been due the
+// longest, or "" when none is due
+func (s *Scraper) nextScheduled(ctx context.Context, now time.Time) string {
+	scheduled, err := s.scheduledSources(ctx)
+	if err != nil {
+		logging.Warnf("Failed to load source schedules: %v", err)
+		return ""
+	}
+
+	var next string
+	var nextDue time.Time
+	for name, due := range s.dueSources(ctx, scheduled, now) {
+		if next == "" || due.Before(nextDue) || (due.Equal(nextDue) && name < next) {
+			next, nextDue = name, due
+		}
+	}
+	return next
+}
+
+// OverdueSources returns the scheduled sources that have been due to run
+// for longer than grace, with when they became due, and how many sources
+// have a schedule
+func (s *Scraper) OverdueSources(ctx context.Context, now time.Time, grace time.Duration) (map[string]time.Time, int, error) {
+	scheduled, err := s.scheduledSources(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	overdue := make(map[string]time.Time)
+	for name, due := range s.dueSources(ctx, scheduled, now) {
+		if now.Sub(due) > grace {
+			overdue[name] = due
+		}
+	}
+	return overdue, len(scheduled), nil
+}
+
+// scheduledSources returns the schedules of the enabled sources that have
+// one, by source name
+func (s *Scraper) scheduledSources(ctx context.Context) (map[string]*SourceSchedule, error) {
+	sources, err := s.db.GetSources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := make(map[string]*SourceSchedule)
+	for _, source := range sources {
+		if _, ok := s.sources[source.Name]; (!ok && source.FeedURL == nil) || !source.Enabled {
+			continue
+		}
+		schedule, err := ParseSourceSchedule(source)
+		if err != nil {
+			logging.Warnf("Ignoring schedule of source %s: %v", source.Name, err)
+			continue
+		}
+		if schedule.Empty() {
+			continue
+		}
+		scheduled[source.Name] = schedule
+	}
+	return scheduled, nil
+}
+
+// dueSources returns the scheduled sources that are due at now, with when
+// each became due
+func (s *Scraper) dueSources(ctx context.Context, scheduled map[string]*SourceSchedule, now time.Time) map[string]time.Time {
+	due := make(map[string]time.Time)
+	for name, schedule := range scheduled {
+		history, err := s.db.GetSourceRunHistory(ctx, name, startOfDay(now))
+		if err != nil {
+			logging.Warnf("Failed to check schedule of source %s: %v", name, err)
+			continue
+		}
+		if at, ok := schedule.DueAt(history.LastRun, history.RunsSince, now); ok {
+			due[name] = at
+		}
+	}
+	return due
+}
@@ -7,14 +7,27 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
 	"github.com/go-rod/rod/lib/proto"
-	readability "github.com/go-shiori/go-readability"
+	"github.com/tkilaker/kiln/internal/archive"
+	"github.com/tkilaker/kiln/internal/credentials"
 	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/dedup"
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/internal/media"
+	"github.com/tkilaker/kiln/internal/notify"
+	"github.com/tkilaker/kiln/internal/storage"
+	"github.com/tkilaker/kiln/internal/summary"
+	"github.com/tkilaker/kiln/internal/tags"
+	"github.com/tkilaker/kiln/internal/vault"
+	"github.com/tkilaker/kiln/internal/wayback"
+	"github.com/tkilaker/kiln/pkg/extract"
 )
 
 const (
@@ -28,13 +41,100 @@ type Scraper struct {
 	password   string
 	db         *database.DB
 	sessionDir string
+	controlURL string // remote browser to connect to instead of launching one
+	sources    map[string]SourceConfig
 	browser    *rod.Browser
 	headless   bool
-	progress   *ProgressTracker
+	dedup      *dedup.Detector
+	media      *media.Mirror       // nil when images are not mirrored
+	archive    *archive.Archive    // nil when fetched pages are not kept
+	wayback    *wayback.Submitter  // nil when articles are not submitted
+	summarizer *summary.Summarizer // nil when articles are not summarized
+	comments   bool                // whether to capture comment threads
+	notifier   notify.Notifier     // nil when keyword alerts are not sent
+
+	// credentials holds the encrypted credentials store: references
+	// (store:NAME) and Gasetten's credentials when Username is empty
+	credentials *credentials.Store
+
+	// vault reads credentials references to Vault (vault:PATH)
+	vault *vault.Client
+
+	// diagnostics receives captures of failed pages (nil disables them)
+	diagnostics storage.Store
+
+	// feedMu guards feedSources, the configuration of feed sources without
+	// a site profile, by name
+	feedMu      sync.RWMutex
+	feedSources map[string]SourceConfig
+
+	// browserMu guards starting and replacing the browser, which jobs share
+	browserMu sync.Mutex
+
+	// loginMu keeps the keepalive and a scrape from logging in at once
+	loginMu sync.Mutex
+
+	// jobsMu guards the jobs and the article URLs they are scraping
+	jobsMu  sync.Mutex
+	jobs    map[string]*Job
+	claimed map[string]bool
+	jobWake chan struct{} // signals workers that a job was queued
+
+	sessionMu sync.Mutex
+	session   SessionStatus
+}
+
+// Options configures a scraper
+type Options struct {
+	// Username and Password sign in to Gasetten. When they are empty the
+	// credentials stored as "gasetten" are used.
+	Username string
+	Password string
+	Headless bool
+
+	// Credentials is the encrypted credentials store; nil leaves only
+	// env: credentials references
+	Credentials *credentials.Store
+
+	// Vault reads credentials from HashiCorp Vault for vault:PATH
+	// references; nil leaves them unresolved
+	Vault *vault.Client
+
+	// ControlURL connects to a running Chrome (a DevTools ws:// URL or
+	// host:port) instead of launching one
+	ControlURL string
+
+	// Sources holds the URLs and selectors of every site Kiln can scrape,
+	// including Gasetten (see LoadSources)
+	Sources map[string]SourceConfig
+
+	// Media receives the article images; nil leaves them on the source
+	Media *media.Mirror
+
+	// Archive keeps the original article pages; nil discards them
+	Archive *archive.Archive
+
+	// Wayback submits saved articles to the Wayback Machine; nil doesn't
+	Wayback *wayback.Submitter
+
+	// Summarizer writes summaries of saved articles for the feeds; nil
+	// doesn't
+	Summarizer *summary.Summarizer
+
+	// Comments also captures each article's comment thread
+	Comments bool
+
+	// Diagnostics receives a screenshot and the HTML of pages where login
+	// or extraction failed; nil disables the captures
+	Diagnostics storage.Store
+
+	// Notifier receives the keyword alerts for new articles that mention a
+	// watch term; nil sends none
+	Notifier notify.Notifier
 }
 
 // New creates a new scraper instance
-func New(username, password string, db *database.DB, headless bool) (*Scraper, error) {
+func New(db *database.DB, opts Options) (*Scraper, error) {
 	// Create session directory
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -47,17 +147,35 @@ func New(username, password string, db *database.DB, headless bool) (*Scraper, e
 	}
 
 	return &Scraper{
-		username:   username,
-		password:   password,
+		username:   opts.Username,
+		password:   opts.Password,
 		db:         db,
 		sessionDir: sessionDir,
-		headless:   headless,
-		progress:   NewProgressTracker(),
+		controlURL: opts.ControlURL,
+		sources:    opts.Sources,
+		headless:   opts.Headless,
+		dedup:      dedup.New(db),
+		media:      opts.Media,
+		archive:    opts.Archive,
+		wayback:    opts.Wayback,
+		summarizer: opts.Summarizer,
+		comments:   opts.Comments,
+		notifier:   opts.Notifier,
+		jobs:       make(map[string]*Job),
+		claimed:    make(map[string]bool),
+		jobWake:    make(chan struct{}, 1),
+
+		credentials: opts.Credentials,
+		vault:       opts.Vault,
+		diagnostics: opts.Diagnostics,
 	}, nil
 }
 
 // initBrowser initializes the Rod browser instance
 func (s *Scraper) initBrowser() error {
+	s.browserMu.Lock()
+	defer s.browserMu.Unlock()
+
 	// Check if browser exists and is still alive
 	if s.browser != nil {
 		if s.isBrowserAlive() {
@@ -69,6 +187,10 @@ func (s *Scraper) initBrowser() error {
 		s.browser = nil
 	}
 
+	if s.controlURL != "" {
+		return s.connectRemoteBrowser()
+	}
+
 	// Launch browser
 	path, _ := launcher.LookPath()
 	l := launcher.New().
@@ -96,6 +218,47 @@ func (s *Scraper) initBrowser() error {
 	return nil
 }
 
+// connectRemoteBrowser connects to an already running Chrome. Kiln works in
+// its own browser context there, so closing it leaves the browser and other
+// clients alone; the Gasetten session then lasts as long as the connection.
+func (s *Scraper) connectRemoteBrowser() error {
+	// Bare DevTools endpoints (host:port or http://) name the browser's
+	// WebSocket in /json/version; ws:// URLs are used as given
+	controlURL := s.controlURL
+	if !strings.HasPrefix(controlURL, "ws://") && !strings.HasPrefix(controlURL, "wss://") {
+		resolved, err := launcher.ResolveURL(controlURL)
+		if err != nil {
+			return fmt.Errorf("failed to resolve browser control URL: %w", err)
+		}
+		controlURL = resolved
+	}
+
+	browser := rod.New().ControlURL(controlURL)
+	if err := browser.Connect(); err != nil {
+		return fmt.Errorf("failed to connect to remote browser: %w", err)
+	}
+	isolated, err := browser.Incognito()
+	if err != nil {
+		browser.Close()
+		return fmt.Errorf("failed to create browser context: %w", err)
+	}
+	s.browser = isolated
+
+	logging.Infof("Connected to remote browser")
+	return nil
+}
+
+// BrowserStatus reports whether the browser has been started, and if so
+// whether its connection still responds
+func (s *Scraper) BrowserStatus() (started, alive bool) {
+	s.browserMu.Lock()
+	defer s.browserMu.Unlock()
+	if s.browser == nil {
+		return false, false
+	}
+	return true, s.isBrowserAlive()
+}
+
 // isBrowserAlive checks if the browser connection is still active
 func (s *Scraper) isBrowserAlive() bool {
 	if s.browser == nil {
@@ -104,7 +267,7 @@ func (s *Scraper) isBrowserAlive() bool {
 
 	defer func() {
 		if r := recover(); r != nil {
-			log.Printf("Browser health check failed: %v", r)
+			logging.Warnf("Browser health check failed: %v", r)
 		}
 	}()
 
@@ -136,18 +299,24 @@ func (s *Scraper) createPageWithRetry(url string) (*rod.Page, error) {
 		}
 
 		// Attempt to create the page
-		page, err := s.browser.Page(proto.TargetCreateTarget{URL: url})
+		s.browserMu.Lock()
+		browser := s.browser
+		s.browserMu.Unlock()
+		page, err := browser.Page(proto.TargetCreateTarget{URL: url})
 		if err == nil {
 			return page, nil
 		}
 
-		log.Printf("Failed to create page (attempt %d/%d): %v", attempt, maxRetries, err)
+		logging.Warnf("Failed to create page (attempt %d/%d): %v", attempt, maxRetries, err)
 
-		// On failure, force browser reinitialization for next attempt
-		if s.browser != nil {
+		// On failure, force browser reinitialization for next attempt,
+		// unless another job has done so already
+		s.browserMu.Lock()
+		if s.browser == browser {
 			s.browser.Close()
 			s.browser = nil
 		}
+		s.browserMu.Unlock()
 
 		// Don't retry if we've exhausted attempts
 		if attempt == maxRetries {
@@ -159,75 +328,71 @@ func (s *Scraper) createPageWithRetry(url string) (*rod.Page, error) {
 	return nil, fmt.Errorf("failed to create page")
 }
 
-// GetProgressTracker returns the progress tracker
-func (s *Scraper) GetProgressTracker() *ProgressTracker {
-	return s.progress
+// Login logs into Gasetten using username and password, or the stored
+// credentials, unless the stored session is still valid. The outcome is
+// recorded for SessionStatus.
+func (s *Scraper) Login(ctx context.Context) error {
+	s.loginMu.Lock()
+	defer s.loginMu.Unlock()
+
+	username, password := s.username, s.password
+	var err error
+	if username == "" {
+		username, password, err = s.storedCredentials(ctx, SourceGasetten)
+	}
+	if err == nil {
+		err = s.login(ctx, SourceGasetten, username, password)
+	}
+	s.recordSession(err)
+	return err
 }
 
-// Login logs into Gasetten using username and password
-func (s *Scraper) Login(ctx context.Context) error {
+// login signs in to the named source, unless its session is still valid
+func (s *Scraper) login(ctx context.Context, name, username, password string) error {
 	if err := s.initBrowser(); err != nil {
 		return err
 	}
+	source := s.sources[name]
 
 	// Create page with retry logic for stale connections
-	page, err := s.createPageWithRetry("https://gasetten.se/min-profil/")
+	page, err := s.createPageWithRetry(source.LoginURL)
 	if err != nil {
 		return fmt.Errorf("failed to create login page: %w", err)
 	}
 	defer page.Close()
 
-	// Set page timeout
-	page = page.Timeout(PageTimeout)
+	if err := s.signIn(ctx, page.Timeout(PageTimeout), name, &source, username, password); err != nil {
+		return s.withDiagnostics(ctx, page, "login", err)
+	}
+	return nil
+}
 
+// signIn runs the source's login script or steps on page unless it shows
+// the user is already logged in
+func (s *Scraper) signIn(ctx context.Context, page *rod.Page, name string, source *SourceConfig, username, password string) error {
 	// Wait for page to load
 	if err := page.WaitLoad(); err != nil {
 		return fmt.Errorf("timeout waiting for login page to load: %w", err)
 	}
 
 	// Check if already logged in by looking for logout link or user menu
-	if s.isLoggedIn(page) {
-		log.Println("Already logged in to Gasetten")
+	if s.isLoggedIn(page, source) {
+		logging.Debugf("Already logged in to %s", name)
 		return nil
 	}
 
-	log.Println("Logging into Gasetten...")
-
-	// Find and fill username field (WordPress login form uses name="log")
-	log.Println("Looking for username field...")
-	usernameField, err := page.Element(`input[name="log"]`)
-	if err != nil {
-		return fmt.Errorf("could not find username field (input[name='log']): %w", err)
-	}
-	if err := usernameField.Input(s.username); err != nil {
-		return fmt.Errorf("could not input username: %w", err)
-	}
-	log.Println("Entered username")
-
-	// Find and fill password field (WordPress login form uses name="pwd")
-	log.Println("Looking for password field...")
-	passwordField, err := page.Element(`input[name="pwd"]`)
-	if err != nil {
-		return fmt.Errorf("could not find password field (input[name='pwd']): %w", err)
-	}
-	if err := passwordField.Input(s.password); err != nil {
-		return fmt.Errorf("could not input password: %w", err)
-	}
-	log.Println("Entered password")
+	logging.Infof("Logging into %s...", name)
 
-	// Find and click login button (WordPress uses id="wp-submit")
-	log.Println("Looking for login button...")
-	loginButton, err := page.Element(`input[id="wp-submit"]`)
-	if err != nil {
-		return fmt.Errorf("could not find login button (input[id='wp-submit']): %w", err)
-	}
-	if err := loginButton.Click(proto.InputMouseButtonLeft, 1); err != nil {
-		return fmt.Errorf("could not click login button: %w", err)
+	if source.script.has(scriptLogin) {
+		if err := source.script.login(ctx, page, username, password); err != nil {
+			return err
+		}
+	} else if err := runLoginSteps(page, source, username, password); err != nil {
+		return err
 	}
-	log.Println("Clicked login button")
 
 	// Wait for navigation after login
-	log.Println("Waiting for navigation after login...")
+	logging.Debugf("Waiting for navigation after login...")
 	if err := page.WaitLoad(); err != nil {
 		return fmt.Errorf("timeout waiting for page after login: %w", err)
 	}
@@ -235,20 +400,23 @@ func (s *Scraper) Login(ctx context.Context) error {
 	// Log where we ended up
 	pageInfo, err := page.Info()
 	if err == nil {
-		log.Printf("After login, redirected to: %s", pageInfo.URL)
+		logging.Debugf("After login, redirected to: %s", pageInfo.URL)
 	}
 
 	// Verify login was successful
-	if !s.isLoggedIn(page) {
+	if !s.isLoggedIn(page, source) {
 		// Log additional debug info
 		currentURL := "unknown"
 		if pageInfo, err := page.Info(); err == nil {
 			currentURL = pageInfo.URL
 		}
-		log.Printf("Login verification failed at URL: %s", currentURL)
+		logging.Warnf("Login verification failed at URL: %s", currentURL)
 
 		// Check if there's an error message on the page
-		errorMsg := s.extractText(page, `.login-error, #login_error, .error`)
+		errorMsg := ""
+		if source.LoginErrorSelector != "" {
+			errorMsg = s.extractText(page, source.LoginErrorSelector)
+		}
 		if errorMsg != "" {
 			return fmt.Errorf("login failed - error message: %s", errorMsg)
 		}
@@ -256,164 +424,630 @@ func (s *Scraper) Login(ctx context.Context) error {
 		return fmt.Errorf("login failed - could not verify successful authentication at %s", currentURL)
 	}
 
-	log.Println("Successfully logged into Gasetten")
+	logging.Infof("Successfully logged into %s", name)
+	return nil
+}
+
+// runLoginSteps fills in and clicks through the source's login steps
+func runLoginSteps(page *rod.Page, source *SourceConfig, username, password string) error {
+	credentials := strings.NewReplacer("{username}", username, "{password}", password)
+	for _, step := range source.loginSteps() {
+		if step.Fill != "" {
+			field, err := page.Element(step.Fill)
+			if err != nil {
+				return fmt.Errorf("could not find login field (%s): %w", step.Fill, err)
+			}
+			if err := field.Input(credentials.Replace(step.Value)); err != nil {
+				return fmt.Errorf("could not fill login field (%s): %w", step.Fill, err)
+			}
+			logging.Debugf("Filled %s", step.Fill)
+			continue
+		}
+
+		button, err := page.Element(step.Click)
+		if err != nil {
+			return fmt.Errorf("could not find login button (%s): %w", step.Click, err)
+		}
+		if err := button.Click(proto.InputMouseButtonLeft, 1); err != nil {
+			return fmt.Errorf("could not click login button (%s): %w", step.Click, err)
+		}
+		logging.Debugf("Clicked %s", step.Click)
+	}
 	return nil
 }
 
 // isLoggedIn checks if the current page shows signs of being logged in
-func (s *Scraper) isLoggedIn(page *rod.Page) bool {
+func (s *Scraper) isLoggedIn(page *rod.Page, source *SourceConfig) bool {
 	// Primary check: if login form is present, we're NOT logged in
-	hasLoginForm, _, _ := page.Has(`form#loginform`)
+	hasLoginForm, _, _ := page.Has(source.LoginFormSelector)
 	if hasLoginForm {
-		log.Println("Login form still present - not logged in")
+		logging.Debugf("Login form still present - not logged in")
 		return false
 	}
 
 	// If no login form is present, we're logged in
 	// This works because WordPress shows the login form when not authenticated,
 	// and shows profile/account content when authenticated
-	log.Println("No login form found - logged in successfully")
+	logging.Debugf("No login form found - logged in successfully")
 	return true
 }
 
-// ScrapeArticles fetches and stores articles from Gasetten
-func (s *Scraper) ScrapeArticles(ctx context.Context) (int, error) {
-	// Mark as active and reset progress
-	s.progress.SetActive(true)
-	s.progress.UpdateStatus(StatusStarting, "Initializing browser...")
-	defer s.progress.SetActive(false)
+// ScrapeArticles fetches and stores articles from Gasetten. Articles waiting
+// in the retry queue are attempted again after the newly discovered ones. The
+// run and its outcome are recorded in scrape_runs with the given trigger.
+func (s *Scraper) ScrapeArticles(ctx context.Context, trigger string) (int, error) {
+	return s.runScrape(ctx, trigger, "", func(ctx context.Context, run *database.ScrapeRun) ([]string, error) {
+		return s.discoverTargets(ctx, run, "", false)
+	})
+}
+
+// ScrapeSource fetches and stores the articles of a single source, followed
+// by the queued retries of its articles
+func (s *Scraper) ScrapeSource(ctx context.Context, trigger, name string) (int, error) {
+	return s.runScrape(ctx, trigger, name, func(ctx context.Context, run *database.ScrapeRun) ([]string, error) {
+		return s.discoverTargets(ctx, run, name, false)
+	})
+}
+
+// Backfill fetches and stores every article in the sitemaps or WordPress
+// API of a source, e.g. to fill in its archive. Articles that already exist
+// are skipped.
+func (s *Scraper) Backfill(ctx context.Context, trigger, name string) (int, error) {
+	if !s.CanBackfill(name) {
+		return 0, fmt.Errorf("source %s has no sitemaps or WordPress API to backfill from", name)
+	}
+	return s.runScrape(ctx, trigger, name, func(ctx context.Context, run *database.ScrapeRun) ([]string, error) {
+		return s.discoverTargets(ctx, run, name, true)
+	})
+}
+
+// CanBackfill reports whether the named source lists its whole archive,
+// through sitemaps or the WordPress API
+func (s *Scraper) CanBackfill(name string) bool {
+	source := s.sources[name]
+	return len(source.Sitemaps) > 0 || source.Mode == ModeWordPress
+}
+
+// RetryFailed attempts only the articles waiting in the retry queue
+func (s *Scraper) RetryFailed(ctx context.Context, trigger string) (int, error) {
+	return s.runScrape(ctx, trigger, "", s.retryTargets)
+}
+
+// ScrapeURLs scrapes the given article URLs, e.g. ones added by the user.
+// Articles that already exist are skipped.
+func (s *Scraper) ScrapeURLs(ctx context.Context, trigger string, articleURLs []string) (int, error) {
+	return s.runScrape(ctx, trigger, "", func(ctx context.Context, run *database.ScrapeRun) ([]string, error) {
+		run.ArticlesFound = len(articleURLs)
+		return articleURLs, nil
+	})
+}
+
+// NormalizeArticleURL checks that raw is a Gasetten article URL and returns
+// it in the form used by discovered links
+func NormalizeArticleURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", fmt.Errorf("not a valid URL")
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if host != "gasetten.se" {
+		return "", fmt.Errorf("only gasetten.se articles are supported")
+	}
+	if strings.Trim(u.Path, "/") == "" {
+		return "", fmt.Errorf("URL does not point to an article")
+	}
+
+	u.Scheme = "https"
+	u.Host = "gasetten.se"
+	u.RawQuery = ""
+	u.Fragment = ""
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return u.String(), nil
+}
+
+// targetFunc produces the list of article URLs a run should process
+type targetFunc func(ctx context.Context, run *database.ScrapeRun) ([]string, error)
+
+// runScrape logs in, collects the run's target URLs and scrapes each of them.
+// source names the source the run is limited to, "" for every source.
+func (s *Scraper) runScrape(ctx context.Context, trigger, source string, targets targetFunc) (added int, err error) {
+	// Runs started outside of a job (e.g. scheduled ones) get their own
+	if _, ok := ctx.Value(jobKey{}).(*Job); !ok {
+		return s.RunJob(ctx, runLabel(trigger, source), func(ctx context.Context) (int, error) {
+			return s.runScrape(ctx, trigger, source, targets)
+		})
+	}
+	progress := progressOf(ctx)
+
+	run := s.startRun(ctx, trigger, source)
+	defer func() { s.finishRun(ctx, run, added, err) }()
+	s.refreshFeedSources(ctx)
+
+	progress.Update(ProgressUpdate{
+		Status:  StatusStarting,
+		Message: "Initializing browser...",
+		RunID:   run.ID,
+	})
 
 	if err := s.initBrowser(); err != nil {
-		s.progress.UpdateStatus(StatusFailed, fmt.Sprintf("Failed to initialize browser: %v", err))
+		progress.UpdateStatus(StatusFailed, fmt.Sprintf("Failed to initialize browser: %v", err))
 		return 0, err
 	}
 
-	// Ensure we're logged in
-	s.progress.UpdateStatus(StatusLoggingIn, "Logging into Gasetten...")
-	if err := s.Login(ctx); err != nil {
-		s.progress.UpdateStatus(StatusFailed, fmt.Sprintf("Login failed: %v", err))
-		return 0, fmt.Errorf("failed to login: %w", err)
+	// Ensure we're logged in; other sources sign in when discovered
+	if source == "" || source == SourceGasetten {
+		progress.UpdateStatus(StatusLoggingIn, "Logging into Gasetten...")
+		if err := s.Login(ctx); err != nil {
+			s.recordLoginFailure(ctx, run, err)
+			progress.UpdateStatus(StatusFailed, fmt.Sprintf("Login failed: %v", err))
+			return 0, fmt.Errorf("failed to login: %w", err)
+		}
 	}
 
 	// Check if context was cancelled
 	select {
 	case <-ctx.Done():
-		s.progress.UpdateStatus(StatusCancelled, "Operation cancelled by user")
+		progress.UpdateStatus(StatusCancelled, "Operation cancelled by user")
 		return 0, ctx.Err()
 	default:
 	}
 
-	s.progress.UpdateStatus(StatusScraping, "Loading article category page...")
-
-	// Scrape from the Malmö FF category page which has better article organization
-	page, err := s.createPageWithRetry("https://gasetten.se/category/malmo-ff/")
+	articleLinks, err := targets(ctx, run)
 	if err != nil {
-		s.progress.UpdateStatus(StatusFailed, "Failed to load category page")
-		return 0, fmt.Errorf("failed to create category page: %w", err)
-	}
-	defer page.Close()
-
-	// Set page timeout
-	page = page.Timeout(PageTimeout)
-
-	if err := page.WaitLoad(); err != nil {
-		s.progress.UpdateStatus(StatusFailed, "Timeout waiting for category page")
-		return 0, fmt.Errorf("timeout waiting for category page to load: %w", err)
+		return 0, err
 	}
 
-	log.Println("Loaded category page, extracting article links...")
-	s.progress.UpdateStatus(StatusScraping, "Extracting article links...")
-
-	// Find all article links on the page
-	// Adjust selector based on actual Gasetten HTML structure
-	articleLinks := s.extractArticleLinks(page)
-
-	log.Printf("Found %d articles to scrape", len(articleLinks))
-	s.progress.Update(ProgressUpdate{
+	progress.Update(ProgressUpdate{
 		Status:     StatusScraping,
 		Message:    fmt.Sprintf("Found %d articles, starting to scrape...", len(articleLinks)),
 		TotalItems: len(articleLinks),
+		RunID:      run.ID,
 	})
 
+	// Adapt the delay between requests to how the site is responding
+	polite := newPoliteness()
+
+	// Scraped articles are saved a chunk at a time; flush saves the pending
+	// chunk once done items have been processed
+	var pending []*scrapedArticle
 	scrapedCount := 0
+	flush := func(done int) {
+		if len(pending) == 0 {
+			return
+		}
+		// Articles scraped before a cancellation are still saved
+		saveCtx := context.WithoutCancel(ctx)
+		saved := s.savePending(saveCtx, run, pending)
+		for _, p := range pending {
+			s.releaseURL(ctx, p.link)
+		}
+		pending = nil
+
+		for _, article := range saved {
+			scrapedCount++
+			logging.Infof("Successfully scraped and saved article: %s", article.URL)
+
+			// Duplicates are hidden from the list, and re-scraped partial
+			// articles already have a card, so don't push a card for them
+			newArticleID := article.ID
+			if article.DuplicateOf != nil || article.CreatedAt.Before(run.StartedAt) {
+				newArticleID = 0
+			}
+
+			progress.Update(ProgressUpdate{
+				Status:         StatusScraping,
+				Message:        fmt.Sprintf("Saved articles up to %d/%d (%d new)", done, len(articleLinks), scrapedCount),
+				CurrentItem:    done,
+				TotalItems:     len(articleLinks),
+				ArticlesAdded:  scrapedCount,
+				ArticlesFailed: run.ArticlesFailed,
+				NewArticleID:   newArticleID,
+				RunID:          run.ID,
+			})
+		}
+	}
+
+	job := jobOf(ctx)
+	dryRun := isDryRun(ctx)
 	for i, link := range articleLinks {
+		// Idle between articles while the job is paused, with what was
+		// scraped so far saved
+		if job != nil && job.Paused() {
+			flush(i)
+			progress.UpdateStatus(StatusPaused, fmt.Sprintf("Paused after article %d/%d", i, len(articleLinks)))
+			if job.waitWhilePaused(ctx) == nil {
+				progress.UpdateStatus(StatusScraping, "Resuming...")
+			}
+		}
+
 		// Check if context was cancelled
 		select {
 		case <-ctx.Done():
-			s.progress.UpdateStatus(StatusCancelled, fmt.Sprintf("Operation cancelled. Scraped %d articles before cancellation.", scrapedCount))
+			flush(i)
+			progress.UpdateStatus(StatusCancelled, fmt.Sprintf("Operation cancelled. Scraped %d articles before cancellation.", scrapedCount))
 			return scrapedCount, ctx.Err()
 		default:
 		}
 
-		s.progress.UpdateProgress(i+1, len(articleLinks), fmt.Sprintf("Processing article %d/%d...", i+1, len(articleLinks)))
+		progress.UpdateProgress(i+1, len(articleLinks), fmt.Sprintf("Processing article %d/%d...", i+1, len(articleLinks)))
 		log.Printf("Scraping article %d/%d: %s", i+1, len(articleLinks), link)
 
-		// Check if article already exists
-		exists, err := s.db.ArticleExists(ctx, link)
+		// Another job may be scraping the same article
+		if !s.claimURL(ctx, link) {
+			logging.Infof("Article is being scraped by another job, skipping: %s", link)
+			run.ArticlesSkipped++
+			progress.UpdateProgress(i+1, len(articleLinks), fmt.Sprintf("Article %d/%d is being scraped by another job, skipping...", i+1, len(articleLinks)))
+			continue
+		}
+		scraped, stage, err := s.scrapeNew(ctx, polite, link)
 		if err != nil {
-			log.Printf("Error checking article existence: %v", err)
+			s.releaseURL(ctx, link)
+			s.recordFailure(ctx, run, link, stage, err)
 			continue
 		}
-		if exists {
+		if scraped == nil {
+			s.releaseURL(ctx, link)
 			log.Printf("Article already exists, skipping: %s", link)
-			s.progress.UpdateProgress(i+1, len(articleLinks), fmt.Sprintf("Article %d/%d already exists, skipping...", i+1, len(articleLinks)))
+			run.ArticlesSkipped++
+			if dryRun {
+				previewArticle(ctx, PreviewItem{URL: link, Action: PreviewSkip, Reason: "already saved"})
+			}
+			progress.UpdateProgress(i+1, len(articleLinks), fmt.Sprintf("Article %d/%d already exists, skipping...", i+1, len(articleLinks)))
+			continue
+		}
+		if dryRun {
+			scrapedCount++
+			previewScraped(ctx, scraped)
 			continue
 		}
 
-		// Scrape the article
-		article, err := s.scrapeArticle(ctx, link)
-		if err != nil {
-			log.Printf("Error scraping article %s: %v", link, err)
+		// The URL stays claimed until its chunk is saved
+		pending = append(pending, scraped)
+		if len(pending) >= saveBatchSize {
+			flush(i + 1)
+		}
+	}
+	flush(len(articleLinks))
+
+	message := fmt.Sprintf("Completed! Added %d new articles (%d skipped, %d failed).", scrapedCount, run.ArticlesSkipped, run.ArticlesFailed)
+	if dryRun {
+		message = fmt.Sprintf("Dry run completed: would add %d articles (%d skipped, %d failed). Nothing was saved.", scrapedCount, run.ArticlesSkipped, run.ArticlesFailed)
+	}
+	progress.Update(ProgressUpdate{
+		Status:         StatusCompleted,
+		Message:        message,
+		CurrentItem:    len(articleLinks),
+		TotalItems:     len(articleLinks),
+		ArticlesAdded:  scrapedCount,
+		ArticlesFailed: run.ArticlesFailed,
+		RunID:          run.ID,
+	})
+
+	return scrapedCount, nil
+}
+
+// runLabel describes a run for its job, e.g. "scheduled scrape of gasetten"
+func runLabel(trigger, source string) string {
+	if source == "" {
+		return trigger + " scrape"
+	}
+	return trigger + " scrape of " + source
+}
+
+// discoverTargets collects article links from the start pages or sitemaps
+// of every enabled source, or only the one named, followed by any queued
+// retries that were not rediscovered. A backfill takes every article in the
+// sitemaps or the WordPress API rather than the recent ones.
+func (s *Scraper) discoverTargets(ctx context.Context, run *database.ScrapeRun, only string, backfill bool) ([]string, error) {
+	var articleLinks []string
+	for _, target := range s.discoverySources(ctx) {
+		if only != "" && target.name != only {
 			continue
 		}
+		source, _ := s.sourceConfig(target.name)
+
+		// Gasetten was signed in to when the run started
+		if target.name != SourceGasetten && source.LoginURL != "" {
+			if err := s.loginSource(ctx, target); err != nil {
+				logging.Warnf("Skipping source %s: login failed: %v", target.name, err)
+				continue
+			}
+		}
+
+		// A failed fixtures page doesn't hold up the articles
+		if source.Calendar.URL != "" && !backfill {
+			if err := s.scrapeCalendar(ctx, target.name, &source); err != nil {
+				logging.Warnf("Failed to scrape match calendar of %s: %v", target.name, err)
+			}
+		}
 
-		// Save to database
-		if err := s.db.CreateArticle(ctx, article); err != nil {
-			log.Printf("Error saving article %s: %v", link, err)
+		// Feeds, the REST API and sitemaps replace the start pages of
+		// sources that use them
+		if target.feedURL != "" {
+			links, err := s.collectFeedLinks(ctx, target.feedURL, &source)
+			if err != nil {
+				// Imported feeds come and go; one that fails doesn't
+				// stop the others
+				logging.Warnf("Skipping source %s: failed to read feed %s: %v", target.name, target.feedURL, err)
+				continue
+			}
+			articleLinks = appendNew(articleLinks, links)
+			continue
+		}
+		if source.Mode == ModeWordPress {
+			links, err := s.collectWordPressLinks(ctx, &source, backfill)
+			if err != nil {
+				return nil, err
+			}
+			articleLinks = appendNew(articleLinks, links)
+			continue
+		}
+		if len(source.Sitemaps) > 0 {
+			since := time.Now().Add(-source.sitemapMaxAge)
+			if backfill {
+				since = time.Time{}
+			}
+			links, err := s.collectSitemapLinks(ctx, &source, since)
+			if err != nil {
+				return nil, err
+			}
+			articleLinks = appendNew(articleLinks, links)
 			continue
 		}
 
-		scrapedCount++
-		log.Printf("Successfully scraped and saved article: %s", article.URL)
+		for _, startURL := range target.startURLs {
+			links, err := s.collectArticleLinks(ctx, startURL, &source)
+			if err != nil {
+				return nil, err
+			}
+			articleLinks = appendNew(articleLinks, links)
+		}
+	}
 
-		// Update progress with new article ID
-		s.progress.Update(ProgressUpdate{
-			Status:        StatusScraping,
-			Message:       fmt.Sprintf("Saved article %d/%d (%d new)", i+1, len(articleLinks), scrapedCount),
-			CurrentItem:   i + 1,
-			TotalItems:    len(articleLinks),
-			ArticlesAdded: scrapedCount,
-			NewArticleID:  article.ID,
-		})
+	logging.Infof("Found %d articles to scrape", len(articleLinks))
+	run.ArticlesFound = len(articleLinks)
 
-		// Small delay to be respectful to the server
-		time.Sleep(1 * time.Second)
+	retries, err := s.retryTargets(ctx, run)
+	if err != nil {
+		// The retry queue is best effort; still scrape what was discovered
+		logging.Warnf("Failed to load retry queue: %v", err)
+		return articleLinks, nil
+	}
+	for _, link := range retries {
+		if only != "" {
+			if name, _ := s.sourceFor(link); name != only {
+				continue
+			}
+		}
+		if !contains(articleLinks, link) {
+			articleLinks = append(articleLinks, link)
+		}
 	}
 
-	s.progress.Update(ProgressUpdate{
-		Status:        StatusCompleted,
-		Message:       fmt.Sprintf("Completed! Added %d new articles.", scrapedCount),
-		CurrentItem:   len(articleLinks),
-		TotalItems:    len(articleLinks),
-		ArticlesAdded: scrapedCount,
-	})
+	return articleLinks, nil
+}
 
-	return scrapedCount, nil
+// discoverySource is a source to look for new articles on
+type discoverySource struct {
+	name           string
+	startURLs      []string
+	feedURL        string
+	credentialsRef *string
+}
+
+// discoverySources returns the enabled sources managed on /sources that
+// have a site configuration. A source without start URLs uses its
+// configured category page, as does Gasetten when it has no row.
+func (s *Scraper) discoverySources(ctx context.Context) []discoverySource {
+	gasetten := discoverySource{name: SourceGasetten, startURLs: []string{s.sources[SourceGasetten].CategoryURL}}
+
+	rows, err := s.db.GetSources(ctx)
+	if err != nil {
+		logging.Warnf("Failed to load sources, using the default start page: %v", err)
+		return []discoverySource{gasetten}
+	}
+
+	var targets []discoverySource
+	seenGasetten := false
+	for _, row := range rows {
+		seenGasetten = seenGasetten || row.Name == SourceGasetten
+		source, ok := s.sourceConfig(row.Name)
+		switch {
+		case !row.Enabled:
+			logging.Infof("Source %s is disabled, not discovering new articles", row.Name)
+			continue
+		case !ok:
+			logging.Infof("Source %s has no site profile, not discovering new articles", row.Name)
+			continue
+		}
+
+		target := discoverySource{name: row.Name, startURLs: row.StartURLs, credentialsRef: row.CredentialsRef}
+		if row.FeedURL != nil {
+			target.feedURL = *row.FeedURL
+		}
+		if len(target.startURLs) == 0 && source.CategoryURL != "" {
+			target.startURLs = []string{source.CategoryURL}
+		}
+		targets = append(targets, target)
+	}
+	if !seenGasetten {
+		targets = append([]discoverySource{gasetten}, targets...)
+	}
+	return targets
+}
+
+// loginSource signs in to a source other than Gasetten with the credentials
+// its reference points to
+func (s *Scraper) loginSource(ctx context.Context, target discoverySource) error {
+	if target.credentialsRef == nil {
+		return fmt.Errorf("no credentials reference")
+	}
+	username, password, err := s.resolveCredentials(ctx, *target.credentialsRef)
+	if err != nil {
+		return err
+	}
+
+	s.loginMu.Lock()
+	defer s.loginMu.Unlock()
+	return s.login(ctx, target.name, username, password)
+}
+
+// sourceFor returns the name and configuration of the source an article URL
+// belongs to, Gasetten when no other source claims it. Sources with a site
+// profile take precedence over feed sources.
+func (s *Scraper) sourceFor(link string) (string, SourceConfig) {
+	for name, source := range s.sources {
+		if name != SourceGasetten && source.owns(link) {
+			return name, source
+		}
+	}
+	s.feedMu.RLock()
+	defer s.feedMu.RUnlock()
+	for name, source := range s.feedSources {
+		if source.owns(link) {
+			return name, source
+		}
+	}
+	return SourceGasetten, s.sources[SourceGasetten]
+}
+
+// collectArticleLinks loads a start page and extracts its article links
+func (s *Scraper) collectArticleLinks(ctx context.Context, startURL string, source *SourceConfig) ([]string, error) {
+	progress := progressOf(ctx)
+	progress.UpdateStatus(StatusScraping, "Loading article category page...")
+
+	page, err := s.createPageWithRetry(startURL)
+	if err != nil {
+		progress.UpdateStatus(StatusFailed, "Failed to load category page")
+		return nil, fmt.Errorf("failed to create category page: %w", err)
+	}
+	defer page.Close()
+
+	// Set page timeout
+	page = page.Timeout(PageTimeout)
+
+	if err := page.WaitLoad(); err != nil {
+		progress.UpdateStatus(StatusFailed, "Timeout waiting for category page")
+		return nil, fmt.Errorf("timeout waiting for category page to load: %w", err)
+	}
+
+	logging.Debugf("Loaded %s, extracting article links...", startURL)
+	progress.UpdateStatus(StatusScraping, "Extracting article links...")
+
+	return s.extractArticleLinks(page, source), nil
+}
+
+// retryTargets returns the URLs waiting in the retry queue
+func (s *Scraper) retryTargets(ctx context.Context, run *database.ScrapeRun) ([]string, error) {
+	retries, err := s.db.GetPendingRetries(ctx, MaxRetriesPerRun)
+	if err != nil {
+		return nil, err
+	}
+
+	links := make([]string, 0, len(retries))
+	for _, retry := range retries {
+		links = append(links, retry.URL)
+	}
+	if len(links) > 0 {
+		logging.Infof("%d articles queued for retry", len(links))
+	}
+	return links, nil
+}
+
+// scrapeNew scrapes a single article to be saved with the rest of its
+// chunk. It returns nil without error when the article already exists; on
+// failure it returns the stage that failed. Partial articles that exist
+// already are scraped again, to be updated.
+func (s *Scraper) scrapeNew(ctx context.Context, polite *politeness, link string) (*scrapedArticle, string, error) {
+	// Check if article already exists
+	existing, err := s.db.GetArticleByURL(ctx, link)
+	if err != nil {
+		return nil, database.FailureStageCheck, err
+	}
+	if existing != nil && !existing.Partial {
+		s.dequeueRetry(ctx, link)
+		return nil, "", nil
+	}
+
+	// Scrape the article, then delay to be respectful to the server
+	started := time.Now()
+	name, source := s.sourceFor(link)
+	article, comments, err := s.scrapeArticle(ctx, name, &source, link)
+	polite.Observe(time.Since(started), err)
+	defer polite.Wait(ctx)
+	if err != nil {
+		return nil, database.FailureStageScrape, err
+	}
+
+	// Keep teasers, but flag them so they stay out of feeds
+	reason := teaserReason(article)
+	article.Partial = reason != ""
+
+	return &scrapedArticle{
+		link:     link,
+		name:     name,
+		source:   source,
+		article:  article,
+		comments: comments,
+		existing: existing,
+		reason:   reason,
+	}, "", nil
+}
+
+// tagArticle stores the keywords extracted from a saved article and the
+// tags its categories map to as its tags
+func (s *Scraper) tagArticle(ctx context.Context, source *SourceConfig, article *database.Article) {
+	var title, text string
+	if article.Title != nil {
+		title = *article.Title
+	}
+	if article.ContentText != nil {
+		text = *article.ContentText
+	}
+
+	articleTags := source.categoryTags(article.Categories)
+	for _, tag := range tags.Extract(title, text) {
+		if !slices.ContainsFunc(articleTags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			articleTags = append(articleTags, tag)
+		}
+	}
+	if err := s.db.SetArticleTags(ctx, article.ID, articleTags); err != nil {
+		logging.Warnf("Failed to tag %s: %v", article.URL, err)
+	}
+}
+
+// queuePartial handles an article that came back as a teaser. Teasers
+// usually mean the session expired, so the login is checked (and renewed)
+// before the article is queued for another attempt.
+func (s *Scraper) queuePartial(ctx context.Context, name, link, reason string) {
+	logging.Infof("Article %s looks partial (%s), queuing for retry", link, reason)
+
+	// Other sources are signed in to again on their next discovery
+	if name == SourceGasetten {
+		if err := s.Login(ctx); err != nil {
+			logging.Warnf("Login check after partial article failed: %v", err)
+		}
+	}
+	if err := s.db.EnqueueRetry(context.WithoutCancel(ctx), link, "partial content: "+reason, MaxRetryAttempts); err != nil {
+		logging.Warnf("Failed to queue retry for %s: %v", link, err)
+	}
 }
 
 // extractArticleLinks extracts article URLs from a page
-func (s *Scraper) extractArticleLinks(page *rod.Page) []string {
+func (s *Scraper) extractArticleLinks(page *rod.Page, source *SourceConfig) []string {
 	var links []string
 	seen := make(map[string]bool)
 
 	// Get all links on the page
 	elements, err := page.Elements(`a[href]`)
 	if err != nil {
-		log.Printf("Error finding links: %v", err)
+		logging.Warnf("Error finding links: %v", err)
 		return links
 	}
 
-	log.Printf("Found %d total links on page", len(elements))
+	logging.Debugf("Found %d total links on page", len(elements))
 
 	for _, el := range elements {
 		href, err := el.Attribute("href")
@@ -421,41 +1055,8 @@ func (s *Scraper) extractArticleLinks(page *rod.Page) []string {
 			continue
 		}
 
-		url := *href
-
-		// Convert relative URLs to absolute
-		if strings.HasPrefix(url, "/") {
-			url = "https://gasetten.se" + url
-		}
-
-		// Filter for article URLs - Gasetten articles are in categories like /malmo-ff/, /blogg/, etc.
-		// Skip navigation links, author pages, tag pages, category pages, etc.
-		if !strings.HasPrefix(url, "https://gasetten.se/") {
-			continue
-		}
-
-		// Skip non-article pages
-		if strings.Contains(url, "/author/") ||
-			strings.Contains(url, "/tag/") ||
-			strings.Contains(url, "/category/") ||
-			strings.Contains(url, "/page/") ||
-			strings.Contains(url, "/wp-content/") ||
-			strings.Contains(url, "/wp-login") ||
-			strings.Contains(url, "/min-profil") ||
-			strings.Contains(url, "/about") ||
-			strings.Contains(url, "/arkiv") ||
-			strings.Contains(url, "/stotta-oss") ||
-			strings.Contains(url, "/annonsera") ||
-			strings.Contains(url, "/registrera") ||
-			strings.Contains(url, "/kop-plus") ||
-			strings.HasSuffix(url, "gasetten.se/") ||
-			strings.HasSuffix(url, "gasetten.se/#") {
-			continue
-		}
-
-		// Must have at least 2 path segments (e.g., /malmo-ff/article-slug/)
-		parts := strings.Split(strings.TrimPrefix(strings.TrimSuffix(url, "/"), "https://gasetten.se/"), "/")
-		if len(parts) < 2 {
+		url := source.articleLink(*href)
+		if url == "" {
 			continue
 		}
 
@@ -466,23 +1067,35 @@ func (s *Scraper) extractArticleLinks(page *rod.Page) []string {
 		}
 	}
 
-	log.Printf("Filtered to %d article links", len(links))
+	logging.Debugf("Filtered to %d article links", len(links))
 	return links
 }
 
-// scrapeArticle scrapes a single article page using Mozilla Readability
-func (s *Scraper) scrapeArticle(ctx context.Context, articleURL string) (*database.Article, error) {
+// scrapeArticle scrapes a single article page using Mozilla Readability, or
+// fetches it from the WordPress API
+func (s *Scraper) scrapeArticle(ctx context.Context, name string, source *SourceConfig, articleURL string) (*database.Article, []*database.Comment, error) {
+	if source.Mode == ModeWordPress {
+		return s.fetchWordPressArticle(ctx, name, source, articleURL)
+	}
+
 	page, err := s.createPageWithRetry(articleURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create article page: %w", err)
+		return nil, nil, fmt.Errorf("failed to create article page: %w", err)
 	}
 	defer page.Close()
 
-	// Set page timeout
-	page = page.Timeout(PageTimeout)
+	article, comments, err := s.extractArticle(ctx, page.Timeout(PageTimeout), name, source, articleURL)
+	if err != nil {
+		return nil, nil, s.withDiagnostics(ctx, page, "article", err)
+	}
+	return article, comments, nil
+}
 
+// extractArticle extracts the article and its comments from a loaded page.
+// The source's content selectors take precedence over readability.
+func (s *Scraper) extractArticle(ctx context.Context, page *rod.Page, name string, source *SourceConfig, articleURL string) (*database.Article, []*database.Comment, error) {
 	if err := page.WaitLoad(); err != nil {
-		return nil, fmt.Errorf("timeout waiting for article page to load: %w", err)
+		return nil, nil, fmt.Errorf("timeout waiting for article page to load: %w", err)
 	}
 
 	// Wait a bit for any lazy-loaded content
@@ -491,59 +1104,175 @@ func (s *Scraper) scrapeArticle(ctx context.Context, articleURL string) (*databa
 	// Get the full HTML of the page
 	htmlContent, err := page.HTML()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get page HTML: %w", err)
+		return nil, nil, fmt.Errorf("failed to get page HTML: %w", err)
 	}
+	s.archivePage(ctx, articleURL, "text/html", []byte(htmlContent))
 
 	// Parse URL for readability
 	parsedURL, err := url.Parse(articleURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse URL: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse URL: %w", err)
 	}
 
-	// Use Mozilla Readability to extract article content
-	readabilityArticle, err := readability.FromReader(strings.NewReader(htmlContent), parsedURL)
+	// Use Mozilla Readability to extract article content, limited to the
+	// article body when the source says where it is. Embeds readability
+	// dropped are put back and relative URLs made absolute, so links and
+	// images work outside gasetten.se.
+	body := ""
+	if source.Content.Body != "" {
+		if body = s.extractHTML(page, source.Content.Body); body == "" {
+			logging.Debugf("Body selector %q matched nothing on %s, using the whole page", source.Content.Body, articleURL)
+		}
+	}
+	extracted, err := extract.FromHTML(htmlContent, body, parsedURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse article with readability: %w", err)
+		return nil, nil, err
 	}
 
-	log.Printf("Readability extracted: title='%s', byline='%s', content=%d chars, text=%d chars",
-		readabilityArticle.Title,
-		readabilityArticle.Byline,
-		len(readabilityArticle.Content),
-		len(readabilityArticle.TextContent))
+	logging.Debugf("Readability extracted: title='%s', byline='%s', content=%d chars, text=%d chars",
+		extracted.Title,
+		extracted.Byline,
+		len(extracted.ContentHTML),
+		len(extracted.TextContent))
 
 	// Create article from readability results
 	article := &database.Article{
-		Source:      "gasetten",
+		Source:      name,
 		URL:         articleURL,
-		ContentHTML: &readabilityArticle.Content,
-		ContentText: &readabilityArticle.TextContent,
+		ContentHTML: &extracted.ContentHTML,
+		ContentText: &extracted.TextContent,
 	}
 
 	// Set title
-	if readabilityArticle.Title != "" {
-		article.Title = &readabilityArticle.Title
+	if source.Content.Title != "" {
+		if title := s.extractText(page, source.Content.Title); title != "" {
+			extracted.Title = title
+		}
+	}
+	if extracted.Title != "" {
+		article.Title = &extracted.Title
 	}
 
 	// Set author (byline)
-	if readabilityArticle.Byline != "" {
-		article.Author = &readabilityArticle.Byline
+	if source.Content.Author != "" {
+		if author := s.extractText(page, source.Content.Author); author != "" {
+			extracted.Byline = author
+		}
+	}
+	if extracted.Byline != "" {
+		article.Author = &extracted.Byline
 	}
 
-	// Try to extract published date from meta tags or readability
-	if readabilityArticle.PublishedTime != nil && !readabilityArticle.PublishedTime.IsZero() {
-		article.PublishedAt = readabilityArticle.PublishedTime
-		log.Printf("Extracted date from readability: %v", readabilityArticle.PublishedTime)
-	} else {
-		// Fallback to manual date extraction
-		publishedAt := s.extractDate(page)
-		if publishedAt != nil {
+	// Set lead image
+	if extracted.ImageURL != "" {
+		article.ImageURL = &extracted.ImageURL
+	}
+
+	// Pass on an embedded podcast episode to feeds
+	if extracted.AudioURL != "" {
+		article.AudioURL, article.AudioType = &extracted.AudioURL, &extracted.AudioType
+		logging.Debugf("Found audio %s (%s)", extracted.AudioURL, extracted.AudioType)
+	}
+
+	// Let the source's script and then its extractor plugin override the
+	// fields they return
+	var categories []string
+	if source.script.has(scriptExtract) {
+		fields, err := source.script.extract(ctx, page, parsedURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		applyPlugin(article, fields)
+		if len(fields.Categories) > 0 {
+			categories = fields.Categories
+		}
+	}
+	if extractor := source.extractor(); extractor != nil {
+		plugin, err := extractor.Run(ctx, htmlContent, parsedURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		applyPlugin(article, plugin)
+		if len(plugin.Categories) > 0 {
+			categories = plugin.Categories
+		}
+	}
+
+	// Try to extract published date from the script or plugin, the source's
+	// selector, meta tags or readability
+	if article.PublishedAt != nil {
+		logging.Debugf("Extracted date with the script or extractor plugin: %v", article.PublishedAt)
+	} else if source.Content.Published != "" {
+		article.PublishedAt = s.extractDate(page, source)
+		if article.PublishedAt != nil {
+			logging.Debugf("Extracted date with the source's selector: %v", article.PublishedAt)
+		}
+	}
+	if article.PublishedAt == nil {
+		if extracted.PublishedAt != nil {
+			article.PublishedAt = extracted.PublishedAt
+			logging.Debugf("Extracted date from readability: %v", extracted.PublishedAt)
+		} else if publishedAt := s.extractDate(page, source); publishedAt != nil {
+			// Fallback to manual date extraction
 			article.PublishedAt = publishedAt
-			log.Printf("Extracted date manually: %v", publishedAt)
+			logging.Debugf("Extracted date manually: %v", publishedAt)
 		}
 	}
 
-	return article, nil
+	if len(categories) > 0 {
+		article.Categories = categories
+	} else {
+		article.Categories = s.extractCategories(page, source)
+	}
+
+	if s.media != nil {
+		s.mirrorImages(ctx, article, parsedURL)
+	}
+
+	// Comment threads are saved once the article has an ID
+	var comments []*database.Comment
+	if s.comments {
+		comments = s.extractComments(page)
+	}
+
+	return article, comments, nil
+}
+
+// applyPlugin sets the fields an extractor plugin or script returned on
+// article
+func applyPlugin(article *database.Article, plugin *extract.PluginResponse) {
+	if plugin.Title != "" {
+		article.Title = &plugin.Title
+	}
+	if plugin.Author != "" {
+		article.Author = &plugin.Author
+	}
+	if plugin.Published != "" {
+		if published, err := plugin.PublishedTime(); err == nil {
+			article.PublishedAt = &published
+		}
+	}
+	if plugin.ContentHTML != "" {
+		article.ContentHTML = &plugin.ContentHTML
+	}
+	if plugin.Text != "" {
+		article.ContentText = &plugin.Text
+	}
+	if plugin.ImageURL != "" {
+		article.ImageURL = &plugin.ImageURL
+	}
+}
+
+// archivePage keeps the page an article was extracted from, when archiving
+// is enabled and this is not a dry run. Failures are logged and don't fail
+// the article.
+func (s *Scraper) archivePage(ctx context.Context, pageURL, contentType string, body []byte) {
+	if s.archive == nil || isDryRun(ctx) {
+		return
+	}
+	if err := s.archive.Save(ctx, pageURL, contentType, body, time.Now()); err != nil {
+		logging.Warnf("Failed to archive %s: %v", pageURL, err)
+	}
 }
 
 // extractText extracts text content using multiple selector fallbacks
@@ -578,17 +1307,22 @@ func (s *Scraper) extractHTML(page *rod.Page, selectors string) string {
 	return ""
 }
 
-// extractDate tries to extract and parse publication date
-func (s *Scraper) extractDate(page *rod.Page) *time.Time {
+// extractDate tries to extract and parse publication date. The source's
+// published selector and date formats are tried first.
+func (s *Scraper) extractDate(page *rod.Page, source *SourceConfig) *time.Time {
 	// Try to find date in various formats and selectors
-	selectors := []string{
+	var selectors []string
+	if source.Content.Published != "" {
+		selectors = append(selectors, source.Content.Published)
+	}
+	selectors = append(selectors,
 		`time.post-date[datetime]`,
 		`time.entry-date[datetime]`,
 		`time[datetime]`,
 		`meta[property="article:published_time"]`,
 		`.post-date`,
 		`[class*="date"]`,
-	}
+	)
 
 	for _, selector := range selectors {
 		el, err := page.Element(selector)
@@ -601,14 +1335,14 @@ func (s *Scraper) extractDate(page *rod.Page) *time.Time {
 			dateStr := *datetime
 
 			// Try various date formats
-			formats := []string{
+			formats := slices.Concat(source.DateFormats, []string{
 				time.RFC3339,
 				"2006-01-02",
 				"2006-01-02T15:04:05",
-			}
+			})
 
 			for _, format := range formats {
-				if t, err := time.Parse(format, dateStr); err == nil {
+				if t, err := time.ParseInLocation(format, dateStr, time.Local); err == nil {
 					return &t
 				}
 			}
@@ -623,30 +1357,34 @@ func (s *Scraper) extractDate(page *rod.Page) *time.Time {
 
 		// Try parsing text content
 		if text, err := el.Text(); err == nil {
-			// Try common Swedish/international date formats
-			formats := []string{
-				"2 January 2006", // "9 november, 2025"
-				"2 January, 2006",
-				"January 2, 2006",
-				"2006-01-02",
-				"02 Jan 2006",
-			}
-
-			// Clean up text (remove extra spaces, commas at weird places)
-			cleanText := strings.TrimSpace(text)
-			cleanText = strings.Replace(cleanText, ",", "", -1)
-
-			for _, format := range formats {
-				if t, err := time.Parse(format, cleanText); err == nil {
+			// The source's formats may rely on the text as written
+			text = strings.TrimSpace(text)
+			for _, format := range source.DateFormats {
+				if t, err := time.ParseInLocation(format, text, time.Local); err == nil {
 					return &t
 				}
 			}
+
+			// Try common formats, with month names in the source's language
+			if t, ok := extract.ParseDate(text, source.DateLocale, source.MonthNames, source.DateFormats); ok {
+				return &t
+			}
 		}
 	}
 
 	return nil
 }
 
+// appendNew appends the items not in slice already
+func appendNew(slice, items []string) []string {
+	for _, item := range items {
+		if !contains(slice, item) {
+			slice = append(slice, item)
+		}
+	}
+	return slice
+}
+
 // contains checks if a string slice contains a value
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
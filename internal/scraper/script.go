@@ -0,0 +1,358 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/pkg/extract"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// maxScriptSteps bounds the Starlark steps of one script call, so a runaway
+// loop fails the call instead of the run
+const maxScriptSteps = 10_000_000
+
+// Script hooks a site script may define
+const (
+	scriptLogin   = "login"   // login(page, username, password)
+	scriptExtract = "extract" // extract(page) -> dict
+)
+
+// siteScript is a source's Starlark script. It defines login, extract or
+// both, which take the place of the login steps and add to the content
+// selectors. Its top level runs once, when the script is loaded, and has no
+// page to work on.
+type siteScript struct {
+	path    string
+	globals starlark.StringDict
+}
+
+// loadScript reads and runs the top level of the script at path
+func loadScript(path string) (*siteScript, error) {
+	thread := &starlark.Thread{Name: path, Print: scriptPrint}
+	thread.SetMaxExecutionSteps(maxScriptSteps)
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load script %s: %w", path, err)
+	}
+
+	script := &siteScript{path: path, globals: globals}
+	if !script.has(scriptLogin) && !script.has(scriptExtract) {
+		return nil, fmt.Errorf("script %s defines neither login nor extract", path)
+	}
+	for _, name := range []string{scriptLogin, scriptExtract} {
+		if fn, ok := globals[name]; ok {
+			if _, ok := fn.(starlark.Callable); !ok {
+				return nil, fmt.Errorf("script %s: %s is a %s, not a function", path, name, fn.Type())
+			}
+		}
+	}
+	return script, nil
+}
+
+// has reports whether the script defines the named hook. A nil script has
+// none.
+func (s *siteScript) has(name string) bool {
+	if s == nil {
+		return false
+	}
+	_, ok := s.globals[name]
+	return ok
+}
+
+// call calls a hook of the script, stopping it when ctx ends
+func (s *siteScript) call(ctx context.Context, name string, args ...starlark.Value) (starlark.Value, error) {
+	thread := &starlark.Thread{Name: s.path, Print: scriptPrint}
+	thread.SetMaxExecutionSteps(maxScriptSteps)
+	stop := context.AfterFunc(ctx, func() { thread.Cancel(ctx.Err().Error()) })
+	defer stop()
+
+	result, err := starlark.Call(thread, s.globals[name], args, nil)
+	if err != nil {
+		return nil, fmt.Errorf("script %s: %s failed: %w", s.path, name, scriptError(err))
+	}
+	return result, nil
+}
+
+// login signs in on page with the script's login hook
+func (s *siteScript) login(ctx context.Context, page *rod.Page, username, password string) error {
+	_, err := s.call(ctx, scriptLogin, &scriptPage{page: page}, starlark.String(username), starlark.String(password))
+	return err
+}
+
+// extract runs the script's extract hook on an article page. It returns a
+// dict with the fields of an extractor plugin response, or None to leave
+// the page to the other extraction steps.
+func (s *siteScript) extract(ctx context.Context, page *rod.Page, pageURL *url.URL) (*extract.PluginResponse, error) {
+	result, err := s.call(ctx, scriptExtract, &scriptPage{page: page})
+	if err != nil {
+		return nil, err
+	}
+	if result == starlark.None {
+		return &extract.PluginResponse{}, nil
+	}
+	dict, ok := result.(*starlark.Dict)
+	if !ok {
+		return nil, fmt.Errorf("script %s: extract returned a %s, not a dict", s.path, result.Type())
+	}
+
+	resp := &extract.PluginResponse{}
+	fields := map[string]*string{
+		"title":        &resp.Title,
+		"author":       &resp.Author,
+		"published":    &resp.Published,
+		"content_html": &resp.ContentHTML,
+		"text":         &resp.Text,
+		"image_url":    &resp.ImageURL,
+	}
+	for _, item := range dict.Items() {
+		key, ok := starlark.AsString(item[0])
+		if !ok {
+			return nil, fmt.Errorf("script %s: extract returned a non-string key %s", s.path, item[0])
+		}
+		if item[1] == starlark.None {
+			continue
+		}
+		if key == "categories" {
+			categories, err := scriptStrings(item[1])
+			if err != nil {
+				return nil, fmt.Errorf("script %s: categories: %w", s.path, err)
+			}
+			resp.Categories = categories
+			continue
+		}
+		field, ok := fields[key]
+		if !ok {
+			return nil, fmt.Errorf("script %s: extract returned unknown field %q", s.path, key)
+		}
+		value, ok := starlark.AsString(item[1])
+		if !ok {
+			return nil, fmt.Errorf("script %s: %s is a %s, not a string", s.path, key, item[1].Type())
+		}
+		*field = strings.TrimSpace(value)
+	}
+
+	if err := resp.Normalize(pageURL); err != nil {
+		return nil, fmt.Errorf("script %s: %w", s.path, err)
+	}
+	return resp, nil
+}
+
+// scriptPrint sends the output of print() to the debug log
+func scriptPrint(thread *starlark.Thread, msg string) {
+	logging.Debugf("%s: %s", thread.Name, msg)
+}
+
+// scriptError returns the error of a failed call with the script's
+// backtrace, when it has one
+func scriptError(err error) error {
+	if evalErr, ok := err.(*starlark.EvalError); ok {
+		return fmt.Errorf("%s", evalErr.Backtrace())
+	}
+	return err
+}
+
+// scriptStrings converts a list or tuple of strings
+func scriptStrings(v starlark.Value) ([]string, error) {
+	iterable, ok := v.(starlark.Iterable)
+	if !ok || v.Type() == "string" {
+		return nil, fmt.Errorf("got a %s, not a list", v.Type())
+	}
+	var values []string
+	iter := iterable.Iterate()
+	defer iter.Done()
+	var item starlark.Value
+	for iter.Next(&item) {
+		s, ok := starlark.AsString(item)
+		if !ok {
+			return nil, fmt.Errorf("got a %s, not a string", item.Type())
+		}
+		values = append(values, s)
+	}
+	return values, nil
+}
+
+// scriptPage is the page a script hook works on. Its methods take CSS
+// selectors; the ones acting on an element fail when nothing matches.
+type scriptPage struct {
+	page *rod.Page
+}
+
+// scriptPageMethods are the methods of a script's page
+var scriptPageMethods = map[string]func(p *scriptPage, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error){
+	// navigate(url) loads a page and waits for it
+	"navigate": func(p *scriptPage, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var target string
+		if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &target); err != nil {
+			return nil, err
+		}
+		if err := p.page.Navigate(target); err != nil {
+			return nil, err
+		}
+		return starlark.None, p.page.WaitLoad()
+	},
+	// fill(selector, value) types value into a field
+	"fill": func(p *scriptPage, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var selector, value string
+		if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 2, &selector, &value); err != nil {
+			return nil, err
+		}
+		el, err := p.page.Element(selector)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.None, el.Input(value)
+	},
+	// click(selector) clicks an element
+	"click": func(p *scriptPage, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var selector string
+		if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &selector); err != nil {
+			return nil, err
+		}
+		el, err := p.page.Element(selector)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.None, el.Click(proto.InputMouseButtonLeft, 1)
+	},
+	// select(selector, option) picks the option containing that text in a
+	// <select>
+	"select": func(p *scriptPage, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var selector, option string
+		if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 2, &selector, &option); err != nil {
+			return nil, err
+		}
+		el, err := p.page.Element(selector)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.None, el.Select([]string{option}, true, rod.SelectorTypeText)
+	},
+	// wait(selector) waits until an element appears; wait() until the page
+	// has loaded
+	"wait": func(p *scriptPage, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var selector string
+		if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 0, &selector); err != nil {
+			return nil, err
+		}
+		if selector == "" {
+			return starlark.None, p.page.WaitLoad()
+		}
+		_, err := p.page.Element(selector)
+		return starlark.None, err
+	},
+	// has(selector) reports whether an element matches
+	"has": func(p *scriptPage, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var selector string
+		if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &selector); err != nil {
+			return nil, err
+		}
+		has, _, err := p.page.Has(selector)
+		return starlark.Bool(has), err
+	},
+	// text(selector) returns the text of the first match, or None
+	"text": func(p *scriptPage, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		return p.first(fn, args, kwargs, func(el *rod.Element) (string, error) { return el.Text() })
+	},
+	// html(selector) returns the outer HTML of the first match, or None
+	"html": func(p *scriptPage, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		return p.first(fn, args, kwargs, func(el *rod.Element) (string, error) { return el.HTML() })
+	},
+	// texts(selector) returns the text of every match
+	"texts": func(p *scriptPage, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var selector string
+		if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &selector); err != nil {
+			return nil, err
+		}
+		elements, err := p.page.Elements(selector)
+		if err != nil {
+			return nil, err
+		}
+		texts := make([]starlark.Value, 0, len(elements))
+		for _, el := range elements {
+			if text, err := el.Text(); err == nil {
+				texts = append(texts, starlark.String(strings.TrimSpace(text)))
+			}
+		}
+		return starlark.NewList(texts), nil
+	},
+	// attr(selector, name) returns an attribute of the first match, or None
+	"attr": func(p *scriptPage, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var selector, name string
+		if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 2, &selector, &name); err != nil {
+			return nil, err
+		}
+		has, el, err := p.page.Has(selector)
+		if err != nil || !has {
+			return starlark.None, err
+		}
+		value, err := el.Attribute(name)
+		if err != nil || value == nil {
+			return starlark.None, err
+		}
+		return starlark.String(*value), nil
+	},
+	// url() returns the page's current URL
+	"url": func(p *scriptPage, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		info, err := p.page.Info()
+		if err != nil {
+			return nil, err
+		}
+		return starlark.String(info.URL), nil
+	},
+}
+
+// first applies get to the first element matching the selector argument,
+// returning None when nothing matches
+func (p *scriptPage) first(fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple, get func(*rod.Element) (string, error)) (starlark.Value, error) {
+	var selector string
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &selector); err != nil {
+		return nil, err
+	}
+	has, el, err := p.page.Has(selector)
+	if err != nil || !has {
+		return starlark.None, err
+	}
+	value, err := get(el)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.String(strings.TrimSpace(value)), nil
+}
+
+func (p *scriptPage) String() string        { return "<page>" }
+func (p *scriptPage) Type() string          { return "page" }
+func (p *scriptPage) Freeze()               {}
+func (p *scriptPage) Truth() starlark.Bool  { return starlark.True }
+func (p *scriptPage) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable type: page") }
+
+// Attr implements starlark.HasAttrs
+func (p *scriptPage) Attr(name string) (starlark.Value, error) {
+	method, ok := scriptPageMethods[name]
+	if !ok {
+		return nil, nil
+	}
+	return starlark.NewBuiltin(name, func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		return method(p, fn, args, kwargs)
+	}), nil
+}
+
+// AttrNames implements starlark.HasAttrs
+func (p *scriptPage) AttrNames() []string {
+	names := make([]string, 0, len(scriptPageMethods))
+	for name := range scriptPageMethods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
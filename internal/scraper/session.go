@@ -0,0 +1,31 @@
+package scraper
+
+import "time"
+
+// SessionStatus is the last known state of the Gasetten login session
+type SessionStatus struct {
+	Valid     bool       `json:"valid"`
+	CheckedAt *time.Time `json:"checked_at"` // nil until the first check
+	Error     string     `json:"error,omitempty"`
+}
+
+// SessionStatus reports whether the session was valid when it was last
+// checked by a login, a scrape or the keepalive
+func (s *Scraper) SessionStatus() SessionStatus {
+	s.sessionMu.Lock()
+	defer s.sessionMu.Unlock()
+	return s.session
+}
+
+// recordSession stores the outcome of a login check
+func (s *Scraper) recordSession(err error) {
+	now := time.Now()
+	status := SessionStatus{Valid: err == nil, CheckedAt: &now}
+	if err != nil {
+		status.Error = err.Error()
+	}
+
+	s.sessionMu.Lock()
+	s.session = status
+	s.sessionMu.Unlock()
+}
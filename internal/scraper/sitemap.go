@@ -0,0 +1,122 @@
+package scraper
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/pkg/extract"
+)
+
+const (
+	// DefaultSitemapMaxAge is how far back regular runs look in sitemaps
+	DefaultSitemapMaxAge = 7 * 24 * time.Hour
+
+	// maxSitemapSize is the largest sitemap the protocol allows (50 MB)
+	maxSitemapSize = 50 << 20
+
+	// maxSitemapDepth limits how deep sitemap indexes are followed
+	maxSitemapDepth = 3
+)
+
+// collectSitemapLinks returns the article links in the source's sitemaps,
+// following sitemap indexes. Entries last modified before since are left
+// out; a zero since takes the whole archive. Entries without a lastmod are
+// always included.
+func (s *Scraper) collectSitemapLinks(ctx context.Context, source *SourceConfig, since time.Time) ([]string, error) {
+	progressOf(ctx).UpdateStatus(StatusScraping, "Reading sitemaps...")
+
+	base, err := url.Parse(source.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base_url: %w", err)
+	}
+	client := &http.Client{Timeout: PageTimeout, Jar: s.browserCookies(base)}
+
+	var links []string
+	seen := make(map[string]bool)
+	visited := make(map[string]bool)
+
+	var walk func(sitemapURL string, depth int) error
+	walk = func(sitemapURL string, depth int) error {
+		if visited[sitemapURL] {
+			return nil
+		}
+		visited[sitemapURL] = true
+
+		doc, err := fetchSitemap(ctx, client, sitemapURL)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range doc.Sitemaps {
+			if depth >= maxSitemapDepth {
+				logging.Warnf("Not following sitemap %s: nested too deep", entry.Loc)
+				continue
+			}
+			// A sitemap that has not changed since holds no newer entries
+			if !entry.LastMod.IsZero() && entry.LastMod.Before(since) {
+				continue
+			}
+			if err := walk(entry.Loc, depth+1); err != nil {
+				logging.Warnf("Skipping sitemap %s: %v", entry.Loc, err)
+			}
+		}
+
+		for _, entry := range doc.URLs {
+			if !entry.LastMod.IsZero() && entry.LastMod.Before(since) {
+				continue
+			}
+			link := source.articleLink(entry.Loc)
+			if link != "" && !seen[link] {
+				seen[link] = true
+				links = append(links, link)
+			}
+		}
+		return nil
+	}
+
+	for _, sitemapURL := range source.Sitemaps {
+		if err := walk(sitemapURL, 0); err != nil {
+			progressOf(ctx).UpdateStatus(StatusFailed, "Failed to read sitemap")
+			return nil, fmt.Errorf("failed to read sitemap %s: %w", sitemapURL, err)
+		}
+	}
+
+	logging.Infof("Found %d article links in %d sitemaps", len(links), len(visited))
+	return links, nil
+}
+
+// fetchSitemap downloads and parses a sitemap, gzipped or not
+func fetchSitemap(ctx context.Context, client *http.Client, sitemapURL string) (*extract.Sitemap, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sitemapURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var body io.Reader = io.LimitReader(resp.Body, maxSitemapSize)
+	if strings.HasSuffix(req.URL.Path, ".gz") {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress sitemap: %w", err)
+		}
+		defer gz.Close()
+		body = io.LimitReader(gz, maxSitemapSize)
+	}
+
+	return extract.ParseSitemap(body)
+}
@@ -0,0 +1,483 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/config"
+	"github.com/tkilaker/kiln/internal/credentials"
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/internal/vault"
+	"github.com/tkilaker/kiln/pkg/extract"
+	"gopkg.in/yaml.v3"
+)
+
+// SourceGasetten is the name of the Gasetten source
+const SourceGasetten = "gasetten"
+
+// Source modes: how article links and content are fetched
+const (
+	// ModeBrowser renders listing and article pages in the browser
+	ModeBrowser = "browser"
+
+	// ModeWordPress reads posts from the WordPress REST API
+	// (/wp-json/wp/v2/posts) with the browser's cookies, using the browser
+	// only to log in
+	ModeWordPress = "wordpress"
+)
+
+// SourceConfig holds the site-specific URLs and selectors the scraper uses
+// for a source, so a theme change can be handled without a release. Site
+// profiles (YAML) and the sources file (JSON) use the same field names.
+type SourceConfig struct {
+	// Mode is ModeBrowser (the default) or ModeWordPress
+	Mode string `json:"mode" yaml:"mode"`
+
+	// Login page, which shows the login form until signed in. Sites without
+	// a login leave it empty.
+	LoginURL string `json:"login_url" yaml:"login_url"`
+
+	// Login form selectors, used when no LoginSteps are given.
+	// LoginFormSelector only matches while signed out.
+	UsernameSelector   string `json:"username_selector" yaml:"username_selector"`
+	PasswordSelector   string `json:"password_selector" yaml:"password_selector"`
+	SubmitSelector     string `json:"submit_selector" yaml:"submit_selector"`
+	LoginFormSelector  string `json:"login_form_selector" yaml:"login_form_selector"`
+	LoginErrorSelector string `json:"login_error_selector" yaml:"login_error_selector"`
+
+	// LoginSteps scripts a login form that does not fit the selectors above
+	LoginSteps []LoginStep `json:"login_steps" yaml:"login_steps"`
+
+	// Page that lists new articles, used when the source has no start URLs
+	CategoryURL string `json:"category_url" yaml:"category_url"`
+
+	// Sitemaps (sitemap indexes or URL sets, e.g. /wp-sitemap.xml) are read
+	// instead of rendering start pages. Regular runs take the entries
+	// modified within SitemapMaxAge (a duration, 7 days by default);
+	// backfills take them all.
+	Sitemaps      []string `json:"sitemaps" yaml:"sitemaps"`
+	SitemapMaxAge string   `json:"sitemap_max_age" yaml:"sitemap_max_age"`
+
+	// Article link filters: links must be on BaseURL, have at least
+	// MinPathSegments path segments, match one of IncludeLinks (regular
+	// expressions, when given) and contain none of ExcludeLinks
+	BaseURL         string   `json:"base_url" yaml:"base_url"`
+	MinPathSegments int      `json:"min_path_segments" yaml:"min_path_segments"`
+	IncludeLinks    []string `json:"include_links" yaml:"include_links"`
+	ExcludeLinks    []string `json:"exclude_links" yaml:"exclude_links"`
+
+	// Content overrides what readability finds on article pages
+	Content ContentSelectors `json:"content" yaml:"content"`
+
+	// Extractor is an extractor plugin (a program and its arguments) given
+	// each article page as JSON on stdin; the fields it returns take
+	// precedence over the content selectors and readability. Browser mode
+	// only.
+	Extractor []string `json:"extractor" yaml:"extractor"`
+
+	// Script is a Starlark script (a path, relative to the file setting it)
+	// defining login(page, username, password), which replaces the login
+	// steps, and extract(page), which returns fields like an extractor
+	// plugin. See script.go for the page's methods.
+	Script string `json:"script" yaml:"script"`
+
+	// DateFormats are Go time layouts for the published date text, tried
+	// before the built-in formats
+	DateFormats []string `json:"date_formats" yaml:"date_formats"`
+
+	// DateLocale selects the month names used in dates written out as text
+	// (e.g. "sv" for "9 maj 2025"), and MonthNames adds to or overrides
+	// them, mapping a name to its English equivalent ("" drops the word)
+	DateLocale string            `json:"date_locale" yaml:"date_locale"`
+	MonthNames map[string]string `json:"month_names" yaml:"month_names"`
+
+	// CategoryTags maps the site's categories, by name or slug (e.g.
+	// "malmo-ff" or "Krönika"), to the tags articles filed under them get;
+	// "" leaves a category out. Categories that are not listed become tags
+	// as they are named on the site.
+	CategoryTags map[string]string `json:"category_tags" yaml:"category_tags"`
+
+	// Calendar locates the site's fixtures page, which is scraped into the
+	// match calendar on every run
+	Calendar CalendarSelectors `json:"calendar" yaml:"calendar"`
+
+	include       []*regexp.Regexp
+	sitemapMaxAge time.Duration
+	script        *siteScript
+}
+
+// LoginStep is one action of a scripted login: typing Value into the field
+// matched by Fill, or clicking the element matched by Click. "{username}"
+// and "{password}" in Value are replaced with the source's credentials.
+type LoginStep struct {
+	Fill  string `json:"fill,omitempty" yaml:"fill"`
+	Value string `json:"value,omitempty" yaml:"value"`
+	Click string `json:"click,omitempty" yaml:"click"`
+}
+
+// ContentSelectors locate parts of an article page. Empty selectors leave
+// the part to readability.
+type ContentSelectors struct {
+	Body      string `json:"body" yaml:"body"` // readability runs on this element only
+	Title     string `json:"title" yaml:"title"`
+	Author    string `json:"author" yaml:"author"`
+	Published string `json:"published" yaml:"published"` // datetime/content attribute or text
+	Category  string `json:"category" yaml:"category"`   // every match is a category
+}
+
+// CalendarSelectors locate the matches on a fixtures page. Each element
+// matching Match is one match; the other selectors are looked up below it.
+// Teams is for sites that write both teams in one element, e.g. "Malmö FF -
+// AIK", and is used when Home and Away are not given.
+type CalendarSelectors struct {
+	URL         string `json:"url" yaml:"url"`
+	Match       string `json:"match" yaml:"match"`
+	Kickoff     string `json:"kickoff" yaml:"kickoff"` // datetime attribute or text
+	Home        string `json:"home" yaml:"home"`
+	Away        string `json:"away" yaml:"away"`
+	Teams       string `json:"teams" yaml:"teams"`
+	Competition string `json:"competition" yaml:"competition"`
+	Venue       string `json:"venue" yaml:"venue"`
+	Result      string `json:"result" yaml:"result"` // e.g. "2-1", once played
+}
+
+// siteProfile is a site profile file: a source configuration and its name
+type siteProfile struct {
+	Name string `yaml:"name"`
+}
+
+// DefaultSources returns the built-in configuration of every source
+func DefaultSources() map[string]SourceConfig {
+	return map[string]SourceConfig{
+		SourceGasetten: {
+			// WordPress login form
+			LoginURL:           "https://gasetten.se/min-profil/",
+			UsernameSelector:   `input[name="log"]`,
+			PasswordSelector:   `input[name="pwd"]`,
+			SubmitSelector:     `input[id="wp-submit"]`,
+			LoginFormSelector:  `form#loginform`,
+			LoginErrorSelector: `.login-error, #login_error, .error`,
+
+			// The Malmö FF category page has better article organization
+			CategoryURL: "https://gasetten.se/category/malmo-ff/",
+
+			// Dates are written out in Swedish, e.g. "9 maj 2025"
+			DateLocale: "sv",
+
+			// Articles live under a category, e.g. /malmo-ff/article-slug/
+			BaseURL:         "https://gasetten.se",
+			MinPathSegments: 2,
+			ExcludeLinks: []string{
+				"/author/", "/tag/", "/category/", "/page/", "/wp-content/", "/wp-login",
+				"/min-profil", "/about", "/arkiv", "/stotta-oss", "/annonsera", "/registrera", "/kop-plus",
+			},
+		},
+	}
+}
+
+// LoadSources returns the built-in source configuration with the site
+// profiles in profilesDir (*.yaml, *.yml) and then the overrides in the JSON
+// file at sourcesFile applied, e.g.
+//
+//	{"gasetten": {"submit_selector": "button[type=submit]"}}
+//
+// A profile or override for a known source only changes the fields it
+// sets; a profile with a new name adds a source. Empty paths are skipped.
+func LoadSources(profilesDir, sourcesFile string) (map[string]SourceConfig, error) {
+	sources := DefaultSources()
+
+	if profilesDir != "" {
+		if err := loadProfiles(profilesDir, sources); err != nil {
+			return nil, err
+		}
+	}
+
+	if sourcesFile != "" {
+		if err := loadOverrides(sourcesFile, sources); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, source := range sources {
+		if err := source.compile(); err != nil {
+			return nil, fmt.Errorf("source %q: %w", name, err)
+		}
+		sources[name] = source
+	}
+	return sources, nil
+}
+
+// loadProfiles applies the site profiles in dir. A missing directory has no
+// profiles.
+func loadProfiles(dir string, sources map[string]SourceConfig) error {
+	var files []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return fmt.Errorf("failed to list site profiles: %w", err)
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read site profile: %w", err)
+		}
+
+		// Profiles are named in the file or after it
+		var profile siteProfile
+		if err := yaml.Unmarshal(data, &profile); err != nil {
+			return fmt.Errorf("failed to parse site profile %s: %w", filepath.Base(file), err)
+		}
+		name := profile.Name
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		}
+
+		source, known := sources[name]
+		if err := yaml.Unmarshal(data, &source); err != nil {
+			return fmt.Errorf("failed to parse site profile %s: %w", filepath.Base(file), err)
+		}
+		if source.BaseURL == "" {
+			return fmt.Errorf("site profile %s has no base_url", filepath.Base(file))
+		}
+		source.Script = resolvePath(dir, source.Script)
+		sources[name] = source
+
+		if known {
+			logging.Infof("Loaded site profile %s (overrides built-in %s)", filepath.Base(file), name)
+		} else {
+			logging.Infof("Loaded site profile %s for source %s", filepath.Base(file), name)
+		}
+	}
+	return nil
+}
+
+// loadOverrides applies the JSON sources file at path to known sources
+func loadOverrides(path string, sources map[string]SourceConfig) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read sources file: %w", err)
+	}
+	var overrides map[string]json.RawMessage
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return fmt.Errorf("failed to parse sources file: %w", err)
+	}
+
+	for name, raw := range overrides {
+		source, ok := sources[name]
+		if !ok {
+			return fmt.Errorf("unknown source %q in sources file", name)
+		}
+		if err := json.Unmarshal(raw, &source); err != nil {
+			return fmt.Errorf("failed to parse source %q: %w", name, err)
+		}
+		source.Script = resolvePath(filepath.Dir(path), source.Script)
+		sources[name] = source
+	}
+	return nil
+}
+
+// compile checks the configuration and prepares its link patterns
+func (c *SourceConfig) compile() error {
+	c.include = nil
+	for _, pattern := range c.IncludeLinks {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid include_links pattern %q: %w", pattern, err)
+		}
+		c.include = append(c.include, re)
+	}
+	switch c.Mode {
+	case "", ModeBrowser, ModeWordPress:
+	default:
+		return fmt.Errorf("unsupported mode %q", c.Mode)
+	}
+	c.sitemapMaxAge = DefaultSitemapMaxAge
+	if c.SitemapMaxAge != "" {
+		maxAge, err := time.ParseDuration(c.SitemapMaxAge)
+		if err != nil || maxAge <= 0 {
+			return fmt.Errorf("invalid sitemap_max_age %q, expected e.g. 72h", c.SitemapMaxAge)
+		}
+		c.sitemapMaxAge = maxAge
+	}
+	for _, sitemap := range c.Sitemaps {
+		if u, err := url.Parse(sitemap); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("invalid sitemap URL %q", sitemap)
+		}
+	}
+	if len(c.Extractor) > 0 {
+		if _, err := exec.LookPath(c.Extractor[0]); err != nil {
+			return fmt.Errorf("invalid extractor: %w", err)
+		}
+	}
+	c.script = nil
+	if c.Script != "" {
+		script, err := loadScript(c.Script)
+		if err != nil {
+			return err
+		}
+		c.script = script
+	}
+	if c.DateLocale != "" && !extract.SupportedLocale(c.DateLocale) {
+		return fmt.Errorf("unsupported date_locale %q", c.DateLocale)
+	}
+	if len(c.MonthNames) > 0 {
+		names := make(map[string]string, len(c.MonthNames))
+		for name, english := range c.MonthNames {
+			names[strings.ToLower(name)] = english
+		}
+		c.MonthNames = names
+	}
+	if len(c.CategoryTags) > 0 {
+		rules := make(map[string]string, len(c.CategoryTags))
+		for category, tag := range c.CategoryTags {
+			rules[categorySlug(category)] = strings.TrimSpace(tag)
+		}
+		c.CategoryTags = rules
+	}
+	if err := c.Calendar.check(); err != nil {
+		return err
+	}
+	if c.LoginURL != "" && c.LoginFormSelector == "" {
+		return fmt.Errorf("login_url needs a login_form_selector to tell when signed in")
+	}
+	for _, step := range c.LoginSteps {
+		if (step.Fill == "") == (step.Click == "") {
+			return fmt.Errorf("each login step needs exactly one of fill or click")
+		}
+	}
+	return nil
+}
+
+// extractor returns the source's extractor plugin, or nil
+func (c *SourceConfig) extractor() *extract.Plugin {
+	if len(c.Extractor) == 0 {
+		return nil
+	}
+	return &extract.Plugin{Command: c.Extractor, Timeout: PageTimeout}
+}
+
+// loginSteps returns the scripted login, or the steps filling in the
+// username and password selectors and clicking submit
+func (c *SourceConfig) loginSteps() []LoginStep {
+	if len(c.LoginSteps) > 0 {
+		return c.LoginSteps
+	}
+	return []LoginStep{
+		{Fill: c.UsernameSelector, Value: "{username}"},
+		{Fill: c.PasswordSelector, Value: "{password}"},
+		{Click: c.SubmitSelector},
+	}
+}
+
+// articleLink returns href as an absolute article URL, or "" when the
+// source's link filters reject it
+func (c *SourceConfig) articleLink(href string) string {
+	base := strings.TrimSuffix(c.BaseURL, "/")
+
+	// Convert relative URLs to absolute
+	if strings.HasPrefix(href, "/") {
+		href = base + href
+	}
+
+	// Skip links to other sites, navigation, author, tag and category pages
+	path, ok := strings.CutPrefix(href, base+"/")
+	if !ok {
+		return ""
+	}
+	for _, exclude := range c.ExcludeLinks {
+		if strings.Contains(href, exclude) {
+			return ""
+		}
+	}
+	if len(c.include) > 0 && !matchesAny(c.include, href) {
+		return ""
+	}
+
+	// Articles are nested below a section (e.g. /malmo-ff/article-slug/)
+	path = strings.TrimSuffix(path, "/")
+	if path == "" || path == "#" || len(strings.Split(path, "/")) < c.MinPathSegments {
+		return ""
+	}
+	return href
+}
+
+// owns reports whether link is on the source's site
+func (c *SourceConfig) owns(link string) bool {
+	return strings.HasPrefix(link, strings.TrimSuffix(c.BaseURL, "/")+"/")
+}
+
+// resolveCredentials looks up the username and password a credentials
+// reference points to: "env:NAME" reads NAME_USER and NAME_PASS,
+// "store:NAME" the credentials stored encrypted as NAME, and "vault:PATH"
+// the username and password of a Vault secret
+func (s *Scraper) resolveCredentials(ctx context.Context, ref string) (string, string, error) {
+	if name, ok := strings.CutPrefix(ref, "store:"); ok && name != "" {
+		return s.storedCredentials(ctx, name)
+	}
+	if path, _, ok := vault.ParseRef(ref); ok {
+		if s.vault == nil {
+			return "", "", fmt.Errorf("no vault configured for %s; set VAULT_ADDR", ref)
+		}
+		return s.vault.Credentials(ctx, path)
+	}
+	prefix, ok := strings.CutPrefix(ref, "env:")
+	if !ok || prefix == "" {
+		return "", "", fmt.Errorf("unsupported credentials reference %q", ref)
+	}
+	username, err := config.Secret(prefix + "_USER")
+	if err != nil {
+		return "", "", err
+	}
+	password, err := config.Secret(prefix + "_PASS")
+	if err != nil {
+		return "", "", err
+	}
+	if username == "" || password == "" {
+		return "", "", fmt.Errorf("%s_USER and %s_PASS are not set", prefix, prefix)
+	}
+	return username, password, nil
+}
+
+// storedCredentials returns the credentials stored encrypted as name
+func (s *Scraper) storedCredentials(ctx context.Context, name string) (string, string, error) {
+	if s.credentials == nil {
+		return "", "", credentials.ErrNoKey
+	}
+	username, password, err := s.credentials.Get(ctx, name)
+	if errors.Is(err, database.ErrCredentialNotFound) {
+		return "", "", fmt.Errorf("no credentials stored as %s; add them with kiln credentials set %s", name, name)
+	}
+	return username, password, err
+}
+
+// resolvePath returns path relative to dir, unless it is empty or absolute
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+func matchesAny(patterns []*regexp.Regexp, s string) bool {
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package scraper
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tkilaker/kiln/internal/database"
+)
+
+// MinArticleWords is the word count below which extracted content is treated
+// as a teaser rather than a full article
+const MinArticleWords = 80
+
+// teaserMarkers are phrases Gasetten shows in place of locked content
+var teaserMarkers = []string{
+	"logga in för att läsa",
+	"logga in för att fortsätta läsa",
+	"logga in för att ta del",
+	"bli medlem för att läsa",
+	"för att läsa hela artikeln",
+	"endast för medlemmar",
+	"endast för prenumeranter",
+}
+
+// teaserReason explains why an article's content looks like a paywall
+// teaser, or returns "" when it looks complete
+func teaserReason(article *database.Article) string {
+	text := ""
+	if article.ContentText != nil {
+		text = strings.ToLower(*article.ContentText)
+	}
+
+	for _, marker := range teaserMarkers {
+		if strings.Contains(text, marker) {
+			return fmt.Sprintf("content contains %q", marker)
+		}
+	}
+	if words := database.CountWords(article.ContentText); words < MinArticleWords {
+		return fmt.Sprintf("content has only %d words", words)
+	}
+	return ""
+}
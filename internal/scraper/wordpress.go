@@ -0,0 +1,303 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/pkg/extract"
+	"golang.org/x/net/html"
+)
+
+const (
+	// wordPressPageSize is the most posts the REST API returns per page
+	wordPressPageSize = 100
+
+	// wordPressDateLayout is the layout of the API's date_gmt fields
+	wordPressDateLayout = "2006-01-02T15:04:05"
+)
+
+// wordPressPost is the part of a /wp-json/wp/v2/posts item Kiln uses
+type wordPressPost struct {
+	ID      int                 `json:"id"`
+	Link    string              `json:"link"`
+	DateGMT string              `json:"date_gmt"`
+	Title   wordPressRendered   `json:"title"`
+	Content wordPressRendered   `json:"content"`
+	Embeds  wordPressPostEmbeds `json:"_embedded"`
+}
+
+type wordPressPostEmbeds struct {
+	Author []struct {
+		Name string `json:"name"`
+	} `json:"author"`
+	FeaturedMedia []struct {
+		SourceURL string `json:"source_url"`
+	} `json:"wp:featuredmedia"`
+	Terms [][]struct {
+		Taxonomy string `json:"taxonomy"`
+		Name     string `json:"name"`
+	} `json:"wp:term"`
+}
+
+type wordPressComment struct {
+	AuthorName string            `json:"author_name"`
+	DateGMT    string            `json:"date_gmt"`
+	Content    wordPressRendered `json:"content"`
+}
+
+type wordPressRendered struct {
+	Rendered string `json:"rendered"`
+}
+
+// wordPressAPI calls a site's REST API with the browser's cookies, so
+// members-only content comes back as it does when signed in
+type wordPressAPI struct {
+	base   string
+	client *http.Client
+	nonce  string // REST nonce of the signed in user, "" when signed out
+}
+
+// wordPressClient returns a REST API client for the source. Cookie
+// authentication needs a nonce, which WordPress hands out to signed in users
+// at admin-ajax.php?action=rest-nonce.
+func (s *Scraper) wordPressClient(ctx context.Context, source *SourceConfig) (*wordPressAPI, error) {
+	base, err := url.Parse(source.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base_url: %w", err)
+	}
+	api := &wordPressAPI{
+		base:   strings.TrimSuffix(source.BaseURL, "/"),
+		client: &http.Client{Timeout: PageTimeout, Jar: s.browserCookies(base)},
+	}
+
+	if source.LoginURL != "" {
+		body, _, err := api.get(ctx, "/wp-admin/admin-ajax.php?action=rest-nonce")
+		nonce := strings.TrimSpace(string(body))
+		if err != nil || nonce == "" || nonce == "0" || nonce == "-1" {
+			logging.Warnf("No REST nonce from %s, members-only content may be missing: %v", api.base, err)
+		} else {
+			api.nonce = nonce
+		}
+	}
+	return api, nil
+}
+
+// get requests a path on the site and returns the response body and headers
+func (api *wordPressAPI) get(ctx context.Context, path string) ([]byte, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.base+path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if api.nonce != "" {
+		req.Header.Set("X-WP-Nonce", api.nonce)
+	}
+	resp, err := api.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSitemapSize))
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("%s returned %s", path, resp.Status)
+	}
+	return body, resp.Header, nil
+}
+
+// getJSON requests a path on the site and decodes the JSON response into v
+func (api *wordPressAPI) getJSON(ctx context.Context, path string, v any) (http.Header, error) {
+	body, header, err := api.get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return header, nil
+}
+
+// collectWordPressLinks lists article links through the REST API: the
+// latest page of posts, or every post when backfilling
+func (s *Scraper) collectWordPressLinks(ctx context.Context, source *SourceConfig, backfill bool) ([]string, error) {
+	progressOf(ctx).UpdateStatus(StatusScraping, "Listing posts from the WordPress API...")
+
+	api, err := s.wordPressClient(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []string
+	seen := make(map[string]bool)
+	for page, pages := 1, 1; page <= pages; page++ {
+		var posts []wordPressPost
+		header, err := api.getJSON(ctx, fmt.Sprintf("/wp-json/wp/v2/posts?per_page=%d&page=%d&_fields=link", wordPressPageSize, page), &posts)
+		if err != nil {
+			progressOf(ctx).UpdateStatus(StatusFailed, "Failed to list posts")
+			return nil, fmt.Errorf("failed to list posts: %w", err)
+		}
+		for _, post := range posts {
+			link := source.articleLink(post.Link)
+			if link != "" && !seen[link] {
+				seen[link] = true
+				links = append(links, link)
+			}
+		}
+		if backfill {
+			pages, _ = strconv.Atoi(header.Get("X-WP-TotalPages"))
+		}
+	}
+
+	logging.Infof("Listed %d article links from the WordPress API", len(links))
+	return links, nil
+}
+
+// fetchWordPressArticle loads an article and its comments through the REST
+// API, looking the post up by the slug in its URL
+func (s *Scraper) fetchWordPressArticle(ctx context.Context, name string, source *SourceConfig, articleURL string) (*database.Article, []*database.Comment, error) {
+	parsedURL, err := url.Parse(articleURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	api, err := s.wordPressClient(ctx, source)
+	if err != nil {
+		return nil, nil, err
+	}
+	post, raw, err := api.postFor(ctx, parsedURL, "&_embed=author,wp:featuredmedia,wp:term")
+	if err != nil {
+		return nil, nil, err
+	}
+	s.archivePage(ctx, articleURL, "application/json", raw)
+
+	content, err := extract.AbsolutizeURLs(post.Content.Rendered, parsedURL)
+	if err != nil {
+		logging.Warnf("Failed to rewrite relative URLs for %s: %v", articleURL, err)
+		content = post.Content.Rendered
+	}
+	text := extract.Text(content)
+
+	article := &database.Article{
+		Source:      name,
+		URL:         articleURL,
+		ContentHTML: &content,
+		ContentText: &text,
+	}
+	title := extract.Text(post.Title.Rendered)
+	if title != "" {
+		article.Title = &title
+	}
+	if len(post.Embeds.Author) > 0 && post.Embeds.Author[0].Name != "" {
+		article.Author = &post.Embeds.Author[0].Name
+	}
+	if len(post.Embeds.FeaturedMedia) > 0 && post.Embeds.FeaturedMedia[0].SourceURL != "" {
+		article.ImageURL = &post.Embeds.FeaturedMedia[0].SourceURL
+	}
+	if audioURL, audioType := extract.FindAudio("", content, parsedURL); audioURL != "" {
+		article.AudioURL, article.AudioType = &audioURL, &audioType
+	}
+	if published, err := time.Parse(wordPressDateLayout, post.DateGMT); err == nil {
+		article.PublishedAt = &published
+	}
+	// Terms come grouped by taxonomy; tags are left to Kiln's own tagging
+	for _, terms := range post.Embeds.Terms {
+		for _, term := range terms {
+			if term.Taxonomy == "category" {
+				article.Categories = appendCategory(article.Categories, html.UnescapeString(term.Name))
+			}
+		}
+	}
+
+	logging.Debugf("WordPress API returned: title='%s', content=%d chars, text=%d chars", title, len(content), len(text))
+
+	if s.media != nil {
+		s.mirrorImages(ctx, article, parsedURL)
+	}
+
+	var comments []*database.Comment
+	if s.comments {
+		comments, err = fetchWordPressComments(ctx, api, post.ID)
+		if err != nil {
+			logging.Warnf("Failed to fetch comments for %s: %v", articleURL, err)
+			comments = nil
+		}
+	}
+	return article, comments, nil
+}
+
+// postFor looks up the post at an article URL by its slug, and returns it
+// with the API response it came in
+func (api *wordPressAPI) postFor(ctx context.Context, articleURL *url.URL, params string) (*wordPressPost, []byte, error) {
+	slug := path.Base(strings.TrimSuffix(articleURL.Path, "/"))
+
+	body, _, err := api.get(ctx, "/wp-json/wp/v2/posts?slug="+url.QueryEscape(slug)+params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch post: %w", err)
+	}
+	var posts []wordPressPost
+	if err := json.Unmarshal(body, &posts); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse post: %w", err)
+	}
+	if len(posts) == 0 {
+		return nil, nil, fmt.Errorf("no post with slug %q", slug)
+	}
+	return &posts[0], body, nil
+}
+
+// wordPressComments fetches the current comment thread of an article
+func (s *Scraper) wordPressComments(ctx context.Context, source *SourceConfig, articleURL string) ([]*database.Comment, error) {
+	parsedURL, err := url.Parse(articleURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	api, err := s.wordPressClient(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	post, _, err := api.postFor(ctx, parsedURL, "&_fields=id")
+	if err != nil {
+		return nil, err
+	}
+	return fetchWordPressComments(ctx, api, post.ID)
+}
+
+// fetchWordPressComments returns the approved comments of a post, oldest
+// first and with replies flattened
+func fetchWordPressComments(ctx context.Context, api *wordPressAPI, postID int) ([]*database.Comment, error) {
+	comments := []*database.Comment{}
+	for page, pages := 1, 1; page <= pages; page++ {
+		var items []wordPressComment
+		header, err := api.getJSON(ctx, fmt.Sprintf("/wp-json/wp/v2/comments?post=%d&order=asc&per_page=%d&page=%d", postID, wordPressPageSize, page), &items)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			body := extract.Text(item.Content.Rendered)
+			if body == "" {
+				continue
+			}
+			comment := &database.Comment{Body: body}
+			if item.AuthorName != "" {
+				comment.Author = &item.AuthorName
+			}
+			if posted, err := time.Parse(wordPressDateLayout, item.DateGMT); err == nil {
+				comment.PostedAt = &posted
+			}
+			comments = append(comments, comment)
+		}
+		pages, _ = strconv.Atoi(header.Get("X-WP-TotalPages"))
+	}
+	return comments, nil
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/i18n"
+	"github.com/tkilaker/kiln/internal/scraper"
+)
+
+// handleAddArticle scrapes a single article URL submitted from the articles
+// page as a job of its own
+func (s *Server) handleAddArticle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	articleURL, err := scraper.NormalizeArticleURL(r.FormValue("url"))
+	if err != nil {
+		writeNotice(w, "red", i18n.Tf(ctx, "Cannot add article: %v", err))
+		return
+	}
+
+	existing, err := s.db.GetArticleByURL(ctx, articleURL)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to check article: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if existing != nil {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, `<div class="p-4 bg-yellow-100 border border-yellow-400 text-yellow-700 rounded">
+			%s <a href="%s" class="underline">%s</a>
+		</div>`, html.EscapeString(i18n.T(ctx, "This article is already saved:")), html.EscapeString(articlePath(existing)), html.EscapeString(getTitle(existing)))
+		return
+	}
+
+	s.startBackgroundScrape(w, r, "scrape of "+articleURL, scraper.JobRequest{Kind: scraper.JobURLs, Trigger: database.RunTriggerURL, URLs: []string{articleURL}})
+}
+
+// maxBatchURLs caps the number of URLs accepted by one batch import
+const maxBatchURLs = 500
+
+// maxBatchUploadSize caps the size of an uploaded URL list
+const maxBatchUploadSize = 1 << 20
+
+// handleBatchImport scrapes a pasted or uploaded list of URLs (one per line)
+// as a single run. Invalid and duplicate lines are reported and dropped.
+func (s *Server) handleBatchImport(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBatchUploadSize+4096)
+	if err := r.ParseMultipartForm(maxBatchUploadSize); err != nil && err != http.ErrNotMultipart {
+		writeNotice(w, "red", i18n.Tf(r.Context(), "Cannot read URL list: %v", err))
+		return
+	}
+
+	text := r.FormValue("urls")
+	if file, _, err := r.FormFile("file"); err == nil {
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			writeNotice(w, "red", i18n.Tf(r.Context(), "Cannot read uploaded file: %v", err))
+			return
+		}
+		text += "\n" + string(data)
+	}
+
+	urls, invalid := parseURLList(text)
+	if len(urls) == 0 {
+		writeNotice(w, "red", i18n.Tf(r.Context(), "No valid article URLs found (%d lines rejected).", len(invalid)))
+		return
+	}
+	if len(urls) > maxBatchURLs {
+		writeNotice(w, "red", i18n.Tf(r.Context(), "Too many URLs (%d); import at most %d at a time.", len(urls), maxBatchURLs))
+		return
+	}
+
+	if len(invalid) > 0 {
+		shown := invalid[:min(len(invalid), 5)]
+		writeNotice(w, "yellow", i18n.Tf(r.Context(), "Ignored %d invalid lines: %s", len(invalid), strings.Join(shown, ", ")))
+	}
+
+	s.startBackgroundScrape(w, r, fmt.Sprintf("batch import of %d URLs", len(urls)), scraper.JobRequest{Kind: scraper.JobURLs, Trigger: database.RunTriggerBatch, URLs: urls})
+}
+
+// parseURLList normalizes one URL per line, dropping blank lines, comments
+// (#) and duplicates. Lines that are not article URLs are returned as
+// invalid.
+func parseURLList(text string) (urls, invalid []string) {
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		u, err := scraper.NormalizeArticleURL(line)
+		if err != nil {
+			invalid = append(invalid, line)
+			continue
+		}
+		if !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	return urls, invalid
+}
+
+// writeNotice writes a colored notice box for HTMX to swap in
+func writeNotice(w http.ResponseWriter, color, message string) {
+	w.Header().Set("Content-Type", "text/html")
+	fmt.Fprintf(w, `<div class="p-4 bg-%[1]s-100 border border-%[1]s-400 text-%[1]s-700 rounded">%[2]s</div>`,
+		color, html.EscapeString(message))
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/i18n"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+// maxWatchTermLength bounds a watch term, which is a word or a name
+const maxWatchTermLength = 100
+
+// handleAlerts lists the watch terms
+func (s *Server) handleAlerts(w http.ResponseWriter, r *http.Request) {
+	s.renderAlerts(w, r, http.StatusOK, "")
+}
+
+// handleCreateWatchTerm adds the submitted watch term
+func (s *Server) handleCreateWatchTerm(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		s.renderAlerts(w, r, http.StatusBadRequest, i18n.T(r.Context(), "Invalid form"))
+		return
+	}
+	term := &database.WatchTerm{Term: strings.Join(strings.Fields(r.PostForm.Get("term")), " ")}
+	switch {
+	case term.Term == "":
+		s.renderAlerts(w, r, http.StatusBadRequest, i18n.T(r.Context(), "Enter a word or name to watch for"))
+		return
+	case len(term.Term) > maxWatchTermLength:
+		s.renderAlerts(w, r, http.StatusBadRequest, i18n.Tf(r.Context(), "Watch terms are at most %d characters", maxWatchTermLength))
+		return
+	}
+
+	err := s.db.CreateWatchTerm(r.Context(), term)
+	if errors.Is(err, database.ErrWatchTermExists) {
+		s.renderAlerts(w, r, http.StatusConflict, fmt.Sprintf("%q is %v", term.Term, err))
+		return
+	}
+	if err != nil {
+		s.renderAlerts(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	logging.Infof("Watching for %q", term.Term)
+	s.audit(r, database.AuditWatchCreate, term.Term, term)
+	http.Redirect(w, r, "/alerts", http.StatusSeeOther)
+}
+
+// handleDeleteWatchTerm stops watching for a term
+func (s *Server) handleDeleteWatchTerm(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid watch term ID", http.StatusBadRequest)
+		return
+	}
+
+	term, err := s.db.DeleteWatchTerm(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to delete watch term: %v", err), http.StatusNotFound)
+		return
+	}
+
+	logging.Infof("Stopped watching for %q", term.Term)
+	s.audit(r, database.AuditWatchDelete, term.Term, term)
+
+	// Return empty response for HTMX to remove the row
+	w.WriteHeader(http.StatusOK)
+}
+
+// renderAlerts renders the watch terms with an error from the form, if any
+func (s *Server) renderAlerts(w http.ResponseWriter, r *http.Request, status int, formError string) {
+	terms, err := s.db.GetWatchTerms(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch watch terms: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(status)
+	AlertsPage(terms, s.config.NotifyWebhookURL != "", formError).Render(r.Context(), w)
+}
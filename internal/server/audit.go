@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/tkilaker/kiln/internal/auth"
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+// auditPageSize is how many entries the audit log page shows
+const auditPageSize = 200
+
+// auditActions are the actions the audit log page can be filtered by
+var auditActions = []string{
+	database.AuditArticleDelete,
+	database.AuditArticleEdit,
+	database.AuditArticleRescrape,
+	database.AuditArticlesClear,
+	database.AuditArticlesPrune,
+	database.AuditSourceCreate,
+	database.AuditSourceUpdate,
+	database.AuditSourceDelete,
+	database.AuditSearchCreate,
+	database.AuditSearchDelete,
+	database.AuditWatchCreate,
+	database.AuditWatchDelete,
+}
+
+// articleAudit is what the audit log keeps of an article acted on
+type articleAudit struct {
+	UUID        string `json:"uuid"`
+	Title       string `json:"title,omitempty"`
+	Source      string `json:"source"`
+	PublishedAt string `json:"published_at,omitempty"`
+}
+
+func newArticleAudit(article *database.Article) articleAudit {
+	a := articleAudit{UUID: article.UUID, Source: article.Source}
+	if article.Title != nil {
+		a.Title = *article.Title
+	}
+	if article.PublishedAt != nil {
+		a.PublishedAt = article.PublishedAt.Format(editDateLayout)
+	}
+	return a
+}
+
+// audit records an operation done through r in the audit log, with the
+// signed in user as the actor. A failure to record it is logged; the
+// operation has happened either way.
+func (s *Server) audit(r *http.Request, action, target string, details any) {
+	actor := "anonymous"
+	var method *string
+	if p := auth.FromContext(r.Context()); p != nil {
+		actor = p.Subject
+		m := string(p.Method)
+		method = &m
+	}
+
+	entry, err := database.NewAuditEntry(actor, action, target, details)
+	if err != nil {
+		logging.Warnf("Failed to record %s in the audit log: %v", action, err)
+		return
+	}
+	entry.AuthMethod = method
+
+	// RemoteAddr is the client a trusted proxy forwarded for, if any
+	addr := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	entry.RemoteAddr = &addr
+
+	if err := s.db.CreateAuditEntry(context.WithoutCancel(r.Context()), entry); err != nil {
+		logging.Warnf("Failed to record %s in the audit log: %v", action, err)
+	}
+}
+
+// handleAudit renders the audit log, optionally only the entries of the
+// action in ?action=
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	action := r.URL.Query().Get("action")
+
+	entries, err := s.db.GetAuditEntries(ctx, action, auditPageSize)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch audit log: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		writeJSON(w, http.StatusOK, entries)
+		return
+	}
+	AuditPage(entries, auditActions, action).Render(ctx, w)
+}
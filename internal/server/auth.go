@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/tkilaker/kiln/internal/auth"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+// setupAuth builds the authentication chain from the configuration. Password
+// sessions, Basic auth, API keys, feed tokens and OIDC are each enabled by
+// their config.
+func (s *Server) setupAuth(ctx context.Context) error {
+	secret := []byte(s.config.AuthSecret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		rand.Read(secret)
+		logging.Warnf("AUTH_SECRET is not set; sessions, feed tokens and share links will change on restart")
+	}
+
+	secure := strings.HasPrefix(s.config.FeedLink, "https://")
+	s.sessions = auth.NewSessions(secret, s.config.SessionTTL, secure)
+	s.feedTokens = auth.NewFeedTokens(secret)
+	s.readerTokens = auth.NewReaderTokens(secret)
+	s.shareLinks = auth.NewShareLinks(secret)
+	s.auth = auth.NewChain("/login")
+
+	if !s.config.AuthEnabled() {
+		return nil
+	}
+
+	if s.config.AuthPassword != "" {
+		s.passwordLogin = auth.NewPasswordLogin(s.config.AuthUser, s.config.AuthPassword)
+		s.auth.Add(auth.NewSessionAuthenticator(s.sessions, auth.MethodSession))
+	}
+
+	if s.config.BasicAuthPass != "" {
+		s.auth.Add(auth.NewBasicAuthenticator(s.config.BasicAuthUser, s.config.BasicAuthPass))
+	}
+
+	if len(s.config.APIKeys) > 0 {
+		s.apiKeys = auth.NewAPIKeyAuthenticator(s.config.APIKeys)
+		s.auth.Add(s.apiKeys)
+	}
+
+	if s.config.OIDCIssuer != "" {
+		oidc, err := auth.NewOIDC(ctx, auth.OIDCConfig{
+			Issuer:        s.config.OIDCIssuer,
+			ClientID:      s.config.OIDCClientID,
+			ClientSecret:  s.config.OIDCClientSecret,
+			RedirectURL:   strings.TrimSuffix(s.config.FeedLink, "/") + "/auth/oidc/callback",
+			AllowedEmails: s.config.OIDCAllowedEmails,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set up OIDC: %w", err)
+		}
+		s.oidc = oidc
+		s.auth.Add(auth.NewSessionAuthenticator(s.sessions, auth.MethodOIDC))
+		s.auth.Add(oidc)
+	}
+
+	// Feed tokens let readers of the other methods' users fetch feeds, and
+	// reader tokens let them use the Google Reader API
+	s.auth.Add(s.feedTokens)
+	s.auth.Add(s.readerTokens)
+
+	logging.Infof("Authentication enabled")
+	return nil
+}
+
+// handleLoginPage renders the login form
+func (s *Server) handleLoginPage(w http.ResponseWriter, r *http.Request) {
+	LoginPage(safeNext(r.URL.Query().Get("next")), "", s.passwordLogin != nil, s.oidc != nil).Render(r.Context(), w)
+}
+
+// handleLogin verifies the submitted credentials and issues a session
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	next := safeNext(r.FormValue("next"))
+
+	if s.passwordLogin == nil || !s.passwordLogin.Check(r.FormValue("username"), r.FormValue("password")) {
+		logging.Warnf("Failed login attempt for %q", r.FormValue("username"))
+		w.WriteHeader(http.StatusUnauthorized)
+		LoginPage(next, "Invalid username or password", s.passwordLogin != nil, s.oidc != nil).Render(r.Context(), w)
+		return
+	}
+
+	s.sessions.Issue(w, &auth.Principal{Subject: s.config.AuthUser, Method: auth.MethodSession})
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// handleLogout clears the session
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	s.sessions.Clear(w)
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// handleOIDCLogin starts the OIDC authorization code flow
+func (s *Server) handleOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	if s.oidc == nil {
+		http.NotFound(w, r)
+		return
+	}
+	s.oidc.StartLogin(w, r, safeNext(r.URL.Query().Get("next")))
+}
+
+// handleOIDCCallback completes the OIDC login and issues a session
+func (s *Server) handleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if s.oidc == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	principal, next, err := s.oidc.FinishLogin(r)
+	if err != nil {
+		logging.Warnf("OIDC login failed: %v", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		LoginPage("/", "Single sign-on failed", s.passwordLogin != nil, true).Render(r.Context(), w)
+		return
+	}
+
+	s.sessions.Issue(w, principal)
+	http.Redirect(w, r, safeNext(next), http.StatusSeeOther)
+}
+
+// safeNext only allows local redirect targets after login
+func safeNext(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/i18n"
+)
+
+// pageCacheTTL bounds how long a page is served from the cache, covering
+// writes the process cannot see (e.g. another instance on the same database)
+const pageCacheTTL = 5 * time.Minute
+
+// pageCache keeps rendered pages in memory. Entries are tagged with the
+// article version of the database and become stale as soon as any article
+// is written.
+type pageCache struct {
+	db      *database.DB
+	maxSize int
+
+	mu      sync.Mutex
+	entries map[string]*cachedPage
+}
+
+type cachedPage struct {
+	version uint64
+	stored  time.Time
+	header  http.Header
+	body    []byte
+}
+
+// newPageCache creates a cache holding up to maxSize pages; a size of zero
+// disables caching
+func newPageCache(db *database.DB, maxSize int) *pageCache {
+	return &pageCache{db: db, maxSize: maxSize, entries: make(map[string]*cachedPage)}
+}
+
+// Middleware serves successful GET responses from the cache
+func (c *pageCache) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c.maxSize <= 0 || r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Pages differ for signed-in users, HTMX partial requests and UI
+		// languages
+		key := strconv.FormatBool(signedIn(r.Context())) + "|" + r.Header.Get("HX-Request") + "|" + i18n.Language(r.Context()) + "|" + r.URL.RequestURI()
+		version := c.db.ArticlesVersion()
+
+		if page := c.get(key, version); page != nil {
+			for k, v := range page.header {
+				w.Header()[k] = v
+			}
+			w.Header().Set("X-Cache", "HIT")
+			w.Write(page.body)
+			return
+		}
+
+		rec := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		if rec.status == http.StatusOK {
+			c.put(key, &cachedPage{version: version, stored: time.Now(), header: rec.header.Clone(), body: rec.body.Bytes()})
+		}
+
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		w.Header().Set("X-Cache", "MISS")
+		w.WriteHeader(rec.status)
+		w.Write(rec.body.Bytes())
+	})
+}
+
+func (c *pageCache) get(key string, version uint64) *cachedPage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	page, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if page.version != version || time.Since(page.stored) > pageCacheTTL {
+		delete(c.entries, key)
+		return nil
+	}
+	return page
+}
+
+func (c *pageCache) put(key string, page *cachedPage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Drop stale pages first; if still full, start over
+	if len(c.entries) >= c.maxSize {
+		for k, p := range c.entries {
+			if p.version != page.version {
+				delete(c.entries, k)
+			}
+		}
+	}
+	if len(c.entries) >= c.maxSize {
+		c.entries = make(map[string]*cachedPage)
+	}
+	c.entries[key] = page
+}
+
+// bufferedResponse collects a response so it can be cached before sending
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(status int) { b.status = status }
+
+func (b *bufferedResponse) Write(p []byte) (int, error) { return b.body.Write(p) }
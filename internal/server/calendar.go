@@ -0,0 +1,140 @@
+package server
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tkilaker/kiln/internal/auth"
+	"github.com/tkilaker/kiln/internal/database"
+)
+
+const (
+	// calendarHistory is how far back played matches stay in the calendar
+	calendarHistory = 90 * 24 * time.Hour
+
+	// matchDuration is the length of a match event, including half time
+	// and stoppage time
+	matchDuration = 2 * time.Hour
+)
+
+// icalEscaper escapes text values in an iCalendar file (RFC 5545 3.3.11)
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// handleCalendar serves the match calendar as an iCal feed, of every source
+// or only the one named by the source query parameter
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	s.feedAccess.count("calendar")
+	source := r.URL.Query().Get("source")
+
+	matches, err := s.db.GetMatches(r.Context(), source, time.Now().Add(-calendarHistory))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch matches: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	name := s.config.FeedTitle + " matches"
+	if source != "" {
+		name += " - " + source
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Write([]byte(generateCalendar(name, matches)))
+}
+
+// generateCalendar renders matches as an iCalendar file, one event per
+// match. Matches keep their UID when their kickoff moves to another time of
+// the same day, so calendar apps update them in place.
+func generateCalendar(name string, matches []*database.Match) string {
+	var b strings.Builder
+	line := func(property, value string) {
+		writeICalLine(&b, property+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//Kiln//Match calendar//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", icalEscaper.Replace(name))
+	for _, match := range matches {
+		summary := match.HomeTeam + " - " + match.AwayTeam
+		if match.Result != nil {
+			summary += " (" + *match.Result + ")"
+		}
+		start := match.Kickoff.UTC()
+
+		line("BEGIN", "VEVENT")
+		line("UID", matchUID(match))
+		line("DTSTAMP", match.UpdatedAt.UTC().Format("20060102T150405Z"))
+		if isDateOnly(match.Kickoff) {
+			// Fixtures without a time yet are all-day events
+			writeICalLine(&b, "DTSTART;VALUE=DATE:"+match.Kickoff.In(time.Local).Format("20060102"))
+		} else {
+			line("DTSTART", start.Format("20060102T150405Z"))
+			line("DTEND", start.Add(matchDuration).Format("20060102T150405Z"))
+		}
+		line("SUMMARY", icalEscaper.Replace(summary))
+		if match.Competition != nil {
+			line("DESCRIPTION", icalEscaper.Replace(*match.Competition))
+			line("CATEGORIES", icalEscaper.Replace(*match.Competition))
+		}
+		if match.Venue != nil {
+			line("LOCATION", icalEscaper.Replace(*match.Venue))
+		}
+		if match.URL != nil {
+			line("URL", *match.URL)
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return b.String()
+}
+
+// matchUID identifies a match across scrapes by its source, teams and
+// kickoff date
+func matchUID(match *database.Match) string {
+	key := strings.Join([]string{match.Source, match.HomeTeam, match.AwayTeam, match.Kickoff.Format("2006-01-02")}, "\x00")
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:10]) + "@kiln"
+}
+
+// isDateOnly reports whether a kickoff was scraped without a time of day
+func isDateOnly(t time.Time) bool {
+	t = t.In(time.Local)
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0
+}
+
+// writeICalLine writes a content line, folded so no line is longer than 75
+// octets (RFC 5545 3.1) without splitting a UTF-8 sequence
+func writeICalLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// calendarURL returns the absolute URL of the match calendar, carrying the
+// feed token when one is given
+func (s *Server) calendarURL(token string) string {
+	calendarURL := strings.TrimSuffix(s.config.FeedLink, "/") + "/calendar.ics"
+	if token != "" {
+		calendarURL += "?" + url.Values{auth.FeedTokenParam: {token}}.Encode()
+	}
+	return calendarURL
+}
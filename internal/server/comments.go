@@ -0,0 +1,30 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tkilaker/kiln/internal/database"
+)
+
+// handleRefreshComments scrapes an article's comment thread again and
+// responds with the updated comments section
+func (s *Server) handleRefreshComments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	article, _, err := s.resolveArticle(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Article not found: %v", err), http.StatusNotFound)
+		return
+	}
+
+	comments, err := s.scraper.RefreshComments(ctx, article)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to refresh comments: %v", err), http.StatusInternalServerError)
+		return
+	}
+	s.audit(r, database.AuditArticleRescrape, article.URL, map[string]any{"part": "comments", "comments": len(comments)})
+
+	CommentsSection(article, comments).Render(ctx, w)
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/andybalholm/brotli"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// compressionLevel balances CPU time against size for both gzip and brotli
+const compressionLevel = 5
+
+// compressibleTypes are the response types worth compressing
+var compressibleTypes = []string{
+	"text/html",
+	"text/plain",
+	"text/css",
+	"text/calendar",
+	"text/xml",
+	"application/xml",
+	"application/rss+xml",
+	"application/atom+xml",
+	"application/feed+json",
+	"application/json",
+	"application/javascript",
+}
+
+// compressor returns middleware that compresses responses with brotli or
+// gzip, depending on what the client accepts. Event streams and images are
+// passed through unchanged.
+func compressor() func(http.Handler) http.Handler {
+	c := middleware.NewCompressor(compressionLevel, compressibleTypes...)
+	c.SetEncoder("br", func(w io.Writer, level int) io.Writer {
+		return brotli.NewWriterLevel(w, level)
+	})
+	return c.Handler
+}
+
+// defaultHTML labels responses as HTML unless the handler sets another type.
+// Templ components do not set a Content-Type, and the compressor only
+// compresses responses whose type is known when the header is written, so
+// this must run inside it. The type is only filled in as the header is
+// written: handlers like http.ServeContent pick their own type when none
+// is set yet.
+func defaultHTML(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&htmlDefaultWriter{ResponseWriter: w}, r)
+	})
+}
+
+// htmlDefaultWriter sets the HTML Content-Type when the header is written
+// without one
+type htmlDefaultWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *htmlDefaultWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *htmlDefaultWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush lets event streams through
+func (w *htmlDefaultWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack lets WebSocket connections through
+func (w *htmlDefaultWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap gives http.ResponseController the wrapped writer
+func (w *htmlDefaultWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
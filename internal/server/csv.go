@@ -0,0 +1,96 @@
+package server
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+// csvPageSize is how many articles the CSV export fetches at a time
+const csvPageSize = 1000
+
+// csvHeader names the columns of the CSV export
+var csvHeader = []string{"id", "source", "url", "title", "author", "published_at", "word_count", "tags"}
+
+// handleAPIArticlesCSV exports the metadata of every article matching the
+// same filters as the articles API as CSV, most recently saved first. The
+// rows are written a page at a time, so large exports don't pile up in
+// memory.
+func (s *Server) handleAPIArticlesCSV(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := parseArticleFilter(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Fetch the first page before answering, so a failure can still be
+	// reported with an error status
+	articles, err := s.db.GetArticlesBefore(ctx, filter, database.ArticleCursor{}, csvPageSize)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("failed to fetch articles: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="kiln-articles.csv"`)
+
+	out := csv.NewWriter(w)
+	out.Write(csvHeader)
+	for len(articles) > 0 {
+		tags, err := s.db.GetTagsForArticles(ctx, articleIDs(articles))
+		if err != nil {
+			logging.Warnf("Failed to export articles as CSV: %v", err)
+			break
+		}
+		for _, article := range articles {
+			out.Write(csvRecord(article, tags[article.ID]))
+		}
+		if len(articles) < csvPageSize {
+			break
+		}
+
+		last := articles[len(articles)-1]
+		articles, err = s.db.GetArticlesBefore(ctx, filter, database.ArticleCursor{CreatedAt: last.CreatedAt, ID: last.ID}, csvPageSize)
+		if err != nil {
+			// The response has started; all that's left is to cut it short
+			logging.Warnf("Failed to export articles as CSV: %v", err)
+			break
+		}
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		logging.Warnf("Failed to write CSV export: %v", err)
+	}
+}
+
+// csvRecord is the CSV row of an article and its tags
+func csvRecord(article *database.Article, tags []string) []string {
+	var title, author, publishedAt string
+	if article.Title != nil {
+		title = *article.Title
+	}
+	if article.Author != nil {
+		author = *article.Author
+	}
+	if article.PublishedAt != nil {
+		publishedAt = article.PublishedAt.Format(time.RFC3339)
+	}
+	return []string{
+		articleRef(article),
+		article.Source,
+		article.URL,
+		title,
+		author,
+		publishedAt,
+		strconv.Itoa(article.WordCount),
+		strings.Join(tags, ";"),
+	}
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/internal/storage"
+)
+
+// handleDiagnostics serves a screenshot or the HTML captured of a page a
+// scrape failed on
+func (s *Server) handleDiagnostics(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	body, err := s.scraper.OpenDiagnostics(r.Context(), name)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		logging.Warnf("Failed to open diagnostics %s: %v", name, err)
+		http.Error(w, "Failed to load diagnostics", http.StatusInternalServerError)
+		return
+	}
+	defer body.Close()
+
+	// The captured HTML is Gasetten's page; keep its scripts from running
+	// on our origin
+	if path.Ext(name) == ".html" {
+		w.Header().Set("Content-Security-Policy", "sandbox")
+	}
+	// Captures are PNG screenshots or HTML; name the type for both ways of
+	// serving them below, as nosniff keeps browsers from guessing it
+	w.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(name)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	// Files on local disk are served with range and conditional requests;
+	// remote ones are streamed
+	if file, ok := body.(*os.File); ok {
+		var modTime time.Time
+		if info, err := file.Stat(); err == nil {
+			modTime = info.ModTime()
+		}
+		http.ServeContent(w, r, name, modTime, file)
+		return
+	}
+	io.Copy(w, body)
+}
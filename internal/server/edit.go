@@ -0,0 +1,90 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+// editDateLayout is the format of the published date in the edit form
+const editDateLayout = "2006-01-02"
+
+// handleEditArticle updates an article's title, author and published date.
+// Only fields present in the form are changed; an empty value clears it.
+func (s *Server) handleEditArticle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	article, _, err := s.resolveArticle(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Article not found: %v", err), http.StatusNotFound)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	before := newArticleAudit(article)
+	if err := applyArticleEdits(article, r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.UpdateArticleMetadata(ctx, article); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to update article: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	logging.Infof("Updated metadata of article %s", article.UUID)
+	s.audit(r, database.AuditArticleEdit, article.URL, map[string]any{"before": before, "after": newArticleAudit(article)})
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", articlePath(article))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	http.Redirect(w, r, articlePath(article), http.StatusSeeOther)
+}
+
+// applyArticleEdits copies the submitted title, author and published_at
+// fields onto the article
+func applyArticleEdits(article *database.Article, r *http.Request) error {
+	if _, ok := r.PostForm["title"]; ok {
+		article.Title = optionalString(r.PostForm.Get("title"))
+	}
+	if _, ok := r.PostForm["author"]; ok {
+		article.Author = optionalString(r.PostForm.Get("author"))
+	}
+	if _, ok := r.PostForm["published_at"]; ok {
+		value := strings.TrimSpace(r.PostForm.Get("published_at"))
+		if value == "" {
+			article.PublishedAt = nil
+			return nil
+		}
+		t, err := time.ParseInLocation(editDateLayout, value, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid published date %q", value)
+		}
+		// Keep the time of day when only the date changed
+		if article.PublishedAt != nil {
+			p := *article.PublishedAt
+			t = time.Date(t.Year(), t.Month(), t.Day(), p.Hour(), p.Minute(), p.Second(), 0, p.Location())
+		}
+		article.PublishedAt = &t
+	}
+	return nil
+}
+
+// optionalString trims value and returns nil when it is empty
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+// feedAccessFlushInterval is how often feed request counts are written to
+// the database
+const feedAccessFlushInterval = time.Minute
+
+// feedAccessWindow is the period the stats report feed requests over
+const feedAccessWindow = 30 * 24 * time.Hour
+
+// feedAccess counts feed requests in memory between flushes, so serving a
+// feed doesn't wait for a database write
+type feedAccess struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newFeedAccess() *feedAccess {
+	return &feedAccess{counts: make(map[string]int)}
+}
+
+// count records a request to feed
+func (fa *feedAccess) count(feed string) {
+	fa.mu.Lock()
+	defer fa.mu.Unlock()
+	fa.counts[feed]++
+}
+
+// take returns the counts since the last call and resets them
+func (fa *feedAccess) take() map[string]int {
+	fa.mu.Lock()
+	defer fa.mu.Unlock()
+	counts := fa.counts
+	fa.counts = make(map[string]int)
+	return counts
+}
+
+// runFeedAccess writes the feed request counts to the database every
+// feedAccessFlushInterval, and a last time when ctx ends
+func (s *Server) runFeedAccess(ctx context.Context) {
+	ticker := time.NewTicker(feedAccessFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.flushFeedAccess(context.WithoutCancel(ctx))
+			return
+		case <-ticker.C:
+			s.flushFeedAccess(ctx)
+		}
+	}
+}
+
+func (s *Server) flushFeedAccess(ctx context.Context) {
+	counts := s.feedAccess.take()
+	if len(counts) == 0 {
+		return
+	}
+	if err := s.db.AddFeedAccess(ctx, time.Now(), counts); err != nil {
+		logging.Warnf("Failed to record feed access: %v", err)
+	}
+}
+
+// countFeedAccess counts the requests to the routes it wraps under feed
+func (s *Server) countFeedAccess(feed string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s.feedAccess.count(feed)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rssFeedName names an RSS feed for the access counters by its filter, e.g.
+// "rss" or "rss?source=gasetten"
+func rssFeedName(query url.Values) string {
+	filter := url.Values{}
+	for _, key := range []string{"q", "source", "author", "tag", "unread"} {
+		if v := query.Get(key); v != "" {
+			filter.Set(key, v)
+		}
+	}
+	if len(filter) == 0 {
+		return "rss"
+	}
+	return "rss?" + filter.Encode()
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/tkilaker/kiln/internal/auth"
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+// FeedEntry is a single subscribable feed listed in the feed directory
+type FeedEntry struct {
+	Title string
+	URL   string
+	Count int
+
+	// Alternates are the same feed in other formats
+	Alternates []FeedAlternate
+
+	// DeleteURL removes the feed, for feeds that were added (saved searches)
+	DeleteURL string
+}
+
+// FeedAlternate is a feed in another format, e.g. Atom
+type FeedAlternate struct {
+	Format string
+	URL    string
+}
+
+// FeedGroup is a titled group of feed entries (e.g. "By source")
+type FeedGroup struct {
+	Title   string
+	Entries []FeedEntry
+}
+
+// handleFeedDirectory renders an index of all feeds Kiln can generate
+func (s *Server) handleFeedDirectory(w http.ResponseWriter, r *http.Request) {
+	groups, err := s.feedGroups(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list feeds: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	FeedDirectoryPage(groups, s.calendarURL(s.feedToken(r))).Render(r.Context(), w)
+}
+
+// handleFeedOPML serves the feeds of the feed directory as an OPML file, one
+// outline per group, for subscribing to all of them in one step
+func (s *Server) handleFeedOPML(w http.ResponseWriter, r *http.Request) {
+	groups, err := s.feedGroups(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list feeds: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	doc := opmlDocument{Version: "2.0", Title: s.config.FeedTitle}
+	for _, group := range groups {
+		if len(group.Entries) == 0 {
+			continue
+		}
+		category := opmlOutline{Text: group.Title}
+		for _, entry := range group.Entries {
+			category.Outlines = append(category.Outlines, opmlOutline{
+				Text:   entry.Title,
+				Title:  entry.Title,
+				Type:   "rss",
+				XMLURL: entry.URL,
+			})
+		}
+		doc.Body = append(doc.Body, category)
+	}
+
+	w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="kiln-feeds.opml"`)
+	w.Write([]byte(xml.Header))
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		logging.Warnf("Failed to write feed OPML: %v", err)
+	}
+}
+
+// feedGroups lists the feeds Kiln can generate, with the feed token of the
+// signed-in user in their URLs
+func (s *Server) feedGroups(r *http.Request) ([]FeedGroup, error) {
+	ctx := r.Context()
+
+	sources, err := s.db.GetSourceCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	authors, err := s.db.GetAuthorCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.db.GetTagCounts(ctx, maxFeedTags)
+	if err != nil {
+		return nil, err
+	}
+
+	searches, err := s.db.GetSavedSearches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token := s.feedToken(r)
+	return []FeedGroup{
+		{
+			Title: "All articles",
+			Entries: []FeedEntry{
+				{Title: s.config.FeedTitle, URL: s.feedURL(database.ArticleFilter{}, token)},
+				{Title: "Unread articles", URL: s.feedURL(database.ArticleFilter{Unread: true}, token)},
+			},
+		},
+		{
+			Title: "Reading queue",
+			Entries: []FeedEntry{{
+				Title: "Read later",
+				URL:   s.queueFeedURL(FeedRSS, token),
+				Alternates: []FeedAlternate{
+					{Format: "Atom", URL: s.queueFeedURL(FeedAtom, token)},
+					{Format: "JSON", URL: s.queueFeedURL(FeedJSON, token)},
+				},
+			}},
+		},
+		{Title: "Saved searches", Entries: s.savedSearchEntries(searches, token)},
+		{Title: "By source", Entries: s.sourceFeedEntries(sources, token)},
+		{Title: "By author", Entries: s.facetFeedEntries(authors, token, func(name string) database.ArticleFilter {
+			return database.ArticleFilter{Author: name}
+		})},
+		{Title: "By tag", Entries: s.facetFeedEntries(tags, token, func(name string) database.ArticleFilter {
+			return database.ArticleFilter{Tag: name}
+		})},
+	}, nil
+}
+
+// facetFeedEntries builds one feed entry per facet value
+func (s *Server) facetFeedEntries(facets []database.FacetCount, token string, filterFor func(string) database.ArticleFilter) []FeedEntry {
+	entries := make([]FeedEntry, 0, len(facets))
+	for _, facet := range facets {
+		entries = append(entries, FeedEntry{
+			Title: facet.Name,
+			URL:   s.feedURL(filterFor(facet.Name), token),
+			Count: facet.Count,
+		})
+	}
+	return entries
+}
+
+// sourceFeedEntries lists the per-source feeds, with their Atom and JSON
+// feeds as alternates
+func (s *Server) sourceFeedEntries(sources []database.FacetCount, token string) []FeedEntry {
+	entries := make([]FeedEntry, 0, len(sources))
+	for _, source := range sources {
+		entries = append(entries, FeedEntry{
+			Title: source.Name,
+			URL:   s.sourceFeedURL(source.Name, FeedRSS, token),
+			Alternates: []FeedAlternate{
+				{Format: "Atom", URL: s.sourceFeedURL(source.Name, FeedAtom, token)},
+				{Format: "JSON", URL: s.sourceFeedURL(source.Name, FeedJSON, token)},
+			},
+			Count: source.Count,
+		})
+	}
+	return entries
+}
+
+// sourceFeedURL returns the absolute URL of a source's feed in format,
+// carrying the feed token when one is given
+func (s *Server) sourceFeedURL(source, format, token string) string {
+	feedURL := strings.TrimSuffix(s.config.FeedLink, "/") + sourceFeedPath(source, format)
+	if token != "" {
+		feedURL += "?" + url.Values{auth.FeedTokenParam: {token}}.Encode()
+	}
+	return feedURL
+}
+
+// sourceFeedPath returns the path of a source's feed in format; the RSS
+// feed is rss.xml like the feed of all sources
+func sourceFeedPath(source, format string) string {
+	if format == FeedRSS {
+		return "/feeds/" + url.PathEscape(source) + "/rss.xml"
+	}
+	return "/feeds/" + url.PathEscape(source) + "/feed." + format
+}
+
+// feedToken returns the feed token for the signed-in user, or "" when
+// authentication is disabled
+func (s *Server) feedToken(r *http.Request) string {
+	principal := auth.FromContext(r.Context())
+	if !s.auth.Enabled() || principal == nil {
+		return ""
+	}
+	return s.feedTokens.Token(principal.Subject)
+}
+
+// feedURL returns the absolute RSS feed URL for the given filter, carrying
+// the feed token when one is given
+func (s *Server) feedURL(filter database.ArticleFilter, token string) string {
+	params := url.Values{}
+	if filter.Query != "" {
+		params.Set("q", filter.Query)
+	}
+	if filter.Source != "" {
+		params.Set("source", filter.Source)
+	}
+	if filter.Author != "" {
+		params.Set("author", filter.Author)
+	}
+	if filter.Tag != "" {
+		params.Set("tag", filter.Tag)
+	}
+	if filter.Unread {
+		params.Set("unread", "1")
+	}
+	if token != "" {
+		params.Set(auth.FeedTokenParam, token)
+	}
+
+	feedURL := strings.TrimSuffix(s.config.FeedLink, "/") + "/rss.xml"
+	if len(params) > 0 {
+		feedURL += "?" + params.Encode()
+	}
+	return feedURL
+}
+
+// feedTitleSuffix describes a filter for use in a feed title
+func feedTitleSuffix(filter database.ArticleFilter) string {
+	var parts []string
+	if filter.Query != "" {
+		parts = append(parts, `"`+filter.Query+`"`)
+	}
+	if filter.Source != "" {
+		parts = append(parts, filter.Source)
+	}
+	if filter.Author != "" {
+		parts = append(parts, filter.Author)
+	}
+	if filter.Tag != "" {
+		parts = append(parts, filter.Tag)
+	}
+	if filter.Unread {
+		parts = append(parts, "unread")
+	}
+	return strings.Join(parts, " - ")
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/database"
+)
+
+// filterDateLayout is the date format used by the from/to query parameters
+const filterDateLayout = "2006-01-02"
+
+// maxSearchSuggestions is how many similar titles a search that found
+// nothing suggests
+const maxSearchSuggestions = 5
+
+// ArticleFilterForm holds the filter form state for the article list
+type ArticleFilterForm struct {
+	Query   string
+	Source  string
+	Author  string
+	Tag     string
+	From    string
+	To      string
+	Unread  bool
+	Sources []database.FacetCount
+	Authors []database.FacetCount
+	Tags    []database.FacetCount
+
+	// Suggestions are articles with titles close to a search that found
+	// nothing
+	Suggestions []*database.Article
+
+	// UnreadCount is the number of articles not marked read
+	UnreadCount int
+}
+
+// Active reports whether any filter is applied
+func (f ArticleFilterForm) Active() bool {
+	return f.Query != "" || f.Source != "" || f.Author != "" || f.Tag != "" || f.From != "" || f.To != "" || f.Unread
+}
+
+// parseArticleFilter reads q, source, author, tag, from, to and unread query
+// parameters. q is a web-style search (words, "quoted phrases", -excluded);
+// dates use YYYY-MM-DD and the to date is inclusive; unread=1 keeps the
+// articles not marked read.
+func parseArticleFilter(r *http.Request) (database.ArticleFilter, error) {
+	q := r.URL.Query()
+	filter := database.ArticleFilter{
+		Query:  strings.TrimSpace(q.Get("q")),
+		Source: q.Get("source"),
+		Author: q.Get("author"),
+		Tag:    q.Get("tag"),
+	}
+
+	if from := q.Get("from"); from != "" {
+		t, err := time.ParseInLocation(filterDateLayout, from, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("invalid from date %q", from)
+		}
+		filter.From = &t
+	}
+
+	if to := q.Get("to"); to != "" {
+		t, err := time.ParseInLocation(filterDateLayout, to, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("invalid to date %q", to)
+		}
+		// Include the whole "to" day
+		t = t.AddDate(0, 0, 1)
+		filter.To = &t
+	}
+
+	if unread := q.Get("unread"); unread != "" {
+		b, err := strconv.ParseBool(unread)
+		if err != nil {
+			return filter, fmt.Errorf("invalid unread value %q", unread)
+		}
+		filter.Unread = b
+	}
+
+	return filter, nil
+}
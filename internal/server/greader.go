@@ -0,0 +1,506 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tkilaker/kiln/internal/auth"
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/internal/media"
+	"github.com/tkilaker/kiln/pkg/feed"
+)
+
+// ReaderAPIPath is where the Google Reader API is served; clients are set
+// up with this URL as their server (like FreshRSS's /api/greader.php)
+const ReaderAPIPath = "/api/greader"
+
+// Google Reader stream and tag IDs. Clients send "user/-/..." or the user
+// ID in place of "-".
+const (
+	readerStateReadingList = "state/com.google/reading-list"
+	readerStateRead        = "state/com.google/read"
+	readerStateKeptUnread  = "state/com.google/kept-unread"
+	readerFeedPrefix       = "feed/"
+	readerItemPrefix       = "tag:google.com,2005:reader/item/"
+
+	readerDefaultItems = 20
+	readerMaxItems     = 1000
+)
+
+type readerSubscription struct {
+	ID         string   `json:"id"`
+	Title      string   `json:"title"`
+	Categories []string `json:"categories"`
+	URL        string   `json:"url"`
+	HTMLURL    string   `json:"htmlUrl"`
+	IconURL    string   `json:"iconUrl"`
+}
+
+type readerUnreadCount struct {
+	ID                      string `json:"id"`
+	Count                   int    `json:"count"`
+	NewestItemTimestampUsec string `json:"newestItemTimestampUsec"`
+}
+
+type readerItemRef struct {
+	ID              string   `json:"id"`
+	DirectStreamIDs []string `json:"directStreamIds"`
+	TimestampUsec   string   `json:"timestampUsec"`
+}
+
+type readerLink struct {
+	Href string `json:"href"`
+	Type string `json:"type,omitempty"`
+}
+
+type readerItem struct {
+	ID            string       `json:"id"`
+	CrawlTimeMsec string       `json:"crawlTimeMsec"`
+	TimestampUsec string       `json:"timestampUsec"`
+	Published     int64        `json:"published"`
+	Updated       int64        `json:"updated"`
+	Title         string       `json:"title"`
+	Author        string       `json:"author,omitempty"`
+	Canonical     []readerLink `json:"canonical"`
+	Alternate     []readerLink `json:"alternate"`
+	Enclosure     []readerLink `json:"enclosure,omitempty"`
+	Categories    []string     `json:"categories"`
+	Origin        readerOrigin `json:"origin"`
+	Summary       readerText   `json:"summary"`
+}
+
+type readerOrigin struct {
+	StreamID string `json:"streamId"`
+	Title    string `json:"title"`
+	HTMLURL  string `json:"htmlUrl"`
+}
+
+type readerText struct {
+	Content string `json:"content"`
+}
+
+// handleReaderLogin implements ClientLogin: the app's username and password
+// are checked against the password login, Basic auth or, as the password,
+// an API key, and a reader token is returned
+func (s *Server) handleReaderLogin(w http.ResponseWriter, r *http.Request) {
+	email, passwd := r.FormValue("Email"), r.FormValue("Passwd")
+
+	subject := ""
+	switch {
+	case !s.config.AuthEnabled():
+		subject = email
+	case s.passwordLogin != nil && s.passwordLogin.Check(email, passwd):
+		subject = email
+	case s.config.BasicAuthPass != "" && auth.NewPasswordLogin(s.config.BasicAuthUser, s.config.BasicAuthPass).Check(email, passwd):
+		subject = email
+	case s.apiKeys != nil:
+		if name, ok := s.apiKeys.Check(passwd); ok {
+			subject = name
+		}
+	}
+	if subject == "" {
+		logging.Warnf("Failed Google Reader login for %q", email)
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, "Error=BadAuthentication\n")
+		return
+	}
+
+	token := s.readerTokens.Token(subject)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "SID=%s\nLSID=%s\nAuth=%s\n", token, token, token)
+}
+
+// handleReaderToken returns the token clients send with edits. Requests
+// are authenticated by header, so it is not checked.
+func (s *Server) handleReaderToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, s.readerTokens.Token(readerSubject(r)))
+}
+
+// handleReaderUserInfo describes the signed-in user
+func (s *Server) handleReaderUserInfo(w http.ResponseWriter, r *http.Request) {
+	subject := readerSubject(r)
+	writeJSON(w, http.StatusOK, map[string]string{
+		"userId":        subject,
+		"userName":      subject,
+		"userProfileId": subject,
+		"userEmail":     "",
+	})
+}
+
+// handleReaderSubscriptions lists every source with articles as a feed
+func (s *Server) handleReaderSubscriptions(w http.ResponseWriter, r *http.Request) {
+	sources, err := s.db.GetSourceCounts(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch sources: %v", err))
+		return
+	}
+
+	subscriptions := make([]readerSubscription, 0, len(sources))
+	for _, source := range sources {
+		subscriptions = append(subscriptions, readerSubscription{
+			ID:         readerFeedPrefix + source.Name,
+			Title:      source.Name,
+			Categories: []string{},
+			URL:        s.feedURL(database.ArticleFilter{Source: source.Name}, ""),
+			HTMLURL:    s.config.FeedLink,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"subscriptions": subscriptions})
+}
+
+// handleReaderTags lists the labels; Kiln has none
+func (s *Server) handleReaderTags(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"tags": []any{}})
+}
+
+// handleReaderUnreadCount returns the unread articles per source and in all
+func (s *Server) handleReaderUnreadCount(w http.ResponseWriter, r *http.Request) {
+	counts, err := s.db.GetUnreadCounts(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to count unread articles: %v", err))
+		return
+	}
+
+	var total int
+	var newest time.Time
+	unread := make([]readerUnreadCount, 0, len(counts)+1)
+	for _, c := range counts {
+		unread = append(unread, readerUnreadCount{
+			ID:                      readerFeedPrefix + c.Source,
+			Count:                   c.Count,
+			NewestItemTimestampUsec: usec(c.Newest),
+		})
+		total += c.Count
+		if c.Newest.After(newest) {
+			newest = c.Newest
+		}
+	}
+	unread = append(unread, readerUnreadCount{
+		ID:                      "user/-/" + readerStateReadingList,
+		Count:                   total,
+		NewestItemTimestampUsec: usec(newest),
+	})
+
+	writeJSON(w, http.StatusOK, map[string]any{"max": readerMaxItems, "unreadcounts": unread})
+}
+
+// handleReaderItemIDs lists the IDs of a stream's items
+func (s *Server) handleReaderItemIDs(w http.ResponseWriter, r *http.Request) {
+	query, limit, offset, ok, err := parseReaderStream(r, r.FormValue("s"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	refs := []readerItemRef{}
+	if ok {
+		ids, times, err := s.db.GetReaderArticleIDs(r.Context(), query, limit, offset)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch items: %v", err))
+			return
+		}
+		for i, id := range ids {
+			refs = append(refs, readerItemRef{ID: strconv.Itoa(id), DirectStreamIDs: []string{}, TimestampUsec: usec(times[i])})
+		}
+	}
+
+	response := map[string]any{"itemRefs": refs}
+	if len(refs) == limit {
+		response["continuation"] = strconv.Itoa(offset + limit)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleReaderItemContents returns the items with the IDs given as "i"
+func (s *Server) handleReaderItemContents(w http.ResponseWriter, r *http.Request) {
+	ids, err := parseReaderItemIDs(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	articles, err := s.db.GetArticlesByIDs(r.Context(), ids)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch items: %v", err))
+		return
+	}
+	s.writeReaderItems(w, r, "user/-/"+readerStateReadingList, articles, "")
+}
+
+// handleReaderStreamContents returns a page of a stream's items. The stream
+// ID follows the path or is given as "s".
+func (s *Server) handleReaderStreamContents(w http.ResponseWriter, r *http.Request) {
+	streamID, err := url.PathUnescape(chi.URLParam(r, "*"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid stream ID")
+		return
+	}
+	if streamID == "" {
+		streamID = r.FormValue("s")
+	}
+
+	query, limit, offset, ok, err := parseReaderStream(r, streamID)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var articles []*database.Article
+	if ok {
+		articles, err = s.db.GetReaderArticles(r.Context(), query, limit, offset)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch items: %v", err))
+			return
+		}
+	}
+
+	continuation := ""
+	if len(articles) == limit {
+		continuation = strconv.Itoa(offset + limit)
+	}
+	s.writeReaderItems(w, r, streamID, articles, continuation)
+}
+
+// writeReaderItems writes articles as a stream of Google Reader items
+func (s *Server) writeReaderItems(w http.ResponseWriter, r *http.Request, streamID string, articles []*database.Article, continuation string) {
+	ids := make([]int, len(articles))
+	for i, article := range articles {
+		ids[i] = article.ID
+	}
+	read, err := s.db.GetReadArticleIDs(r.Context(), ids)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	base := strings.TrimSuffix(s.config.FeedLink, "/")
+	items := make([]readerItem, 0, len(articles))
+	for _, article := range articles {
+		item := readerItem{
+			ID:            fmt.Sprintf("%s%016x", readerItemPrefix, article.ID),
+			CrawlTimeMsec: strconv.FormatInt(article.CreatedAt.UnixMilli(), 10),
+			TimestampUsec: usec(article.CreatedAt),
+			Published:     article.CreatedAt.Unix(),
+			Updated:       article.UpdatedAt.Unix(),
+			Title:         getArticleTitle(article),
+			Author:        derefString(article.Author),
+			Canonical:     []readerLink{{Href: article.URL}},
+			Alternate:     []readerLink{{Href: article.URL, Type: "text/html"}},
+			Categories:    []string{"user/-/" + readerStateReadingList},
+			Origin: readerOrigin{
+				StreamID: readerFeedPrefix + article.Source,
+				Title:    article.Source,
+				HTMLURL:  s.config.FeedLink,
+			},
+		}
+		if article.PublishedAt != nil {
+			item.Published = article.PublishedAt.Unix()
+		}
+		if read[article.ID] {
+			item.Categories = append(item.Categories, "user/-/"+readerStateRead)
+		}
+
+		// Mirrored images have a path relative to Kiln
+		if article.ContentHTML != nil {
+			item.Summary.Content = strings.ReplaceAll(*article.ContentHTML, `src="`+media.URLPrefix, `src="`+base+media.URLPrefix)
+		}
+		if article.ImageURL != nil {
+			imageURL := *article.ImageURL
+			if strings.HasPrefix(imageURL, "/") {
+				imageURL = base + imageURL
+			}
+			item.Enclosure = []readerLink{{Href: imageURL, Type: feed.ImageType(imageURL)}}
+		}
+		if article.AudioURL != nil && article.AudioType != nil {
+			item.Enclosure = append(item.Enclosure, readerLink{Href: *article.AudioURL, Type: *article.AudioType})
+		}
+
+		items = append(items, item)
+	}
+
+	response := map[string]any{
+		"id":      streamID,
+		"updated": time.Now().Unix(),
+		"items":   items,
+	}
+	if continuation != "" {
+		response["continuation"] = continuation
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleReaderEditTag marks the items given as "i" read ("a" is the read
+// state) or unread ("r" is the read state, or "a" is kept-unread). Other
+// tags, such as starred, are not kept.
+func (s *Server) handleReaderEditTag(w http.ResponseWriter, r *http.Request) {
+	ids, err := parseReaderItemIDs(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	changed, read := false, false
+	for _, tag := range r.Form["a"] {
+		switch readerState(tag) {
+		case readerStateRead:
+			changed, read = true, true
+		case readerStateKeptUnread:
+			changed, read = true, false
+		}
+	}
+	for _, tag := range r.Form["r"] {
+		if readerState(tag) == readerStateRead {
+			changed, read = true, false
+		}
+	}
+
+	if changed {
+		if err := s.db.SetArticlesRead(r.Context(), ids, read); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to update read state: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, "OK")
+}
+
+// handleReaderMarkAllRead marks a stream read up to the time "ts" (in
+// microseconds), or up to now
+func (s *Server) handleReaderMarkAllRead(w http.ResponseWriter, r *http.Request) {
+	query, _, _, ok, err := parseReaderStream(r, r.FormValue("s"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	before := time.Now()
+	if ts := r.FormValue("ts"); ts != "" {
+		n, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid ts %q", ts), http.StatusBadRequest)
+			return
+		}
+		before = time.UnixMicro(n)
+	}
+
+	if ok {
+		if _, err := s.db.MarkAllRead(r.Context(), query.Source, before); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to mark articles read: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, "OK")
+}
+
+// parseReaderStream turns a stream ID and the paging and filter parameters
+// into a query. ok is false for streams Kiln has no items in, such as
+// starred items and labels.
+func parseReaderStream(r *http.Request, streamID string) (query database.ReaderQuery, limit, offset int, ok bool, err error) {
+	read, unread := true, false
+
+	switch {
+	case streamID == "" || readerState(streamID) == readerStateReadingList:
+		ok = true
+	case readerState(streamID) == readerStateRead:
+		query.Read = &read
+		ok = true
+	case strings.HasPrefix(streamID, readerFeedPrefix):
+		query.Source = strings.TrimPrefix(streamID, readerFeedPrefix)
+		ok = true
+	}
+
+	// Exclude read items ("xt") or include only them ("it")
+	if readerState(r.FormValue("xt")) == readerStateRead {
+		query.Read = &unread
+	}
+	if readerState(r.FormValue("it")) == readerStateRead {
+		query.Read = &read
+	}
+
+	// Items scraped after "ot" and before "nt" (Unix seconds)
+	for param, bound := range map[string]**time.Time{"ot": &query.Since, "nt": &query.Until} {
+		if value := r.FormValue(param); value != "" {
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return query, 0, 0, false, fmt.Errorf("invalid %s %q", param, value)
+			}
+			t := time.Unix(n, 0)
+			*bound = &t
+		}
+	}
+	query.OldestFirst = r.FormValue("r") == "o"
+
+	limit = readerDefaultItems
+	if value := r.FormValue("n"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 {
+			return query, 0, 0, false, fmt.Errorf("invalid n %q", value)
+		}
+		limit = min(limit, readerMaxItems)
+	}
+	if value := r.FormValue("c"); value != "" {
+		if offset, err = strconv.Atoi(value); err != nil || offset < 0 {
+			return query, 0, 0, false, fmt.Errorf("invalid continuation %q", value)
+		}
+	}
+
+	return query, limit, offset, ok, nil
+}
+
+// parseReaderItemIDs reads the item IDs given as "i", in long
+// ("tag:google.com,2005:reader/item/<hex>") or short (decimal) form
+func parseReaderItemIDs(r *http.Request) ([]int, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, fmt.Errorf("invalid form")
+	}
+
+	ids := make([]int, 0, len(r.Form["i"]))
+	for _, value := range r.Form["i"] {
+		var id int64
+		var err error
+		if hexID, ok := strings.CutPrefix(value, readerItemPrefix); ok {
+			id, err = strconv.ParseInt(hexID, 16, 64)
+		} else {
+			id, err = strconv.ParseInt(value, 10, 64)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid item ID %q", value)
+		}
+		ids = append(ids, int(id))
+	}
+	return ids, nil
+}
+
+// readerState returns a "user/<id>/state/..." stream or tag ID without its
+// user part
+func readerState(id string) string {
+	rest, ok := strings.CutPrefix(id, "user/")
+	if !ok {
+		return ""
+	}
+	_, state, _ := strings.Cut(rest, "/")
+	return state
+}
+
+// readerSubject names the user of a Google Reader API request
+func readerSubject(r *http.Request) string {
+	if principal := auth.FromContext(r.Context()); principal != nil {
+		return principal.Subject
+	}
+	return "kiln"
+}
+
+// usec formats t in microseconds, as Google Reader timestamps are
+func usec(t time.Time) string {
+	return strconv.FormatInt(t.UnixMicro(), 10)
+}
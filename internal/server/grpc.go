@@ -0,0 +1,240 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	kilnv1 "github.com/tkilaker/kiln/api/kiln/v1"
+	"github.com/tkilaker/kiln/internal/auth"
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/scraper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcAPI implements the gRPC API (api/kiln/v1). It is served by the HTTP
+// router over HTTP/2, so it shares the authentication of the other routes.
+type grpcAPI struct {
+	kilnv1.UnimplementedKilnServiceServer
+	s *Server
+}
+
+// newGRPCServer creates the gRPC server with the Kiln service registered
+func (s *Server) newGRPCServer() *grpc.Server {
+	server := grpc.NewServer()
+	kilnv1.RegisterKilnServiceServer(server, &grpcAPI{s: s})
+	return server
+}
+
+// grpcRoutes registers the gRPC methods with the router, each under its
+// access policy. They are outside the timeout group, as WatchProgress
+// streams for as long as the job runs.
+func (s *Server) grpcRoutes() {
+	server := s.newGRPCServer()
+	policies := map[string]auth.Policy{
+		kilnv1.KilnService_ListArticles_FullMethodName:  auth.PolicyAPI,
+		kilnv1.KilnService_GetArticle_FullMethodName:    auth.PolicyAPI,
+		kilnv1.KilnService_WatchProgress_FullMethodName: auth.PolicyAPI,
+		// Starting jobs needs the admin policy, like POST /scrape
+		kilnv1.KilnService_TriggerScrape_FullMethodName: auth.PolicyAdmin,
+	}
+	for method, policy := range policies {
+		s.router.With(s.auth.Require(policy)).Post(method, server.ServeHTTP)
+	}
+}
+
+// ListArticles implements KilnServiceServer
+func (g *grpcAPI) ListArticles(ctx context.Context, req *kilnv1.ListArticlesRequest) (*kilnv1.ListArticlesResponse, error) {
+	filter := database.ArticleFilter{
+		Query:  strings.TrimSpace(req.Query),
+		Source: req.Source,
+		Author: req.Author,
+		Tag:    req.Tag,
+		Unread: req.Unread,
+	}
+	if req.From != nil {
+		from := req.From.AsTime()
+		filter.From = &from
+	}
+	if req.To != nil {
+		to := req.To.AsTime()
+		filter.To = &to
+	}
+
+	limit := int(req.PageSize)
+	if limit == 0 {
+		limit = defaultAPIArticles
+	}
+	if limit < 1 || limit > maxAPIArticles {
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must be between 1 and %d", maxAPIArticles)
+	}
+	cursor, err := parseArticleCursor(req.PageToken)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid page_token: %v", err)
+	}
+
+	// Fetch one extra article to know whether another page follows
+	articles, err := g.s.db.GetArticlesBefore(ctx, filter, cursor, limit+1)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch articles: %v", err)
+	}
+	resp := &kilnv1.ListArticlesResponse{}
+	if len(articles) > limit {
+		articles = articles[:limit]
+		last := articles[limit-1]
+		resp.NextPageToken = articleCursorString(database.ArticleCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	tags, err := g.s.db.GetTagsForArticles(ctx, articleIDs(articles))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch tags: %v", err)
+	}
+	read, err := g.s.db.GetReadArticleIDs(ctx, articleIDs(articles))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch read state: %v", err)
+	}
+
+	resp.Articles = make([]*kilnv1.Article, 0, len(articles))
+	for _, article := range articles {
+		resp.Articles = append(resp.Articles, grpcArticle(article, tags[article.ID], read[article.ID]))
+	}
+	return resp, nil
+}
+
+// GetArticle implements KilnServiceServer
+func (g *grpcAPI) GetArticle(ctx context.Context, req *kilnv1.GetArticleRequest) (*kilnv1.Article, error) {
+	article, _, err := g.s.resolveArticle(ctx, req.Id)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "article not found: %v", err)
+	}
+
+	tags, err := g.s.db.GetArticleTags(ctx, article.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch tags: %v", err)
+	}
+	read, err := g.s.db.GetReadArticleIDs(ctx, []int{article.ID})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch read state: %v", err)
+	}
+
+	result := grpcArticle(article, tags, read[article.ID])
+	if article.ContentHTML != nil {
+		result.ContentHtml = *article.ContentHTML
+	}
+	if article.ContentText != nil {
+		result.ContentText = *article.ContentText
+	}
+	return result, nil
+}
+
+// TriggerScrape implements KilnServiceServer
+func (g *grpcAPI) TriggerScrape(ctx context.Context, req *kilnv1.TriggerScrapeRequest) (*kilnv1.TriggerScrapeResponse, error) {
+	label := "manual scrape"
+	jobReq := scraper.JobRequest{Kind: scraper.JobScrape, Trigger: database.RunTriggerManual}
+	if req.RetryFailed {
+		label = "retry of failed articles"
+		jobReq = scraper.JobRequest{Kind: scraper.JobRetry, Trigger: database.RunTriggerRetry}
+	}
+	if req.DryRun {
+		jobReq.DryRun = true
+		label = "dry run of " + label
+	}
+
+	job, err := g.s.scraper.Enqueue(ctx, label, jobReq)
+	if errors.Is(err, database.ErrJobActive) {
+		return nil, status.Errorf(codes.FailedPrecondition, "not started: %v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to queue job: %v", err)
+	}
+	return &kilnv1.TriggerScrapeResponse{JobId: job.ID}, nil
+}
+
+// WatchProgress implements KilnServiceServer
+func (g *grpcAPI) WatchProgress(req *kilnv1.WatchProgressRequest, stream grpc.ServerStreamingServer[kilnv1.ProgressUpdate]) error {
+	ctx := stream.Context()
+	job := g.s.scraper.Job(req.JobId)
+	if job == nil {
+		return status.Error(codes.NotFound, scraper.ErrJobNotFound.Error())
+	}
+
+	tracker := job.Progress()
+	updates := tracker.SubscribeFrom(int(req.LastSeq))
+	defer tracker.Unsubscribe(updates)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case update, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(g.progressUpdate(ctx, update)); err != nil {
+				return err
+			}
+			if update.Status.Finished() {
+				return nil
+			}
+		}
+	}
+}
+
+// progressUpdate converts a progress update for the gRPC API
+func (g *grpcAPI) progressUpdate(ctx context.Context, update scraper.ProgressUpdate) *kilnv1.ProgressUpdate {
+	result := &kilnv1.ProgressUpdate{
+		Seq:            int32(update.Seq),
+		Status:         string(update.Status),
+		Message:        update.Message,
+		CurrentItem:    int32(update.CurrentItem),
+		TotalItems:     int32(update.TotalItems),
+		ArticlesAdded:  int32(update.ArticlesAdded),
+		ArticlesFailed: int32(update.ArticlesFailed),
+		RunId:          int32(update.RunID),
+		FailedUrl:      update.FailedURL,
+		FailedStage:    update.FailedStage,
+		Error:          update.Error,
+		Timestamp:      timestamppb.New(update.Timestamp),
+	}
+	if update.NewArticleID != 0 {
+		if article, err := g.s.db.GetArticleByID(ctx, update.NewArticleID); err == nil {
+			result.NewArticleId = articleRef(article)
+		}
+	}
+	return result
+}
+
+// grpcArticle converts an article for the gRPC API, without its content
+func grpcArticle(article *database.Article, tags []string, read bool) *kilnv1.Article {
+	result := &kilnv1.Article{
+		Id:         articleRef(article),
+		Url:        article.URL,
+		Source:     article.Source,
+		CreatedAt:  timestamppb.New(article.CreatedAt),
+		UpdatedAt:  timestamppb.New(article.UpdatedAt),
+		WordCount:  int32(article.WordCount),
+		Partial:    article.Partial,
+		Read:       read,
+		Categories: article.Categories,
+		Tags:       tags,
+	}
+	if article.Title != nil {
+		result.Title = *article.Title
+	}
+	if article.Author != nil {
+		result.Author = *article.Author
+	}
+	if article.PublishedAt != nil {
+		result.PublishedAt = timestamppb.New(*article.PublishedAt)
+	}
+	if article.ImageURL != nil {
+		result.ImageUrl = *article.ImageURL
+	}
+	if article.Summary != nil {
+		result.Summary = *article.Summary
+	}
+	return result
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// healthCheckTimeout bounds each component check of /health/ready
+	healthCheckTimeout = 5 * time.Second
+
+	// scheduleGrace is how long a scheduled source may wait past its due
+	// time, e.g. behind other runs, before the scheduler counts as stalled
+	scheduleGrace = 2 * time.Hour
+)
+
+// Component states; a readiness check fails when any component is failing
+const (
+	healthOK      = "ok"
+	healthIdle    = "idle"
+	healthFailing = "failing"
+)
+
+// HealthReport is the readiness of the service and each of its components
+type HealthReport struct {
+	Status     string                     `json:"status"`
+	CheckedAt  time.Time                  `json:"checked_at"`
+	Components map[string]ComponentHealth `json:"components"`
+}
+
+// ComponentHealth is the state of one component
+type ComponentHealth struct {
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// handleReady serves /health/ready: the database, the browser and the
+// scrape scheduler are checked, and the response is 503 when any of them is
+// failing
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]func(ctx context.Context) (string, string){
+		"database":  s.checkDatabase,
+		"browser":   s.checkBrowser,
+		"scheduler": s.checkScheduler,
+	}
+
+	report := HealthReport{
+		Status:     healthOK,
+		CheckedAt:  time.Now(),
+		Components: make(map[string]ComponentHealth, len(checks)),
+	}
+	for name, check := range checks {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		started := time.Now()
+		status, detail := check(ctx)
+		cancel()
+
+		report.Components[name] = ComponentHealth{
+			Status:    status,
+			LatencyMS: time.Since(started).Milliseconds(),
+			Detail:    detail,
+		}
+		if status == healthFailing {
+			report.Status = healthFailing
+		}
+	}
+
+	code := http.StatusOK
+	if report.Status == healthFailing {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, report)
+}
+
+// checkDatabase pings the database through the pool
+func (s *Server) checkDatabase(ctx context.Context) (string, string) {
+	if err := s.db.Pool().Ping(ctx); err != nil {
+		return healthFailing, "ping failed"
+	}
+	return healthOK, ""
+}
+
+// checkBrowser checks the browser connection. The browser starts with the
+// first scrape, so one that hasn't started yet is idle.
+func (s *Server) checkBrowser(ctx context.Context) (string, string) {
+	started, alive := s.scraper.BrowserStatus()
+	switch {
+	case !started:
+		return healthIdle, "not started"
+	case !alive:
+		return healthFailing, "connection lost"
+	default:
+		return healthOK, ""
+	}
+}
+
+// checkScheduler checks that no scheduled source has been waiting to run
+// for longer than scheduleGrace
+func (s *Server) checkScheduler(ctx context.Context) (string, string) {
+	overdue, scheduled, err := s.scraper.OverdueSources(ctx, time.Now(), scheduleGrace)
+	if err != nil {
+		return healthFailing, "failed to load schedules"
+	}
+	if scheduled == 0 {
+		return healthIdle, "no scheduled sources"
+	}
+	if len(overdue) == 0 {
+		return healthOK, fmt.Sprintf("%d scheduled sources on time", scheduled)
+	}
+
+	late := make([]string, 0, len(overdue))
+	for name, due := range overdue {
+		late = append(late, fmt.Sprintf("%s (due %s)", name, due.Format(time.RFC3339)))
+	}
+	sort.Strings(late)
+	return healthFailing, "overdue: " + strings.Join(late, ", ")
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/favicon"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+const (
+	// iconMaxAge is how long a fetched source icon is used before it is
+	// fetched again
+	iconMaxAge = 30 * 24 * time.Hour
+
+	// iconRetryAfter is how long a site without a usable icon is left
+	// alone
+	iconRetryAfter = 24 * time.Hour
+)
+
+// sourceIcons fetches source icons one at a time, so a page full of article
+// cards doesn't fetch the same icon for every card
+type sourceIcons struct {
+	mu sync.Mutex
+}
+
+// sourceIconPath is the path a source's icon is served from
+func sourceIconPath(source string) string {
+	return "/icons/" + url.PathEscape(source)
+}
+
+// sourceIcon returns the cached icon of source, fetching it from the
+// source's site when it is missing or expired. Returns nil for sources
+// without a usable icon.
+func (s *Server) sourceIcon(ctx context.Context, source string) (*database.SourceIcon, error) {
+	icon, err := s.db.GetSourceIcon(ctx, source)
+	if err != nil || fresh(icon) {
+		return icon, err
+	}
+
+	s.icons.mu.Lock()
+	defer s.icons.mu.Unlock()
+
+	// Another request may have fetched it while this one waited
+	if icon, err = s.db.GetSourceIcon(ctx, source); err != nil || fresh(icon) {
+		return icon, err
+	}
+
+	siteURL, err := s.db.GetSourceSiteURL(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	if siteURL == "" {
+		return nil, nil
+	}
+
+	fetched := &database.SourceIcon{Source: source}
+	found, err := favicon.Fetch(ctx, siteURL)
+	switch {
+	case err == nil:
+		fetched.IconURL = &found.URL
+		fetched.ContentType = &found.ContentType
+		fetched.Data = found.Data
+	case errors.Is(err, favicon.ErrNotFound):
+		logging.Debugf("No icon found for source %s", source)
+	default:
+		// Keep serving the old icon; try again on the next request
+		logging.Warnf("Failed to fetch icon of source %s: %v", source, err)
+		return icon, nil
+	}
+
+	if err := s.db.SaveSourceIcon(context.WithoutCancel(ctx), fetched); err != nil {
+		return nil, err
+	}
+	return fetched, nil
+}
+
+// fresh reports whether a cached icon can be used without fetching it again
+func fresh(icon *database.SourceIcon) bool {
+	if icon == nil {
+		return false
+	}
+	if icon.Data == nil {
+		return time.Since(icon.FetchedAt) < iconRetryAfter
+	}
+	return time.Since(icon.FetchedAt) < iconMaxAge
+}
+
+// handleSourceIcon serves a source's favicon. Icons are public so feed
+// readers can load them.
+func (s *Server) handleSourceIcon(w http.ResponseWriter, r *http.Request) {
+	source, err := url.PathUnescape(chi.URLParam(r, "source"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	icon, err := s.sourceIcon(r.Context(), source)
+	if err != nil {
+		logging.Warnf("Failed to load icon of source %s: %v", source, err)
+		http.Error(w, "Failed to load icon", http.StatusInternalServerError)
+		return
+	}
+	if icon == nil || icon.Data == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", *icon.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(icon.Data)))
+	// SVG icons may contain scripts; keep them from running on our origin
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Write(icon.Data)
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/tkilaker/kiln/internal/database"
+)
+
+// uuidPattern matches the canonical textual form of a UUID
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// articleRef returns the public identifier of an article. Serial IDs stay
+// internal so URLs do not reveal the size of the archive.
+func articleRef(article *database.Article) string {
+	return article.UUID
+}
+
+// articlePath returns the URL path of an article's detail page
+func articlePath(article *database.Article) string {
+	return "/articles/" + articleRef(article)
+}
+
+// resolveArticle looks up an article by its public identifier. Legacy
+// numeric IDs are still accepted; legacy reports whether one was used so
+// callers can redirect to the canonical URL.
+func (s *Server) resolveArticle(ctx context.Context, ref string) (article *database.Article, legacy bool, err error) {
+	if uuidPattern.MatchString(ref) {
+		article, err = s.db.GetArticleByUUID(ctx, ref)
+		return article, false, err
+	}
+
+	id, err := strconv.Atoi(ref)
+	if err != nil || id <= 0 {
+		return nil, false, fmt.Errorf("invalid article ID %q", ref)
+	}
+	article, err = s.db.GetArticleByID(ctx, id)
+	return article, true, err
+}
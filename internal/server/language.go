@@ -0,0 +1,20 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/tkilaker/kiln/internal/i18n"
+)
+
+// language sets the UI language of the request: the configured one, else
+// the one the browser prefers, else English
+func (s *Server) language(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := s.config.UILanguage
+		if lang == "" {
+			lang = i18n.Negotiate(r.Header.Get("Accept-Language"))
+			w.Header().Add("Vary", "Accept-Language")
+		}
+		next.ServeHTTP(w, r.WithContext(i18n.WithLanguage(r.Context(), lang)))
+	})
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/internal/storage"
+)
+
+// handleMedia serves a mirrored article image. Images are public so feed
+// readers can load them.
+func (s *Server) handleMedia(w http.ResponseWriter, r *http.Request) {
+	if s.media == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, contentType, err := s.media.Open(r.Context(), chi.URLParam(r, "name"))
+	if errors.Is(err, storage.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		logging.Warnf("Failed to open media %s: %v", chi.URLParam(r, "name"), err)
+		http.Error(w, "Failed to load image", http.StatusInternalServerError)
+		return
+	}
+	defer body.Close()
+
+	// Names are derived from the source URL, which may be re-downloaded, so
+	// cache for a day rather than forever
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	io.Copy(w, body)
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/i18n"
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/internal/scraper"
+)
+
+const (
+	// maxOPMLUploadSize caps the size of an uploaded OPML file
+	maxOPMLUploadSize = 1 << 20
+
+	// feedScrapeInterval is how often imported feeds are read; only the
+	// articles not yet saved are scraped
+	feedScrapeInterval = "1h"
+)
+
+// opmlDocument is an OPML subscription list, e.g. exported from FreshRSS or
+// from /feeds.opml. Feeds may be nested in category outlines.
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr,omitempty"`
+	Title   string        `xml:"head>title,omitempty"`
+	Body    []opmlOutline `xml:"body>outline"`
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string        `xml:"htmlUrl,attr,omitempty"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// opmlFeed is a feed listed in an OPML file
+type opmlFeed struct {
+	Title   string
+	FeedURL string
+	SiteURL string
+}
+
+// parseOPML returns the feeds in an OPML file, in document order
+func parseOPML(r io.Reader) ([]opmlFeed, error) {
+	var doc opmlDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("not an OPML file: %w", err)
+	}
+
+	var feeds []opmlFeed
+	var walk func(outlines []opmlOutline)
+	walk = func(outlines []opmlOutline) {
+		for _, outline := range outlines {
+			if outline.XMLURL != "" {
+				title := outline.Title
+				if title == "" {
+					title = outline.Text
+				}
+				feeds = append(feeds, opmlFeed{
+					Title:   strings.TrimSpace(title),
+					FeedURL: strings.TrimSpace(outline.XMLURL),
+					SiteURL: strings.TrimSpace(outline.HTMLURL),
+				})
+			}
+			walk(outline.Outlines)
+		}
+	}
+	walk(doc.Body)
+	return feeds, nil
+}
+
+// handleImportOPML registers the feeds in an uploaded OPML file as feed
+// sources. Feeds that are already registered are skipped, so a file can be
+// imported again after adding feeds to it.
+func (s *Server) handleImportOPML(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxOPMLUploadSize+4096)
+	if err := r.ParseMultipartForm(maxOPMLUploadSize); err != nil {
+		writeNotice(w, "red", i18n.Tf(ctx, "Cannot read OPML file: %v", err))
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		writeNotice(w, "red", i18n.T(ctx, "Choose an OPML file to import."))
+		return
+	}
+	defer file.Close()
+
+	feeds, err := parseOPML(file)
+	if err != nil {
+		writeNotice(w, "red", i18n.Tf(ctx, "Cannot import: %v", err))
+		return
+	}
+
+	existing, err := s.db.GetSources(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch sources: %v", err), http.StatusInternalServerError)
+		return
+	}
+	names := make(map[string]bool)
+	registered := make(map[string]bool)
+	for _, source := range existing {
+		names[source.Name] = true
+		if source.FeedURL != nil {
+			registered[*source.FeedURL] = true
+		}
+	}
+
+	var added []string
+	var skipped, invalid int
+	for _, feed := range feeds {
+		source, err := newFeedSource(feed, names)
+		if err != nil {
+			logging.Warnf("Not importing feed %q: %v", feed.FeedURL, err)
+			invalid++
+			continue
+		}
+		if registered[*source.FeedURL] {
+			skipped++
+			continue
+		}
+
+		if err := s.db.CreateSource(ctx, source); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to create source %s: %v", source.Name, err), http.StatusInternalServerError)
+			return
+		}
+		names[source.Name] = true
+		registered[*source.FeedURL] = true
+		added = append(added, source.Name)
+
+		logging.Infof("Added feed source %s (%s)", source.Name, *source.FeedURL)
+		s.audit(r, database.AuditSourceCreate, source.Name, source)
+	}
+
+	message := i18n.Tf(ctx, "Added %d feed sources", len(added))
+	if len(added) > 0 {
+		message += ": " + strings.Join(added, ", ")
+	}
+	message += i18n.Tf(ctx, ". %d already registered, %d invalid. Reload the page to see them.", skipped, invalid)
+	color := "green"
+	if len(added) == 0 {
+		color = "yellow"
+	}
+	writeNotice(w, color, message)
+}
+
+// newFeedSource builds the source for an imported feed, named after its
+// title and unlike any name in names. Its articles are expected on its
+// site; feeds without one use the feed's host.
+func newFeedSource(feed opmlFeed, names map[string]bool) (*database.Source, error) {
+	feedURL, err := url.Parse(feed.FeedURL)
+	if err != nil || (feedURL.Scheme != "http" && feedURL.Scheme != "https") || feedURL.Host == "" {
+		return nil, fmt.Errorf("invalid feed URL")
+	}
+
+	siteURL := feedURL.Scheme + "://" + feedURL.Host + "/"
+	if site, err := url.Parse(feed.SiteURL); err == nil && (site.Scheme == "http" || site.Scheme == "https") && site.Host != "" {
+		siteURL = site.String()
+	}
+
+	name := sourceNameFrom(feed.Title)
+	if name == "" {
+		name = sourceNameFrom(strings.TrimPrefix(feedURL.Hostname(), "www."))
+	}
+	base := name
+	for n := 2; names[name]; n++ {
+		name = base + "-" + strconv.Itoa(n)
+	}
+
+	interval := feedScrapeInterval
+	feedURLString := feedURL.String()
+	source := &database.Source{
+		Name:           name,
+		StartURLs:      []string{siteURL},
+		FeedURL:        &feedURLString,
+		ScrapeInterval: &interval,
+		Enabled:        true,
+	}
+	if _, err := scraper.ParseSourceSchedule(source); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// sourceNameReplacer spells out the letters of feed titles that have an
+// obvious ASCII form
+var sourceNameReplacer = strings.NewReplacer(
+	"å", "a", "ä", "a", "ö", "o", "æ", "ae", "ø", "o", "ü", "u", "ß", "ss",
+	"é", "e", "è", "e", "ê", "e", "á", "a", "à", "a", "í", "i", "ó", "o", "ú", "u", "ñ", "n",
+)
+
+// sourceNameFrom turns a feed title into a source name, e.g. "Malmö FF
+// News" into "malmo-ff-news". Returns "" when nothing is left.
+func sourceNameFrom(title string) string {
+	title = sourceNameReplacer.Replace(strings.ToLower(title))
+
+	var b strings.Builder
+	dash := false
+	for _, r := range title {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+		if b.Len() >= 40 {
+			break
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,304 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+)
+
+const (
+	// offlineArticleDays is how far back the articles kept for reading
+	// offline go
+	offlineArticleDays = 7
+
+	// maxOfflineArticles bounds the articles synced to a device
+	maxOfflineArticles = 100
+
+	// themeColor is the accent of the app icon and the browser chrome of
+	// the installed app
+	themeColor = "#ea580c"
+)
+
+// appIconSizes are the sizes of the app icon, the ones Android asks for
+var appIconSizes = []int{192, 512}
+
+// OfflineArticle is an article as synced to a device for reading offline
+type OfflineArticle struct {
+	ID          string     `json:"id"`
+	URL         string     `json:"url"`
+	Source      string     `json:"source"`
+	Title       *string    `json:"title"`
+	Author      *string    `json:"author"`
+	PublishedAt *time.Time `json:"published_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	WordCount   int        `json:"word_count"`
+	ContentHTML *string    `json:"content_html"`
+}
+
+// handleOfflineArticles serves the content of the articles scraped in the
+// last week for the service worker to keep. The ETag changes only when the
+// articles do, so a sync that finds nothing new costs a 304.
+func (s *Server) handleOfflineArticles(w http.ResponseWriter, r *http.Request) {
+	since := time.Now().AddDate(0, 0, -offlineArticleDays)
+	articles, err := s.db.GetRecentArticleContent(r.Context(), since, maxOfflineArticles)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("failed to fetch articles: %v", err))
+		return
+	}
+
+	etag := offlineETag(articles)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	result := make([]OfflineArticle, 0, len(articles))
+	for _, article := range articles {
+		result = append(result, OfflineArticle{
+			ID:          articleRef(article),
+			URL:         article.URL,
+			Source:      article.Source,
+			Title:       article.Title,
+			Author:      article.Author,
+			PublishedAt: article.PublishedAt,
+			CreatedAt:   article.CreatedAt,
+			UpdatedAt:   article.UpdatedAt,
+			WordCount:   article.WordCount,
+			ContentHTML: article.ContentHTML,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"articles": result})
+}
+
+// offlineETag identifies a set of synced articles by their IDs and update
+// times
+func offlineETag(articles []*database.Article) string {
+	h := sha1.New()
+	for _, article := range articles {
+		fmt.Fprintf(h, "%s/%d\n", article.UUID, article.UpdatedAt.UnixNano())
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil))[:16] + `"`
+}
+
+// handleManifest serves the web app manifest that makes the UI installable
+func (s *Server) handleManifest(w http.ResponseWriter, r *http.Request) {
+	icons := make([]map[string]string, 0, len(appIconSizes))
+	for _, size := range appIconSizes {
+		icons = append(icons, map[string]string{
+			"src":     fmt.Sprintf("/app-icons/%d.png", size),
+			"sizes":   fmt.Sprintf("%dx%d", size, size),
+			"type":    "image/png",
+			"purpose": "any maskable",
+		})
+	}
+
+	manifest := map[string]any{
+		"name":             s.config.FeedTitle,
+		"short_name":       "Kiln",
+		"start_url":        "/articles",
+		"scope":            "/",
+		"display":          "standalone",
+		"background_color": "#f9fafb",
+		"theme_color":      themeColor,
+		"icons":            icons,
+	}
+
+	writeJSON(w, http.StatusOK, manifest)
+}
+
+// handleServiceWorker serves the service worker. It is served from the root
+// so its scope covers every page, and revalidated on every load so updates
+// reach installed apps.
+func (s *Server) handleServiceWorker(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write([]byte(serviceWorkerJS))
+}
+
+// handleAppIcon serves the app icon in one of appIconSizes
+func (s *Server) handleAppIcon(w http.ResponseWriter, r *http.Request) {
+	size, err := strconv.Atoi(chi.URLParam(r, "size"))
+	if err != nil || !validIconSize(size) {
+		http.NotFound(w, r)
+		return
+	}
+
+	icon, err := renderAppIcon(size)
+	if err != nil {
+		logging.Warnf("Failed to render app icon: %v", err)
+		http.Error(w, "Failed to render icon", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Write(icon)
+}
+
+func validIconSize(size int) bool {
+	for _, s := range appIconSizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
+
+// renderAppIcon draws the app icon: a "K" in the theme color on the dark
+// background of the share cards. The letter stays inside the middle 80%, the
+// safe zone of maskable icons.
+func renderAppIcon(size int) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), &image.Uniform{color.RGBA{0x11, 0x18, 0x27, 0xff}}, image.Point{}, draw.Src)
+
+	face, err := loadFace(gobold.TTF, float64(size)/2)
+	if err != nil {
+		return nil, err
+	}
+	bounds, _ := font.BoundString(face, "K")
+	width := (bounds.Max.X - bounds.Min.X).Ceil()
+	height := (bounds.Max.Y - bounds.Min.Y).Ceil()
+	x := (size-width)/2 - bounds.Min.X.Floor()
+	y := (size+height)/2 - bounds.Max.Y.Ceil()
+	drawText(img, face, image.NewUniform(color.RGBA{0xea, 0x58, 0x0c, 0xff}), x, y, "K")
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode app icon: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// serviceWorkerJS keeps the recent articles from /api/v1/offline in the
+// Cache API and, when a page cannot be loaded, renders the article (or a
+// list of the kept articles) from them. Pages post "sync" when they load;
+// browsers with periodic background sync also sync while the app is closed.
+const serviceWorkerJS = `const ARTICLES_CACHE = 'kiln-articles';
+const SYNC_URL = '/api/v1/offline';
+const SYNC_INTERVAL = 15 * 60 * 1000;
+
+let lastSync = 0;
+
+self.addEventListener('install', () => self.skipWaiting());
+
+self.addEventListener('activate', (event) => {
+  event.waitUntil(self.clients.claim());
+});
+
+self.addEventListener('message', (event) => {
+  if (event.data === 'sync') {
+    event.waitUntil(sync(false));
+  }
+});
+
+self.addEventListener('periodicsync', (event) => {
+  if (event.tag === 'kiln-articles') {
+    event.waitUntil(sync(true));
+  }
+});
+
+self.addEventListener('fetch', (event) => {
+  const request = event.request;
+  if (request.mode !== 'navigate' || request.method !== 'GET') {
+    return;
+  }
+  event.respondWith(fetch(request).catch(() => offlinePage(new URL(request.url).pathname)));
+});
+
+async function sync(force) {
+  if (!force && Date.now() - lastSync < SYNC_INTERVAL) {
+    return;
+  }
+  lastSync = Date.now();
+
+  const cache = await caches.open(ARTICLES_CACHE);
+  const cached = await cache.match(SYNC_URL);
+  const headers = {};
+  if (cached && cached.headers.get('ETag')) {
+    headers['If-None-Match'] = cached.headers.get('ETag');
+  }
+  try {
+    const response = await fetch(SYNC_URL, { headers, credentials: 'same-origin', cache: 'no-store' });
+    if (response.ok) {
+      await cache.put(SYNC_URL, response);
+    }
+  } catch (err) {
+    // Offline; the kept articles stay until the next sync
+  }
+}
+
+async function offlineArticles() {
+  const cache = await caches.open(ARTICLES_CACHE);
+  const cached = await cache.match(SYNC_URL);
+  if (!cached) {
+    return [];
+  }
+  return (await cached.json()).articles || [];
+}
+
+async function offlinePage(path) {
+  const articles = await offlineArticles();
+  const match = path.match(/^\/articles\/([^/]+)$/);
+  if (match) {
+    const article = articles.find((a) => a.id === decodeURIComponent(match[1]));
+    if (article) {
+      return page(article.title || 'Untitled Article', articleBody(article));
+    }
+  }
+  return page('Offline', listBody(articles));
+}
+
+function articleBody(article) {
+  const byline = [article.source, article.author, formatDate(article)].filter(Boolean).map(escapeHTML).join(' · ');
+  return '<p><a href="/articles">&larr; Offline articles</a></p>' +
+    '<h1>' + escapeHTML(article.title || 'Untitled Article') + '</h1>' +
+    '<p class="meta">' + byline + '</p>' +
+    '<article>' + (article.content_html || '') + '</article>';
+}
+
+function listBody(articles) {
+  if (articles.length === 0) {
+    return '<h1>Offline</h1><p class="meta">No articles are kept for reading offline yet. Open Kiln once while online to fetch them.</p>';
+  }
+  const items = articles.map((a) =>
+    '<li><a href="/articles/' + encodeURIComponent(a.id) + '">' + escapeHTML(a.title || 'Untitled Article') + '</a>' +
+    '<br><span class="meta">' + escapeHTML(a.source + ' · ' + formatDate(a)) + '</span></li>').join('');
+  return '<h1>Offline</h1><p class="meta">You are offline. These recent articles can still be read.</p><ul>' + items + '</ul>';
+}
+
+function formatDate(article) {
+  const date = new Date(article.published_at || article.created_at);
+  return date.toLocaleDateString(undefined, { year: 'numeric', month: 'long', day: 'numeric' });
+}
+
+function escapeHTML(text) {
+  return String(text).replace(/[&<>"']/g, (c) => ({ '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;' })[c]);
+}
+
+function page(title, body) {
+  const html = '<!DOCTYPE html><html lang="en"><head><meta charset="UTF-8">' +
+    '<meta name="viewport" content="width=device-width, initial-scale=1.0">' +
+    '<title>' + escapeHTML(title) + ' - Kiln</title>' +
+    '<style>body{font-family:system-ui,sans-serif;max-width:42rem;margin:0 auto;padding:1rem;line-height:1.6;color:#111827;background:#f9fafb}' +
+    'a{color:#2563eb}.meta{color:#6b7280;font-size:.875rem}ul{list-style:none;padding:0}li{margin:0 0 1rem}img{max-width:100%;height:auto}</style>' +
+    '</head><body>' + body + '</body></html>';
+  return new Response(html, { headers: { 'Content-Type': 'text/html; charset=utf-8' } });
+}
+`
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tkilaker/kiln/internal/auth"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+// maxQueueSize bounds the articles shown on the queue page and in its feed
+const maxQueueSize = 200
+
+// handleQueue lists the reading queue, most recently added first
+func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	articles, err := s.db.GetQueuedArticles(ctx, maxQueueSize)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch reading queue: %v", err), http.StatusInternalServerError)
+		return
+	}
+	articleTags, err := s.db.GetTagsForArticles(ctx, articleIDs(articles))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch tags: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	read, err := s.db.GetReadArticleIDs(ctx, articleIDs(articles))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch read state: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	QueuePage(articles, articleTags, read, s.queueFeedURL(FeedRSS, s.feedToken(r))).Render(ctx, w)
+}
+
+// handleQueueArticle adds an article to the reading queue (POST) or removes
+// it (DELETE) and renders its queue button again
+func (s *Server) handleQueueArticle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	article, _, err := s.resolveArticle(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Article not found: %v", err), http.StatusNotFound)
+		return
+	}
+
+	queued := r.Method == http.MethodPost
+	if err := s.db.SetArticleQueued(ctx, article.ID, queued); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to update reading queue: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if queued {
+		logging.Infof("Queued article %s to read later", article.UUID)
+	} else {
+		logging.Infof("Removed article %s from the reading queue", article.UUID)
+	}
+	queueButton(article, queued).Render(ctx, w)
+}
+
+// handleQueueFeed serves the reading queue as an RSS, Atom or JSON feed, by
+// the extension of the URL
+func (s *Server) handleQueueFeed(w http.ResponseWriter, r *http.Request) {
+	format := chi.URLParam(r, "format")
+	contentType, ok := feedContentTypes[format]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	s.feedAccess.count("queue")
+
+	articles, err := s.db.GetQueuedArticles(r.Context(), maxQueueSize)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch reading queue: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := s.loadFeedContent(r.Context(), articles); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch articles: %v", err), http.StatusInternalServerError)
+		return
+	}
+	articleTags, err := s.db.GetTagsForArticles(r.Context(), articleIDs(articles))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch tags: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	feed, err := GenerateFeed(format, articles, articleTags, s.config, "Read later", true)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to generate feed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write([]byte(feed))
+}
+
+// queueFeedURL returns the absolute URL of the reading queue's feed in
+// format, carrying the feed token when one is given
+func (s *Server) queueFeedURL(format, token string) string {
+	feedURL := strings.TrimSuffix(s.config.FeedLink, "/") + "/queue." + format
+	if token != "" {
+		feedURL += "?" + url.Values{auth.FeedTokenParam: {token}}.Encode()
+	}
+	return feedURL
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// handleAPISetRead marks an article read (PUT) or unread (DELETE)
+func (s *Server) handleAPISetRead(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	article, _, err := s.resolveArticle(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("article not found: %v", err))
+		return
+	}
+
+	read := r.Method == http.MethodPut
+	if err := s.db.SetArticlesRead(ctx, []int{article.ID}, read); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"id": articleRef(article), "read": read})
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// realIP returns middleware that replaces RemoteAddr with the client address
+// forwarded by a reverse proxy, but only for requests whose TCP peer is one
+// of the trusted proxies. Anyone else could set X-Forwarded-For to whatever
+// they like, so their own address is kept; the rate limiter and the audit
+// log rely on that.
+func realIP(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if peer, ok := remoteIP(r.RemoteAddr); ok && isTrusted(trusted, peer) {
+				if client := forwardedClient(r, trusted); client.IsValid() {
+					r.RemoteAddr = client.String()
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedClient returns the client address a trusted proxy forwarded: the
+// last X-Forwarded-For entry not added by a trusted proxy, else X-Real-IP
+func forwardedClient(r *http.Request, trusted []netip.Prefix) netip.Addr {
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	var client netip.Addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = addr.Unmap()
+		if !isTrusted(trusted, client) {
+			return client
+		}
+	}
+	if client.IsValid() {
+		return client
+	}
+	if addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return addr.Unmap()
+	}
+	return netip.Addr{}
+}
+
+// remoteIP parses the IP of a RemoteAddr, with or without a port
+func remoteIP(remoteAddr string) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// isTrusted reports whether addr is within one of the trusted prefixes
+func isTrusted(trusted []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,69 +1,158 @@
 package server
 
 import (
+	"context"
 	"fmt"
-	"time"
+	"sort"
+	"strings"
 
-	"github.com/gorilla/feeds"
 	"github.com/tkilaker/kiln/internal/config"
 	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/media"
+	"github.com/tkilaker/kiln/pkg/feed"
 )
 
-// GenerateRSSFeed creates an RSS feed from articles
-func GenerateRSSFeed(articles []*database.Article, cfg *config.Config) (string, error) {
-	now := time.Now()
+// Feed formats
+const (
+	FeedRSS  = feed.RSS
+	FeedAtom = feed.Atom
+	FeedJSON = feed.JSON
+)
+
+// maxChannelCategories bounds the categories of a feed's channel
+const maxChannelCategories = 10
+
+// feedContentTypes are the response types of the feed formats
+var feedContentTypes = feed.ContentTypes
+
+// GenerateRSSFeed creates an RSS feed from articles. A non-empty titleSuffix
+// is appended to the configured feed title (used for filtered feeds). Items
+// of feeds with several sources name their source, and use its icon as the
+// thumbnail when they have no lead image. The articles' tags, by article ID,
+// become the categories of their items, and the most common of them the
+// categories of the channel.
+func GenerateRSSFeed(articles []*database.Article, articleTags map[int][]string, cfg *config.Config, titleSuffix string, multiSource bool) (string, error) {
+	return GenerateFeed(FeedRSS, articles, articleTags, cfg, titleSuffix, multiSource)
+}
+
+// GenerateFeed creates a feed from articles in format, one of FeedRSS,
+// FeedAtom and FeedJSON (JSON Feed). Only RSS feeds carry the itunes and
+// media extensions. The articles' tags are the categories of RSS items and
+// the tags of JSON Feed items.
+func GenerateFeed(format string, articles []*database.Article, articleTags map[int][]string, cfg *config.Config, titleSuffix string, multiSource bool) (string, error) {
+	return feed.Generate(format, buildFeed(articles, articleTags, cfg, titleSuffix, multiSource))
+}
+
+// buildFeed converts articles to a feed
+func buildFeed(articles []*database.Article, articleTags map[int][]string, cfg *config.Config, titleSuffix string, multiSource bool) *feed.Feed {
+	base := strings.TrimSuffix(cfg.FeedLink, "/")
 
-	feed := &feeds.Feed{
-		Title:       cfg.FeedTitle,
-		Link:        &feeds.Link{Href: cfg.FeedLink},
+	title := cfg.FeedTitle
+	if titleSuffix != "" {
+		title = fmt.Sprintf("%s - %s", title, titleSuffix)
+	}
+
+	f := &feed.Feed{
+		Title:       title,
+		Link:        cfg.FeedLink,
 		Description: cfg.FeedDescription,
-		Author:      &feeds.Author{Name: cfg.FeedAuthor},
-		Created:     now,
+		Author:      cfg.FeedAuthor,
+		Categories:  channelCategories(articles, articleTags),
+		Items:       make([]*feed.Item, 0, len(articles)),
 	}
 
-	// Convert articles to feed items
-	feed.Items = make([]*feeds.Item, 0, len(articles))
 	for _, article := range articles {
-		item := &feeds.Item{
-			Title: getArticleTitle(article),
-			Link:  &feeds.Link{Href: article.URL},
-			Id:    fmt.Sprintf("%s/articles/%d", cfg.FeedLink, article.ID),
+		item := &feed.Item{
+			ID:         base + articlePath(article),
+			Title:      getArticleTitle(article),
+			Link:       article.URL,
+			Published:  article.CreatedAt,
+			Categories: articleTags[article.ID],
+		}
+		if article.PublishedAt != nil {
+			item.Published = *article.PublishedAt
+		}
+		if article.Author != nil {
+			item.Author = *article.Author
 		}
 
-		// Set description from content
+		// Describe the item by the start of its text, or its summary once
+		// written. HTML feeds also carry the whole article, with mirrored
+		// images linked absolutely.
 		if article.ContentText != nil {
-			// Truncate to reasonable length for RSS
-			description := *article.ContentText
-			if len(description) > 500 {
-				description = description[:500] + "..."
+			item.Description = feed.Truncate(*article.ContentText, cfg.FeedItemLength)
+		}
+		switch cfg.FeedItemContent {
+		case config.FeedItemSummary:
+			if article.Summary != nil {
+				item.Description = *article.Summary
+			}
+		case config.FeedItemHTML:
+			if article.ContentHTML != nil {
+				item.Content = strings.ReplaceAll(*article.ContentHTML, `src="`+media.URLPrefix, `src="`+base+media.URLPrefix)
 			}
-			item.Description = description
-		} else if article.ContentHTML != nil {
-			item.Description = *article.ContentHTML
 		}
 
-		// Set author
-		if article.Author != nil {
-			item.Author = &feeds.Author{Name: *article.Author}
+		// Mirrored images have a path relative to Kiln
+		if article.ImageURL != nil {
+			item.ImageURL = *article.ImageURL
+			if strings.HasPrefix(item.ImageURL, "/") {
+				item.ImageURL = base + item.ImageURL
+			}
+		}
+		if article.AudioURL != nil {
+			item.AudioURL = *article.AudioURL
+			if article.AudioType != nil {
+				item.AudioType = *article.AudioType
+			}
 		}
 
-		// Set published date
-		if article.PublishedAt != nil {
-			item.Created = *article.PublishedAt
-		} else {
-			item.Created = article.CreatedAt
+		// The reading time is the itunes duration
+		if article.WordCount != 0 {
+			item.Duration = article.ReadingTime()
 		}
 
-		feed.Items = append(feed.Items, item)
+		if multiSource {
+			item.Source = &feed.Source{Name: article.Source, URL: base + sourceFeedPath(article.Source, FeedRSS)}
+			item.Thumbnail = base + sourceIconPath(article.Source)
+		}
+		f.Items = append(f.Items, item)
 	}
 
-	// Generate RSS 2.0 format
-	rss, err := feed.ToRss()
-	if err != nil {
-		return "", fmt.Errorf("failed to generate RSS: %w", err)
+	return f
+}
+
+// channelCategories returns the tags most common among articles, for the
+// categories of a feed's channel
+func channelCategories(articles []*database.Article, articleTags map[int][]string) []string {
+	counts := make(map[string]int)
+	for _, article := range articles {
+		for _, tag := range articleTags[article.ID] {
+			counts[tag]++
+		}
 	}
 
-	return rss, nil
+	categories := make([]string, 0, len(counts))
+	for tag := range counts {
+		categories = append(categories, tag)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if counts[categories[i]] != counts[categories[j]] {
+			return counts[categories[i]] > counts[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+	return categories[:min(len(categories), maxChannelCategories)]
+}
+
+// loadFeedContent loads the full content of feed articles, which are
+// retrieved as summaries, when their items need more than the excerpt: for
+// HTML feeds, and descriptions longer than the excerpt
+func (s *Server) loadFeedContent(ctx context.Context, articles []*database.Article) error {
+	if s.config.FeedItemContent != config.FeedItemHTML && s.config.FeedItemLength <= database.ExcerptLength {
+		return nil
+	}
+	return s.db.LoadArticleContent(ctx, articles)
 }
 
 func getArticleTitle(article *database.Article) string {
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tkilaker/kiln/internal/auth"
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/i18n"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+// handleCreateSavedSearch saves the article list's current search under the
+// submitted name
+func (s *Server) handleCreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeNotice(w, "red", i18n.T(r.Context(), "Invalid form."))
+		return
+	}
+
+	search := &database.SavedSearch{
+		Name:   strings.TrimSpace(r.PostForm.Get("name")),
+		Query:  strings.TrimSpace(r.PostForm.Get("q")),
+		Source: optionalString(r.PostForm.Get("source")),
+		Author: optionalString(r.PostForm.Get("author")),
+		Tag:    optionalString(r.PostForm.Get("tag")),
+	}
+	search.Slug = sourceNameFrom(search.Name)
+	switch {
+	case search.Slug == "":
+		writeNotice(w, "red", i18n.T(r.Context(), "Name the search, e.g. academy players."))
+		return
+	case search.Filter() == database.ArticleFilter{}:
+		writeNotice(w, "red", i18n.T(r.Context(), "Search for something or pick a filter before saving."))
+		return
+	}
+
+	err := s.db.CreateSavedSearch(r.Context(), search)
+	if errors.Is(err, database.ErrSavedSearchExists) {
+		writeNotice(w, "yellow", i18n.Tf(r.Context(), "Cannot save %q: %v.", search.Name, err))
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to save search: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	logging.Infof("Saved search %s (%q)", search.Slug, search.Query)
+	s.audit(r, database.AuditSearchCreate, search.Slug, search)
+	writeNotice(w, "green", i18n.Tf(r.Context(), "Saved search %q. Its feed is listed on the Feeds page.", search.Name))
+}
+
+// handleDeleteSavedSearch removes a saved search and with it its feed
+func (s *Server) handleDeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid saved search ID", http.StatusBadRequest)
+		return
+	}
+
+	search, err := s.db.DeleteSavedSearch(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to delete saved search: %v", err), http.StatusNotFound)
+		return
+	}
+
+	logging.Infof("Deleted saved search %s", search.Slug)
+	s.audit(r, database.AuditSearchDelete, search.Slug, search)
+
+	// Return empty response for HTMX to remove the row
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleSavedSearchFeed serves the recent articles matching a saved search
+// as an RSS, Atom or JSON feed, by the extension of the URL
+func (s *Server) handleSavedSearchFeed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	slug, format := chi.URLParam(r, "slug"), chi.URLParam(r, "format")
+	contentType, ok := feedContentTypes[format]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	search, err := s.db.GetSavedSearch(ctx, slug)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch saved search: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if search == nil {
+		http.NotFound(w, r)
+		return
+	}
+	s.feedAccess.count("search/" + slug)
+
+	// Like /rss.xml, the articles of the last 30 days
+	since := time.Now().AddDate(0, 0, -30)
+	articles, err := s.db.GetRecentArticlesFiltered(ctx, since, search.Filter(), 50)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch articles: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := s.loadFeedContent(ctx, articles); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch articles: %v", err), http.StatusInternalServerError)
+		return
+	}
+	articleTags, err := s.db.GetTagsForArticles(ctx, articleIDs(articles))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch tags: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	feed, err := GenerateFeed(format, articles, articleTags, s.config, search.Name, search.Source == nil)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to generate feed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write([]byte(feed))
+}
+
+// savedSearchEntries lists the saved searches for the feed directory, with
+// their Atom and JSON feeds as alternates
+func (s *Server) savedSearchEntries(searches []*database.SavedSearch, token string) []FeedEntry {
+	entries := make([]FeedEntry, 0, len(searches))
+	for _, search := range searches {
+		entries = append(entries, FeedEntry{
+			Title: search.Name,
+			URL:   s.savedSearchURL(search, FeedRSS, token),
+			Alternates: []FeedAlternate{
+				{Format: "Atom", URL: s.savedSearchURL(search, FeedAtom, token)},
+				{Format: "JSON", URL: s.savedSearchURL(search, FeedJSON, token)},
+			},
+			DeleteURL: fmt.Sprintf("/searches/%d", search.ID),
+		})
+	}
+	return entries
+}
+
+// savedSearchURL returns the absolute URL of a saved search's feed in
+// format, carrying the feed token when one is given
+func (s *Server) savedSearchURL(search *database.SavedSearch, format, token string) string {
+	feedURL := strings.TrimSuffix(s.config.FeedLink, "/") + "/searches/" + search.Slug + "." + format
+	if token != "" {
+		feedURL += "?" + url.Values{auth.FeedTokenParam: {token}}.Encode()
+	}
+	return feedURL
+}
@@ -2,17 +2,28 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/tkilaker/kiln/internal/auth"
 	"github.com/tkilaker/kiln/internal/config"
 	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/i18n"
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/internal/media"
+	"github.com/tkilaker/kiln/internal/peersync"
 	"github.com/tkilaker/kiln/internal/scraper"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 // Server represents the HTTP server
@@ -20,50 +31,201 @@ type Server struct {
 	router  *chi.Mux
 	db      *database.DB
 	scraper *scraper.Scraper
+	media   *media.Mirror // nil when images are not mirrored
 	config  *config.Config
+	pages   *pageCache
+	limiter *rateLimiter
+
+	feedAccess *feedAccess
+	mediaUsage *mediaUsage
+	icons      *sourceIcons
+
+	auth          *auth.Chain
+	sessions      *auth.Sessions
+	feedTokens    *auth.FeedTokens
+	readerTokens  *auth.ReaderTokens
+	apiKeys       *auth.APIKeyAuthenticator // nil without API keys
+	shareLinks    *auth.ShareLinks
+	passwordLogin *auth.PasswordLogin
+	oidc          *auth.OIDC
 }
 
 // New creates a new server instance
-func New(db *database.DB, scraper *scraper.Scraper, cfg *config.Config) *Server {
+func New(ctx context.Context, db *database.DB, scraper *scraper.Scraper, mirror *media.Mirror, cfg *config.Config) (*Server, error) {
 	s := &Server{
 		router:  chi.NewRouter(),
 		db:      db,
 		scraper: scraper,
+		media:   mirror,
 		config:  cfg,
+		pages:   newPageCache(db, cfg.PageCacheSize),
+		limiter: newRateLimiter(cfg.RateLimitPerMinute, cfg.RateLimitBurst),
+
+		feedAccess: newFeedAccess(),
+		mediaUsage: &mediaUsage{},
+		icons:      &sourceIcons{},
+	}
+	go s.runFeedAccess(ctx)
+
+	if err := s.setupAuth(ctx); err != nil {
+		return nil, err
 	}
 
 	s.setupRoutes()
-	return s
+	return s, nil
 }
 
-// setupRoutes configures all HTTP routes
+// setupRoutes configures all HTTP routes. Every route is declared inside
+// exactly one access policy group, so access control can be audited here.
 func (s *Server) setupRoutes() {
 	// Middleware
 	s.router.Use(middleware.Logger)
 	s.router.Use(middleware.Recoverer)
 	s.router.Use(middleware.RequestID)
-	s.router.Use(middleware.RealIP)
+	s.router.Use(realIP(s.config.TrustedProxies))
+	s.router.Use(s.language)
+	s.router.Use(compressor())
+	s.router.Use(defaultHTML)
+
+	// Public: health check and login
+	s.router.Group(func(r chi.Router) {
+		r.Use(s.auth.Require(auth.PolicyPublic))
+		r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("OK"))
+		})
+		r.Get("/health/ready", s.handleReady)
+		r.Get("/login", s.handleLoginPage)
+		r.With(s.limiter.Middleware).Post("/login", s.handleLogin)
+		r.Post("/logout", s.handleLogout)
+		r.Get("/auth/oidc/login", s.handleOIDCLogin)
+		r.Get("/auth/oidc/callback", s.handleOIDCCallback)
+		r.With(s.pages.Middleware).Get("/share/{token}", s.handleSharedArticle)
+		r.With(s.pages.Middleware).Get("/share/{token}/card.png", s.handleShareCard)
+		r.Get(media.URLPrefix+"{name}", s.handleMedia)
+		r.Get("/icons/{source}", s.handleSourceIcon)
+		r.Get("/manifest.webmanifest", s.handleManifest)
+		r.Get("/sw.js", s.handleServiceWorker)
+		r.Get("/app-icons/{size}.png", s.handleAppIcon)
+		r.With(s.limiter.Middleware).HandleFunc(ReaderAPIPath+"/accounts/ClientLogin", s.handleReaderLogin)
+	})
 
 	// Routes (no timeout middleware for SSE endpoint)
 	s.router.Group(func(r chi.Router) {
 		r.Use(middleware.Timeout(60 * time.Second))
-		r.Get("/", s.handleIndex)
-		r.Get("/articles", s.handleArticleList)
-		r.Get("/articles/{id}", s.handleArticleDetail)
-		r.Delete("/articles/{id}", s.handleDeleteArticle)
-		r.Post("/scrape", s.handleScrape)
-		r.Post("/articles/clear", s.handleClearArticles)
-		r.Get("/rss.xml", s.handleRSS)
+
+		// UI: browser pages, redirect to login
+		r.Group(func(r chi.Router) {
+			r.Use(s.auth.Require(auth.PolicyUI))
+			r.Get("/", s.handleIndex)
+			r.With(s.pages.Middleware).Get("/articles", s.handleArticleList)
+			r.With(s.pages.Middleware).Get("/articles/{id}", s.handleArticleDetail)
+			r.Get("/feeds", s.handleFeedDirectory)
+			r.Get("/feeds.opml", s.handleFeedOPML)
+			r.Get("/sitemap.xml", s.handleSitemap)
+			r.Get("/tags", s.handleTags)
+			r.Get("/queue", s.handleQueue)
+			r.Get("/alerts", s.handleAlerts)
+			r.With(s.pages.Middleware).Get("/tags/{tag}", s.handleTagArticles)
+			r.Get("/stats", s.handleStats)
+			r.Get("/runs", s.handleRuns)
+			r.Get("/runs/{id}", s.handleRunDetail)
+			r.Get("/runs/diagnostics/{name}", s.handleDiagnostics)
+			r.Get("/sources", s.handleSources)
+			r.Get("/sources/new", s.handleNewSource)
+			r.Get("/sources/{id}", s.handleEditSource)
+			r.Get("/session/badge", s.handleSessionBadge)
+		})
+
+		// Admin: mutations and scraping. The rate limit comes before
+		// authentication, so failed attempts count against it too.
+		r.Group(func(r chi.Router) {
+			r.Use(s.limiter.Middleware)
+			r.Use(s.auth.Require(auth.PolicyAdmin))
+			r.Patch("/articles/{id}", s.handleEditArticle)
+			r.Post("/articles/{id}/comments", s.handleRefreshComments)
+			r.Post("/articles/{id}/tags", s.handleAddArticleTag)
+			r.Post("/articles/{id}/queue", s.handleQueueArticle)
+			r.Delete("/articles/{id}/queue", s.handleQueueArticle)
+			r.Delete("/articles/{id}/tags/{tag}", s.handleRemoveArticleTag)
+			r.Delete("/articles/{id}", s.handleDeleteArticle)
+			r.Post("/scrape", s.handleScrape)
+			r.Post("/scrape/retry", s.handleRetryFailed)
+			r.Post("/scrape/jobs/{id}/cancel", s.handleCancelJob)
+			r.Post("/scrape/jobs/{id}/pause", s.handlePauseJob)
+			r.Post("/scrape/jobs/{id}/resume", s.handleResumeJob)
+			r.Get("/scrape/jobs/{id}/preview", s.handleJobPreview)
+			r.Get("/audit", s.handleAudit)
+			r.Post("/scrape/batch", s.handleBatchImport)
+			r.Post("/articles/clear", s.handleClearArticles)
+			r.Post("/articles/add", s.handleAddArticle)
+			r.Post("/sources", s.handleCreateSource)
+			r.Post("/sources/import", s.handleImportOPML)
+			r.Post("/sources/{id}", s.handleUpdateSource)
+			r.Delete("/sources/{id}", s.handleDeleteSource)
+			r.Post("/sources/{id}/backfill", s.handleBackfillSource)
+			r.Post("/alerts", s.handleCreateWatchTerm)
+			r.Delete("/alerts/{id}", s.handleDeleteWatchTerm)
+			r.Post("/searches", s.handleCreateSavedSearch)
+			r.Delete("/searches/{id}", s.handleDeleteSavedSearch)
+		})
+
+		// Feeds: readers authenticate with feed tokens
+		r.Group(func(r chi.Router) {
+			r.Use(s.auth.Require(auth.PolicyFeed))
+			r.Get("/rss.xml", s.handleFeed)
+			r.Get("/feed.{format}", s.handleFeed)
+			r.Get("/feeds/{source}/rss.xml", s.handleFeed)
+			r.Get("/feeds/{source}/feed.{format}", s.handleFeed)
+			r.Get("/calendar.ics", s.handleCalendar)
+			r.Get("/searches/{slug}.{format}", s.handleSavedSearchFeed)
+			r.Get("/queue.{format}", s.handleQueueFeed)
+		})
+
+		// JSON API
+		r.Group(func(r chi.Router) {
+			r.Use(s.limiter.Middleware)
+			r.Use(s.auth.Require(auth.PolicyAPI))
+			r.Get("/api/v1/stats", s.handleAPIStats)
+			r.Get("/api/v1/session", s.handleAPISession)
+			r.Get("/api/v1/articles", s.handleAPIArticles)
+			r.Get("/api/v1/articles.csv", s.handleAPIArticlesCSV)
+			r.Put("/api/v1/articles/{id}/read", s.handleAPISetRead)
+			r.Delete("/api/v1/articles/{id}/read", s.handleAPISetRead)
+			r.Get("/api/v1/tags", s.handleAPITags)
+			r.Get("/api/v1/offline", s.handleOfflineArticles)
+			r.Get(peersync.SyncPath, s.handleSyncArticles)
+		})
+
+		// Google Reader API for mobile RSS apps
+		r.Group(func(r chi.Router) {
+			r.Use(s.auth.Require(auth.PolicyReader))
+			r.Use(s.countFeedAccess("reader"))
+			r.Route(ReaderAPIPath+"/reader/api/0", func(r chi.Router) {
+				r.Get("/token", s.handleReaderToken)
+				r.Get("/user-info", s.handleReaderUserInfo)
+				r.Get("/subscription/list", s.handleReaderSubscriptions)
+				r.Get("/tag/list", s.handleReaderTags)
+				r.Get("/unread-count", s.handleReaderUnreadCount)
+				r.Get("/stream/items/ids", s.handleReaderItemIDs)
+				r.HandleFunc("/stream/items/contents", s.handleReaderItemContents)
+				r.Get("/stream/contents", s.handleReaderStreamContents)
+				r.Get("/stream/contents/*", s.handleReaderStreamContents)
+				r.Post("/edit-tag", s.handleReaderEditTag)
+				r.Post("/mark-all-as-read", s.handleReaderMarkAllRead)
+			})
+		})
 	})
 
 	// SSE endpoint (no timeout)
-	s.router.Get("/scrape/progress", s.handleScrapeProgress)
+	s.router.With(s.auth.Require(auth.PolicyUI)).Get("/scrape/progress/{id}", s.handleScrapeProgress)
 
-	// Health check
-	s.router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
+	// WebSocket progress channel (no timeout); it takes job commands, so it
+	// needs the admin policy
+	s.router.With(s.auth.Require(auth.PolicyAdmin)).Get("/ws/progress", s.handleProgressSocket)
+
+	// gRPC API (no timeout, for the progress stream)
+	s.grpcRoutes()
 }
 
 // Router returns the Chi router
@@ -71,10 +233,16 @@ func (s *Server) Router() *chi.Mux {
 	return s.router
 }
 
-// Start starts the HTTP server
+// Start starts the HTTP server. When TLS is configured, HTTPS is served on
+// the HTTPS port and addr only redirects to it. Plain HTTP also accepts
+// HTTP/2 without TLS (h2c), which gRPC clients use.
 func (s *Server) Start(addr string) error {
+	if s.TLSEnabled() {
+		return s.startTLS(addr)
+	}
+
 	log.Printf("Starting server on %s", addr)
-	return http.ListenAndServe(addr, s.router)
+	return http.ListenAndServe(addr, h2c.NewHandler(s.router, &http2.Server{}))
 }
 
 // handleIndex renders the home page (redirects to articles list)
@@ -82,151 +250,327 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/articles", http.StatusSeeOther)
 }
 
-// handleArticleList renders the list of all articles
+// handleArticleList renders the list of articles, optionally filtered by
+// source, author, tag and date range
 func (s *Server) handleArticleList(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseArticleFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.renderArticleList(w, r, filter)
+}
+
+// renderArticleList renders the articles matching filter with the filter bar
+func (s *Server) renderArticleList(w http.ResponseWriter, r *http.Request, filter database.ArticleFilter) {
 	ctx := r.Context()
 
-	articles, err := s.db.GetAllArticles(ctx, 100)
+	articles, err := s.db.GetFilteredArticles(ctx, filter, 100)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to fetch articles: %v", err), http.StatusInternalServerError)
 		return
 	}
+	articleTags, err := s.db.GetTagsForArticles(ctx, articleIDs(articles))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch tags: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Populate filter dropdowns
+	sources, err := s.db.GetSourceCounts(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch sources: %v", err), http.StatusInternalServerError)
+		return
+	}
+	authors, err := s.db.GetAuthorCounts(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch authors: %v", err), http.StatusInternalServerError)
+		return
+	}
+	tags, err := s.db.GetTagCounts(ctx, maxFilterTags)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch tags: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	form := ArticleFilterForm{
+		Query:   filter.Query,
+		Source:  filter.Source,
+		Author:  filter.Author,
+		Tag:     filter.Tag,
+		From:    r.URL.Query().Get("from"),
+		To:      r.URL.Query().Get("to"),
+		Unread:  filter.Unread,
+		Sources: sources,
+		Authors: authors,
+		Tags:    tags,
+	}
+
+	// Suggest articles with similar titles when a search finds nothing
+	if filter.Query != "" && len(articles) == 0 {
+		form.Suggestions, err = s.db.GetSimilarTitles(ctx, filter.Query, maxSearchSuggestions)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to fetch suggestions: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	form.UnreadCount, err = s.db.CountUnreadArticles(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to count unread articles: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	queued, err := s.db.GetQueuedArticleIDs(ctx, articleIDs(articles))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch reading queue: %v", err), http.StatusInternalServerError)
+		return
+	}
+	read, err := s.db.GetReadArticleIDs(ctx, articleIDs(articles))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch read state: %v", err), http.StatusInternalServerError)
+		return
+	}
 
 	// Render template
-	ArticleListPage(articles).Render(ctx, w)
+	ArticleListPage(articles, articleTags, queued, read, form).Render(ctx, w)
 }
 
 // handleArticleDetail renders a single article
 func (s *Server) handleArticleDetail(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	idStr := chi.URLParam(r, "id")
 
-	var id int
-	if _, err := fmt.Sscanf(idStr, "%d", &id); err != nil {
-		http.Error(w, "Invalid article ID", http.StatusBadRequest)
+	article, legacy, err := s.resolveArticle(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Article not found: %v", err), http.StatusNotFound)
 		return
 	}
 
-	article, err := s.db.GetArticleByID(ctx, id)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Article not found: %v", err), http.StatusNotFound)
+	// Old numeric URLs redirect to the canonical UUID URL
+	if legacy {
+		http.Redirect(w, r, articlePath(article), http.StatusMovedPermanently)
 		return
 	}
 
+	// Other copies of the same article
+	var original *database.Article
+	if article.DuplicateOf != nil {
+		original, err = s.db.GetArticleByID(ctx, *article.DuplicateOf)
+		if err != nil {
+			logging.Warnf("Failed to load original of article %s: %v", article.UUID, err)
+		}
+	}
+	duplicates, err := s.db.GetDuplicates(ctx, article.ID)
+	if err != nil {
+		logging.Warnf("Failed to load duplicates of article %s: %v", article.UUID, err)
+	}
+
+	comments, err := s.db.GetComments(ctx, article.ID)
+	if err != nil {
+		logging.Warnf("Failed to load comments of article %s: %v", article.UUID, err)
+	}
+	showComments := s.config.ScrapeComments || len(comments) > 0
+
+	tags, err := s.db.GetArticleTags(ctx, article.ID)
+	if err != nil {
+		logging.Warnf("Failed to load tags of article %s: %v", article.UUID, err)
+	}
+
+	queued, err := s.db.GetQueuedArticleIDs(ctx, []int{article.ID})
+	if err != nil {
+		logging.Warnf("Failed to load reading queue state of article %s: %v", article.UUID, err)
+	}
+
+	// Viewing an article marks it read
+	if err := s.db.SetArticlesRead(ctx, []int{article.ID}, true); err != nil {
+		logging.Warnf("Failed to mark article %s read: %v", article.UUID, err)
+	}
+
 	// Render template
-	ArticleDetailPage(article).Render(ctx, w)
+	ArticleDetailPage(article, s.shareURL(article), original, duplicates, tags, comments, showComments, queued[article.ID]).Render(ctx, w)
 }
 
 // handleScrape triggers a manual scrape operation
 func (s *Server) handleScrape(w http.ResponseWriter, r *http.Request) {
-	// Check if scraping is already in progress
-	if s.scraper.GetProgressTracker().IsActive() {
-		w.Header().Set("Content-Type", "text/html")
-		fmt.Fprint(w, `<div class="p-4 bg-yellow-100 border border-yellow-400 text-yellow-700 rounded">
-			Scraping is already in progress. Please wait for it to complete.
-		</div>`)
+	s.startBackgroundScrape(w, r, "manual scrape", scraper.JobRequest{Kind: scraper.JobScrape, Trigger: database.RunTriggerManual})
+}
+
+// handleRetryFailed triggers a run over the queued failed articles only
+func (s *Server) handleRetryFailed(w http.ResponseWriter, r *http.Request) {
+	s.startBackgroundScrape(w, r, "retry of failed articles", scraper.JobRequest{Kind: scraper.JobRetry, Trigger: database.RunTriggerRetry})
+}
+
+// startBackgroundScrape queues a scrape job and responds with the live
+// progress UI for it. The job ID is also sent in the X-Job-ID header. With
+// dry_run=1 the job only reports what it would save.
+func (s *Server) startBackgroundScrape(w http.ResponseWriter, r *http.Request, label string, req scraper.JobRequest) {
+	if dryRun, _ := strconv.ParseBool(r.FormValue("dry_run")); dryRun {
+		req.DryRun = true
+		label = "dry run of " + label
+	}
+
+	job, err := s.scraper.Enqueue(r.Context(), label, req)
+	if errors.Is(err, database.ErrJobActive) {
+		writeNotice(w, "yellow", i18n.Tf(r.Context(), "Not started: %v. Please wait for it to complete.", err))
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to queue job: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	log.Println("Starting manual scrape in background...")
+	// Return immediate response with progress UI
+	w.Header().Set("X-Job-ID", job.ID)
+	JobPanel(job.ID).Render(r.Context(), w)
+}
 
-	// Start scraping in a background goroutine
-	go func() {
-		// Create a new context that won't be cancelled when the HTTP request ends
-		ctx := context.Background()
-		count, err := s.scraper.ScrapeArticles(ctx)
-		if err != nil {
-			log.Printf("Scrape failed: %v", err)
-		} else {
-			log.Printf("Scrape completed: %d new articles", count)
-		}
-	}()
+// handleJobPreview returns what a dry run job found so far as JSON: the
+// articles it would add or update, and the ones it would skip or failed on
+func (s *Server) handleJobPreview(w http.ResponseWriter, r *http.Request) {
+	job := s.scraper.Job(chi.URLParam(r, "id"))
+	if job == nil {
+		writeJSONError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, job.Preview())
+}
 
-	// Return immediate response with progress UI
-	w.Header().Set("Content-Type", "text/html")
-	fmt.Fprint(w, `<div id="scrape-progress" class="p-4 bg-blue-100 border border-blue-400 text-blue-700 rounded">
-		<div class="flex items-center gap-2 mb-2">
-			<svg class="animate-spin h-4 w-4 text-blue-700" xmlns="http://www.w3.org/2000/svg" fill="none" viewBox="0 0 24 24">
-				<circle class="opacity-25" cx="12" cy="12" r="10" stroke="currentColor" stroke-width="4"></circle>
-				<path class="opacity-75" fill="currentColor" d="M4 12a8 8 0 018-8V0C5.373 0 0 5.373 0 12h4zm2 5.291A7.962 7.962 0 014 12H0c0 3.042 1.135 5.824 3 7.938l3-2.647z"></path>
-			</svg>
-			<span class="font-semibold">Scraping in progress...</span>
-		</div>
-		<div id="progress-message">Initializing...</div>
-		<div id="progress-bar-container" class="mt-2 w-full bg-gray-200 rounded-full h-2 hidden">
-			<div id="progress-bar" class="bg-blue-600 h-2 rounded-full transition-all duration-300" style="width: 0%"></div>
-		</div>
-		<div id="progress-details" class="mt-2 text-sm text-blue-600"></div>
-	</div>
-	<script>
-		const eventSource = new EventSource('/scrape/progress');
-		eventSource.onmessage = function(e) {
-			const data = JSON.parse(e.data);
-			const message = document.getElementById('progress-message');
-			const details = document.getElementById('progress-details');
-			const barContainer = document.getElementById('progress-bar-container');
-			const bar = document.getElementById('progress-bar');
-
-			message.textContent = data.message;
-
-			if (data.total_items > 0) {
-				barContainer.classList.remove('hidden');
-				const percent = (data.current_item / data.total_items) * 100;
-				bar.style.width = percent + '%';
-				details.textContent = 'Articles added: ' + data.articles_added;
-			}
+// handleCancelJob stops a running scrape job, or takes a queued one off the
+// queue. The job's progress stream reports the cancellation.
+func (s *Server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	err := s.scraper.Cancel(r.Context(), chi.URLParam(r, "id"))
+	switch {
+	case errors.Is(err, scraper.ErrJobNotFound):
+		http.Error(w, "Job not found", http.StatusNotFound)
+	case errors.Is(err, scraper.ErrJobFinished):
+		http.Error(w, "Job has already finished", http.StatusConflict)
+	case err != nil:
+		http.Error(w, fmt.Sprintf("Failed to cancel job: %v", err), http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
 
-			if (data.article_html) {
-				const emptyState = document.querySelector('.text-center.py-12');
-				let articleList = document.querySelector('.space-y-4');
-
-				if (emptyState) {
-					const container = document.createElement('div');
-					container.className = 'space-y-4';
-					container.innerHTML = data.article_html;
-					emptyState.replaceWith(container);
-				} else if (articleList) {
-					const temp = document.createElement('div');
-					temp.innerHTML = data.article_html;
-					if (temp.firstElementChild) {
-						articleList.insertBefore(temp.firstElementChild, articleList.firstChild);
-					}
-				}
-			}
+// handlePauseJob pauses a running scrape job once it has finished its
+// current article
+func (s *Server) handlePauseJob(w http.ResponseWriter, r *http.Request) {
+	s.setJobPaused(w, r, true)
+}
 
-			if (data.status === 'completed') {
-				eventSource.close();
-				document.getElementById('scrape-progress').innerHTML =
-					'<div class="p-4 bg-green-100 border border-green-400 text-green-700 rounded">' +
-					data.message +
-					'</div>';
-			} else if (data.status === 'failed' || data.status === 'cancelled') {
-				eventSource.close();
-				document.getElementById('scrape-progress').innerHTML =
-					'<div class="p-4 bg-red-100 border border-red-400 text-red-700 rounded">' +
-					data.message +
-					'</div>';
-			}
-		};
-		eventSource.onerror = function() {
-			eventSource.close();
-		};
-	</script>`)
+// handleResumeJob resumes a paused scrape job
+func (s *Server) handleResumeJob(w http.ResponseWriter, r *http.Request) {
+	s.setJobPaused(w, r, false)
+}
+
+func (s *Server) setJobPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	id := chi.URLParam(r, "id")
+	var err error
+	if paused {
+		err = s.scraper.Pause(r.Context(), id)
+	} else {
+		err = s.scraper.Resume(r.Context(), id)
+	}
+	switch {
+	case errors.Is(err, scraper.ErrJobNotFound):
+		http.Error(w, "Job not found", http.StatusNotFound)
+	case errors.Is(err, scraper.ErrJobFinished):
+		http.Error(w, "Job has already finished", http.StatusConflict)
+	case err != nil:
+		http.Error(w, fmt.Sprintf("Failed to update job: %v", err), http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// progressEvent is a progress update as sent to the progress panel
+type progressEvent struct {
+	Status         string `json:"status"`
+	Message        string `json:"message"`
+	CurrentItem    int    `json:"current_item"`
+	TotalItems     int    `json:"total_items"`
+	ArticlesAdded  int    `json:"articles_added"`
+	ArticlesFailed int    `json:"articles_failed"`
+	RunID          int    `json:"run_id"`
+	ArticleHTML    string `json:"article_html"`
+	FailedURL      string `json:"failed_url,omitempty"`
+	FailedStage    string `json:"failed_stage,omitempty"`
+	Error          string `json:"error,omitempty"`
+
+	Preview *scraper.PreviewItem `json:"preview,omitempty"`
+}
+
+// progressHeartbeatInterval is how often an idle progress stream sends a
+// heartbeat, so proxies and the browser don't take it for dead
+const progressHeartbeatInterval = 15 * time.Second
+
+// newArticle fetches the article a progress update reports as added, with
+// its tags, or returns nil
+func (s *Server) newArticle(ctx context.Context, update scraper.ProgressUpdate) (*database.Article, []string) {
+	if update.NewArticleID == 0 {
+		return nil, nil
+	}
+	article, err := s.db.GetArticleByID(ctx, update.NewArticleID)
+	if err != nil {
+		return nil, nil
+	}
+	tags, err := s.db.GetArticleTags(ctx, article.ID)
+	if err != nil {
+		logging.Warnf("Failed to fetch tags of article %s: %v", article.UUID, err)
+	}
+	return article, tags
+}
+
+// newProgressEvent prepares a progress update for API clients, rendering
+// the card of a newly added article
+func (s *Server) newProgressEvent(ctx context.Context, update scraper.ProgressUpdate) progressEvent {
+	articleHTML := ""
+	if article, tags := s.newArticle(ctx, update); article != nil {
+		var buf strings.Builder
+		if err := ArticleCard(article, tags, false, true).Render(ctx, &buf); err == nil {
+			articleHTML = buf.String()
+		}
+	}
+
+	return progressEvent{
+		Status:         string(update.Status),
+		Message:        update.Message,
+		CurrentItem:    update.CurrentItem,
+		TotalItems:     update.TotalItems,
+		ArticlesAdded:  update.ArticlesAdded,
+		ArticlesFailed: update.ArticlesFailed,
+		RunID:          update.RunID,
+		ArticleHTML:    articleHTML,
+		FailedURL:      update.FailedURL,
+		FailedStage:    update.FailedStage,
+		Error:          update.Error,
+		Preview:        update.Preview,
+	}
 }
 
-// handleScrapeProgress streams progress updates via Server-Sent Events
+// handleScrapeProgress streams the progress of a job via Server-Sent Events.
+// Updates are JSON messages, or with ?format=html "progress" events holding
+// the rendered panel for the htmx SSE extension. Each event carries its ID,
+// so a client that reconnects with Last-Event-ID gets the updates it missed.
 func (s *Server) handleScrapeProgress(w http.ResponseWriter, r *http.Request) {
+	job := s.scraper.Job(chi.URLParam(r, "id"))
+	if job == nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	asHTML := r.URL.Query().Get("format") == "html"
+
 	// Set headers for SSE
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	// Get progress tracker
-	tracker := s.scraper.GetProgressTracker()
+	tracker := job.Progress()
 
-	// Subscribe to progress updates
-	updates := tracker.Subscribe()
+	// Subscribe to progress updates, replaying any the client missed
+	lastSeq, _ := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+	updates := tracker.SubscribeFrom(lastSeq)
 	defer tracker.Unsubscribe(updates)
 
 	// Stream updates to client
@@ -236,79 +580,133 @@ func (s *Server) handleScrapeProgress(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Tell the browser how soon to reconnect after losing the stream
+	fmt.Fprint(w, "retry: 3000\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(progressHeartbeatInterval)
+	defer heartbeat.Stop()
+
 	ctx := r.Context()
 	for {
 		select {
 		case <-ctx.Done():
 			// Client disconnected
 			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
 		case update, ok := <-updates:
 			if !ok {
 				// Channel closed
 				return
 			}
 
-			// Fetch and render article HTML if a new article was added
-			articleHTML := ""
-			if update.NewArticleID > 0 {
-				article, err := s.db.GetArticleByID(ctx, update.NewArticleID)
-				if err == nil {
-					// Render article card to HTML
-					var buf strings.Builder
-					if err := ArticleCard(article).Render(ctx, &buf); err == nil {
-						articleHTML = buf.String()
-					}
+			if asHTML {
+				article, tags := s.newArticle(ctx, update)
+				var buf strings.Builder
+				if err := JobProgress(job.ID, update, article, tags).Render(ctx, &buf); err != nil {
+					logging.Warnf("Failed to render progress update: %v", err)
+					continue
+				}
+				writeEvent(w, "progress", update.Seq, buf.String())
+			} else {
+				// Format as JSON; error messages may hold any character, so
+				// the fields are encoded rather than escaped by hand
+				data, err := json.Marshal(s.newProgressEvent(ctx, update))
+				if err != nil {
+					logging.Warnf("Failed to encode progress update: %v", err)
+					continue
 				}
+				writeEvent(w, "", update.Seq, string(data))
 			}
-
-			// Escape strings for JSON
-			message := strings.ReplaceAll(update.Message, `"`, `\"`)
-			message = strings.ReplaceAll(message, "\n", `\n`)
-			articleHTML = strings.ReplaceAll(articleHTML, `"`, `\"`)
-			articleHTML = strings.ReplaceAll(articleHTML, "\n", "")
-			articleHTML = strings.ReplaceAll(articleHTML, "\t", "")
-
-			// Format as JSON
-			data := fmt.Sprintf(`{"status":"%s","message":"%s","current_item":%d,"total_items":%d,"articles_added":%d,"article_html":"%s"}`,
-				update.Status,
-				message,
-				update.CurrentItem,
-				update.TotalItems,
-				update.ArticlesAdded,
-				articleHTML,
-			)
-
-			// Send SSE message
-			fmt.Fprintf(w, "data: %s\n\n", data)
 			flusher.Flush()
+			heartbeat.Reset(progressHeartbeatInterval)
 
 			// Close connection after completion/failure/cancellation
-			if update.Status == "completed" || update.Status == "failed" || update.Status == "cancelled" {
+			if update.Status.Finished() {
 				return
 			}
 		}
 	}
 }
 
-// handleDeleteArticle deletes a specific article
-func (s *Server) handleDeleteArticle(w http.ResponseWriter, r *http.Request) {
+// writeEvent writes a Server-Sent Event; an empty name sends a plain message
+func writeEvent(w io.Writer, name string, id int, data string) {
+	if name != "" {
+		fmt.Fprintf(w, "event: %s\n", name)
+	}
+	fmt.Fprintf(w, "id: %d\n", id)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	fmt.Fprint(w, "\n")
+}
+
+// handleRuns renders the scrape run history
+func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	runs, err := s.db.GetScrapeRuns(ctx, 100)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch scrape runs: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	pending, abandoned, err := s.db.CountRetries(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to count retries: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	RunsPage(runs, pending, abandoned).Render(ctx, w)
+}
+
+// handleRunDetail renders a single scrape run with its failed articles
+func (s *Server) handleRunDetail(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	idStr := chi.URLParam(r, "id")
 
 	var id int
 	if _, err := fmt.Sscanf(idStr, "%d", &id); err != nil {
-		http.Error(w, "Invalid article ID", http.StatusBadRequest)
+		http.Error(w, "Invalid run ID", http.StatusBadRequest)
+		return
+	}
+
+	run, err := s.db.GetScrapeRun(ctx, id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Run not found: %v", err), http.StatusNotFound)
+		return
+	}
+
+	failures, err := s.db.GetScrapeFailures(ctx, id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch run failures: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	RunDetailPage(run, failures).Render(ctx, w)
+}
+
+// handleDeleteArticle deletes a specific article
+func (s *Server) handleDeleteArticle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	article, _, err := s.resolveArticle(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Article not found: %v", err), http.StatusNotFound)
 		return
 	}
 
-	log.Printf("Deleting article %d...", id)
+	logging.Infof("Deleting article %s...", article.UUID)
 
-	if err := s.db.DeleteArticle(ctx, id); err != nil {
+	if err := s.db.DeleteArticle(ctx, article.ID); err != nil {
 		http.Error(w, fmt.Sprintf("Failed to delete article: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	log.Printf("Deleted article %d", id)
+	logging.Infof("Deleted article %s", article.UUID)
+	s.audit(r, database.AuditArticleDelete, article.URL, newArticleAudit(article))
 
 	// Return empty response (article card will be removed by HTMX)
 	w.WriteHeader(http.StatusOK)
@@ -320,6 +718,13 @@ func (s *Server) handleClearArticles(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("Clearing all articles...")
 
+	// The audit log keeps how many articles of each source were cleared
+	sources, err := s.db.GetSourceCounts(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to count articles: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	count, err := s.db.DeleteAllArticles(ctx)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to clear articles: %v", err), http.StatusInternalServerError)
@@ -327,53 +732,64 @@ func (s *Server) handleClearArticles(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("Deleted %d articles", count)
+	s.audit(r, database.AuditArticlesClear, "", map[string]any{"articles": count, "sources": sources})
 
-	// Return HTMX response with script to update the page
-	w.Header().Set("Content-Type", "text/html")
-	fmt.Fprintf(w, `<div class="p-4 bg-yellow-100 border border-yellow-400 text-yellow-700 rounded">
-		Deleted %d articles. Database is now empty.
-	</div>
-	<script>
-		// Remove all article cards
-		const articleList = document.querySelector('.space-y-4');
-		if (articleList) {
-			articleList.remove();
-		}
-
-		// Show empty state
-		const main = document.querySelector('main');
-		const emptyState = document.createElement('div');
-		emptyState.className = 'text-center py-12';
-		emptyState.innerHTML = '<p class="text-gray-500 text-lg">No articles yet. Click "Scrape New Articles" to get started!</p>';
-		main.appendChild(emptyState);
-
-		// Hide the Clear All button
-		const clearButton = document.querySelector('button[hx-post="/articles/clear"]');
-		if (clearButton) {
-			clearButton.style.display = 'none';
-		}
-	</script>`, count)
+	ArticlesCleared(count).Render(ctx, w)
 }
 
-// handleRSS generates and serves the RSS feed
-func (s *Server) handleRSS(w http.ResponseWriter, r *http.Request) {
+// handleFeed generates and serves the article feed, optionally restricted
+// by the filter parameters of the article list (q, source, author, tag,
+// from, to and unread). /rss.xml serves it as RSS, /feed.{format} as RSS,
+// Atom or JSON by the extension; under /feeds/{source}/ the same URLs serve
+// the feed of one source.
+func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	format := FeedRSS
+	if ext := chi.URLParam(r, "format"); ext != "" {
+		format = ext
+	}
+	contentType, ok := feedContentTypes[format]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	filter, err := parseArticleFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Per-source feeds name their source in the path
+	query := r.URL.Query()
+	if source := chi.URLParam(r, "source"); source != "" {
+		filter.Source = source
+		query.Set("source", source)
+	}
+	s.feedAccess.count(rssFeedName(query))
 
 	// Get recent articles (last 30 days)
 	since := time.Now().AddDate(0, 0, -30)
-	articles, err := s.db.GetRecentArticles(ctx, since, 50)
+	articles, err := s.db.GetRecentArticlesFiltered(ctx, since, filter, 50)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to fetch articles: %v", err), http.StatusInternalServerError)
 		return
 	}
+	if err := s.loadFeedContent(ctx, articles); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch articles: %v", err), http.StatusInternalServerError)
+		return
+	}
+	articleTags, err := s.db.GetTagsForArticles(ctx, articleIDs(articles))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch tags: %v", err), http.StatusInternalServerError)
+		return
+	}
 
-	// Generate RSS feed
-	feed, err := GenerateRSSFeed(articles, s.config)
+	feed, err := GenerateFeed(format, articles, articleTags, s.config, feedTitleSuffix(filter), filter.Source == "")
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to generate feed: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.Header().Set("Content-Type", contentType)
 	w.Write([]byte(feed))
 }
@@ -0,0 +1,13 @@
+package server
+
+import "net/http"
+
+// handleAPISession reports the state of the Gasetten login session
+func (s *Server) handleAPISession(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.scraper.SessionStatus())
+}
+
+// handleSessionBadge renders the session indicator shown in the header
+func (s *Server) handleSessionBadge(w http.ResponseWriter, r *http.Request) {
+	SessionBadge(s.scraper.SessionStatus()).Render(r.Context(), w)
+}
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Social preview card dimensions (the size recommended by OpenGraph consumers)
+const (
+	shareCardWidth  = 1200
+	shareCardHeight = 630
+	shareCardMargin = 80
+)
+
+// ShareMeta holds the OpenGraph/Twitter card metadata for a shared article
+type ShareMeta struct {
+	Title       string
+	Description string
+	URL         string
+	ImageURL    string
+	SiteName    string
+}
+
+// shareURL returns the public share URL of an article
+func (s *Server) shareURL(article *database.Article) string {
+	return strings.TrimSuffix(s.config.FeedLink, "/") + "/share/" + s.shareLinks.Token(articleRef(article))
+}
+
+// sharedArticle resolves the share token in the URL to its article
+func (s *Server) sharedArticle(r *http.Request) (*database.Article, string, error) {
+	token := chi.URLParam(r, "token")
+	ref, err := s.shareLinks.Verify(token)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Links shared before UUIDs were introduced carry the numeric ID
+	article, _, err := s.resolveArticle(r.Context(), ref)
+	if err != nil {
+		return nil, "", err
+	}
+	return article, token, nil
+}
+
+// handleSharedArticle serves the public page of a shared article
+func (s *Server) handleSharedArticle(w http.ResponseWriter, r *http.Request) {
+	article, token, err := s.sharedArticle(r)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	base := strings.TrimSuffix(s.config.FeedLink, "/")
+	meta := ShareMeta{
+		Title:    getTitle(article),
+		URL:      base + "/share/" + token,
+		ImageURL: base + "/share/" + token + "/card.png",
+		SiteName: s.config.FeedTitle,
+	}
+	if article.ContentText != nil {
+		meta.Description = truncate(strings.Join(strings.Fields(*article.ContentText), " "), 200)
+	}
+
+	SharedArticlePage(article, meta).Render(r.Context(), w)
+}
+
+// handleShareCard serves the generated social preview image
+func (s *Server) handleShareCard(w http.ResponseWriter, r *http.Request) {
+	article, _, err := s.sharedArticle(r)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	card, err := renderShareCard(getTitle(article), shareCardByline(article), s.config.FeedTitle)
+	if err != nil {
+		logging.Warnf("Failed to render share card for article %s: %v", article.UUID, err)
+		http.Error(w, "Failed to render preview", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Write(card)
+}
+
+// shareCardByline describes the author and date for the preview card
+func shareCardByline(article *database.Article) string {
+	var parts []string
+	if article.Author != nil {
+		parts = append(parts, *article.Author)
+	}
+	if article.PublishedAt != nil {
+		parts = append(parts, article.PublishedAt.Format("January 2, 2006"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// renderShareCard draws a PNG preview card with the title, byline and site name
+func renderShareCard(title, byline, site string) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, shareCardWidth, shareCardHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{color.RGBA{0x11, 0x18, 0x27, 0xff}}, image.Point{}, draw.Src)
+
+	// Accent bar along the left edge
+	accent := image.Rect(0, 0, 16, shareCardHeight)
+	draw.Draw(img, accent, &image.Uniform{color.RGBA{0xea, 0x58, 0x0c, 0xff}}, image.Point{}, draw.Src)
+
+	titleFace, err := loadFace(gobold.TTF, 64)
+	if err != nil {
+		return nil, err
+	}
+	smallFace, err := loadFace(goregular.TTF, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	white := image.NewUniform(color.White)
+	grey := image.NewUniform(color.RGBA{0x9c, 0xa3, 0xaf, 0xff})
+
+	// Title, wrapped to at most four lines
+	lines := wrapText(titleFace, title, shareCardWidth-2*shareCardMargin, 4)
+	y := shareCardMargin + 64
+	for _, line := range lines {
+		drawText(img, titleFace, white, shareCardMargin, y, line)
+		y += 80
+	}
+
+	if byline != "" {
+		drawText(img, smallFace, grey, shareCardMargin, shareCardHeight-shareCardMargin-48, byline)
+	}
+	drawText(img, smallFace, grey, shareCardMargin, shareCardHeight-shareCardMargin, site)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode share card: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func loadFace(ttf []byte, size float64) (font.Face, error) {
+	parsed, err := opentype.Parse(ttf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create font face: %w", err)
+	}
+	return face, nil
+}
+
+func drawText(img draw.Image, face font.Face, src image.Image, x, y int, text string) {
+	d := &font.Drawer{Dst: img, Src: src, Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(text)
+}
+
+// wrapText breaks text into lines no wider than maxWidth pixels. Text that
+// does not fit in maxLines is cut with an ellipsis.
+func wrapText(face font.Face, text string, maxWidth, maxLines int) []string {
+	var lines []string
+	current := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if current == "" || font.MeasureString(face, candidate).Ceil() <= maxWidth {
+			current = candidate
+			continue
+		}
+		if len(lines) == maxLines-1 {
+			return append(lines, ellipsize(face, current, maxWidth))
+		}
+		lines = append(lines, current)
+		current = word
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+// ellipsize drops trailing words until the line plus an ellipsis fits
+func ellipsize(face font.Face, line string, maxWidth int) string {
+	for font.MeasureString(face, line+"…").Ceil() > maxWidth {
+		i := strings.LastIndex(line, " ")
+		if i <= 0 {
+			break
+		}
+		line = line[:i]
+	}
+	return line + "…"
+}
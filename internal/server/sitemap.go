@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxSitemapURLs is the most URLs a sitemap may list; beyond it only the
+// most recent articles are listed
+const maxSitemapURLs = 50000
+
+// sitemapURLSet is the root element of a sitemap (sitemaps.org protocol 0.9)
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+// handleSitemap serves the detail pages of the archived articles as a
+// sitemap, for crawlers such as search appliances or mirroring tools. The
+// last modification is when the article was last scraped or edited.
+func (s *Server) handleSitemap(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.db.GetSitemapEntries(r.Context(), maxSitemapURLs)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch articles: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	base := strings.TrimSuffix(s.config.FeedLink, "/")
+	set := sitemapURLSet{
+		XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		URLs:  make([]sitemapURL, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		set.URLs = append(set.URLs, sitemapURL{
+			Loc:     base + "/articles/" + entry.UUID,
+			LastMod: entry.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	output, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to generate sitemap: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	w.Write(output)
+}
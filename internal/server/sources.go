@@ -0,0 +1,199 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/i18n"
+	"github.com/tkilaker/kiln/internal/logging"
+	"github.com/tkilaker/kiln/internal/scraper"
+)
+
+var (
+	// sourceName matches source names, which are also stored on articles
+	sourceName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+	// credentialsRef matches references to credentials: "env:GASETTEN" for
+	// GASETTEN_USER and GASETTEN_PASS, "store:gasetten" for the encrypted
+	// credentials stored as gasetten, or "vault:secret/data/gasetten" for a
+	// Vault secret
+	credentialsRef = regexp.MustCompile(`^(env:[A-Z][A-Z0-9_]*|store:[a-z0-9][a-z0-9-]*|vault:[A-Za-z0-9_][A-Za-z0-9_./-]*)$`)
+)
+
+// handleSources lists the configured sources
+func (s *Server) handleSources(w http.ResponseWriter, r *http.Request) {
+	sources, err := s.db.GetSources(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch sources: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	backfill := make(map[string]bool)
+	for _, source := range sources {
+		backfill[source.Name] = s.scraper.CanBackfill(source.Name)
+	}
+
+	SourcesPage(sources, backfill).Render(r.Context(), w)
+}
+
+// handleNewSource renders the form for adding a source
+func (s *Server) handleNewSource(w http.ResponseWriter, r *http.Request) {
+	SourceFormPage(&database.Source{Enabled: true}, "").Render(r.Context(), w)
+}
+
+// handleEditSource renders the form for editing a source
+func (s *Server) handleEditSource(w http.ResponseWriter, r *http.Request) {
+	source, err := s.sourceFromURL(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Source not found: %v", err), http.StatusNotFound)
+		return
+	}
+
+	SourceFormPage(source, "").Render(r.Context(), w)
+}
+
+// handleCreateSource adds a source from the submitted form
+func (s *Server) handleCreateSource(w http.ResponseWriter, r *http.Request) {
+	source := &database.Source{}
+	if err := applySourceForm(source, r); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		SourceFormPage(source, err.Error()).Render(r.Context(), w)
+		return
+	}
+
+	if err := s.db.CreateSource(r.Context(), source); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		SourceFormPage(source, err.Error()).Render(r.Context(), w)
+		return
+	}
+
+	logging.Infof("Added source %s", source.Name)
+	s.audit(r, database.AuditSourceCreate, source.Name, source)
+	http.Redirect(w, r, "/sources", http.StatusSeeOther)
+}
+
+// handleUpdateSource stores the submitted form of an existing source
+func (s *Server) handleUpdateSource(w http.ResponseWriter, r *http.Request) {
+	source, err := s.sourceFromURL(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Source not found: %v", err), http.StatusNotFound)
+		return
+	}
+
+	before := *source
+	if err := applySourceForm(source, r); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		SourceFormPage(source, err.Error()).Render(r.Context(), w)
+		return
+	}
+
+	if err := s.db.UpdateSource(r.Context(), source); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		SourceFormPage(source, err.Error()).Render(r.Context(), w)
+		return
+	}
+
+	logging.Infof("Updated source %s", source.Name)
+	s.audit(r, database.AuditSourceUpdate, source.Name, map[string]any{"before": &before, "after": source})
+	http.Redirect(w, r, "/sources", http.StatusSeeOther)
+}
+
+// handleDeleteSource removes a source; its articles are kept
+func (s *Server) handleDeleteSource(w http.ResponseWriter, r *http.Request) {
+	source, err := s.sourceFromURL(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Source not found: %v", err), http.StatusNotFound)
+		return
+	}
+
+	if err := s.db.DeleteSource(r.Context(), source.ID); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to delete source: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	logging.Infof("Deleted source %s", source.Name)
+	s.audit(r, database.AuditSourceDelete, source.Name, source)
+
+	// Return empty response for HTMX to remove the row
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleBackfillSource scrapes every article in a source's sitemaps
+func (s *Server) handleBackfillSource(w http.ResponseWriter, r *http.Request) {
+	source, err := s.sourceFromURL(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Source not found: %v", err), http.StatusNotFound)
+		return
+	}
+	if !s.scraper.CanBackfill(source.Name) {
+		writeNotice(w, "yellow", i18n.Tf(r.Context(), "Source %s has no sitemaps or WordPress API to backfill from.", source.Name))
+		return
+	}
+
+	s.startBackgroundScrape(w, r, "backfill of "+source.Name, scraper.JobRequest{Kind: scraper.JobBackfill, Trigger: database.RunTriggerBackfill, Source: source.Name})
+}
+
+func (s *Server) sourceFromURL(r *http.Request) (*database.Source, error) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid source ID")
+	}
+	return s.db.GetSource(r.Context(), id)
+}
+
+// applySourceForm copies and validates the submitted source fields. The
+// fields are applied even when invalid, so the form can be shown again.
+func applySourceForm(source *database.Source, r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return fmt.Errorf("invalid form")
+	}
+
+	source.Name = strings.TrimSpace(r.PostForm.Get("name"))
+	source.StartURLs = strings.Fields(r.PostForm.Get("start_urls"))
+	source.CredentialsRef = optionalString(r.PostForm.Get("credentials_ref"))
+	source.FeedURL = optionalString(r.PostForm.Get("feed_url"))
+	source.Schedule = optionalString(r.PostForm.Get("schedule"))
+	source.ScrapeInterval = optionalString(r.PostForm.Get("scrape_interval"))
+	source.QuietHours = optionalString(r.PostForm.Get("quiet_hours"))
+	source.MaxRunsPerDay = nil
+	if value := strings.TrimSpace(r.PostForm.Get("max_runs_per_day")); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("max runs per day must be a number")
+		}
+		source.MaxRunsPerDay = &n
+	}
+	source.Enabled = r.PostForm.Get("enabled") != ""
+
+	if !sourceName.MatchString(source.Name) {
+		return fmt.Errorf("name must be lowercase letters, digits and dashes")
+	}
+	if len(source.StartURLs) == 0 {
+		return fmt.Errorf("at least one start URL is required")
+	}
+	for _, raw := range source.StartURLs {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid start URL %q", raw)
+		}
+	}
+	if source.FeedURL != nil {
+		u, err := url.Parse(*source.FeedURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid feed URL %q", *source.FeedURL)
+		}
+	}
+	if source.CredentialsRef != nil && !credentialsRef.MatchString(*source.CredentialsRef) {
+		return fmt.Errorf("credentials reference must look like env:NAME, store:name or vault:path")
+	}
+	if _, err := scraper.ParseSourceSchedule(source); err != nil {
+		return err
+	}
+	return nil
+}
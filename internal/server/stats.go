@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/media"
+)
+
+// mediaUsageTTL is how long the size of the mirrored images is reused;
+// adding it up lists the whole store
+const mediaUsageTTL = 15 * time.Minute
+
+// StatsReport combines archive statistics with scrape run statistics,
+// storage use and feed requests
+type StatsReport struct {
+	Articles    *database.Stats    `json:"articles"`
+	Runs        *database.RunStats `json:"runs"`
+	SuccessRate float64            `json:"success_rate"`
+	Storage     StorageStats       `json:"storage"`
+
+	// FeedRequests counts the requests per feed over the last 30 days
+	FeedRequests []database.FacetCount `json:"feed_requests"`
+}
+
+// StorageStats is the space used by the database and the mirrored images
+type StorageStats struct {
+	DatabaseBytes int64 `json:"database_bytes"`
+	MediaBytes    int64 `json:"media_bytes"`
+	MediaFiles    int   `json:"media_files"`
+	TotalBytes    int64 `json:"total_bytes"`
+}
+
+// mediaUsage caches the size of the mirrored images
+type mediaUsage struct {
+	mu      sync.Mutex
+	bytes   int64
+	files   int
+	updated time.Time
+}
+
+// get returns the size and number of the mirrored images, listing the
+// store when the cached figures are older than mediaUsageTTL
+func (u *mediaUsage) get(ctx context.Context, mirror *media.Mirror) (int64, int, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if time.Since(u.updated) < mediaUsageTTL {
+		return u.bytes, u.files, nil
+	}
+
+	images, err := mirror.List(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	u.bytes, u.files = 0, len(images)
+	for _, image := range images {
+		u.bytes += image.Size
+	}
+	u.updated = time.Now()
+	return u.bytes, u.files, nil
+}
+
+// buildStatsReport gathers the statistics shown on /stats and /api/v1/stats
+func (s *Server) buildStatsReport(r *http.Request) (*StatsReport, error) {
+	ctx := r.Context()
+
+	articleStats, err := s.db.GetStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := s.db.GetRunStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	feeds, err := s.db.GetFeedAccessCounts(ctx, time.Now().Add(-feedAccessWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	storage := StorageStats{DatabaseBytes: articleStats.DatabaseSize}
+	if s.media != nil {
+		if storage.MediaBytes, storage.MediaFiles, err = s.mediaUsage.get(ctx, s.media); err != nil {
+			return nil, err
+		}
+	}
+	storage.TotalBytes = storage.DatabaseBytes + storage.MediaBytes
+
+	return &StatsReport{
+		Articles:     articleStats,
+		Runs:         runs,
+		SuccessRate:  runs.SuccessRate(),
+		Storage:      storage,
+		FeedRequests: feeds,
+	}, nil
+}
+
+// handleStats renders the statistics dashboard
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	report, err := s.buildStatsReport(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch statistics: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	StatsPage(report).Render(r.Context(), w)
+}
+
+// handleAPIStats serves the statistics as JSON
+func (s *Server) handleAPIStats(w http.ResponseWriter, r *http.Request) {
+	report, err := s.buildStatsReport(r)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("failed to fetch statistics: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// formatBytes renders a byte count in human readable units
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// maxFacetCount returns the largest count, for scaling bar widths
+func maxFacetCount(facets []database.FacetCount) int {
+	max := 0
+	for _, f := range facets {
+		if f.Count > max {
+			max = f.Count
+		}
+	}
+	return max
+}
+
+// barWidth returns a CSS width percentage for a count relative to max
+func barWidth(count, max int) string {
+	if max == 0 {
+		return "width: 0%"
+	}
+	return fmt.Sprintf("width: %d%%", count*100/max)
+}
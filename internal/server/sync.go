@@ -0,0 +1,38 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/tkilaker/kiln/internal/peersync"
+)
+
+// handleSyncArticles serves a page of article changes to peer instances
+func (s *Server) handleSyncArticles(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	cursor, err := peersync.ParseCursor(q.Get("cursor"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit := peersync.DefaultPageSize
+	if v := q.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = min(limit, peersync.MaxPageSize)
+	}
+
+	// Fetch one extra article to know whether another page follows
+	articles, err := s.db.GetArticlesChangedSince(r.Context(), cursor.UpdatedAt, cursor.UUID, limit+1)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to fetch changes")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, peersync.NewPage(articles, limit, cursor))
+}
@@ -0,0 +1,281 @@
+package server
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+const (
+	// maxFeedTags is how many of the most used tags get a feed listed
+	maxFeedTags = 50
+
+	// maxFilterTags is how many of the most used tags the filter offers
+	maxFilterTags = 200
+
+	// maxTagsPage is how many tags the tags page lists
+	maxTagsPage = 1000
+
+	// maxTagLength bounds the length of a tag added by hand
+	maxTagLength = 64
+
+	// defaultAPIArticles and maxAPIArticles bound the articles API's limit
+	defaultAPIArticles = 50
+	maxAPIArticles     = 500
+)
+
+// handleTags lists the tags of the archive, each linking to its articles
+func (s *Server) handleTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := s.db.GetTagCounts(r.Context(), maxTagsPage)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch tags: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	TagsPage(tags).Render(r.Context(), w)
+}
+
+// handleTagArticles lists the articles with a tag, narrowed by any other
+// filters in the query
+func (s *Server) handleTagArticles(w http.ResponseWriter, r *http.Request) {
+	tag, err := url.PathUnescape(chi.URLParam(r, "tag"))
+	if err != nil {
+		http.Error(w, "Invalid tag", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := parseArticleFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.Tag = tag
+
+	s.renderArticleList(w, r, filter)
+}
+
+// handleAddArticleTag tags an article by hand and renders its tags again
+func (s *Server) handleAddArticleTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	article, _, err := s.resolveArticle(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Article not found: %v", err), http.StatusNotFound)
+		return
+	}
+
+	tag, err := normalizeTag(r.FormValue("tag"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.db.AddArticleTag(ctx, article.ID, tag); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to add tag: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	logging.Infof("Tagged article %s with %q", article.UUID, tag)
+	s.renderArticleTags(w, r, article)
+}
+
+// handleRemoveArticleTag removes a tag from an article and renders its tags
+// again
+func (s *Server) handleRemoveArticleTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	article, _, err := s.resolveArticle(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Article not found: %v", err), http.StatusNotFound)
+		return
+	}
+	tag, err := url.PathUnescape(chi.URLParam(r, "tag"))
+	if err != nil {
+		http.Error(w, "Invalid tag", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.RemoveArticleTag(ctx, article.ID, tag); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to remove tag: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	logging.Infof("Removed tag %q from article %s", tag, article.UUID)
+	s.renderArticleTags(w, r, article)
+}
+
+func (s *Server) renderArticleTags(w http.ResponseWriter, r *http.Request, article *database.Article) {
+	tags, err := s.db.GetArticleTags(r.Context(), article.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch tags: %v", err), http.StatusInternalServerError)
+		return
+	}
+	ArticleTags(article, tags).Render(r.Context(), w)
+}
+
+// normalizeTag trims a tag and collapses its whitespace
+func normalizeTag(tag string) (string, error) {
+	tag = strings.Join(strings.Fields(tag), " ")
+	if tag == "" {
+		return "", fmt.Errorf("tag is required")
+	}
+	if utf8.RuneCountInString(tag) > maxTagLength {
+		return "", fmt.Errorf("tag is longer than %d characters", maxTagLength)
+	}
+	return tag, nil
+}
+
+// APIArticle is an article as listed by the JSON API
+type APIArticle struct {
+	ID          string     `json:"id"`
+	URL         string     `json:"url"`
+	Source      string     `json:"source"`
+	Title       *string    `json:"title"`
+	Author      *string    `json:"author"`
+	PublishedAt *time.Time `json:"published_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	WordCount   int        `json:"word_count"`
+	Partial     bool       `json:"partial"`
+	Read        bool       `json:"read"`
+	Categories  []string   `json:"categories"`
+	Tags        []string   `json:"tags"`
+}
+
+// handleAPIArticles lists articles as JSON, most recently saved first,
+// narrowed by the source, author, tag, from, to and unread query parameters.
+// limit sets how many are returned; when more follow, the cursor of the next
+// page is sent in the Link and X-Next-Cursor headers.
+func (s *Server) handleAPIArticles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := parseArticleFilter(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit := defaultAPIArticles
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxAPIArticles {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxAPIArticles))
+			return
+		}
+	}
+
+	cursor, err := parseArticleCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Fetch one extra article to know whether another page follows
+	articles, err := s.db.GetArticlesBefore(ctx, filter, cursor, limit+1)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("failed to fetch articles: %v", err))
+		return
+	}
+	if len(articles) > limit {
+		articles = articles[:limit]
+		last := articles[limit-1]
+		next := articleCursorString(database.ArticleCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+
+		q := r.URL.Query()
+		q.Set("cursor", next)
+		w.Header().Set("Link", fmt.Sprintf("<%s?%s>; rel=\"next\"", r.URL.Path, q.Encode()))
+		w.Header().Set("X-Next-Cursor", next)
+	}
+	tags, err := s.db.GetTagsForArticles(ctx, articleIDs(articles))
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("failed to fetch tags: %v", err))
+		return
+	}
+	read, err := s.db.GetReadArticleIDs(ctx, articleIDs(articles))
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("failed to fetch read state: %v", err))
+		return
+	}
+
+	result := make([]APIArticle, 0, len(articles))
+	for _, article := range articles {
+		articleTags := tags[article.ID]
+		if articleTags == nil {
+			articleTags = []string{}
+		}
+		result = append(result, APIArticle{
+			ID:          articleRef(article),
+			URL:         article.URL,
+			Source:      article.Source,
+			Title:       article.Title,
+			Author:      article.Author,
+			PublishedAt: article.PublishedAt,
+			CreatedAt:   article.CreatedAt,
+			WordCount:   article.WordCount,
+			Partial:     article.Partial,
+			Read:        read[article.ID],
+			Categories:  article.Categories,
+			Tags:        articleTags,
+		})
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleAPITags lists the tags with their number of articles as JSON
+func (s *Server) handleAPITags(w http.ResponseWriter, r *http.Request) {
+	tags, err := s.db.GetTagCounts(r.Context(), maxTagsPage)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("failed to fetch tags: %v", err))
+		return
+	}
+	if tags == nil {
+		tags = []database.FacetCount{}
+	}
+	writeJSON(w, http.StatusOK, tags)
+}
+
+// articleCursorString encodes a position in the articles API
+func articleCursorString(c database.ArticleCursor) string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.Itoa(c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// parseArticleCursor decodes a cursor from the articles API; "" is the start
+// of the list
+func parseArticleCursor(s string) (database.ArticleCursor, error) {
+	if s == "" {
+		return database.ArticleCursor{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return database.ArticleCursor{}, fmt.Errorf("malformed cursor")
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return database.ArticleCursor{}, fmt.Errorf("malformed cursor")
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return database.ArticleCursor{}, fmt.Errorf("malformed cursor: %w", err)
+	}
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return database.ArticleCursor{}, fmt.Errorf("malformed cursor: %w", err)
+	}
+	return database.ArticleCursor{CreatedAt: createdAt, ID: n}, nil
+}
+
+func articleIDs(articles []*database.Article) []int {
+	ids := make([]int, 0, len(articles))
+	for _, article := range articles {
+		ids = append(ids, article.ID)
+	}
+	return ids
+}
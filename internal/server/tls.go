@@ -0,0 +1,84 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/logging"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TLSEnabled reports whether the server terminates HTTPS itself
+func (s *Server) TLSEnabled() bool {
+	return s.config.TLSCertFile != "" || len(s.config.ACMEDomains) > 0
+}
+
+// startTLS serves HTTPS on the configured HTTPS port and redirects plain
+// HTTP on addr to it. With ACME domains configured, certificates are
+// obtained from Let's Encrypt and the HTTP listener also answers the
+// HTTP-01 challenges.
+func (s *Server) startTLS(addr string) error {
+	httpsAddr := fmt.Sprintf(":%d", s.config.HTTPSPort)
+	httpsServer := &http.Server{
+		Addr:              httpsAddr,
+		Handler:           s.router,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	var redirect http.Handler = http.HandlerFunc(s.redirectToHTTPS)
+	if len(s.config.ACMEDomains) > 0 {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(s.config.ACMEDomains...),
+			Cache:      autocert.DirCache(s.config.ACMECacheDir),
+			Email:      s.config.ACMEEmail,
+		}
+		httpsServer.TLSConfig = manager.TLSConfig()
+		redirect = manager.HTTPHandler(redirect)
+		logging.Infof("Obtaining certificates for %s via ACME", strings.Join(s.config.ACMEDomains, ", "))
+	} else {
+		httpsServer.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	redirectServer := &http.Server{
+		Addr:              addr,
+		Handler:           redirect,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		logging.Infof("Redirecting HTTP on %s to HTTPS", addr)
+		if err := redirectServer.ListenAndServe(); err != nil {
+			logging.Warnf("HTTP redirect server stopped: %v", err)
+		}
+	}()
+
+	logging.Infof("Starting HTTPS server on %s", httpsAddr)
+	// With ACME the certificate comes from TLSConfig, so no files are given
+	return httpsServer.ListenAndServeTLS(s.config.TLSCertFile, s.config.TLSKeyFile)
+}
+
+// redirectToHTTPS sends plain HTTP requests to the HTTPS site. FEED_LINK is
+// used when it is an https URL, since it names the public address.
+func (s *Server) redirectToHTTPS(w http.ResponseWriter, r *http.Request) {
+	target := url.URL{Scheme: "https", Path: r.URL.Path, RawQuery: r.URL.RawQuery}
+
+	if public, err := url.Parse(s.config.FeedLink); err == nil && public.Scheme == "https" {
+		target.Host = public.Host
+	} else {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		target.Host = host
+		if s.config.HTTPSPort != 443 {
+			target.Host = net.JoinHostPort(host, fmt.Sprint(s.config.HTTPSPort))
+		}
+	}
+
+	http.Redirect(w, r, target.String(), http.StatusPermanentRedirect)
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/scraper"
+	"golang.org/x/net/websocket"
+)
+
+// socketCommand is a message from a progress socket client. Type is one of
+// subscribe, unsubscribe, cancel, pause or resume; LastSeq lets a subscriber
+// that reconnects get the updates it missed.
+type socketCommand struct {
+	Type    string `json:"type"`
+	Job     string `json:"job"`
+	LastSeq int    `json:"last_seq"`
+}
+
+// socketMessage is a message to a progress socket client: a progress update
+// of a subscribed job, an acknowledged command, an error or a heartbeat
+type socketMessage struct {
+	Type     string         `json:"type"`
+	Job      string         `json:"job,omitempty"`
+	Seq      int            `json:"seq,omitempty"`
+	Progress *progressEvent `json:"progress,omitempty"`
+	Action   string         `json:"action,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// handleProgressSocket serves /ws/progress, a WebSocket alternative to the
+// progress streams that also takes commands for the jobs it follows
+func (s *Server) handleProgressSocket(w http.ResponseWriter, r *http.Request) {
+	server := websocket.Server{
+		Handshake: sameOrigin,
+		Handler:   s.serveProgressSocket,
+	}
+	server.ServeHTTP(w, r)
+}
+
+// sameOrigin rejects handshakes from other sites, as browsers send the
+// session cookie with them. Clients that are not browsers send no Origin.
+func sameOrigin(config *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host != r.Host {
+		return fmt.Errorf("origin %q not allowed", origin)
+	}
+	config.Origin = u
+	return nil
+}
+
+// progressSocket is a connected progress socket and the jobs it follows
+type progressSocket struct {
+	conn   *websocket.Conn
+	cancel context.CancelFunc // closes the socket
+
+	mu   sync.Mutex
+	subs map[string]*socketSub
+}
+
+// socketSub is a job followed by a progress socket
+type socketSub struct {
+	cancel context.CancelFunc
+}
+
+// send writes a message, closing the socket when that fails
+func (ps *progressSocket) send(msg socketMessage) {
+	if err := websocket.JSON.Send(ps.conn, msg); err != nil {
+		ps.cancel()
+	}
+}
+
+func (s *Server) serveProgressSocket(conn *websocket.Conn) {
+	ctx, cancel := context.WithCancel(conn.Request().Context())
+	defer cancel()
+
+	ps := &progressSocket{
+		conn:   conn,
+		cancel: cancel,
+		subs:   make(map[string]*socketSub),
+	}
+
+	// Reading blocks until the client sends something, so the socket is
+	// closed from here when the context ends
+	go func() {
+		heartbeat := time.NewTicker(progressHeartbeatInterval)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				conn.Close()
+				return
+			case <-heartbeat.C:
+				ps.send(socketMessage{Type: "heartbeat"})
+			}
+		}
+	}()
+
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(conn, &data); err != nil {
+			return
+		}
+
+		var cmd socketCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			ps.send(socketMessage{Type: "error", Error: fmt.Sprintf("invalid command: %v", err)})
+			continue
+		}
+		s.handleSocketCommand(ctx, ps, cmd)
+	}
+}
+
+// handleSocketCommand carries out a command from a progress socket client
+func (s *Server) handleSocketCommand(ctx context.Context, ps *progressSocket, cmd socketCommand) {
+	var err error
+	switch cmd.Type {
+	case "subscribe":
+		err = s.subscribeSocket(ctx, ps, cmd.Job, cmd.LastSeq)
+	case "unsubscribe":
+		ps.unsubscribe(cmd.Job)
+	case "cancel":
+		err = s.scraper.Cancel(ctx, cmd.Job)
+	case "pause":
+		err = s.scraper.Pause(ctx, cmd.Job)
+	case "resume":
+		err = s.scraper.Resume(ctx, cmd.Job)
+	default:
+		err = fmt.Errorf("unknown command %q", cmd.Type)
+	}
+
+	if err != nil {
+		ps.send(socketMessage{Type: "error", Job: cmd.Job, Action: cmd.Type, Error: err.Error()})
+		return
+	}
+	ps.send(socketMessage{Type: "ack", Job: cmd.Job, Action: cmd.Type})
+}
+
+// subscribeSocket sends the progress of a job to the socket until the job
+// finishes or the client unsubscribes
+func (s *Server) subscribeSocket(ctx context.Context, ps *progressSocket, id string, lastSeq int) error {
+	job := s.scraper.Job(id)
+	if job == nil {
+		return scraper.ErrJobNotFound
+	}
+
+	ps.mu.Lock()
+	if _, ok := ps.subs[id]; ok {
+		ps.mu.Unlock()
+		return nil
+	}
+	subCtx, cancel := context.WithCancel(ctx)
+	sub := &socketSub{cancel: cancel}
+	ps.subs[id] = sub
+	ps.mu.Unlock()
+
+	tracker := job.Progress()
+	updates := tracker.SubscribeFrom(lastSeq)
+
+	go func() {
+		defer ps.remove(id, sub)
+		defer tracker.Unsubscribe(updates)
+
+		for {
+			select {
+			case <-subCtx.Done():
+				return
+			case update, ok := <-updates:
+				if !ok {
+					return
+				}
+
+				event := s.newProgressEvent(subCtx, update)
+				ps.send(socketMessage{Type: "progress", Job: id, Seq: update.Seq, Progress: &event})
+				if update.Status.Finished() {
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// unsubscribe stops following a job
+func (ps *progressSocket) unsubscribe(id string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if sub, ok := ps.subs[id]; ok {
+		sub.cancel()
+		delete(ps.subs, id)
+	}
+}
+
+// remove forgets a subscription that ended, unless the job has been
+// subscribed to again since
+func (ps *progressSocket) remove(id string, sub *socketSub) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	sub.cancel()
+	if ps.subs[id] == sub {
+		delete(ps.subs, id)
+	}
+}
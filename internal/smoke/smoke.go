@@ -0,0 +1,472 @@
+package smoke
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Options configures a smoke test run against a live instance
+type Options struct {
+	BaseURL  string
+	APIKey   string
+	Username string
+	Password string
+	Timeout  time.Duration
+
+	// ScrapeTimeout bounds the dry-run scrape, which fetches every source;
+	// zero uses Timeout
+	ScrapeTimeout time.Duration
+}
+
+// Result is the outcome of a single check
+type Result struct {
+	Name     string
+	Status   string // "PASS", "FAIL" or "SKIP"
+	Detail   string
+	Duration time.Duration
+}
+
+// errSkip marks a check that does not apply to the instance
+type errSkip struct{ reason string }
+
+func (e errSkip) Error() string { return e.reason }
+
+func skip(format string, args ...any) error {
+	return errSkip{reason: fmt.Sprintf(format, args...)}
+}
+
+// check is a single named smoke test step
+type check struct {
+	name   string
+	run    func(ctx context.Context, c *client) (string, error)
+	scrape bool // runs a scrape, bounded by ScrapeTimeout
+}
+
+var checks = []check{
+	{"health", checkHealth, false},
+	{"auth", checkAuth, false},
+	{"list", checkList, false},
+	{"feed", checkFeed, false},
+	{"dry-run scrape", checkDryRun, true},
+	{"sse", checkSSE, false},
+}
+
+// Run executes all checks in order and returns their results
+func Run(ctx context.Context, opts Options) []Result {
+	c, err := newClient(opts)
+	if err != nil {
+		return []Result{{Name: "setup", Status: "FAIL", Detail: err.Error()}}
+	}
+
+	results := make([]Result, 0, len(checks))
+	for _, chk := range checks {
+		timeout := opts.Timeout
+		if chk.scrape && opts.ScrapeTimeout > 0 {
+			timeout = opts.ScrapeTimeout
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		started := time.Now()
+		detail, err := chk.run(checkCtx, c)
+		cancel()
+
+		result := Result{Name: chk.name, Status: "PASS", Detail: detail, Duration: time.Since(started)}
+		var skipped errSkip
+		switch {
+		case errors.As(err, &skipped):
+			result.Status = "SKIP"
+			result.Detail = skipped.reason
+		case err != nil:
+			result.Status = "FAIL"
+			result.Detail = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// Passed reports whether no check failed
+func Passed(results []Result) bool {
+	for _, r := range results {
+		if r.Status == "FAIL" {
+			return false
+		}
+	}
+	return true
+}
+
+// client wraps an HTTP client with the instance's base URL and credentials
+type client struct {
+	opts   Options
+	http   *http.Client
+	base   *url.URL
+	authed bool // whether the instance requires authentication
+	basic  bool // whether to send the credentials as HTTP Basic auth
+
+	jobID string // the dry-run job, once started
+}
+
+func newClient(opts Options) (*client, error) {
+	base, err := url.Parse(strings.TrimSuffix(opts.BaseURL, "/"))
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q", opts.BaseURL)
+	}
+
+	jar, _ := cookiejar.New(nil)
+	return &client{
+		opts: opts,
+		base: base,
+		http: &http.Client{
+			Jar: jar,
+			// Surface redirects (e.g. to /login) instead of following them
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}, nil
+}
+
+// get issues a GET request, sending the API key when configured
+func (c *client) get(ctx context.Context, path string, withKey bool) (*http.Response, error) {
+	return c.do(ctx, http.MethodGet, path, withKey)
+}
+
+// do issues a request without a body, sending the API key when configured
+func (c *client) do(ctx context.Context, method, path string, withKey bool) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.base.String()+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if withKey && c.opts.APIKey != "" {
+		req.Header.Set("X-API-Key", c.opts.APIKey)
+	}
+	if c.basic {
+		req.SetBasicAuth(c.opts.Username, c.opts.Password)
+	}
+	return c.http.Do(req)
+}
+
+func checkHealth(ctx context.Context, c *client) (string, error) {
+	resp, err := c.get(ctx, "/health", false)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("/health returned %s", resp.Status)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+func checkAuth(ctx context.Context, c *client) (string, error) {
+	// An anonymous API request tells us whether authentication is enabled
+	resp, err := c.get(ctx, "/api/v1/stats", false)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return "authentication disabled on instance", nil
+	case http.StatusUnauthorized:
+		c.authed = true
+	default:
+		return "", fmt.Errorf("anonymous API request returned %s", resp.Status)
+	}
+
+	var details []string
+	if c.opts.APIKey != "" {
+		resp, err := c.get(ctx, "/api/v1/stats", true)
+		if err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("API key rejected: %s", resp.Status)
+		}
+		details = append(details, "API key accepted")
+	}
+
+	if c.opts.Username != "" && basicAuthAccepted(ctx, c) {
+		c.basic = true
+		details = append(details, "basic auth accepted")
+	} else if c.opts.Username != "" {
+		form := url.Values{"username": {c.opts.Username}, "password": {c.opts.Password}, "next": {"/articles"}}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base.String()+"/login", strings.NewReader(form.Encode()))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusSeeOther {
+			return "", fmt.Errorf("login failed: %s", resp.Status)
+		}
+		details = append(details, "password login accepted")
+	}
+
+	if len(details) == 0 {
+		return "", fmt.Errorf("instance requires authentication; pass --api-key and/or --user/--password")
+	}
+	return strings.Join(details, ", "), nil
+}
+
+// basicAuthAccepted reports whether the instance accepts the credentials as
+// HTTP Basic auth
+func basicAuthAccepted(ctx context.Context, c *client) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base.String()+"/api/v1/stats", nil)
+	if err != nil {
+		return false
+	}
+	req.SetBasicAuth(c.opts.Username, c.opts.Password)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+func checkList(ctx context.Context, c *client) (string, error) {
+	if c.authed && c.opts.Username == "" {
+		return "", skip("article list needs a browser session; pass --user/--password")
+	}
+
+	resp, err := c.get(ctx, "/articles", false)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("/articles returned %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	cards := strings.Count(string(body), `id="article-`)
+	return fmt.Sprintf("%d articles rendered", cards), nil
+}
+
+func checkFeed(ctx context.Context, c *client) (string, error) {
+	resp, err := c.get(ctx, "/rss.xml", true)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("/rss.xml returned %s", resp.Status)
+	}
+
+	var rss struct {
+		XMLName xml.Name `xml:"rss"`
+		Version string   `xml:"version,attr"`
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				Title string `xml:"title"`
+				Link  string `xml:"link"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&rss); err != nil {
+		return "", fmt.Errorf("feed is not valid RSS: %w", err)
+	}
+	if rss.Channel.Title == "" {
+		return "", fmt.Errorf("feed channel has no title")
+	}
+	for i, item := range rss.Channel.Items {
+		if item.Title == "" || item.Link == "" {
+			return "", fmt.Errorf("feed item %d is missing a title or link", i+1)
+		}
+	}
+	return fmt.Sprintf("RSS %s, %d items", rss.Version, len(rss.Channel.Items)), nil
+}
+
+func checkDryRun(ctx context.Context, c *client) (string, error) {
+	if c.authed && c.opts.Username == "" {
+		return "", skip("following the scrape needs a browser session; pass --user/--password")
+	}
+
+	before, err := articleCount(ctx, c)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, "/scrape?dry_run=1", true)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("/scrape?dry_run=1 returned %s", resp.Status)
+	}
+	c.jobID = resp.Header.Get("X-Job-ID")
+	if c.jobID == "" {
+		// The instance answers with a notice instead of a job when one is
+		// already running
+		return "", skip("dry run not started; another job is running")
+	}
+
+	events, last, err := followJob(ctx, c, c.jobID)
+	if err != nil {
+		if ctx.Err() != nil {
+			cancelJob(c, c.jobID)
+		}
+		return "", err
+	}
+	if last.Status != "completed" {
+		return "", fmt.Errorf("dry run ended %s: %s", last.Status, last.Message)
+	}
+
+	after, err := articleCount(ctx, c)
+	if err != nil {
+		return "", err
+	}
+	if after != before {
+		return "", fmt.Errorf("dry run changed the number of articles from %d to %d", before, after)
+	}
+
+	preview, err := jobPreview(ctx, c, c.jobID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("job %s completed after %d events, %d articles previewed, none written", c.jobID, events, preview), nil
+}
+
+// jobPreview returns how many articles a dry run job reported on
+func jobPreview(ctx context.Context, c *client, id string) (int, error) {
+	resp, err := c.get(ctx, "/scrape/jobs/"+url.PathEscape(id)+"/preview", true)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("job preview returned %s", resp.Status)
+	}
+	var items []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return 0, fmt.Errorf("invalid job preview: %w", err)
+	}
+	return len(items), nil
+}
+
+// articleCount returns the number of articles the instance holds
+func articleCount(ctx context.Context, c *client) (int, error) {
+	resp, err := c.get(ctx, "/api/v1/stats", true)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("/api/v1/stats returned %s", resp.Status)
+	}
+	var stats struct {
+		Articles struct {
+			TotalArticles int `json:"total_articles"`
+		} `json:"articles"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return 0, fmt.Errorf("invalid stats: %w", err)
+	}
+	return stats.Articles.TotalArticles, nil
+}
+
+// progressUpdate is the part of a progress event the checks look at
+type progressUpdate struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// followJob reads the progress stream of a job, which starts with its
+// current state, until the job finishes. Returns the number of events and
+// the last one.
+func followJob(ctx context.Context, c *client, id string) (int, progressUpdate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base.String()+"/scrape/progress/"+url.PathEscape(id), nil)
+	if err != nil {
+		return 0, progressUpdate{}, err
+	}
+	if c.basic {
+		req.SetBasicAuth(c.opts.Username, c.opts.Password)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, progressUpdate{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, progressUpdate{}, fmt.Errorf("/scrape/progress/%s returned %s", id, resp.Status)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		return 0, progressUpdate{}, fmt.Errorf("unexpected content type %q", ct)
+	}
+
+	var events int
+	var last progressUpdate
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal([]byte(data), &last); err != nil {
+			return events, last, fmt.Errorf("invalid progress event: %w", err)
+		}
+		events++
+		switch last.Status {
+		case "completed", "failed", "cancelled":
+			return events, last, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return events, last, fmt.Errorf("stream error: %w", err)
+	}
+	return events, last, fmt.Errorf("stream closed before the job finished (last status %q)", last.Status)
+}
+
+// cancelJob stops a job the smoke test started, e.g. once its check timed
+// out
+func cancelJob(c *client, id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if resp, err := c.do(ctx, http.MethodPost, "/scrape/jobs/"+url.PathEscape(id)+"/cancel", true); err == nil {
+		resp.Body.Close()
+	}
+}
+
+func checkSSE(ctx context.Context, c *client) (string, error) {
+	if c.authed && c.opts.Username == "" {
+		return "", skip("progress stream needs a browser session; pass --user/--password")
+	}
+	if c.jobID == "" {
+		return "", skip("no dry-run job to follow")
+	}
+
+	// The dry run has finished; its stream still sends the final state
+	events, last, err := followJob(ctx, c, c.jobID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("followed job %s: %d events, status %s", c.jobID, events, last.Status), nil
+}
@@ -0,0 +1,67 @@
+// Package storage stores files in a local directory or on remote object
+// storage (S3-compatible services or WebDAV servers).
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by Get for missing files
+var ErrNotFound = errors.New("not found")
+
+// Object describes a stored file
+type Object struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Store is a flat namespace of named files
+type Store interface {
+	// Put writes body, which is size bytes long, under name
+	Put(ctx context.Context, name string, body io.ReadSeeker, size int64) error
+	// Get opens the file stored under name
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+	// List returns the files whose names start with prefix
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// Delete removes a file; deleting a missing file is not an error
+	Delete(ctx context.Context, name string) error
+}
+
+// Config selects and configures a store
+type Config struct {
+	// URL is file:///dir, s3://bucket/prefix, webdav://host/path or
+	// webdavs://host/path (credentials may be given as userinfo)
+	URL string
+
+	// S3 settings
+	S3Endpoint string // defaults to AWS for the region
+	S3Region   string
+	AccessKey  string
+	SecretKey  string
+}
+
+// Open creates the store described by cfg
+func Open(cfg Config) (Store, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage URL: %w", err)
+	}
+
+	switch u.Scheme {
+	case "file":
+		return NewLocal(u.Path)
+	case "s3":
+		return NewS3(u.Host, strings.Trim(u.Path, "/"), cfg)
+	case "webdav", "webdavs":
+		return NewWebDAV(u)
+	default:
+		return nil, fmt.Errorf("unsupported storage URL scheme %q", u.Scheme)
+	}
+}
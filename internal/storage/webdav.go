@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// propfindBody requests the properties List needs
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:"><d:prop><d:resourcetype/><d:getcontentlength/><d:getlastmodified/></d:prop></d:propfind>`
+
+// WebDAV stores files in a collection on a WebDAV server
+type WebDAV struct {
+	base     *url.URL // collection URL, ending in '/'
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewWebDAV creates a store from a webdav:// or webdavs:// URL. The
+// collection must already exist on the server.
+func NewWebDAV(u *url.URL) (*WebDAV, error) {
+	base := *u
+	base.Scheme = "http"
+	if u.Scheme == "webdavs" {
+		base.Scheme = "https"
+	}
+	base.User = nil
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+
+	w := &WebDAV{base: &base, client: &http.Client{Timeout: 30 * time.Minute}}
+	if u.User != nil {
+		w.username = u.User.Username()
+		w.password, _ = u.User.Password()
+	}
+	return w, nil
+}
+
+// Put implements Store
+func (w *WebDAV) Put(ctx context.Context, name string, body io.ReadSeeker, size int64) error {
+	resp, err := w.do(ctx, http.MethodPut, name, body, size, nil)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", name, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Get implements Store
+func (w *WebDAV) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	resp, err := w.do(ctx, http.MethodGet, name, nil, 0, nil)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	return resp.Body, nil
+}
+
+// List implements Store
+func (w *WebDAV) List(ctx context.Context, prefix string) ([]Object, error) {
+	headers := map[string]string{"Depth": "1", "Content-Type": "application/xml"}
+	body := strings.NewReader(propfindBody)
+	resp, err := w.do(ctx, "PROPFIND", "", body, int64(body.Len()), headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collection: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Responses []struct {
+			Href string `xml:"DAV: href"`
+			Prop struct {
+				ResourceType struct {
+					Collection *struct{} `xml:"DAV: collection"`
+				} `xml:"DAV: resourcetype"`
+				ContentLength string `xml:"DAV: getcontentlength"`
+				LastModified  string `xml:"DAV: getlastmodified"`
+			} `xml:"DAV: propstat>prop"`
+		} `xml:"DAV: response"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode collection listing: %w", err)
+	}
+
+	var objects []Object
+	for _, r := range result.Responses {
+		if r.Prop.ResourceType.Collection != nil {
+			continue
+		}
+		href, err := url.PathUnescape(r.Href)
+		if err != nil {
+			href = r.Href
+		}
+		name := path.Base(href)
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		obj := Object{Name: name}
+		obj.Size, _ = strconv.ParseInt(r.Prop.ContentLength, 10, 64)
+		obj.ModTime, _ = http.ParseTime(r.Prop.LastModified)
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// Delete implements Store
+func (w *WebDAV) Delete(ctx context.Context, name string) error {
+	resp, err := w.do(ctx, http.MethodDelete, name, nil, 0, nil)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete %s: %w", name, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends a request for name within the collection and fails on non-2xx
+// responses
+func (w *WebDAV) do(ctx context.Context, method, name string, body io.Reader, size int64, headers map[string]string) (*http.Response, error) {
+	target := w.base.JoinPath(name)
+	if name == "" {
+		target = w.base
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if w.username != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%s returned %s", method, resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,155 @@
+// Package summary has a language model write short summaries of saved
+// articles for the feed item descriptions. Any OpenAI-compatible chat
+// completions endpoint works, e.g. OpenAI's or a local Ollama.
+package summary
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+const (
+	// queueSize bounds the articles waiting for the worker; more are
+	// dropped rather than slowing down the scrape
+	queueSize = 1000
+
+	// maxInputLength bounds the characters of article text sent, which
+	// keeps long articles within the context of small models
+	maxInputLength = 12000
+
+	requestTimeout = 2 * time.Minute
+)
+
+// prompt instructs the model. Summaries are written in the article's
+// language, as most are in Swedish.
+const prompt = `Summarize the following news article in two or three sentences for a feed reader. ` +
+	`Write in the language of the article. Reply with the summary only.`
+
+// Summarizer summarizes articles one at a time in the background
+type Summarizer struct {
+	db       *database.DB
+	client   *http.Client
+	endpoint string
+	apiKey   string
+	model    string
+	queue    chan job
+}
+
+type job struct {
+	articleID int
+	url       string
+	title     string
+	text      string
+}
+
+// New creates a summarizer recording summaries in db. endpoint is the URL
+// of the chat completions API; apiKey may be empty for local models.
+func New(db *database.DB, endpoint, apiKey, model string) *Summarizer {
+	return &Summarizer{
+		db:       db,
+		client:   &http.Client{Timeout: requestTimeout},
+		endpoint: endpoint,
+		apiKey:   apiKey,
+		model:    model,
+		queue:    make(chan job, queueSize),
+	}
+}
+
+// Summarize queues an article to be summarized. It never blocks; articles
+// that don't fit the queue, or have no text, are skipped.
+func (s *Summarizer) Summarize(article *database.Article) {
+	if article.ContentText == nil || strings.TrimSpace(*article.ContentText) == "" {
+		return
+	}
+	title := ""
+	if article.Title != nil {
+		title = *article.Title
+	}
+	text := *article.ContentText
+	if runes := []rune(text); len(runes) > maxInputLength {
+		text = string(runes[:maxInputLength])
+	}
+
+	select {
+	case s.queue <- job{articleID: article.ID, url: article.URL, title: title, text: text}:
+	default:
+		logging.Warnf("Summary queue is full, not summarizing %s", article.URL)
+	}
+}
+
+// Run summarizes queued articles until ctx is cancelled
+func (s *Summarizer) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case j := <-s.queue:
+			summary, err := s.complete(ctx, j.title+"\n\n"+j.text)
+			if err != nil {
+				logging.Warnf("Summary of %s failed: %v", j.url, err)
+			} else if err := s.db.SetArticleSummary(ctx, j.articleID, summary); err != nil {
+				logging.Warnf("Failed to record summary of %s: %v", j.url, err)
+			} else {
+				logging.Infof("Summarized %s", j.url)
+			}
+		}
+	}
+}
+
+// complete asks the model to summarize text and returns its reply
+func (s *Summarizer) complete(ctx context.Context, text string) (string, error) {
+	payload, err := json.Marshal(map[string]any{
+		"model": s.model,
+		"messages": []map[string]string{
+			{"role": "system", "content": prompt},
+			{"role": "user", "content": text},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("%s returned %s: %s", s.endpoint, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var completion struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(completion.Choices) == 0 || strings.TrimSpace(completion.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("the model returned no summary")
+	}
+	return strings.TrimSpace(completion.Choices[0].Message.Content), nil
+}
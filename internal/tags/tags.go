@@ -0,0 +1,191 @@
+// Package tags extracts keywords (players, opponents, competitions) from
+// article text, so articles can be tagged when they are saved.
+package tags
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MaxTags is the most tags extracted from one article
+	MaxTags = 8
+
+	// minMentions is how often a name must be mentioned in the text to
+	// become a tag; names in the title always qualify
+	minMentions = 2
+
+	// maxPhraseWords bounds the length of a name
+	maxPhraseWords = 4
+)
+
+// particles may appear in lowercase inside names, e.g. "Matthijs de Ligt"
+var particles = map[string]bool{
+	"af": true, "da": true, "de": true, "del": true, "der": true, "di": true,
+	"dos": true, "van": true, "von": true,
+}
+
+// stopwords are capitalized at the start of sentences and in headings but
+// are not names. Swedish first, then English.
+var stopwords = toSet(`
+	alla allt andra att av bara de dem den denna dessa det detta dock där efter
+	eftersom ej eller en enligt ett för före genom han hans har hennes hon hur
+	här i igen inför inte jag kan kommer med men mot mycket män när nästa nu
+	och också om på sedan sin sina själv ska som så till under upp ut utan
+	vad var vi vid vilket vår åt även över
+	a after all also an and as at before but by for from he her his how i if in
+	into it its my next no not now of on or our she so than that the their then
+	there these they this to under we what when where which while who why with
+	you
+	januari februari mars april maj juni juli augusti september oktober november december
+	måndag tisdag onsdag torsdag fredag lördag söndag
+	january february march june july august october november december
+	monday tuesday wednesday thursday friday saturday sunday
+`)
+
+func toSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+// candidate is a name found in the article
+type candidate struct {
+	name  string
+	score int
+	first int // position of the first mention, to break ties
+}
+
+// Extract returns up to MaxTags names mentioned in the article, most
+// mentioned first. Names are runs of capitalized words (e.g. "Malmö FF",
+// "Anders Christiansen", "Allsvenskan"); words that only appear capitalized
+// at the start of a sentence are not names. A surname mentioned alone counts
+// towards the full name mentioned elsewhere.
+func Extract(title, text string) []string {
+	candidates := make(map[string]*candidate)
+	position := 0
+	add := func(phrases []string, weight int) {
+		for _, phrase := range phrases {
+			key := strings.ToLower(phrase)
+			c, ok := candidates[key]
+			if !ok {
+				c = &candidate{name: phrase, first: position}
+				candidates[key] = c
+			}
+			c.score += weight
+			position++
+		}
+	}
+	add(phrases(title), minMentions)
+	add(phrases(text), 1)
+
+	// Fold single words into the longest name containing them
+	for key, c := range candidates {
+		if strings.Contains(key, " ") {
+			continue
+		}
+		var longest *candidate
+		for other, o := range candidates {
+			if other != key && containsWord(other, key) && (longest == nil || len(o.name) > len(longest.name)) {
+				longest = o
+			}
+		}
+		if longest != nil {
+			longest.score += c.score
+			delete(candidates, key)
+		}
+	}
+
+	var ranked []*candidate
+	for _, c := range candidates {
+		if c.score >= minMentions {
+			ranked = append(ranked, c)
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].first < ranked[j].first
+	})
+
+	tags := make([]string, 0, min(len(ranked), MaxTags))
+	for _, c := range ranked[:min(len(ranked), MaxTags)] {
+		tags = append(tags, c.name)
+	}
+	return tags
+}
+
+// phrases returns the runs of capitalized words in text, leaving out
+// stopwords and single words starting a sentence
+func phrases(text string) []string {
+	var found, run []string
+	sentenceStart := true
+	runAtStart := false
+
+	flush := func() {
+		// Particles can't end a name
+		for len(run) > 0 && particles[run[len(run)-1]] {
+			run = run[:len(run)-1]
+		}
+		// Drop leading stopwords ("Men Malmö FF" is "Malmö FF")
+		for len(run) > 0 && stopwords[strings.ToLower(run[0])] {
+			run = run[1:]
+			runAtStart = false
+		}
+		if len(run) > 0 && len(run) <= maxPhraseWords && !(runAtStart && len(run) == 1) {
+			found = append(found, strings.Join(run, " "))
+		}
+		run = nil
+	}
+
+	for _, token := range strings.Fields(text) {
+		word := strings.TrimFunc(token, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		// Possessives: "Malmös" is left as is, "Rosengård's" loses the 's
+		word = strings.TrimSuffix(word, "'s")
+
+		switch {
+		case word == "":
+			flush()
+		case capitalized(word):
+			if len(run) == 0 {
+				runAtStart = sentenceStart
+			}
+			run = append(run, word)
+		case particles[word] && len(run) > 0:
+			run = append(run, word)
+		default:
+			flush()
+		}
+
+		// Punctuation after a word ends the name and maybe the sentence
+		if last, _ := utf8.DecodeLastRuneInString(token); !unicode.IsLetter(last) && !unicode.IsDigit(last) {
+			flush()
+		}
+		sentenceStart = word == "" && sentenceStart || strings.ContainsAny(token[len(token)-1:], ".!?:")
+	}
+	flush()
+	return found
+}
+
+// capitalized reports whether word starts with an uppercase letter
+func capitalized(word string) bool {
+	r, _ := utf8.DecodeRuneInString(word)
+	return unicode.IsUpper(r)
+}
+
+// containsWord reports whether phrase contains word as a whole word
+func containsWord(phrase, word string) bool {
+	for _, w := range strings.Fields(phrase) {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
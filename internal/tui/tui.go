@@ -0,0 +1,452 @@
+// Package tui is a terminal reader for the article archive, for reading
+// over SSH. It lists the articles with their read state, searches them like
+// the article list does and shows an article's text wrapped to the terminal.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tkilaker/kiln/internal/database"
+)
+
+const (
+	// maxArticles bounds the articles listed at a time, like the article
+	// list page
+	maxArticles = 500
+
+	// maxTextWidth keeps lines of article text readable on wide terminals
+	maxTextWidth = 100
+)
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true)
+	headerStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("208"))
+	selectedStyle = lipgloss.NewStyle().Reverse(true)
+	readStyle     = lipgloss.NewStyle().Faint(true)
+	helpStyle     = lipgloss.NewStyle().Faint(true)
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+// Run shows the reader until the user quits or ctx is cancelled
+func Run(ctx context.Context, db *database.DB) error {
+	program := tea.NewProgram(newModel(ctx, db), tea.WithAltScreen(), tea.WithContext(ctx))
+	_, err := program.Run()
+	return err
+}
+
+// view is the screen shown
+type view int
+
+const (
+	listView view = iota
+	searchView
+	articleView
+)
+
+// model is the state of the reader
+type model struct {
+	ctx context.Context
+	db  *database.DB
+
+	view   view
+	width  int
+	height int
+	err    error
+
+	filter   database.ArticleFilter
+	articles []*database.Article
+	read     map[int]bool
+	cursor   int // index of the selected article
+	offset   int // index of the first article on screen
+
+	search  textinput.Model
+	reader  viewport.Model
+	article *database.Article // the article open in the reader
+}
+
+// articlesMsg carries the articles matching the filter
+type articlesMsg struct {
+	articles []*database.Article
+	read     map[int]bool
+}
+
+// articleMsg carries an article with its content, opened in the reader
+type articleMsg struct {
+	article *database.Article
+}
+
+// readMsg reports that an article was marked read or unread
+type readMsg struct {
+	id   int
+	read bool
+}
+
+type errMsg struct{ err error }
+
+func newModel(ctx context.Context, db *database.DB) model {
+	search := textinput.New()
+	search.Prompt = "Search: "
+	search.Placeholder = `words, "phrases", -excluded`
+
+	return model{
+		ctx:    ctx,
+		db:     db,
+		read:   map[int]bool{},
+		search: search,
+		reader: viewport.New(0, 0),
+	}
+}
+
+// Init implements tea.Model
+func (m model) Init() tea.Cmd {
+	return m.loadArticles()
+}
+
+// loadArticles fetches the articles matching the filter with their read
+// state
+func (m model) loadArticles() tea.Cmd {
+	ctx, db, filter := m.ctx, m.db, m.filter
+	return func() tea.Msg {
+		articles, err := db.GetFilteredArticles(ctx, filter, maxArticles)
+		if err != nil {
+			return errMsg{err}
+		}
+		ids := make([]int, 0, len(articles))
+		for _, article := range articles {
+			ids = append(ids, article.ID)
+		}
+		read, err := db.GetReadArticleIDs(ctx, ids)
+		if err != nil {
+			return errMsg{err}
+		}
+		return articlesMsg{articles: articles, read: read}
+	}
+}
+
+// openArticle fetches an article with its content and marks it read, like
+// viewing it in the browser does
+func (m model) openArticle(id int) tea.Cmd {
+	ctx, db := m.ctx, m.db
+	return func() tea.Msg {
+		article, err := db.GetArticleByID(ctx, id)
+		if err != nil {
+			return errMsg{err}
+		}
+		if err := db.SetArticlesRead(ctx, []int{id}, true); err != nil {
+			return errMsg{err}
+		}
+		return articleMsg{article: article}
+	}
+}
+
+// setRead marks an article read or unread
+func (m model) setRead(id int, read bool) tea.Cmd {
+	ctx, db := m.ctx, m.db
+	return func() tea.Msg {
+		if err := db.SetArticlesRead(ctx, []int{id}, read); err != nil {
+			return errMsg{err}
+		}
+		return readMsg{id: id, read: read}
+	}
+}
+
+// Update implements tea.Model
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.reader.Width = min(msg.Width, maxTextWidth)
+		m.reader.Height = max(msg.Height-2, 1)
+		if m.article != nil {
+			m.reader.SetContent(m.renderArticle())
+		}
+		return m, nil
+
+	case articlesMsg:
+		m.articles, m.read, m.err = msg.articles, msg.read, nil
+		m.cursor, m.offset = 0, 0
+		return m, nil
+
+	case articleMsg:
+		m.article, m.err = msg.article, nil
+		m.read[msg.article.ID] = true
+		m.reader.SetContent(m.renderArticle())
+		m.reader.GotoTop()
+		m.view = articleView
+		return m, nil
+
+	case readMsg:
+		m.read[msg.id] = msg.read
+		return m, nil
+
+	case errMsg:
+		m.err = msg.err
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		switch m.view {
+		case searchView:
+			return m.updateSearch(msg)
+		case articleView:
+			return m.updateReader(msg)
+		default:
+			return m.updateList(msg)
+		}
+	}
+	return m, nil
+}
+
+func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "up", "k":
+		m.move(-1)
+	case "down", "j":
+		m.move(1)
+	case "pgup":
+		m.move(-m.listHeight())
+	case "pgdown", " ":
+		m.move(m.listHeight())
+	case "home", "g":
+		m.move(-len(m.articles))
+	case "end", "G":
+		m.move(len(m.articles))
+	case "enter":
+		if article := m.selected(); article != nil {
+			return m, m.openArticle(article.ID)
+		}
+	case "r":
+		if article := m.selected(); article != nil {
+			return m, m.setRead(article.ID, !m.read[article.ID])
+		}
+	case "u":
+		m.filter.Unread = !m.filter.Unread
+		return m, m.loadArticles()
+	case "/":
+		m.view = searchView
+		m.search.SetValue(m.filter.Query)
+		m.search.CursorEnd()
+		return m, m.search.Focus()
+	case "esc":
+		if m.filter.Query != "" {
+			m.filter.Query = ""
+			return m, m.loadArticles()
+		}
+	}
+	return m, nil
+}
+
+func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.view = listView
+		m.search.Blur()
+		m.filter.Query = strings.TrimSpace(m.search.Value())
+		return m, m.loadArticles()
+	case "esc":
+		m.view = listView
+		m.search.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.search, cmd = m.search.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateReader(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc", "backspace":
+		m.view = listView
+		m.article = nil
+		return m, nil
+	case "n":
+		if m.cursor < len(m.articles)-1 {
+			m.move(1)
+			return m, m.openArticle(m.articles[m.cursor].ID)
+		}
+		return m, nil
+	case "p":
+		if m.cursor > 0 {
+			m.move(-1)
+			return m, m.openArticle(m.articles[m.cursor].ID)
+		}
+		return m, nil
+	case "r":
+		return m, m.setRead(m.article.ID, !m.read[m.article.ID])
+	}
+
+	var cmd tea.Cmd
+	m.reader, cmd = m.reader.Update(msg)
+	return m, cmd
+}
+
+// move moves the selection by delta articles, scrolling the list to keep
+// it on screen
+func (m *model) move(delta int) {
+	m.cursor = max(0, min(m.cursor+delta, len(m.articles)-1))
+	height := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+}
+
+// selected returns the selected article, or nil when there are none
+func (m model) selected() *database.Article {
+	if m.cursor < len(m.articles) {
+		return m.articles[m.cursor]
+	}
+	return nil
+}
+
+// listHeight is the number of articles that fit between the header and
+// the help line
+func (m model) listHeight() int {
+	return max(m.height-3, 1)
+}
+
+// View implements tea.Model
+func (m model) View() string {
+	if m.width == 0 {
+		return ""
+	}
+	if m.view == articleView && m.article != nil {
+		return m.reader.View() + "\n" + m.footer("↑/↓ scroll · n/p next/previous · r read/unread · esc back")
+	}
+
+	var b strings.Builder
+	b.WriteString(m.header() + "\n")
+
+	if len(m.articles) == 0 {
+		b.WriteString(helpStyle.Render("No articles found.") + "\n")
+	}
+	end := min(m.offset+m.listHeight(), len(m.articles))
+	for i := m.offset; i < end; i++ {
+		b.WriteString(m.row(i) + "\n")
+	}
+	for i := end - m.offset; i < m.listHeight(); i++ {
+		b.WriteString("\n")
+	}
+
+	if m.view == searchView {
+		b.WriteString(m.search.View())
+	} else {
+		b.WriteString(m.footer("enter read · / search · u unread only · r read/unread · q quit"))
+	}
+	return b.String()
+}
+
+// header names the articles listed
+func (m model) header() string {
+	header := fmt.Sprintf("Kiln · %d articles", len(m.articles))
+	if m.filter.Query != "" {
+		header += fmt.Sprintf(" matching %q", m.filter.Query)
+	}
+	if m.filter.Unread {
+		header += " · unread"
+	}
+	return headerStyle.Render(truncate(header, m.width))
+}
+
+// row renders the article at index i of the list. Unread articles are
+// marked with a dot and read ones dimmed, like on the article list page.
+func (m model) row(i int) string {
+	article := m.articles[i]
+	marker := "•"
+	if m.read[article.ID] {
+		marker = " "
+	}
+
+	date := article.CreatedAt
+	if article.PublishedAt != nil {
+		date = *article.PublishedAt
+	}
+	line := fmt.Sprintf("%s %s  %-12s  %s", marker, date.Local().Format("2006-01-02"), truncate(article.Source, 12), title(article))
+	line = truncate(line, m.width)
+
+	switch {
+	case i == m.cursor:
+		return selectedStyle.Render(line)
+	case m.read[article.ID]:
+		return readStyle.Render(line)
+	}
+	return line
+}
+
+// footer shows the key help, or the last error
+func (m model) footer(help string) string {
+	if m.err != nil {
+		return errorStyle.Render(truncate("Error: "+m.err.Error(), m.width))
+	}
+	return helpStyle.Render(truncate(help, m.width))
+}
+
+// renderArticle renders the open article as text wrapped to the reader
+func (m model) renderArticle() string {
+	article := m.article
+	width := max(m.reader.Width, 20)
+	wrap := lipgloss.NewStyle().Width(width)
+
+	byline := []string{article.Source}
+	if article.Author != nil && *article.Author != "" {
+		byline = append(byline, *article.Author)
+	}
+	date := article.CreatedAt
+	if article.PublishedAt != nil {
+		date = *article.PublishedAt
+	}
+	byline = append(byline, date.Local().Format("2006-01-02 15:04"), fmt.Sprintf("%d min read", article.ReadingMinutes()))
+
+	var b strings.Builder
+	b.WriteString(wrap.Inherit(titleStyle).Render(title(article)) + "\n")
+	b.WriteString(wrap.Inherit(helpStyle).Render(strings.Join(byline, " · ")) + "\n")
+	b.WriteString(helpStyle.Render(truncate(article.URL, width)) + "\n\n")
+
+	text := ""
+	if article.ContentText != nil {
+		text = *article.ContentText
+	}
+	if strings.TrimSpace(text) == "" {
+		b.WriteString(helpStyle.Render("This article has no text."))
+		return b.String()
+	}
+	for _, paragraph := range strings.Split(text, "\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			b.WriteString(wrap.Render(paragraph) + "\n\n")
+		}
+	}
+	return b.String()
+}
+
+// title returns an article's title, or a placeholder when it has none
+func title(article *database.Article) string {
+	if article.Title == nil || *article.Title == "" {
+		return "Untitled Article"
+	}
+	return *article.Title
+}
+
+// truncate cuts s to width terminal cells, ending it with an ellipsis
+func truncate(s string, width int) string {
+	if width <= 0 || lipgloss.Width(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
@@ -0,0 +1,490 @@
+// Package vault reads secrets from HashiCorp Vault: the database URL or its
+// dynamic credentials, and source logins. Secrets with a lease are renewed
+// before they run out and read again once Vault stops extending them;
+// static ones (e.g. from a KV engine) are read again every few minutes.
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+
+	// staticRefresh is how long secrets without a lease are kept before
+	// being read again
+	staticRefresh = 5 * time.Minute
+
+	// checkInterval is how often Run looks for leases due for renewal
+	checkInterval = 30 * time.Second
+)
+
+// Options configures a client. Either Token or RoleID and SecretID (for
+// AppRole login) are required.
+type Options struct {
+	Addr      string
+	Token     string
+	Namespace string
+	RoleID    string
+	SecretID  string
+}
+
+// Client reads secrets from Vault, keeping its token and the leases of the
+// secrets read renewed
+type Client struct {
+	addr      string
+	namespace string
+	roleID    string
+	secretID  string
+	client    *http.Client
+
+	// mu guards the secrets as last read. It is only held to look them up
+	// or store them, never during a request, so reading a current secret
+	// doesn't wait for Vault. Stored secrets are not modified; a renewal
+	// or new read replaces them.
+	mu      sync.Mutex
+	secrets map[string]*secret
+
+	// requestMu serializes the requests to Vault, so a secret or token
+	// found due by several callers is renewed once, and guards the token
+	requestMu sync.Mutex
+	token     string
+	auth      lease // the token's own lease; zero when it isn't renewed
+
+	// tokenChecked records that the TTL of the configured token was
+	// looked up
+	tokenChecked bool
+}
+
+// lease tracks when a lease is due for renewal and when it runs out
+type lease struct {
+	id        string
+	renewable bool
+	ttl       time.Duration // as first granted, asked for on renewal
+	renewAt   time.Time
+	expires   time.Time
+}
+
+// secret is a secret as last read, by path
+type secret struct {
+	data map[string]any
+	lease
+}
+
+// New returns a client for the Vault server at opts.Addr. It makes no
+// requests until a secret is read.
+func New(opts Options) (*Client, error) {
+	if opts.Addr == "" {
+		return nil, fmt.Errorf("vault address is required")
+	}
+	if opts.Token == "" && (opts.RoleID == "" || opts.SecretID == "") {
+		return nil, fmt.Errorf("a vault token or an AppRole role ID and secret ID are required")
+	}
+	return &Client{
+		addr:      strings.TrimRight(opts.Addr, "/"),
+		namespace: opts.Namespace,
+		roleID:    opts.RoleID,
+		secretID:  opts.SecretID,
+		client:    &http.Client{Timeout: requestTimeout},
+		token:     opts.Token,
+		secrets:   make(map[string]*secret),
+	}, nil
+}
+
+// ParseRef splits a reference such as "vault:secret/data/kiln#url" into
+// its path and field. The field is empty when not given.
+func ParseRef(ref string) (path, field string, ok bool) {
+	rest, ok := strings.CutPrefix(ref, "vault:")
+	if !ok {
+		return "", "", false
+	}
+	path, field, _ = strings.Cut(rest, "#")
+	path = strings.Trim(path, "/")
+	return path, field, path != ""
+}
+
+// Read returns the data of the secret at path, from the last read while
+// its lease is current. For KV version 2 paths (secret/data/...) this is
+// the secret itself, without Vault's metadata.
+func (c *Client) Read(ctx context.Context, path string) (map[string]any, error) {
+	path = strings.Trim(path, "/")
+	if s := c.stored(path); s != nil && time.Now().Before(s.renewAt) {
+		return s.data, nil
+	}
+
+	c.requestMu.Lock()
+	defer c.requestMu.Unlock()
+
+	// Another caller may have renewed it while this one waited
+	s := c.stored(path)
+	if s != nil && time.Now().Before(s.renewAt) {
+		return s.data, nil
+	}
+	fresh, err := c.refresh(ctx, path, s)
+	if err != nil {
+		// A secret whose lease is still valid beats none at all
+		if s = c.stored(path); s != nil && (s.id == "" || time.Now().Before(s.expires)) {
+			logging.Warnf("Failed to read %s from vault, using it as last read: %v", path, err)
+			return s.data, nil
+		}
+		return nil, err
+	}
+	return fresh.data, nil
+}
+
+// stored returns the secret at path as last read, or nil
+func (c *Client) stored(path string) *secret {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.secrets[path]
+}
+
+// store keeps a secret as read or renewed
+func (c *Client) store(path string, s *secret) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.secrets[path] = s
+}
+
+// refresh renews the lease of s, the secret at path as last read (nil if
+// never), or reads the secret again when that isn't possible, and stores
+// the result. The caller holds requestMu.
+func (c *Client) refresh(ctx context.Context, path string, s *secret) (*secret, error) {
+	if s != nil && s.id != "" && s.renewable && time.Now().Before(s.expires) {
+		renewed, err := c.renew(ctx, s)
+		if renewed != nil {
+			c.store(path, renewed)
+		}
+		if err == nil {
+			return renewed, nil
+		}
+		if !errors.Is(err, errLeaseEnding) {
+			logging.Warnf("Failed to renew the vault lease for %s: %v", path, err)
+		}
+	}
+
+	fresh, err := c.read(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	c.store(path, fresh)
+	return fresh, nil
+}
+
+// Field returns a string field of the secret at path
+func (c *Client) Field(ctx context.Context, path, field string) (string, error) {
+	data, err := c.Read(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	value, ok := data[field].(string)
+	if !ok || value == "" {
+		return "", fmt.Errorf("vault secret %s has no field %q", path, field)
+	}
+	return value, nil
+}
+
+// Credentials returns the username and password fields of the secret at
+// path, as written by KV engines or generated by database engines
+func (c *Client) Credentials(ctx context.Context, path string) (string, string, error) {
+	username, err := c.Field(ctx, path, "username")
+	if err != nil {
+		return "", "", err
+	}
+	password, err := c.Field(ctx, path, "password")
+	if err != nil {
+		return "", "", err
+	}
+	return username, password, nil
+}
+
+// Run renews the token and the leases of the secrets read as they come
+// due, until ctx is cancelled, so they don't run out between reads. Leases
+// Vault no longer extends are replaced with a new read.
+func (c *Client) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.renewDue(ctx)
+		}
+	}
+}
+
+// renewDue renews the token and every lease past its renewal time
+func (c *Client) renewDue(ctx context.Context) {
+	c.requestMu.Lock()
+	defer c.requestMu.Unlock()
+
+	if err := c.ensureToken(ctx); err != nil {
+		logging.Warnf("Failed to renew the vault token: %v", err)
+	}
+
+	now := time.Now()
+	due := make(map[string]*secret)
+	c.mu.Lock()
+	for path, s := range c.secrets {
+		if s.id != "" && !now.Before(s.renewAt) {
+			due[path] = s
+		}
+	}
+	c.mu.Unlock()
+
+	for path, s := range due {
+		fresh, err := c.refresh(ctx, path, s)
+		if err != nil {
+			logging.Warnf("Failed to read %s from vault: %v", path, err)
+			continue
+		}
+		if fresh.id != s.id {
+			logging.Infof("Read %s from vault again as its lease was ending", path)
+		}
+	}
+}
+
+// errLeaseEnding is returned by renew when Vault extended a lease by less
+// than half of what was asked for: it is near its maximum TTL and should be
+// replaced
+var errLeaseEnding = errors.New("lease is near its maximum TTL")
+
+// renew extends a secret's lease, returning the secret with its new lease.
+// The secret is returned with errLeaseEnding too.
+func (c *Client) renew(ctx context.Context, s *secret) (*secret, error) {
+	resp, err := c.request(ctx, http.MethodPut, "sys/leases/renew", map[string]any{
+		"lease_id":  s.id,
+		"increment": int(s.ttl.Seconds()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	renewed := *s
+	granted := time.Duration(resp.LeaseDuration) * time.Second
+	renewed.renewable = resp.Renewable
+	renewed.schedule(granted)
+	if granted < s.ttl/2 {
+		return &renewed, errLeaseEnding
+	}
+	return &renewed, nil
+}
+
+// read fetches the secret at path
+func (c *Client) read(ctx context.Context, path string) (*secret, error) {
+	resp, err := c.request(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Data == nil {
+		return nil, fmt.Errorf("vault has no secret at %s", path)
+	}
+
+	data := resp.Data
+	// KV version 2 nests the secret beside its metadata
+	if inner, ok := data["data"].(map[string]any); ok {
+		if _, ok := data["metadata"]; ok {
+			data = inner
+		}
+	}
+
+	s := &secret{data: data}
+	if resp.LeaseID != "" && resp.LeaseDuration > 0 {
+		s.id = resp.LeaseID
+		s.renewable = resp.Renewable
+		s.ttl = time.Duration(resp.LeaseDuration) * time.Second
+		s.schedule(s.ttl)
+	} else {
+		s.schedule(staticRefresh)
+	}
+	return s, nil
+}
+
+// schedule sets a lease to be renewed two thirds of the way through ttl
+func (l *lease) schedule(ttl time.Duration) {
+	now := time.Now()
+	l.renewAt = now.Add(ttl * 2 / 3)
+	l.expires = now.Add(ttl)
+}
+
+// ensureToken logs in with AppRole when there is no token yet, and renews
+// the token when it is due. A token that can't be renewed is replaced by
+// logging in again, where AppRole is configured.
+func (c *Client) ensureToken(ctx context.Context) error {
+	if c.token == "" {
+		return c.login(ctx)
+	}
+	if c.auth.renewAt.IsZero() || time.Now().Before(c.auth.renewAt) {
+		return nil
+	}
+
+	var err error
+	if c.auth.renewable {
+		var resp *response
+		resp, err = c.do(ctx, http.MethodPost, "auth/token/renew-self", map[string]any{
+			"increment": int(c.auth.ttl.Seconds()),
+		})
+		if err == nil && resp.Auth != nil {
+			granted := time.Duration(resp.Auth.LeaseDuration) * time.Second
+			c.auth.schedule(granted)
+			if granted >= c.auth.ttl/2 {
+				return nil
+			}
+			err = errLeaseEnding
+		}
+	} else {
+		err = fmt.Errorf("the token is not renewable")
+	}
+	if c.roleID == "" {
+		// Keep trying until the token runs out; then Vault refuses it
+		if !time.Now().Before(c.auth.expires) {
+			c.auth = lease{}
+		}
+		return err
+	}
+	return c.login(ctx)
+}
+
+// login gets a token with the AppRole role and secret IDs
+func (c *Client) login(ctx context.Context) error {
+	if c.roleID == "" {
+		return fmt.Errorf("no vault token")
+	}
+	c.token = ""
+	resp, err := c.do(ctx, http.MethodPost, "auth/approle/login", map[string]any{
+		"role_id":   c.roleID,
+		"secret_id": c.secretID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to log in to vault: %w", err)
+	}
+	if resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return fmt.Errorf("failed to log in to vault: no token returned")
+	}
+	c.token = resp.Auth.ClientToken
+	c.auth = lease{renewable: resp.Auth.Renewable}
+	if resp.Auth.LeaseDuration > 0 {
+		c.auth.ttl = time.Duration(resp.Auth.LeaseDuration) * time.Second
+		c.auth.schedule(c.auth.ttl)
+	}
+	return nil
+}
+
+// lookupToken learns the TTL of a token given in the configuration, so a
+// renewable one is renewed in time
+func (c *Client) lookupToken(ctx context.Context) error {
+	resp, err := c.do(ctx, http.MethodGet, "auth/token/lookup-self", nil)
+	if err != nil {
+		return err
+	}
+	ttl, _ := resp.Data["ttl"].(float64)
+	renewable, _ := resp.Data["renewable"].(bool)
+	if ttl > 0 && renewable {
+		c.auth = lease{renewable: true, ttl: time.Duration(ttl) * time.Second}
+		c.auth.schedule(c.auth.ttl)
+	}
+	return nil
+}
+
+// response is the part of Vault's responses Kiln uses
+type response struct {
+	LeaseID       string         `json:"lease_id"`
+	Renewable     bool           `json:"renewable"`
+	LeaseDuration int            `json:"lease_duration"`
+	Data          map[string]any `json:"data"`
+	Auth          *struct {
+		ClientToken   string `json:"client_token"`
+		Renewable     bool   `json:"renewable"`
+		LeaseDuration int    `json:"lease_duration"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+// request makes an authenticated request, first getting or renewing the
+// token as needed. The caller holds requestMu, as for every function
+// making requests. A token Vault refuses is replaced once by logging in
+// again, where AppRole is configured.
+func (c *Client) request(ctx context.Context, method, path string, body any) (*response, error) {
+	if err := c.ensureToken(ctx); err != nil && c.token == "" {
+		return nil, err
+	}
+	if !c.tokenChecked && c.roleID == "" {
+		c.tokenChecked = true
+		if err := c.lookupToken(ctx); err != nil {
+			logging.Warnf("Failed to look up the vault token, so it won't be renewed: %v", err)
+		}
+	}
+
+	resp, err := c.do(ctx, method, path, body)
+	if errors.Is(err, errForbidden) && c.roleID != "" {
+		if err := c.login(ctx); err != nil {
+			return nil, err
+		}
+		resp, err = c.do(ctx, method, path, body)
+	}
+	return resp, err
+}
+
+// errForbidden is returned for requests Vault refused, as when the token
+// expired
+var errForbidden = errors.New("permission denied")
+
+// do sends a request to the Vault API
+func (c *Client) do(ctx context.Context, method, path string, body any) (*response, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.addr+"/v1/"+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("X-Vault-Token", c.token)
+	}
+	if c.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.namespace)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var decoded response
+	if resp.StatusCode == http.StatusNoContent {
+		return &decoded, nil
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&decoded); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("failed to decode vault response: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("vault %s %s: %w", method, path, errForbidden)
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("vault has no secret at %s", path)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("vault %s %s returned %s: %s", method, path, resp.Status, strings.Join(decoded.Errors, "; "))
+	}
+	return &decoded, nil
+}
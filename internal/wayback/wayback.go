@@ -0,0 +1,178 @@
+// Package wayback submits saved articles to the Internet Archive's Wayback
+// Machine (Save Page Now) as an off-site backup, and records the snapshots.
+package wayback
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tkilaker/kiln/internal/database"
+	"github.com/tkilaker/kiln/internal/logging"
+)
+
+const (
+	// saveURL is the Save Page Now (SPN2) endpoint
+	saveURL = "https://web.archive.org/save"
+
+	// snapshotURL is the prefix of archived snapshots
+	snapshotURL = "https://web.archive.org/web/"
+
+	// queueSize bounds the submissions waiting for the worker; more are
+	// dropped rather than slowing down the scrape
+	queueSize = 1000
+
+	// pollInterval and captureTimeout bound waiting for a capture
+	pollInterval   = 5 * time.Second
+	captureTimeout = 3 * time.Minute
+
+	// submitSpacing separates submissions to stay within the rate limits
+	submitSpacing = 10 * time.Second
+
+	requestTimeout = 30 * time.Second
+)
+
+// Submitter submits articles one at a time in the background
+type Submitter struct {
+	db        *database.DB
+	client    *http.Client
+	accessKey string
+	secretKey string
+	queue     chan submission
+}
+
+type submission struct {
+	articleID int
+	url       string
+}
+
+// New creates a submitter recording snapshots in db. The archive.org S3
+// keys raise the rate limits; both may be empty to submit anonymously.
+func New(db *database.DB, accessKey, secretKey string) *Submitter {
+	return &Submitter{
+		db:        db,
+		client:    &http.Client{Timeout: requestTimeout},
+		accessKey: accessKey,
+		secretKey: secretKey,
+		queue:     make(chan submission, queueSize),
+	}
+}
+
+// Submit queues an article for submission. It never blocks; articles that
+// don't fit the queue are skipped.
+func (s *Submitter) Submit(article *database.Article) {
+	select {
+	case s.queue <- submission{articleID: article.ID, url: article.URL}:
+	default:
+		logging.Warnf("Wayback queue is full, not submitting %s", article.URL)
+	}
+}
+
+// Run submits queued articles until ctx is cancelled
+func (s *Submitter) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sub := <-s.queue:
+			snapshot, err := s.capture(ctx, sub.url)
+			if err != nil {
+				logging.Warnf("Wayback Machine submission of %s failed: %v", sub.url, err)
+			} else if err := s.db.SetArticleWaybackURL(ctx, sub.articleID, snapshot); err != nil {
+				logging.Warnf("Failed to record Wayback snapshot of %s: %v", sub.url, err)
+			} else {
+				logging.Infof("Archived %s at %s", sub.url, snapshot)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(submitSpacing):
+			}
+		}
+	}
+}
+
+// capture asks Save Page Now to capture pageURL and waits for the snapshot
+func (s *Submitter) capture(ctx context.Context, pageURL string) (string, error) {
+	var job struct {
+		JobID   string `json:"job_id"`
+		Message string `json:"message"`
+	}
+	form := url.Values{"url": {pageURL}, "skip_first_archive": {"1"}}
+	if err := s.call(ctx, http.MethodPost, saveURL, form, &job); err != nil {
+		return "", err
+	}
+	if job.JobID == "" {
+		return "", fmt.Errorf("capture not started: %s", job.Message)
+	}
+
+	deadline := time.Now().Add(captureTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(pollInterval):
+		}
+
+		var status struct {
+			Status      string `json:"status"`
+			Timestamp   string `json:"timestamp"`
+			OriginalURL string `json:"original_url"`
+			Message     string `json:"message"`
+		}
+		if err := s.call(ctx, http.MethodGet, saveURL+"/status/"+url.PathEscape(job.JobID), nil, &status); err != nil {
+			return "", err
+		}
+		switch status.Status {
+		case "success":
+			original := status.OriginalURL
+			if original == "" {
+				original = pageURL
+			}
+			return snapshotURL + status.Timestamp + "/" + original, nil
+		case "error":
+			return "", fmt.Errorf("capture failed: %s", status.Message)
+		}
+	}
+	return "", fmt.Errorf("capture did not finish within %s", captureTimeout)
+}
+
+// call makes an API request, posting form when it is not nil, and decodes
+// the JSON response into v
+func (s *Submitter) call(ctx context.Context, method, endpoint string, form url.Values, v any) error {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if s.accessKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("LOW %s:%s", s.accessKey, s.secretKey))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+-- Scrape run history
+-- Records every scrape run so failures of scheduled or manual runs are visible
+
+CREATE TABLE IF NOT EXISTS scrape_runs (
+  id SERIAL PRIMARY KEY,
+  trigger TEXT NOT NULL DEFAULT 'manual',
+  status TEXT NOT NULL DEFAULT 'running',
+  started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  finished_at TIMESTAMP,
+  articles_found INTEGER NOT NULL DEFAULT 0,
+  articles_added INTEGER NOT NULL DEFAULT 0,
+  articles_skipped INTEGER NOT NULL DEFAULT 0,
+  articles_failed INTEGER NOT NULL DEFAULT 0,
+  error TEXT
+);
+
+-- Index on started_at for listing the most recent runs
+CREATE INDEX IF NOT EXISTS idx_scrape_runs_started_at ON scrape_runs(started_at DESC);
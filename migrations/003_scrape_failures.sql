@@ -0,0 +1,14 @@
+-- Per-article scrape failures
+-- Links every URL that failed during a run to that run, with the failing stage
+
+CREATE TABLE IF NOT EXISTS scrape_failures (
+  id SERIAL PRIMARY KEY,
+  run_id INTEGER NOT NULL REFERENCES scrape_runs(id) ON DELETE CASCADE,
+  url TEXT NOT NULL,
+  stage TEXT NOT NULL,
+  error TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Index on run_id for the run detail page
+CREATE INDEX IF NOT EXISTS idx_scrape_failures_run_id ON scrape_failures(run_id);
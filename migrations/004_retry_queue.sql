@@ -0,0 +1,15 @@
+-- Retry queue for articles that failed to scrape
+-- Pending URLs are attempted again by later runs until max attempts is reached
+
+CREATE TABLE IF NOT EXISTS retry_queue (
+  id SERIAL PRIMARY KEY,
+  url TEXT UNIQUE NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending',
+  attempts INTEGER NOT NULL DEFAULT 1,
+  last_error TEXT,
+  last_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Index on status for fetching pending retries
+CREATE INDEX IF NOT EXISTS idx_retry_queue_status ON retry_queue(status, last_attempt_at);
@@ -0,0 +1,7 @@
+-- Public UUID identifiers for articles
+-- Serial IDs stay as the internal key; URLs, feeds and share links use the UUID
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS uuid UUID NOT NULL DEFAULT gen_random_uuid();
+
+-- Unique index for looking up articles by UUID
+CREATE UNIQUE INDEX IF NOT EXISTS idx_articles_uuid ON articles(uuid);
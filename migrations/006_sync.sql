@@ -0,0 +1,25 @@
+-- Instance-to-instance sync
+-- Synced rows keep the updated_at of the instance that last changed them so
+-- that last-writer-wins comparisons converge instead of ping-ponging
+
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Only stamp the time when the statement did not set updated_at itself
+    IF NEW.updated_at IS NOT DISTINCT FROM OLD.updated_at THEN
+        NEW.updated_at = NOW();
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+-- Index for paging through changes in (updated_at, uuid) order
+CREATE INDEX IF NOT EXISTS idx_articles_updated_at_uuid ON articles(updated_at, uuid);
+
+-- Pull position per peer instance
+CREATE TABLE IF NOT EXISTS sync_peers (
+  peer TEXT PRIMARY KEY,
+  cursor TEXT NOT NULL DEFAULT '',
+  last_synced_at TIMESTAMP,
+  last_error TEXT
+);
@@ -0,0 +1,12 @@
+-- Duplicate detection
+-- Articles that repeat another article (e.g. syndicated posts) point to the
+-- original through duplicate_of and are hidden from lists and feeds
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS duplicate_of INTEGER REFERENCES articles(id) ON DELETE SET NULL;
+
+-- Normalized title used to find duplicate candidates
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS title_key TEXT
+  GENERATED ALWAYS AS (btrim(regexp_replace(lower(COALESCE(title, '')), '[^[:alnum:]]+', ' ', 'g'))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_articles_title_key ON articles(title_key);
+CREATE INDEX IF NOT EXISTS idx_articles_duplicate_of ON articles(duplicate_of) WHERE duplicate_of IS NOT NULL;
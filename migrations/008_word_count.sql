@@ -0,0 +1,8 @@
+-- Word count of content_text, used for reading time estimates
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS word_count INTEGER NOT NULL DEFAULT 0;
+
+-- Backfill existing articles
+UPDATE articles
+SET word_count = COALESCE(array_length(regexp_split_to_array(btrim(content_text), '\s+'), 1), 0)
+WHERE btrim(COALESCE(content_text, '')) <> '';
@@ -0,0 +1,3 @@
+-- Lead image (og:image or the first content image) shown on cards and in feeds
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS image_url TEXT;
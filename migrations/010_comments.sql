@@ -0,0 +1,14 @@
+-- Comment threads captured from article pages
+-- A refresh replaces an article's whole thread
+
+CREATE TABLE IF NOT EXISTS comments (
+  id SERIAL PRIMARY KEY,
+  article_id INTEGER NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+  position INTEGER NOT NULL,
+  author TEXT,
+  posted_at TIMESTAMP,
+  body TEXT NOT NULL,
+  fetched_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_comments_article_id ON comments(article_id, position);
@@ -0,0 +1,4 @@
+-- Articles whose extracted content looks like a paywall teaser
+-- Partial articles are kept (and retried) but left out of feeds
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS partial BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,4 @@
+-- Failure diagnostics
+-- Names the screenshot and HTML captured of the page a login or article failed on
+
+ALTER TABLE scrape_failures ADD COLUMN IF NOT EXISTS diagnostics TEXT;
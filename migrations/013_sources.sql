@@ -0,0 +1,19 @@
+-- Scraping sources
+-- Sources are managed at runtime from /sources; credentials are referenced,
+-- never stored
+
+CREATE TABLE IF NOT EXISTS sources (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL UNIQUE,
+  start_urls TEXT[] NOT NULL DEFAULT '{}',
+  credentials_ref TEXT,
+  schedule TEXT,
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Gasetten was the only (built-in) source before sources were configurable
+INSERT INTO sources (name, start_urls, credentials_ref)
+VALUES ('gasetten', ARRAY['https://gasetten.se/category/malmo-ff/'], 'env:GASETTEN')
+ON CONFLICT (name) DO NOTHING;
@@ -0,0 +1,12 @@
+-- Per-source schedules
+-- Sources can run on an interval as well as at daily times, stay quiet during
+-- part of the day and cap their scheduled runs per day. Runs record the source
+-- they were limited to (NULL for runs over every source).
+
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS scrape_interval TEXT;
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS quiet_hours TEXT;
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS max_runs_per_day INTEGER;
+
+ALTER TABLE scrape_runs ADD COLUMN IF NOT EXISTS source TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_scrape_runs_source ON scrape_runs(source, started_at DESC) WHERE source IS NOT NULL;
@@ -0,0 +1,10 @@
+-- Read state
+-- A row per read article, set by viewing it in the UI or TUI, the read
+-- toggle and the Google Reader API. Marking an article read or unread bumps
+-- articles.updated_at, so instance sync and exports carry it; viewing an
+-- article that is read already changes nothing
+
+CREATE TABLE IF NOT EXISTS article_reads (
+  article_id INTEGER PRIMARY KEY REFERENCES articles(id) ON DELETE CASCADE,
+  read_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
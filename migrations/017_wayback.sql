@@ -0,0 +1,4 @@
+-- Wayback Machine snapshots
+-- Set once an article has been submitted to web.archive.org and captured
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS wayback_url TEXT;
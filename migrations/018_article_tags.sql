@@ -0,0 +1,11 @@
+-- Article tags
+-- Keywords (players, opponents, competitions) extracted from an article's
+-- content when it is saved
+
+CREATE TABLE IF NOT EXISTS article_tags (
+  article_id INTEGER NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+  tag TEXT NOT NULL,
+  PRIMARY KEY (article_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_article_tags_tag ON article_tags(tag);
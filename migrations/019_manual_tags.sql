@@ -0,0 +1,4 @@
+-- Manual tags
+-- Tags added by hand are kept when an article's extracted tags are replaced
+
+ALTER TABLE article_tags ADD COLUMN IF NOT EXISTS manual BOOLEAN NOT NULL DEFAULT FALSE;
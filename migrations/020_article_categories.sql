@@ -0,0 +1,6 @@
+-- Source categories
+-- The categories or sections an article was filed under on its site (e.g.
+-- "Malmö FF", "Krönika"), as scraped; they are mapped to tags by the
+-- source's category_tags
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS categories TEXT[] NOT NULL DEFAULT '{}';
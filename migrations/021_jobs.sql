@@ -0,0 +1,22 @@
+-- Job queue
+-- Scrapes requested from the UI are queued here and processed by workers,
+-- so queued work survives restarts
+
+CREATE TABLE IF NOT EXISTS jobs (
+  id TEXT PRIMARY KEY,
+  kind TEXT NOT NULL,
+  label TEXT NOT NULL,
+  payload JSONB NOT NULL DEFAULT '{}',
+  status TEXT NOT NULL DEFAULT 'pending',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  started_at TIMESTAMPTZ,
+  finished_at TIMESTAMPTZ
+);
+
+-- Index on status for claiming the oldest pending job
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status, created_at);
+
+-- The same work is only queued or running once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_active_label ON jobs(label) WHERE status IN ('pending', 'running');
@@ -0,0 +1,4 @@
+-- Paused jobs
+-- A paused job idles between articles
+
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS paused BOOLEAN NOT NULL DEFAULT FALSE;
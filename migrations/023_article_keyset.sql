@@ -0,0 +1,4 @@
+-- Keyset pagination
+-- The articles API pages through articles newest first by (created_at, id)
+
+CREATE INDEX IF NOT EXISTS idx_articles_created_at_id ON articles(created_at DESC, id DESC);
@@ -0,0 +1,11 @@
+-- Indexes for the common article queries
+-- published_at, created_at and url (unique) are indexed since 001
+
+-- Per-source feeds and lists, newest first; covers lookups by source alone,
+-- so the single-column index is dropped
+CREATE INDEX IF NOT EXISTS idx_articles_source_published_at ON articles(source, published_at DESC);
+DROP INDEX IF EXISTS idx_articles_source;
+
+-- The article list orders originals by published date, falling back to the
+-- time the article was scraped
+CREATE INDEX IF NOT EXISTS idx_articles_article_date ON articles((COALESCE(published_at, created_at)) DESC) WHERE duplicate_of IS NULL;
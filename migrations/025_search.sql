@@ -0,0 +1,10 @@
+-- Article search
+-- Full-text search over titles and text, and trigram matching of titles to
+-- suggest articles when a search (e.g. a misspelled name) finds nothing
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_articles_search ON articles
+  USING GIN (to_tsvector('simple', COALESCE(title, '') || ' ' || COALESCE(content_text, '')));
+
+CREATE INDEX IF NOT EXISTS idx_articles_title_trgm ON articles USING GIN (title gin_trgm_ops);
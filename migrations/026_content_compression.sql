@@ -0,0 +1,6 @@
+-- Compressed article content, written instead of content_html and
+-- content_text when CONTENT_COMPRESSION is set. content_text then keeps only
+-- an excerpt for lists, feeds and search.
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS content_html_z BYTEA;
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS content_text_z BYTEA;
@@ -0,0 +1,35 @@
+-- Article content in its own table
+-- Lists, counts and feeds read only the articles table, so the content is
+-- joined in only where an article is shown in full. articles keeps an
+-- excerpt of the text for article cards and feed descriptions.
+
+CREATE TABLE IF NOT EXISTS article_content (
+  article_id INTEGER PRIMARY KEY REFERENCES articles(id) ON DELETE CASCADE,
+  content_html TEXT,
+  content_text TEXT,
+  content_html_z BYTEA,
+  content_text_z BYTEA
+);
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS excerpt TEXT;
+
+INSERT INTO article_content (article_id, content_html, content_text, content_html_z, content_text_z)
+SELECT id, content_html, content_text, content_html_z, content_text_z FROM articles
+ON CONFLICT (article_id) DO NOTHING;
+
+UPDATE articles SET excerpt = left(content_text, 1000) WHERE content_text IS NOT NULL;
+
+-- Titles and texts are now searched through an index each
+DROP INDEX IF EXISTS idx_articles_search;
+
+ALTER TABLE articles
+  DROP COLUMN IF EXISTS content_html,
+  DROP COLUMN IF EXISTS content_text,
+  DROP COLUMN IF EXISTS content_html_z,
+  DROP COLUMN IF EXISTS content_text_z;
+
+CREATE INDEX IF NOT EXISTS idx_articles_title_search ON articles
+  USING GIN (to_tsvector('simple', COALESCE(title, '')));
+
+CREATE INDEX IF NOT EXISTS idx_article_content_search ON article_content
+  USING GIN (to_tsvector('simple', COALESCE(content_text, '')));
@@ -0,0 +1,10 @@
+-- Feed access counters
+-- Requests to the RSS feeds and the Google Reader API per feed and day, for
+-- the stats API
+
+CREATE TABLE IF NOT EXISTS feed_access (
+  feed TEXT NOT NULL,
+  day DATE NOT NULL,
+  requests BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY (feed, day)
+);
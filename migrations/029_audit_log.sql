@@ -0,0 +1,17 @@
+-- Audit log
+-- Destructive operations and configuration changes: who did what, to what
+-- and when, with the details of what was changed or destroyed
+
+CREATE TABLE IF NOT EXISTS audit_log (
+  id BIGSERIAL PRIMARY KEY,
+  actor TEXT NOT NULL,
+  auth_method TEXT,
+  remote_addr TEXT,
+  action TEXT NOT NULL,
+  target TEXT,
+  details JSONB,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log (action, created_at DESC);
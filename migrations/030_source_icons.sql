@@ -0,0 +1,12 @@
+-- Source icons
+-- Each source's favicon, cached so article cards and feeds don't fetch it
+-- from the site. Sites without a usable icon have a row without data, so
+-- they are only asked again when the row expires.
+
+CREATE TABLE IF NOT EXISTS source_icons (
+  source TEXT PRIMARY KEY,
+  icon_url TEXT,
+  content_type TEXT,
+  data BYTEA,
+  fetched_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+-- Feed sources
+-- Sources with a feed URL discover their articles from an RSS or Atom feed
+-- instead of their start pages, and need no site profile
+
+ALTER TABLE sources ADD COLUMN IF NOT EXISTS feed_url TEXT;
@@ -0,0 +1,6 @@
+-- Article audio
+-- The podcast episode an article embeds, passed on to feeds as the item's
+-- enclosure
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS audio_url TEXT;
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS audio_type TEXT;
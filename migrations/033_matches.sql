@@ -0,0 +1,20 @@
+-- Match calendar
+-- Fixtures scraped from the sources' match schedule pages and served as an
+-- iCal feed. Upcoming matches are replaced on every scrape, so rescheduled
+-- and cancelled ones drop out; played ones are kept.
+
+CREATE TABLE IF NOT EXISTS matches (
+  id SERIAL PRIMARY KEY,
+  source TEXT NOT NULL,
+  kickoff TIMESTAMPTZ NOT NULL,
+  home_team TEXT NOT NULL,
+  away_team TEXT NOT NULL,
+  competition TEXT,
+  venue TEXT,
+  result TEXT,
+  url TEXT,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (source, kickoff, home_team, away_team)
+);
+
+CREATE INDEX IF NOT EXISTS idx_matches_kickoff ON matches(kickoff);
@@ -0,0 +1,11 @@
+-- Keyword alerts
+-- Words and names that trigger a notification as soon as a newly scraped
+-- article mentions them
+
+CREATE TABLE IF NOT EXISTS watch_terms (
+  id SERIAL PRIMARY KEY,
+  term TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_watch_terms_term ON watch_terms(LOWER(term));
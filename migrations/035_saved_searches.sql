@@ -0,0 +1,14 @@
+-- Saved searches
+-- Named article searches, each served as its own feed at
+-- /searches/<slug>.rss, .atom and .json
+
+CREATE TABLE IF NOT EXISTS saved_searches (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  slug TEXT NOT NULL UNIQUE,
+  query TEXT NOT NULL DEFAULT '',
+  source TEXT,
+  author TEXT,
+  tag TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
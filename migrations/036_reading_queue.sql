@@ -0,0 +1,10 @@
+-- Reading queue
+-- Articles put aside to read later, in the order they were added; kept
+-- apart from articles like the read state
+
+CREATE TABLE IF NOT EXISTS article_queue (
+  article_id INTEGER PRIMARY KEY REFERENCES articles(id) ON DELETE CASCADE,
+  added_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_article_queue_added_at ON article_queue(added_at DESC);
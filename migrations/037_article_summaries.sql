@@ -0,0 +1,5 @@
+-- Article summaries
+-- Written by a language model for the feed item descriptions when
+-- FEED_ITEM_CONTENT is summary
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS summary TEXT;
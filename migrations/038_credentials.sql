@@ -0,0 +1,12 @@
+-- Encrypted credentials
+-- Source usernames and passwords, sealed with AES-256-GCM under the
+-- credentials key (CREDENTIALS_KEY or CREDENTIALS_KEY_FILE). key_id names
+-- the key a row was sealed with, so a wrong key is reported as such.
+
+CREATE TABLE IF NOT EXISTS credentials (
+  name TEXT PRIMARY KEY,
+  key_id TEXT NOT NULL,
+  sealed BYTEA NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
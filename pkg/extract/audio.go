@@ -0,0 +1,112 @@
+package extract
+
+import (
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// audioTypes maps the extensions of podcast audio files to their MIME types
+var audioTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".m4a":  "audio/mp4",
+	".aac":  "audio/aac",
+	".ogg":  "audio/ogg",
+	".oga":  "audio/ogg",
+	".opus": "audio/ogg",
+	".wav":  "audio/wav",
+}
+
+// FindAudio returns the URL and MIME type of the podcast episode an article
+// embeds: the page's og:audio, else the first <audio> player on the page,
+// else the first link to an audio file in the extracted content. Players
+// that only embed an iframe (e.g. Spotify) have no audio URL to find.
+// Returns "" when there is none.
+func FindAudio(pageHTML, contentHTML string, base *url.URL) (string, string) {
+	var candidates [][2]string // URL and declared type
+
+	if doc, err := html.Parse(strings.NewReader(pageHTML)); err == nil {
+		var meta, players [][2]string
+		metaType := ""
+		walk(doc, func(n *html.Node) {
+			switch n.DataAtom {
+			case atom.Meta:
+				switch attr(n, "property") {
+				case "og:audio", "og:audio:url", "og:audio:secure_url":
+					meta = append(meta, [2]string{attr(n, "content"), ""})
+				case "og:audio:type":
+					metaType = attr(n, "content")
+				}
+			case atom.Audio:
+				players = append(players, [2]string{attr(n, "src"), ""})
+			case atom.Source:
+				if n.Parent != nil && n.Parent.DataAtom == atom.Audio {
+					players = append(players, [2]string{attr(n, "src"), attr(n, "type")})
+				}
+			}
+		})
+		for i := range meta {
+			meta[i][1] = metaType
+		}
+		candidates = append(append(candidates, meta...), players...)
+	}
+
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	if nodes, err := html.ParseFragment(strings.NewReader(contentHTML), body); err == nil {
+		for _, n := range nodes {
+			walk(n, func(n *html.Node) {
+				if n.DataAtom == atom.Audio {
+					candidates = append(candidates, [2]string{attr(n, "src"), ""})
+				}
+				if n.DataAtom == atom.Source && n.Parent != nil && n.Parent.DataAtom == atom.Audio {
+					candidates = append(candidates, [2]string{attr(n, "src"), attr(n, "type")})
+				}
+				if n.DataAtom == atom.A {
+					if href := attr(n, "href"); audioType(href) != "" {
+						candidates = append(candidates, [2]string{href, ""})
+					}
+				}
+			})
+		}
+	}
+
+	for _, candidate := range candidates {
+		raw := strings.TrimSpace(candidate[0])
+		if raw == "" {
+			continue
+		}
+		ref, err := url.Parse(raw)
+		if err != nil {
+			continue
+		}
+		resolved := base.ResolveReference(ref)
+		if resolved.Scheme != "http" && resolved.Scheme != "https" {
+			continue
+		}
+
+		contentType, _, _ := mime.ParseMediaType(candidate[1])
+		if !strings.HasPrefix(contentType, "audio/") {
+			contentType = audioType(resolved.String())
+		}
+		if contentType == "" {
+			// Players often stream from URLs without an extension
+			contentType = "audio/mpeg"
+		}
+		return resolved.String(), contentType
+	}
+	return "", ""
+}
+
+// audioType returns the MIME type of an audio file URL by its extension, or
+// "" when it doesn't look like one
+func audioType(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return audioTypes[strings.ToLower(path.Ext(u.Path))]
+}
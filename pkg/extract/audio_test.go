@@ -0,0 +1,72 @@
+package extract
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestFindAudio(t *testing.T) {
+	base, _ := url.Parse("https://example.com/podcast/episode-1")
+
+	tests := []struct {
+		name     string
+		page     string
+		content  string
+		wantURL  string
+		wantType string
+	}{
+		{
+			name:     "og:audio with its type",
+			page:     `<head><meta property="og:audio" content="https://cdn.example.com/ep1.m4a"><meta property="og:audio:type" content="audio/mp4"></head>`,
+			wantURL:  "https://cdn.example.com/ep1.m4a",
+			wantType: "audio/mp4",
+		},
+		{
+			name:     "og:audio before a player",
+			page:     `<head><meta property="og:audio" content="/ep1.mp3"></head><body><audio src="/player.ogg"></audio></body>`,
+			wantURL:  "https://example.com/ep1.mp3",
+			wantType: "audio/mpeg",
+		},
+		{
+			name:     "audio player source",
+			page:     `<body><audio controls><source src="ep1.ogg" type="audio/ogg; codecs=opus"></audio></body>`,
+			wantURL:  "https://example.com/podcast/ep1.ogg",
+			wantType: "audio/ogg",
+		},
+		{
+			name:     "stream without an extension",
+			page:     `<body><audio src="https://stream.example.com/listen/123"></audio></body>`,
+			wantURL:  "https://stream.example.com/listen/123",
+			wantType: "audio/mpeg",
+		},
+		{
+			name:     "link to an audio file in the content",
+			page:     `<body><p>No player</p></body>`,
+			content:  `<p><a href="/files/ep1.wav">Download</a></p>`,
+			wantURL:  "https://example.com/files/ep1.wav",
+			wantType: "audio/wav",
+		},
+		{
+			name:    "links to other files are ignored",
+			page:    `<body></body>`,
+			content: `<p><a href="/files/ep1.pdf">Transcript</a></p>`,
+		},
+		{
+			name: "iframe players have no audio URL",
+			page: `<body><iframe src="https://open.spotify.com/embed/episode/abc"></iframe></body>`,
+		},
+		{
+			name: "non-HTTP URLs are skipped",
+			page: `<body><audio src="data:audio/mpeg;base64,AAAA"></audio></body>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotURL, gotType := FindAudio(tt.page, tt.content, base)
+			if gotURL != tt.wantURL || gotType != tt.wantType {
+				t.Errorf("FindAudio() = %q, %q, want %q, %q", gotURL, gotType, tt.wantURL, tt.wantType)
+			}
+		})
+	}
+}
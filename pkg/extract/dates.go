@@ -0,0 +1,91 @@
+package extract
+
+import (
+	"strings"
+	"time"
+)
+
+// monthNames translates month names and their abbreviations into the
+// English names Go's time layouts understand, by locale. Weekday names map
+// to "" and are dropped.
+var monthNames = map[string]map[string]string{
+	"sv": {
+		"januari": "January", "jan": "Jan",
+		"februari": "February", "feb": "Feb",
+		"mars": "March", "mar": "Mar",
+		"april": "April", "apr": "Apr",
+		"maj":  "May",
+		"juni": "June", "jun": "Jun",
+		"juli": "July", "jul": "Jul",
+		"augusti": "August", "aug": "Aug",
+		"september": "September", "sep": "Sep", "sept": "Sep",
+		"oktober": "October", "okt": "Oct",
+		"november": "November", "nov": "Nov",
+		"december": "December", "dec": "Dec",
+
+		"måndag": "", "tisdag": "", "onsdag": "", "torsdag": "",
+		"fredag": "", "lördag": "", "söndag": "",
+		"mån": "", "tis": "", "ons": "", "tors": "", "fre": "", "lör": "", "sön": "",
+		"kl": "", // "9 november 2025 kl. 14.32"
+	},
+}
+
+// textDateFormats are the layouts tried on published dates written out as
+// text, after the source's own formats
+var textDateFormats = []string{
+	"2 January 2006 15:04", // "9 november, 2025 kl. 14.32"
+	"2 January 2006",       // "9 november, 2025"
+	"January 2 2006",
+	"2 Jan 2006 15:04",
+	"2 Jan 2006",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseDate parses a date written out as text in the given locale, trying
+// formats before the common layouts. Month names are translated with the
+// locale's table and names (e.g. a site's own abbreviations), commas and
+// weekdays are dropped and "14.32" is read as "14:32". Dates without a zone
+// are in time.Local.
+func ParseDate(text, locale string, names map[string]string, formats []string) (time.Time, bool) {
+	var words []string
+	for _, word := range strings.Fields(strings.ReplaceAll(text, ",", " ")) {
+		key := strings.TrimSuffix(strings.ToLower(word), ".")
+		english, ok := names[key]
+		if !ok {
+			english, ok = monthNames[locale][key]
+		}
+		switch {
+		case ok && english == "":
+			continue
+		case ok:
+			word = english
+		case isClockTime(word):
+			word = strings.Replace(word, ".", ":", 1)
+		}
+		words = append(words, strings.TrimSuffix(word, "."))
+	}
+	cleaned := strings.Join(words, " ")
+
+	for _, layouts := range [][]string{formats, textDateFormats} {
+		for _, layout := range layouts {
+			if t, err := time.ParseInLocation(layout, cleaned, time.Local); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// SupportedLocale reports whether ParseDate knows the month names of a
+// locale
+func SupportedLocale(locale string) bool {
+	return monthNames[locale] != nil
+}
+
+// isClockTime reports whether word looks like "14.32"
+func isClockTime(word string) bool {
+	hours, minutes, ok := strings.Cut(word, ".")
+	return ok && len(hours) >= 1 && len(hours) <= 2 && len(minutes) == 2 &&
+		strings.Trim(hours+minutes, "0123456789") == ""
+}
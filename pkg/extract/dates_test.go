@@ -0,0 +1,94 @@
+package extract
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		locale  string
+		names   map[string]string
+		formats []string
+		want    time.Time
+		wantOK  bool
+	}{
+		{
+			name:   "swedish date and time",
+			text:   "9 november 2025 kl. 14.32",
+			locale: "sv",
+			want:   time.Date(2025, 11, 9, 14, 32, 0, 0, time.Local),
+			wantOK: true,
+		},
+		{
+			name:   "weekday and comma dropped",
+			text:   "Söndag 9 november, 2025",
+			locale: "sv",
+			want:   time.Date(2025, 11, 9, 0, 0, 0, 0, time.Local),
+			wantOK: true,
+		},
+		{
+			name:   "abbreviated month",
+			text:   "3 okt. 2024 08.05",
+			locale: "sv",
+			want:   time.Date(2024, 10, 3, 8, 5, 0, 0, time.Local),
+			wantOK: true,
+		},
+		{
+			name:   "site names override the locale",
+			text:   "1 mej 2025",
+			locale: "sv",
+			names:  map[string]string{"mej": "May"},
+			want:   time.Date(2025, 5, 1, 0, 0, 0, 0, time.Local),
+			wantOK: true,
+		},
+		{
+			name:    "source formats first",
+			text:    "09/11/2025",
+			formats: []string{"02/01/2006"},
+			want:    time.Date(2025, 11, 9, 0, 0, 0, 0, time.Local),
+			wantOK:  true,
+		},
+		{
+			name:   "ISO date",
+			text:   "2025-11-09 14:32",
+			want:   time.Date(2025, 11, 9, 14, 32, 0, 0, time.Local),
+			wantOK: true,
+		},
+		{
+			name:   "english month without locale",
+			text:   "November 9 2025",
+			want:   time.Date(2025, 11, 9, 0, 0, 0, 0, time.Local),
+			wantOK: true,
+		},
+		{
+			name:   "unknown locale leaves names untranslated",
+			text:   "9 november 2025",
+			locale: "fi",
+			want:   time.Date(2025, 11, 9, 0, 0, 0, 0, time.Local),
+			wantOK: true,
+		},
+		{
+			name:   "not a date",
+			text:   "igår",
+			locale: "sv",
+		},
+		{
+			name: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseDate(tt.text, tt.locale, tt.names, tt.formats)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseDate(%q) ok = %v, want %v", tt.text, ok, tt.wantOK)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDate(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
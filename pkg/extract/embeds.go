@@ -0,0 +1,295 @@
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	// youtubeEmbedPath matches /embed/<video id> on YouTube hosts
+	youtubeEmbedPath = regexp.MustCompile(`^/embed/([A-Za-z0-9_-]{6,})`)
+
+	// spotifyEmbedPath matches /embed/<kind>/<id> on open.spotify.com
+	spotifyEmbedPath = regexp.MustCompile(`^/embed/(track|album|playlist|episode|show|artist)/([A-Za-z0-9]+)`)
+
+	// tweetURL matches links to a post on X (Twitter)
+	tweetURL = regexp.MustCompile(`^https?://(?:www\.|mobile\.)?(?:twitter|x)\.com/([A-Za-z0-9_]+)/status(?:es)?/([0-9]+)`)
+)
+
+// embed is a whitelisted embed found in the original page
+type embed struct {
+	key    string     // identifies the embedded item, e.g. "youtube:<id>"
+	block  *html.Node // sanitized replacement markup
+	anchor string     // text of the paragraph preceding the embed
+}
+
+// RestoreEmbeds re-inserts the YouTube, Spotify and X embeds of the original
+// page that readability dropped from the extracted content. Each embed is
+// rebuilt from its ID as a sanitized block and placed after the paragraph
+// that preceded it in the original, or at the end when that paragraph was
+// not kept.
+func RestoreEmbeds(pageHTML, contentHTML string, base *url.URL) (string, error) {
+	doc, err := html.Parse(strings.NewReader(pageHTML))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse page: %w", err)
+	}
+	embeds := findEmbeds(doc, base)
+	if len(embeds) == 0 {
+		return contentHTML, nil
+	}
+
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(contentHTML), body)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse content: %w", err)
+	}
+	root := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+
+	// Embeds readability kept are left alone. Kept tweets may have lost
+	// their class, so any link to a post counts.
+	present := make(map[string]bool)
+	paragraphs := make(map[string]*html.Node)
+	walk(root, func(n *html.Node) {
+		if e := parseEmbed(n, base); e != nil {
+			present[e.key] = true
+		}
+		if n.DataAtom == atom.A {
+			if m := tweetURL.FindStringSubmatch(attr(n, "href")); m != nil {
+				present["tweet:"+m[2]] = true
+			}
+		}
+		if n.DataAtom == atom.P {
+			if text := normalizedText(n); text != "" && paragraphs[text] == nil {
+				paragraphs[text] = n
+			}
+		}
+	})
+
+	// The readable content is usually wrapped in a single element; append
+	// unplaced embeds inside it
+	tail := root
+	if root.FirstChild != nil && root.FirstChild == root.LastChild && root.FirstChild.Type == html.ElementNode {
+		tail = root.FirstChild
+	}
+
+	lastInserted := make(map[*html.Node]*html.Node)
+	for _, e := range embeds {
+		if present[e.key] {
+			continue
+		}
+		present[e.key] = true
+
+		anchor := paragraphs[e.anchor]
+		if anchor == nil || anchor.Parent == nil {
+			tail.AppendChild(e.block)
+			continue
+		}
+		after := anchor
+		if prev := lastInserted[anchor]; prev != nil {
+			after = prev
+		}
+		anchor.Parent.InsertBefore(e.block, after.NextSibling)
+		lastInserted[anchor] = e.block
+	}
+
+	var buf bytes.Buffer
+	for n := root.FirstChild; n != nil; n = n.NextSibling {
+		if err := html.Render(&buf, n); err != nil {
+			return "", fmt.Errorf("failed to render content: %w", err)
+		}
+	}
+	return buf.String(), nil
+}
+
+// findEmbeds returns the whitelisted embeds in doc in document order
+func findEmbeds(doc *html.Node, base *url.URL) []embed {
+	var embeds []embed
+	seen := make(map[string]bool)
+	lastParagraph := ""
+
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		if e := parseEmbed(n, base); e != nil {
+			if !seen[e.key] {
+				seen[e.key] = true
+				e.anchor = lastParagraph
+				embeds = append(embeds, *e)
+			}
+			// Don't descend into embeds (tweets contain paragraphs)
+			return
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			visit(child)
+		}
+		if n.DataAtom == atom.P {
+			if text := normalizedText(n); text != "" {
+				lastParagraph = text
+			}
+		}
+	}
+	visit(doc)
+	return embeds
+}
+
+// parseEmbed recognizes a whitelisted embed element and rebuilds it
+func parseEmbed(n *html.Node, base *url.URL) *embed {
+	if n.Type != html.ElementNode {
+		return nil
+	}
+
+	switch n.DataAtom {
+	case atom.Iframe:
+		src := attr(n, "src")
+		if src == "" {
+			src = attr(n, "data-src")
+		}
+		ref, err := url.Parse(strings.TrimSpace(src))
+		if err != nil {
+			return nil
+		}
+		u := base.ResolveReference(ref)
+		host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+
+		switch host {
+		case "youtube.com", "youtube-nocookie.com":
+			if m := youtubeEmbedPath.FindStringSubmatch(u.Path); m != nil {
+				return &embed{key: "youtube:" + m[1], block: youtubeBlock(m[1])}
+			}
+		case "open.spotify.com":
+			if m := spotifyEmbedPath.FindStringSubmatch(u.Path); m != nil {
+				return &embed{key: "spotify:" + m[1] + "/" + m[2], block: spotifyBlock(m[1], m[2])}
+			}
+		case "platform.twitter.com":
+			if id := u.Query().Get("id"); id != "" && strings.HasPrefix(u.Path, "/embed/") {
+				return &embed{key: "tweet:" + id, block: tweetBlock("i", id, "")}
+			}
+		}
+
+	case atom.Blockquote:
+		if !hasClass(n, "twitter-tweet") {
+			return nil
+		}
+		// The permalink is the last status link in the blockquote
+		var user, id string
+		walk(n, func(c *html.Node) {
+			if c.DataAtom == atom.A {
+				if m := tweetURL.FindStringSubmatch(attr(c, "href")); m != nil {
+					user, id = m[1], m[2]
+				}
+			}
+		})
+		if id == "" {
+			return nil
+		}
+		text := ""
+		walk(n, func(c *html.Node) {
+			if c.DataAtom == atom.P && text == "" {
+				text = normalizedText(c)
+			}
+		})
+		return &embed{key: "tweet:" + id, block: tweetBlock(user, id, text)}
+	}
+	return nil
+}
+
+func youtubeBlock(id string) *html.Node {
+	return element("figure", []html.Attribute{{Key: "class", Val: "kiln-embed"}},
+		element("iframe", []html.Attribute{
+			{Key: "src", Val: "https://www.youtube-nocookie.com/embed/" + id},
+			{Key: "title", Val: "YouTube video"},
+			{Key: "width", Val: "560"},
+			{Key: "height", Val: "315"},
+			{Key: "loading", Val: "lazy"},
+			{Key: "allow", Val: "encrypted-media; picture-in-picture; fullscreen"},
+			{Key: "allowfullscreen", Val: ""},
+			{Key: "referrerpolicy", Val: "strict-origin-when-cross-origin"},
+		}),
+	)
+}
+
+func spotifyBlock(kind, id string) *html.Node {
+	height := "352"
+	if kind == "track" || kind == "episode" {
+		height = "152"
+	}
+	return element("figure", []html.Attribute{{Key: "class", Val: "kiln-embed"}},
+		element("iframe", []html.Attribute{
+			{Key: "src", Val: "https://open.spotify.com/embed/" + kind + "/" + id},
+			{Key: "title", Val: "Spotify"},
+			{Key: "width", Val: "100%"},
+			{Key: "height", Val: height},
+			{Key: "loading", Val: "lazy"},
+			{Key: "allow", Val: "encrypted-media"},
+		}),
+	)
+}
+
+// tweetBlock renders a post on X as a quote linking to it, without the
+// third-party widget script
+func tweetBlock(user, id, text string) *html.Node {
+	link := element("a", []html.Attribute{{Key: "href", Val: "https://x.com/" + user + "/status/" + id}},
+		&html.Node{Type: html.TextNode, Data: "View post on X"})
+
+	quote := element("blockquote", []html.Attribute{{Key: "class", Val: "kiln-embed"}})
+	if text != "" {
+		quote.AppendChild(element("p", nil, &html.Node{Type: html.TextNode, Data: text}))
+	}
+	quote.AppendChild(link)
+	return quote
+}
+
+// element creates an element node with the given attributes and children
+func element(tag string, attrs []html.Attribute, children ...*html.Node) *html.Node {
+	n := &html.Node{Type: html.ElementNode, Data: tag, DataAtom: atom.Lookup([]byte(tag)), Attr: attrs}
+	for _, child := range children {
+		n.AppendChild(child)
+	}
+	return n
+}
+
+// walk calls fn for n and every node below it
+func walk(n *html.Node, fn func(*html.Node)) {
+	fn(n)
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, fn)
+	}
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasClass(n *html.Node, class string) bool {
+	for _, c := range strings.Fields(attr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizedText returns the text below n with whitespace collapsed
+func normalizedText(n *html.Node) string {
+	var b strings.Builder
+	walk(n, func(c *html.Node) {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+			b.WriteByte(' ')
+		}
+	})
+	return strings.Join(strings.Fields(b.String()), " ")
+}
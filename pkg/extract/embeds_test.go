@@ -0,0 +1,98 @@
+package extract
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRestoreEmbeds(t *testing.T) {
+	base, _ := url.Parse("https://example.com/news/article")
+
+	tests := []struct {
+		name    string
+		page    string
+		content string
+		want    []string // in order
+		absent  []string
+	}{
+		{
+			name:    "no embeds",
+			page:    `<body><p>Intro</p></body>`,
+			content: `<div><p>Intro</p></div>`,
+			want:    []string{`<div><p>Intro</p></div>`},
+		},
+		{
+			name:    "youtube after its paragraph",
+			page:    `<body><p>Intro</p><iframe src="https://www.youtube.com/embed/dQw4w9WgXcQ?rel=0"></iframe><p>Outro</p></body>`,
+			content: `<div><p>Intro</p><p>Outro</p></div>`,
+			want: []string{
+				`<p>Intro</p><figure class="kiln-embed"><iframe src="https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ"`,
+				`<p>Outro</p>`,
+			},
+		},
+		{
+			name:    "lazy spotify episode at the end",
+			page:    `<body><p>Gone from the content</p><iframe data-src="https://open.spotify.com/embed/episode/4rOoJ6Egrf8K2IrywzwOMk"></iframe></body>`,
+			content: `<div><p>Kept</p></div>`,
+			want: []string{
+				`<p>Kept</p>`,
+				`src="https://open.spotify.com/embed/episode/4rOoJ6Egrf8K2IrywzwOMk"`,
+				`height="152"`,
+				`</figure></div>`,
+			},
+		},
+		{
+			name:    "tweet without the widget script",
+			page:    `<body><p>Intro</p><blockquote class="twitter-tweet"><p>Hello from X</p>&mdash; Someone <a href="https://twitter.com/someone/status/12345?ref_src=twsrc">November 9, 2025</a></blockquote><script src="https://platform.twitter.com/widgets.js"></script></body>`,
+			content: `<div><p>Intro</p></div>`,
+			want: []string{
+				`<p>Intro</p><blockquote class="kiln-embed"><p>Hello from X</p><a href="https://x.com/someone/status/12345">View post on X</a></blockquote>`,
+			},
+			absent: []string{`<script`},
+		},
+		{
+			name:    "embeds readability kept are left alone",
+			page:    `<body><p>Intro</p><blockquote class="twitter-tweet"><a href="https://x.com/someone/status/12345">link</a></blockquote></body>`,
+			content: `<div><p>Intro</p><blockquote><a href="https://x.com/someone/status/12345">link</a></blockquote></div>`,
+			want:    []string{`<div><p>Intro</p><blockquote><a href="https://x.com/someone/status/12345">link</a></blockquote></div>`},
+			absent:  []string{`kiln-embed`},
+		},
+		{
+			name:    "other iframes are not restored",
+			page:    `<body><p>Intro</p><iframe src="https://ads.example.net/embed/1"></iframe></body>`,
+			content: `<div><p>Intro</p></div>`,
+			want:    []string{`<div><p>Intro</p></div>`},
+			absent:  []string{`iframe`},
+		},
+		{
+			name:    "same video once",
+			page:    `<body><p>Intro</p><iframe src="https://www.youtube.com/embed/dQw4w9WgXcQ"></iframe><iframe src="https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ"></iframe></body>`,
+			content: `<div><p>Intro</p></div>`,
+			want:    []string{`<p>Intro</p><figure class="kiln-embed">`, `</figure></div>`},
+			absent:  []string{`</figure><figure`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RestoreEmbeds(tt.page, tt.content, base)
+			if err != nil {
+				t.Fatalf("RestoreEmbeds() error = %v", err)
+			}
+			rest := got
+			for _, want := range tt.want {
+				i := strings.Index(rest, want)
+				if i < 0 {
+					t.Fatalf("RestoreEmbeds() = %s, want %s in order", got, want)
+				}
+				rest = rest[i+len(want):]
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(got, absent) {
+					t.Errorf("RestoreEmbeds() = %s, should not contain %s", got, absent)
+				}
+			}
+		})
+	}
+}
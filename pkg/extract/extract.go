@@ -0,0 +1,78 @@
+// Package extract holds Kiln's article extraction pipeline: readability
+// extraction of a page with its embeds, absolute URLs, lead image, podcast
+// audio and date, and the parsers for the feeds and sitemaps articles are
+// discovered in. It works on HTML and XML already downloaded, so it can be
+// used without the scraper, browser or database.
+package extract
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	readability "github.com/go-shiori/go-readability"
+	"golang.org/x/net/html"
+)
+
+// Article is an article extracted from a page
+type Article struct {
+	Title       string
+	Byline      string
+	ContentHTML string
+	TextContent string
+	// PublishedAt is the date readability found in the page's metadata, or
+	// nil
+	PublishedAt *time.Time
+	// ImageURL is the absolute URL of the lead image, or ""
+	ImageURL string
+	// AudioURL and AudioType describe an embedded podcast episode, or are ""
+	AudioURL  string
+	AudioType string
+}
+
+// FromHTML extracts the article of a page. body, when not empty, is the
+// part of the page holding the article and is what readability reads;
+// pageHTML is the whole page, which embeds and audio are looked up in.
+// Embeds readability dropped are put back and relative URLs resolved
+// against pageURL; when either fails the content is kept as extracted.
+func FromHTML(pageHTML, body string, pageURL *url.URL) (*Article, error) {
+	readable := pageHTML
+	if body != "" {
+		readable = body
+	}
+	parsed, err := readability.FromReader(strings.NewReader(readable), pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse article with readability: %w", err)
+	}
+
+	article := &Article{
+		Title:       parsed.Title,
+		Byline:      parsed.Byline,
+		ContentHTML: parsed.Content,
+		TextContent: parsed.TextContent,
+		ImageURL:    leadImage(parsed, pageURL),
+	}
+	if parsed.PublishedTime != nil && !parsed.PublishedTime.IsZero() {
+		article.PublishedAt = parsed.PublishedTime
+	}
+
+	if content, err := RestoreEmbeds(pageHTML, article.ContentHTML, pageURL); err == nil {
+		article.ContentHTML = content
+	}
+	if content, err := AbsolutizeURLs(article.ContentHTML, pageURL); err == nil {
+		article.ContentHTML = content
+	}
+	article.AudioURL, article.AudioType = FindAudio(pageHTML, article.ContentHTML, pageURL)
+	return article, nil
+}
+
+// Text returns the text of an HTML fragment, with entities decoded and
+// whitespace collapsed
+func Text(fragment string) string {
+	doc, err := html.Parse(strings.NewReader(fragment))
+	if err != nil {
+		return ""
+	}
+	return normalizedText(doc)
+}
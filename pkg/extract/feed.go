@@ -0,0 +1,84 @@
+package extract
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// feedDocument is an RSS 2.0, RSS 1.0 (RDF) or Atom feed
+type feedDocument struct {
+	XMLName xml.Name
+	Channel struct {
+		Items []feedItem `xml:"item"`
+	} `xml:"channel"`
+	Items   []feedItem  `xml:"item"`  // RSS 1.0 items are outside the channel
+	Entries []feedEntry `xml:"entry"` // Atom
+}
+
+// feedItem is an RSS item. Items may also carry <atom:link> elements, which
+// match "link" too and have no text.
+type feedItem struct {
+	Links []string `xml:"link"`
+	GUID  struct {
+		Value       string `xml:",chardata"`
+		IsPermaLink string `xml:"isPermaLink,attr"`
+	} `xml:"guid"`
+}
+
+type feedEntry struct {
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+}
+
+// FeedLinks parses an RSS 2.0, RSS 1.0 (RDF) or Atom feed and returns the
+// links of its items in feed order. The links are as written in the feed;
+// they are not resolved or deduplicated.
+func FeedLinks(r io.Reader) ([]string, error) {
+	var doc feedDocument
+	decoder := xml.NewDecoder(r)
+	// Feeds in other encodings are rare; read them as UTF-8 rather than
+	// failing
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) { return input, nil }
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+	switch doc.XMLName.Local {
+	case "rss", "RDF", "feed":
+	default:
+		return nil, fmt.Errorf("not a feed: <%s>", doc.XMLName.Local)
+	}
+	return doc.links(), nil
+}
+
+// links returns the article links of the feed's items in feed order
+func (d *feedDocument) links() []string {
+	var links []string
+	for _, item := range append(d.Channel.Items, d.Items...) {
+		var link string
+		for _, l := range item.Links {
+			if link = strings.TrimSpace(l); link != "" {
+				break
+			}
+		}
+		// A GUID is a permalink unless it says otherwise
+		if link == "" && item.GUID.IsPermaLink != "false" {
+			link = strings.TrimSpace(item.GUID.Value)
+		}
+		if link != "" {
+			links = append(links, link)
+		}
+	}
+	for _, entry := range d.Entries {
+		for _, link := range entry.Links {
+			if link.Rel == "" || link.Rel == "alternate" {
+				links = append(links, strings.TrimSpace(link.Href))
+				break
+			}
+		}
+	}
+	return links
+}
@@ -0,0 +1,64 @@
+package extract
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFeedLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		xml     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "rss 2.0",
+			xml: `<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom"><channel>
+  <item><atom:link href="https://example.com/self" rel="self"/><link> https://example.com/a </link></item>
+  <item><guid>https://example.com/b</guid></item>
+  <item><guid isPermaLink="false">tag:example.com,2025:c</guid></item>
+</channel></rss>`,
+			want: []string{"https://example.com/a", "https://example.com/b"},
+		},
+		{
+			name: "rss 1.0",
+			xml: `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/">
+  <channel><link>https://example.com/</link></channel>
+  <item><link>https://example.com/a</link></item>
+</rdf:RDF>`,
+			want: []string{"https://example.com/a"},
+		},
+		{
+			name: "atom",
+			xml: `<feed xmlns="http://www.w3.org/2005/Atom">
+  <entry><link rel="self" href="https://example.com/a.atom"/><link href="https://example.com/a"/></entry>
+  <entry><link rel="alternate" href="https://example.com/b"/></entry>
+</feed>`,
+			want: []string{"https://example.com/a", "https://example.com/b"},
+		},
+		{
+			name: "other encodings read as UTF-8",
+			xml:  `<?xml version="1.0" encoding="ISO-8859-1"?><rss><channel><item><link>https://example.com/a</link></item></channel></rss>`,
+			want: []string{"https://example.com/a"},
+		},
+		{
+			name:    "not a feed",
+			xml:     `<urlset></urlset>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FeedLinks(strings.NewReader(tt.xml))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FeedLinks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FeedLinks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package extract
+
+import (
+	"net/url"
+	"strings"
+
+	readability "github.com/go-shiori/go-readability"
+	"golang.org/x/net/html"
+)
+
+// leadImage returns the article's hero image: the page's og:image (which
+// readability picks up from the meta tags) or else the first image in the
+// extracted content. Returns "" when the article has no usable image.
+func leadImage(article readability.Article, base *url.URL) string {
+	candidates := []string{article.Image}
+	if src := firstImage(article.Node); src != "" {
+		candidates = append(candidates, src)
+	}
+
+	for _, candidate := range candidates {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "" || strings.HasPrefix(candidate, "data:") {
+			continue
+		}
+		ref, err := url.Parse(candidate)
+		if err != nil {
+			continue
+		}
+		resolved := base.ResolveReference(ref)
+		if resolved.Scheme == "http" || resolved.Scheme == "https" {
+			return resolved.String()
+		}
+	}
+	return ""
+}
+
+// firstImage returns the src of the first <img> below node
+func firstImage(node *html.Node) string {
+	if node == nil {
+		return ""
+	}
+	if node.Type == html.ElementNode && node.Data == "img" {
+		for _, attr := range node.Attr {
+			if attr.Key == "src" && attr.Val != "" {
+				return attr.Val
+			}
+		}
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if src := firstImage(child); src != "" {
+			return src
+		}
+	}
+	return ""
+}
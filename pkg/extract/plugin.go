@@ -0,0 +1,131 @@
+package extract
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Plugin is an extractor run as a separate program, so site-specific
+// extraction can be written in any language. The program reads one
+// PluginRequest as JSON on stdin and writes one PluginResponse as JSON on
+// stdout; a non-zero exit status or an error in the response fails the
+// extraction. What it writes to stderr is included in the error.
+type Plugin struct {
+	// Command is the program and its arguments
+	Command []string
+	// Timeout bounds a run; 0 leaves it to the context
+	Timeout time.Duration
+}
+
+// PluginRequest is what an extractor plugin is given: the article page's
+// URL and HTML
+type PluginRequest struct {
+	URL  string `json:"url"`
+	HTML string `json:"html"`
+}
+
+// PluginResponse is what an extractor plugin returns. Fields it leaves
+// empty are left to the other extraction steps.
+type PluginResponse struct {
+	Title  string `json:"title,omitempty"`
+	Author string `json:"author,omitempty"`
+	// Published is an RFC 3339 date and time or a YYYY-MM-DD date
+	Published   string   `json:"published,omitempty"`
+	ContentHTML string   `json:"content_html,omitempty"`
+	Text        string   `json:"text,omitempty"`
+	ImageURL    string   `json:"image_url,omitempty"`
+	Categories  []string `json:"categories,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// maxPluginStderr bounds the stderr output quoted in errors
+const maxPluginStderr = 1024
+
+// Run runs the plugin on a page and returns its response, normalized
+func (p *Plugin) Run(ctx context.Context, pageHTML string, pageURL *url.URL) (*PluginResponse, error) {
+	if len(p.Command) == 0 {
+		return nil, fmt.Errorf("extractor plugin has no command")
+	}
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	input, err := json.Marshal(PluginRequest{URL: pageURL.String(), HTML: pageHTML})
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Command[0], p.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return nil, fmt.Errorf("extractor %s failed: %w%s", p.Command[0], err, stderrSuffix(stderr.String()))
+	}
+
+	var resp PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("extractor %s returned invalid JSON: %w", p.Command[0], err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("extractor %s: %s", p.Command[0], resp.Error)
+	}
+	if err := resp.Normalize(pageURL); err != nil {
+		return nil, fmt.Errorf("extractor %s: %w", p.Command[0], err)
+	}
+	return &resp, nil
+}
+
+// Normalize checks the published date, resolves relative URLs in the
+// content and image against pageURL, and derives the text from the content
+// when it is missing
+func (r *PluginResponse) Normalize(pageURL *url.URL) error {
+	if r.Published != "" {
+		if _, err := r.PublishedTime(); err != nil {
+			return fmt.Errorf("invalid published date %q", r.Published)
+		}
+	}
+	if r.ContentHTML != "" {
+		if content, err := AbsolutizeURLs(r.ContentHTML, pageURL); err == nil {
+			r.ContentHTML = content
+		}
+		if r.Text == "" {
+			r.Text = Text(r.ContentHTML)
+		}
+	}
+	if r.ImageURL != "" {
+		r.ImageURL = absoluteURL(r.ImageURL, pageURL)
+	}
+	return nil
+}
+
+// PublishedTime parses Published; dates without a time are local midnight
+func (r *PluginResponse) PublishedTime() (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, r.Published); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", r.Published, time.Local)
+}
+
+// stderrSuffix quotes the start of a plugin's stderr output for an error
+func stderrSuffix(stderr string) string {
+	stderr = strings.TrimSpace(stderr)
+	if stderr == "" {
+		return ""
+	}
+	if len(stderr) > maxPluginStderr {
+		stderr = stderr[:maxPluginStderr] + "..."
+	}
+	return ": " + stderr
+}
@@ -0,0 +1,82 @@
+package extract
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// lastmodFormats are the W3C datetime forms used for <lastmod>
+var lastmodFormats = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// Sitemap is a parsed sitemap: a sitemap index lists Sitemaps, a URL set
+// lists URLs
+type Sitemap struct {
+	Sitemaps []SitemapEntry
+	URLs     []SitemapEntry
+}
+
+// SitemapEntry is a sitemap or page listed in a sitemap. LastMod is zero
+// when the entry has no valid <lastmod>; dates without a zone are local.
+type SitemapEntry struct {
+	Loc     string
+	LastMod time.Time
+}
+
+// sitemapDocument is either a sitemap index or a URL set
+type sitemapDocument struct {
+	XMLName  xml.Name
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+	URLs     []sitemapEntry `xml:"url"`
+}
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	Lastmod string `xml:"lastmod"`
+}
+
+// ParseSitemap parses a sitemap index or URL set. r must already be
+// decompressed.
+func ParseSitemap(r io.Reader) (*Sitemap, error) {
+	var doc sitemapDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse sitemap: %w", err)
+	}
+	if doc.XMLName.Local != "sitemapindex" && doc.XMLName.Local != "urlset" {
+		return nil, fmt.Errorf("not a sitemap: <%s>", doc.XMLName.Local)
+	}
+	return &Sitemap{Sitemaps: sitemapEntries(doc.Sitemaps), URLs: sitemapEntries(doc.URLs)}, nil
+}
+
+func sitemapEntries(raw []sitemapEntry) []SitemapEntry {
+	entries := make([]SitemapEntry, 0, len(raw))
+	for _, entry := range raw {
+		entries = append(entries, SitemapEntry{
+			Loc:     strings.TrimSpace(entry.Loc),
+			LastMod: parseLastmod(entry.Lastmod),
+		})
+	}
+	return entries
+}
+
+// parseLastmod parses a <lastmod> value, returning the zero time when it is
+// missing or invalid
+func parseLastmod(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range lastmodFormats {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
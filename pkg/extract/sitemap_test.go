@@ -0,0 +1,79 @@
+package extract
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSitemap(t *testing.T) {
+	tests := []struct {
+		name    string
+		xml     string
+		want    *Sitemap
+		wantErr bool
+	}{
+		{
+			name: "url set",
+			xml: `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc> https://example.com/a </loc><lastmod>2025-11-09T14:32:00+01:00</lastmod></url>
+  <url><loc>https://example.com/b</loc><lastmod>2025-11-08</lastmod></url>
+  <url><loc>https://example.com/c</loc></url>
+  <url><loc>https://example.com/d</loc><lastmod>yesterday</lastmod></url>
+</urlset>`,
+			want: &Sitemap{
+				Sitemaps: []SitemapEntry{},
+				URLs: []SitemapEntry{
+					{Loc: "https://example.com/a", LastMod: time.Date(2025, 11, 9, 14, 32, 0, 0, time.FixedZone("", 3600))},
+					{Loc: "https://example.com/b", LastMod: time.Date(2025, 11, 8, 0, 0, 0, 0, time.Local)},
+					{Loc: "https://example.com/c"},
+					{Loc: "https://example.com/d"},
+				},
+			},
+		},
+		{
+			name: "sitemap index",
+			xml: `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://example.com/sitemap-1.xml</loc><lastmod>2025-11-09T14:32Z</lastmod></sitemap>
+</sitemapindex>`,
+			want: &Sitemap{
+				Sitemaps: []SitemapEntry{
+					{Loc: "https://example.com/sitemap-1.xml", LastMod: time.Date(2025, 11, 9, 14, 32, 0, 0, time.UTC)},
+				},
+				URLs: []SitemapEntry{},
+			},
+		},
+		{
+			name:    "not a sitemap",
+			xml:     `<rss version="2.0"><channel></channel></rss>`,
+			wantErr: true,
+		},
+		{
+			name:    "not XML",
+			xml:     `<html><body>Not found`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSitemap(strings.NewReader(tt.xml))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSitemap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got.Sitemaps) != len(tt.want.Sitemaps) || len(got.URLs) != len(tt.want.URLs) {
+				t.Fatalf("ParseSitemap() = %+v, want %+v", got, tt.want)
+			}
+			for i, entry := range append(got.Sitemaps, got.URLs...) {
+				want := append(tt.want.Sitemaps, tt.want.URLs...)[i]
+				if entry.Loc != want.Loc || !entry.LastMod.Equal(want.LastMod) {
+					t.Errorf("entry %d = %+v, want %+v", i, entry, want)
+				}
+			}
+		})
+	}
+}
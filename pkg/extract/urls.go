@@ -0,0 +1,90 @@
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// urlAttributes are the attributes holding a single URL
+var urlAttributes = map[string]bool{
+	"href":       true,
+	"src":        true,
+	"poster":     true,
+	"cite":       true,
+	"data":       true,
+	"action":     true,
+	"background": true,
+}
+
+// AbsolutizeURLs rewrites every relative URL in contentHTML against base,
+// so links and images keep working outside the original site. Readability already
+// fixes the common cases; this also covers srcset lists, iframes, embeds and
+// anything else carrying a URL attribute. Links to fragments within the
+// article are left alone.
+func AbsolutizeURLs(contentHTML string, base *url.URL) (string, error) {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(contentHTML), body)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse content: %w", err)
+	}
+
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			for i, attr := range n.Attr {
+				switch {
+				case urlAttributes[attr.Key]:
+					n.Attr[i].Val = absoluteURL(attr.Val, base)
+				case attr.Key == "srcset":
+					n.Attr[i].Val = absoluteSrcset(attr.Val, base)
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			visit(child)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, n := range nodes {
+		visit(n)
+		if err := html.Render(&buf, n); err != nil {
+			return "", fmt.Errorf("failed to render content: %w", err)
+		}
+	}
+	return buf.String(), nil
+}
+
+// absoluteURL resolves raw against base, leaving fragment links and
+// unparseable values unchanged
+func absoluteURL(raw string, base *url.URL) string {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return raw
+	}
+	ref, err := url.Parse(trimmed)
+	if err != nil {
+		return raw
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// absoluteSrcset resolves each candidate URL in a srcset list, keeping its
+// width or density descriptor
+func absoluteSrcset(srcset string, base *url.URL) string {
+	candidates := strings.Split(srcset, ",")
+	for i, candidate := range candidates {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		fields[0] = absoluteURL(fields[0], base)
+		candidates[i] = strings.Join(fields, " ")
+	}
+	return strings.Join(candidates, ", ")
+}
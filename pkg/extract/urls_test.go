@@ -0,0 +1,69 @@
+package extract
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestAbsolutizeURLs(t *testing.T) {
+	base, _ := url.Parse("https://example.com/news/article.html")
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "relative link",
+			content: `<p><a href="other.html">Other</a></p>`,
+			want:    `<p><a href="https://example.com/news/other.html">Other</a></p>`,
+		},
+		{
+			name:    "root-relative image",
+			content: `<img src="/media/lead.jpg"/>`,
+			want:    `<img src="https://example.com/media/lead.jpg"/>`,
+		},
+		{
+			name:    "protocol-relative iframe",
+			content: `<iframe src="//player.example.org/embed/1"></iframe>`,
+			want:    `<iframe src="https://player.example.org/embed/1"></iframe>`,
+		},
+		{
+			name:    "srcset keeps descriptors",
+			content: `<img srcset="small.jpg 480w, /large.jpg 1080w"/>`,
+			want:    `<img srcset="https://example.com/news/small.jpg 480w, https://example.com/large.jpg 1080w"/>`,
+		},
+		{
+			name:    "fragment links are left alone",
+			content: `<a href="#note-1">1</a>`,
+			want:    `<a href="#note-1">1</a>`,
+		},
+		{
+			name:    "absolute URLs are kept",
+			content: `<a href="https://other.example/page">x</a>`,
+			want:    `<a href="https://other.example/page">x</a>`,
+		},
+		{
+			name:    "video poster and source",
+			content: `<video poster="poster.jpg"><source src="clip.mp4"/></video>`,
+			want:    `<video poster="https://example.com/news/poster.jpg"><source src="https://example.com/news/clip.mp4"/></video>`,
+		},
+		{
+			name:    "other attributes untouched",
+			content: `<p title="a/b" class="x">Text</p>`,
+			want:    `<p title="a/b" class="x">Text</p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AbsolutizeURLs(tt.content, base)
+			if err != nil {
+				t.Fatalf("AbsolutizeURLs() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("AbsolutizeURLs() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
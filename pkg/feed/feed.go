@@ -0,0 +1,193 @@
+// Package feed builds the RSS 2.0, Atom and JSON Feed documents Kiln
+// serves. RSS feeds carry the itunes and media RSS extensions, so podcast
+// apps play embedded episodes and readers show thumbnails, and any number
+// of categories per item and channel.
+package feed
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gorilla/feeds"
+)
+
+// Feed formats
+const (
+	RSS  = "rss"
+	Atom = "atom"
+	JSON = "json"
+)
+
+// ContentTypes are the response types of the feed formats
+var ContentTypes = map[string]string{
+	RSS:  "application/rss+xml; charset=utf-8",
+	Atom: "application/atom+xml; charset=utf-8",
+	JSON: "application/feed+json; charset=utf-8",
+}
+
+// Feed is a feed to generate
+type Feed struct {
+	Title       string
+	Link        string
+	Description string
+	Author      string
+	// Created is the feed's date; the zero time stands for now
+	Created    time.Time
+	Categories []string // of the RSS channel
+	Items      []*Item
+}
+
+// Item is an entry of a feed
+type Item struct {
+	ID          string
+	Title       string
+	Link        string
+	Description string
+	Content     string // HTML, or "" to leave it out
+	Author      string
+	Published   time.Time
+
+	// ImageURL is the lead image, attached as the enclosure and the
+	// thumbnail. Thumbnail is the thumbnail of items without one, e.g. the
+	// source's icon.
+	ImageURL  string
+	Thumbnail string
+
+	// AudioURL is a podcast episode, which takes the enclosure over the
+	// image so podcast apps play it. AudioType defaults to audio/mpeg.
+	AudioURL  string
+	AudioType string
+
+	// Duration is the itunes duration, e.g. the reading time; 0 leaves it
+	// out
+	Duration time.Duration
+
+	// Categories are RSS categories and JSON Feed tags
+	Categories []string
+
+	// Source names the item's source in RSS feeds, for feeds of several
+	// sources
+	Source *Source
+}
+
+// Source is the feed an item comes from
+type Source struct {
+	Name string
+	URL  string
+}
+
+// Generate renders a feed in format, one of RSS, Atom and JSON (JSON
+// Feed). Only RSS feeds carry the itunes and media extensions.
+func Generate(format string, f *Feed) (string, error) {
+	switch format {
+	case RSS:
+		rss, err := feeds.ToXML(newExtendedRss(f))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate RSS: %w", err)
+		}
+		return rss, nil
+	case Atom:
+		atom, err := toFeeds(f).ToAtom()
+		if err != nil {
+			return "", fmt.Errorf("failed to generate Atom: %w", err)
+		}
+		return atom, nil
+	case JSON:
+		jsonFeed := (&feeds.JSON{Feed: toFeeds(f)}).JSONFeed()
+		for i, item := range jsonFeed.Items {
+			item.Tags = f.Items[i].Categories
+		}
+		json, err := jsonFeed.ToJSON()
+		if err != nil {
+			return "", fmt.Errorf("failed to generate JSON feed: %w", err)
+		}
+		return json, nil
+	}
+	return "", fmt.Errorf("unsupported feed format %q", format)
+}
+
+// toFeeds converts a feed to the gorilla/feeds model
+func toFeeds(f *Feed) *feeds.Feed {
+	created := f.Created
+	if created.IsZero() {
+		created = time.Now()
+	}
+	feed := &feeds.Feed{
+		Title:       f.Title,
+		Link:        &feeds.Link{Href: f.Link},
+		Description: f.Description,
+		Author:      &feeds.Author{Name: f.Author},
+		Created:     created,
+		Items:       make([]*feeds.Item, 0, len(f.Items)),
+	}
+
+	for _, it := range f.Items {
+		item := &feeds.Item{
+			Title:       it.Title,
+			Link:        &feeds.Link{Href: it.Link},
+			Id:          it.ID,
+			Description: it.Description,
+			Content:     it.Content,
+			Created:     it.Published,
+		}
+		if it.Author != "" {
+			item.Author = &feeds.Author{Name: it.Author}
+		}
+		if it.ImageURL != "" {
+			item.Enclosure = &feeds.Enclosure{Url: it.ImageURL, Length: "0", Type: ImageType(it.ImageURL)}
+		}
+		if it.AudioURL != "" {
+			audioType := it.AudioType
+			if audioType == "" {
+				audioType = "audio/mpeg"
+			}
+			item.Enclosure = &feeds.Enclosure{Url: it.AudioURL, Length: "0", Type: audioType}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed
+}
+
+// ellipsis marks where Truncate cut the text
+const ellipsis = "..."
+
+// Truncate shortens text to at most maxLen characters, the ellipsis marking
+// the cut included, cutting at the last word that fits. A maxLen of zero or
+// less gives "", and one too short for the ellipsis a plain cut.
+func Truncate(text string, maxLen int) string {
+	if maxLen <= 0 {
+		return ""
+	}
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) <= maxLen {
+		return text
+	}
+	if maxLen <= len(ellipsis) {
+		return string([]rune(text)[:maxLen])
+	}
+	cut := string([]rune(text)[:maxLen-len(ellipsis)])
+
+	// Keep a word cut in half only when it is all there is
+	if i := strings.LastIndexFunc(cut, unicode.IsSpace); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRightFunc(cut, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + ellipsis
+}
+
+// ImageType guesses an image's MIME type from its URL, defaulting to JPEG
+func ImageType(imageURL string) string {
+	if u, err := url.Parse(imageURL); err == nil {
+		if t := mime.TypeByExtension(strings.ToLower(path.Ext(u.Path))); strings.HasPrefix(t, "image/") {
+			return t
+		}
+	}
+	return "image/jpeg"
+}
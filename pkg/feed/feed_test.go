@@ -0,0 +1,119 @@
+package feed
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		maxLen int
+		want   string
+	}{
+		{"short text is kept", "Hello world", 20, "Hello world"},
+		{"exact length is kept", "Hello world", 11, "Hello world"},
+		{"whitespace is trimmed", "  Hello world \n", 11, "Hello world"},
+		{"cut at a word", "The quick brown fox jumps", 15, "The quick..."},
+		{"trailing punctuation dropped", "Hello, world and more", 12, "Hello..."},
+		{"single long word is cut", "Supercalifragilistic", 10, "Superca..."},
+		{"characters, not bytes", "Åsa åker över ån", 12, "Åsa åker..."},
+		{"too short for the ellipsis", "Hello world", 3, "Hel"},
+		{"zero", "Hello world", 0, ""},
+		{"negative", "Hello world", -5, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Truncate(tt.text, tt.maxLen)
+			if got != tt.want {
+				t.Errorf("Truncate(%q, %d) = %q, want %q", tt.text, tt.maxLen, got, tt.want)
+			}
+			if n := utf8.RuneCountInString(got); n > max(tt.maxLen, 0) {
+				t.Errorf("Truncate(%q, %d) is %d characters long", tt.text, tt.maxLen, n)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	published := time.Date(2025, 11, 9, 14, 32, 0, 0, time.UTC)
+	f := &Feed{
+		Title:       "Kiln",
+		Link:        "https://kiln.example.com/",
+		Description: "Saved articles",
+		Created:     published,
+		Categories:  []string{"news"},
+		Items: []*Item{
+			{
+				ID:         "urn:uuid:1",
+				Title:      "First article",
+				Link:       "https://example.com/first",
+				Published:  published,
+				ImageURL:   "https://example.com/lead.png",
+				Duration:   4 * time.Minute,
+				Categories: []string{"AIK", "fotboll"},
+				Source:     &Source{Name: "Example", URL: "https://example.com/feed"},
+			},
+			{
+				ID:        "urn:uuid:2",
+				Title:     "Episode",
+				Link:      "https://example.com/episode",
+				Published: published,
+				AudioURL:  "https://example.com/episode.mp3",
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		want   []string
+	}{
+		{"rss", RSS, []string{
+			`<rss version="2.0"`,
+			`xmlns:itunes=`,
+			`<title>First article</title>`,
+			`<category>news</category>`,
+			`<category>AIK</category>`,
+			`<category>fotboll</category>`,
+			`<itunes:duration>0:04:00</itunes:duration>`,
+			`<media:thumbnail url="https://example.com/lead.png">`,
+			`<source url="https://example.com/feed">Example</source>`,
+			`url="https://example.com/episode.mp3" length="0" type="audio/mpeg"`,
+		}},
+		{"atom", Atom, []string{
+			`<feed xmlns="http://www.w3.org/2005/Atom"`,
+			`<title>First article</title>`,
+			`<id>urn:uuid:1</id>`,
+			`href="https://example.com/episode.mp3"`,
+		}},
+		{"json", JSON, []string{
+			`"version": "https://jsonfeed.org/version/1`,
+			`"title": "First article"`,
+			`"tags": [`,
+			`"AIK"`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Generate(tt.format, f)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Generate() output lacks %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+
+	if _, err := Generate("yaml", f); err == nil {
+		t.Error("Generate() of an unknown format succeeded")
+	}
+}
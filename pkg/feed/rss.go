@@ -0,0 +1,111 @@
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+// extendedRss renders a feed like feeds.Rss, adding elements from the
+// itunes and media RSS extensions to each item, and any number of
+// categories to the channel and items
+type extendedRss struct {
+	feed       *feeds.Feed
+	extras     []rssItemExtras // per item, in feed.Items order
+	categories []string        // of the channel
+}
+
+func newExtendedRss(f *Feed) *extendedRss {
+	extras := make([]rssItemExtras, 0, len(f.Items))
+	for _, item := range f.Items {
+		extra := rssItemExtras{Duration: itunesDuration(item.Duration), categories: item.Categories}
+		if thumbnail := item.ImageURL; thumbnail != "" || item.Thumbnail != "" {
+			if thumbnail == "" {
+				thumbnail = item.Thumbnail
+			}
+			extra.Thumbnail = &mediaThumbnail{URL: thumbnail}
+		}
+		if item.Source != nil {
+			extra.source = &rssSource{URL: item.Source.URL, Name: item.Source.Name}
+		}
+		extras = append(extras, extra)
+	}
+	return &extendedRss{feed: toFeeds(f), extras: extras, categories: f.Categories}
+}
+
+// rssItemExtras holds the extension elements of one item
+type rssItemExtras struct {
+	Duration   string          `xml:"itunes:duration,omitempty"` // reading time
+	Thumbnail  *mediaThumbnail // lead image, or the source's icon
+	source     *rssSource      // set on items of multi-source feeds
+	categories []string        // the article's tags
+}
+
+// rssSource is an item's <source> element: the source's name and feed
+type rssSource struct {
+	XMLName xml.Name `xml:"source"`
+	URL     string   `xml:"url,attr"`
+	Name    string   `xml:",chardata"`
+}
+
+type mediaThumbnail struct {
+	XMLName xml.Name `xml:"media:thumbnail"`
+	URL     string   `xml:"url,attr"`
+}
+
+// rssDocument mirrors feeds.RssFeedXml with the extension namespaces declared
+type rssDocument struct {
+	XMLName          xml.Name `xml:"rss"`
+	Version          string   `xml:"version,attr"`
+	ContentNamespace string   `xml:"xmlns:content,attr"`
+	ITunesNamespace  string   `xml:"xmlns:itunes,attr"`
+	MediaNamespace   string   `xml:"xmlns:media,attr"`
+	Channel          *rssChannel
+}
+
+// rssChannel replaces the channel's items with rssItems, and its single
+// category with a list
+type rssChannel struct {
+	*feeds.RssFeed
+	Categories []string   `xml:"category"`
+	Items      []*rssItem `xml:"item"`
+}
+
+// rssItem adds the extension elements to an item. Source replaces the
+// RssItem's, which has no url attribute, and Categories its single
+// category.
+type rssItem struct {
+	*feeds.RssItem
+	rssItemExtras
+	Source     *rssSource
+	Categories []string `xml:"category"`
+}
+
+// FeedXml implements feeds.XmlFeed
+func (r *extendedRss) FeedXml() interface{} {
+	channel := (&feeds.Rss{Feed: r.feed}).RssFeed()
+	items := make([]*rssItem, len(channel.Items))
+	for i, item := range channel.Items {
+		items[i] = &rssItem{RssItem: item, rssItemExtras: r.extras[i], Source: r.extras[i].source, Categories: r.extras[i].categories}
+	}
+	channel.Items = nil
+
+	return &rssDocument{
+		Version:          "2.0",
+		ContentNamespace: "http://purl.org/rss/1.0/modules/content/",
+		ITunesNamespace:  "http://www.itunes.com/dtds/podcast-1.0.dtd",
+		MediaNamespace:   "http://search.yahoo.com/mrss/",
+		Channel:          &rssChannel{RssFeed: channel, Categories: r.categories, Items: items},
+	}
+}
+
+// itunesDuration formats a duration as H:MM:SS, or "" when it is 0
+func itunesDuration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	seconds := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}